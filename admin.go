@@ -0,0 +1,86 @@
+package passforge
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// EncoderStatus describes the configuration of an encoder for the admin handler.
+// It never contains secrets: peppers and server keys are reported by version or not at all.
+type EncoderStatus struct {
+	Name           string                   `json:"name"`
+	Params         map[string]interface{}   `json:"params,omitempty"`
+	Default        string                   `json:"default,omitempty"`         // Default ID or tier of delegating and tiered encoders
+	PepperVersions []string                 `json:"pepper_versions,omitempty"` // Configured pepper versions, current first
+	Limiter        *LimiterStats            `json:"limiter,omitempty"`
+	CalibrationMS  float64                  `json:"calibration_ms,omitempty"` // Duration of one Encode when calibration is enabled
+	Encoders       map[string]EncoderStatus `json:"encoders,omitempty"`       // Nested encoders by ID, tier or role
+}
+
+// StatusReporter is implemented by encoders that describe their configuration to the AdminHandler.
+// Encoders wrapping others report them in Encoders, built with describe.
+type StatusReporter interface {
+	AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus
+}
+
+// AdminHandler is an http.Handler reporting the hashing configuration as JSON: algorithms and their
+// parameters, pepper versions, limiter counters and, optionally, calibration timings.
+// It is meant for internal admin ports only and must not be exposed publicly.
+type AdminHandler struct {
+	Encoder   PasswordEncoder
+	Calibrate bool // Time one Encode per algorithm on every request
+}
+
+// AdminOption is a functional option used to configure an AdminHandler instance.
+type AdminOption func(*AdminHandler)
+
+// WithAdminCalibration makes every request time one Encode per algorithm.
+// Each request then costs as much as a login per configured algorithm.
+func WithAdminCalibration() AdminOption {
+	return func(a *AdminHandler) {
+		a.Calibrate = true
+	}
+}
+
+// NewAdminHandler creates an AdminHandler describing encoder
+func NewAdminHandler(encoder PasswordEncoder, opts ...AdminOption) *AdminHandler {
+	handler := &AdminHandler{Encoder: encoder}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
+}
+
+// ServeHTTP responds to GET requests with the EncoderStatus of the configured encoder
+func (a *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(a.Status())
+}
+
+// Status describes the configured encoder
+func (a *AdminHandler) Status() EncoderStatus {
+	return a.describe(a.Encoder)
+}
+
+// describe builds the status of an encoder, recursing into wrapping encoders through their AdminStatus
+func (a *AdminHandler) describe(encoder PasswordEncoder) EncoderStatus {
+	var status EncoderStatus
+	if reporter, ok := encoder.(StatusReporter); ok {
+		status = reporter.AdminStatus(a.describe)
+	}
+	status.Name = encoder.Name()
+
+	if a.Calibrate && status.Encoders == nil {
+		start := time.Now()
+		if _, err := encoder.Encode("passforge-calibration"); err == nil {
+			status.CalibrationMS = float64(time.Since(start).Microseconds()) / 1000
+		}
+	}
+	return status
+}
@@ -0,0 +1,85 @@
+package passforge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	argon2 := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	peppered, _ := NewPepperedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), "v2", bytes.Repeat([]byte{2}, 32),
+		WithPepper("v1", bytes.Repeat([]byte{1}, 32)))
+	delegating, _ := NewDelegatingPasswordEncoder("argon2", argon2, peppered)
+	limited := NewLimitedPasswordEncoder(delegating, NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(1)))
+
+	// One allowed failure, then the limiter blocks
+	ctx := ContextWithAttempt(context.Background(), Attempt{User: "alice"})
+	encoded, _ := limited.Encode("password123")
+	_, _ = limited.VerifyContext(ctx, "wrong", encoded)
+	_, _ = limited.VerifyContext(ctx, "wrong", encoded)
+
+	handler := NewAdminHandler(limited, WithAdminCalibration())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/hashing", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("ServeHTTP() = %v %v", recorder.Code, recorder.Header())
+	}
+	body := recorder.Body.String()
+	if strings.Contains(body, "AgICAgIC") || strings.Contains(body, "0202020202") {
+		t.Errorf("ServeHTTP() leaked pepper key material: %s", body)
+	}
+
+	var status EncoderStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if status.Limiter == nil || status.Limiter.Allowed != 1 || status.Limiter.Blocked != 1 {
+		t.Errorf("limiter = %+v, want 1 allowed and 1 blocked", status.Limiter)
+	}
+
+	inner := status.Encoders["inner"]
+	if inner.Name != "delegating" || inner.Default != "argon2" {
+		t.Errorf("inner = %+v, want delegating with argon2 default", inner)
+	}
+	argon2Status := inner.Encoders["argon2"]
+	if argon2Status.Params["memory"] != float64(1024) || argon2Status.CalibrationMS <= 0 {
+		t.Errorf("argon2 = %+v, want parameters and calibration", argon2Status)
+	}
+	pepperStatus := inner.Encoders["peppered"]
+	if strings.Join(pepperStatus.PepperVersions, ",") != "v2,v1" || pepperStatus.Encoders["inner"].Params["cost"] != float64(4) {
+		t.Errorf("peppered = %+v, want versions v2,v1 and bcrypt cost", pepperStatus)
+	}
+}
+
+func TestAdminHandler_MethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewAdminHandler(NewNoOpPasswordEncoder()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP() = %v, want %v", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+// auditedEncoder is a third-party wrapper reporting itself and its inner encoder
+type auditedEncoder struct {
+	PasswordEncoder
+}
+
+func (a *auditedEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"audited": true}, Encoders: map[string]EncoderStatus{"inner": describe(a.PasswordEncoder)}}
+}
+
+func TestAdminHandler_StatusReporter(t *testing.T) {
+	status := NewAdminHandler(&auditedEncoder{NewBcryptPasswordEncoder(WithCost(4))}, WithAdminCalibration()).Status()
+	if status.Name != "bcrypt" || status.Params["audited"] != true || status.CalibrationMS != 0 {
+		t.Errorf("Status() = %+v, want the audited wrapper without calibration", status)
+	}
+	if inner := status.Encoders["inner"]; inner.Params["cost"] != 4 || inner.CalibrationMS <= 0 {
+		t.Errorf("inner = %+v, want bcrypt cost and calibration", inner)
+	}
+}
@@ -1,22 +1,93 @@
 package passforge
 
 import (
-	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
+
+	forkedargon2 "github.com/nduyhai/passforge/internal/argon2"
+)
+
+// ErrArgon2Version is returned for Argon2 hashes of a version other than 19 (0x13)
+var ErrArgon2Version = errors.New("unsupported argon2 version")
+
+// Argon2Variant selects the Argon2 function
+type Argon2Variant int
+
+const (
+	// Argon2id is the hybrid variant recommended for password hashing
+	Argon2id Argon2Variant = iota
+	// Argon2i uses data-independent memory access; only for compatibility with systems that used it
+	Argon2i
+	// Argon2d uses data-dependent memory access, maximizing GPU resistance. It is vulnerable to
+	// side-channel attacks and only suitable where attackers can't observe the hashing machine.
+	Argon2d
 )
 
-// Argon2PasswordEncoder is a password encoder that uses the Argon2id algorithm
+// String returns the name of the variant as written in encoded passwords
+func (v Argon2Variant) String() string {
+	switch v {
+	case Argon2i:
+		return "argon2i"
+	case Argon2d:
+		return "argon2d"
+	}
+	return "argon2id"
+}
+
+// key derives a key with the variant and the optional secret and associated data
+func (v Argon2Variant) key(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	// golang.org/x/crypto/argon2 supports neither Argon2d nor secret and associated data
+	if v == Argon2d || len(secret) > 0 || len(data) > 0 {
+		mode := forkedargon2.Argon2id
+		switch v {
+		case Argon2i:
+			mode = forkedargon2.Argon2i
+		case Argon2d:
+			mode = forkedargon2.Argon2d
+		}
+		return forkedargon2.DeriveKey(mode, password, salt, secret, data, time, memory, threads, keyLen)
+	}
+	if v == Argon2i {
+		return argon2.Key(password, salt, time, memory, threads, keyLen)
+	}
+	return argon2.IDKey(password, salt, time, memory, threads, keyLen)
+}
+
+// parseArgon2Variant parses the name of a variant
+func parseArgon2Variant(name string) (Argon2Variant, bool) {
+	switch name {
+	case "argon2id":
+		return Argon2id, true
+	case "argon2i":
+		return Argon2i, true
+	case "argon2d":
+		return Argon2d, true
+	}
+	return 0, false
+}
+
+// Argon2PasswordEncoder is a password encoder that uses the Argon2id algorithm, or Argon2i when configured
 type Argon2PasswordEncoder struct {
-	Time    uint32 // Number of iterations
-	Memory  uint32 // Memory usage in KiB
-	Threads uint8  // Number of threads
-	KeyLen  uint32 // Length of the derived key
-	SaltLen uint32 // Length of the salt
+	Time    uint32        // Number of iterations
+	Memory  uint32        // Memory usage in KiB
+	Threads uint8         // Number of threads
+	KeyLen  uint32        // Length of the derived key
+	SaltLen uint32        // Length of the salt
+	Variant Argon2Variant // Argon2 function, Argon2id by default
+	PHC     bool          // Encode in the PHC string format instead of the passforge format
+	Rand    io.Reader     // Source of salts, crypto/rand.Reader when nil
+
+	Secret         []byte // Optional secret key K of the keyed variant, never written to the output
+	AssociatedData []byte // Optional associated data X, never written to the output
 }
 
 // Argon2Option is a function that configures an Argon2PasswordEncoder
@@ -63,6 +134,23 @@ func WithArgon2Threads(threads uint8) Argon2Option {
 	}
 }
 
+// availableCPUs returns the number of CPUs the process may use
+var availableCPUs = func() int { return runtime.GOMAXPROCS(0) }
+
+// WithArgon2AutoThreads sets the number of threads to the CPUs available to the process, capped at
+// maxThreads (0 for no cap beyond 255). It uses GOMAXPROCS, which follows runtime.NumCPU and, since
+// Go 1.25, container CPU limits. The thread count is recorded in every hash, so hashes created on
+// machines of different sizes still verify everywhere.
+func WithArgon2AutoThreads(maxThreads uint8) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		threads := min(availableCPUs(), math.MaxUint8)
+		if maxThreads > 0 {
+			threads = min(threads, int(maxThreads))
+		}
+		a.Threads = uint8(max(threads, 1))
+	}
+}
+
 // WithArgon2KeyLen sets the length of the derived key
 // Recommended minimum: 16
 // Recommended maximum: 2^32-1
@@ -93,6 +181,69 @@ func WithArgon2SaltLen(saltLen uint32) Argon2Option {
 	}
 }
 
+// WithArgon2Variant sets the Argon2 function
+// Default: Argon2id
+// Argon2i and Argon2d hashes are written with a ",variant=..." parameter so Verify recomputes them with that variant;
+// Argon2id output keeps the original format.
+func WithArgon2Variant(variant Argon2Variant) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.Variant = variant
+	}
+}
+
+// WithArgon2PHC encodes in the standard PHC string format, $argon2id$v=19$m=MEMORY,t=TIME,p=THREADS$SALT$HASH,
+// as produced and accepted by libsodium, PHP password_hash, argon2-cffi and the reference implementation.
+// Verify accepts both formats regardless of this option.
+func WithArgon2PHC() Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.PHC = true
+	}
+}
+
+// WithArgon2Spring encodes exactly like Spring Security's Argon2PasswordEncoder.defaultsForSpringSecurity_v5_8():
+// Argon2id PHC strings with m=16384,t=2,p=1, a 16-byte salt and a 32-byte hash, base64 in the standard alphabet
+// without padding. Under a DelegatingPasswordEncoder with the "argon2" ID, hashes stored as {argon2}$argon2id$...
+// by Spring verify unchanged, including those of the older defaults (m=4096,t=3), since parameters are read from
+// the hash. Options given after this one override its parameters.
+func WithArgon2Spring() Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.Variant = Argon2id
+		a.PHC = true
+		a.Memory = 1 << 14
+		a.Time = 2
+		a.Threads = 1
+		a.SaltLen = 16
+		a.KeyLen = 32
+	}
+}
+
+// WithArgon2Secret sets the secret key K of the Argon2 specification, binding hashes to a server-side
+// secret: without it a leaked hash can't be attacked offline. The secret is not recorded in the output,
+// so every encoder verifying these hashes needs the same secret; hashes can't be moved to another secret
+// without the password.
+func WithArgon2Secret(secret []byte) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.Secret = secret
+	}
+}
+
+// WithArgon2AssociatedData sets the associated data X of the Argon2 specification, binding hashes to a
+// context such as an application or tenant ID. Like the secret it is not recorded in the output.
+func WithArgon2AssociatedData(data []byte) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.AssociatedData = data
+	}
+}
+
+// WithArgon2Rand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithArgon2Rand(r io.Reader) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.Rand = r
+	}
+}
+
 // NewArgon2PasswordEncoder creates a new Argon2PasswordEncoder with default parameters if not specified
 func NewArgon2PasswordEncoder(opts ...Argon2Option) *Argon2PasswordEncoder {
 	// Set default values if not provided
@@ -109,60 +260,318 @@ func NewArgon2PasswordEncoder(opts ...Argon2Option) *Argon2PasswordEncoder {
 	return encoder
 }
 
-// Encode hashes the raw password using Argon2id
+// Encode hashes the raw password using the configured variant
 func (a *Argon2PasswordEncoder) Encode(rawPassword string) (string, error) {
 	// Generate random salt
 	salt := make([]byte, a.SaltLen)
-	_, err := rand.Read(salt)
+	_, err := io.ReadFull(randReader(a.Rand), salt)
 	if err != nil {
 		return "", err
 	}
+	return a.encode(rawPassword, salt), nil
+}
 
-	// Hash the password with Argon2id
-	hash := argon2.IDKey([]byte(rawPassword), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+// EncodeWithSalt hashes the raw password with the given salt instead of a random one, producing
+// deterministic output for golden tests and cross-language test vectors. Never use it for stored
+// passwords: a fixed salt lets identical passwords be recognized and attacked together.
+func (a *Argon2PasswordEncoder) EncodeWithSalt(rawPassword string, salt []byte) (string, error) {
+	if len(salt) < minSaltLen {
+		return "", fmt.Errorf("argon2: salt must be at least %d bytes", minSaltLen)
+	}
+	return a.encode(rawPassword, salt), nil
+}
 
-	// Format: time=TIME,memory=MEMORY,threads=THREADS,keyLen=KEYLEN$BASE64_SALT$BASE64_HASH
+// encode hashes the raw password with the salt and formats the result
+func (a *Argon2PasswordEncoder) encode(rawPassword string, salt []byte) string {
+	hash := a.Variant.key([]byte(rawPassword), salt, a.Secret, a.AssociatedData, a.Time, a.Memory, a.Threads, a.KeyLen)
+	if a.PHC {
+		return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", a.Variant, argon2.Version, a.Memory, a.Time, a.Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+	}
+
+	// Format: time=TIME,memory=MEMORY,threads=THREADS,keyLen=KEYLEN,v=VERSION[,variant=VARIANT]$BASE64_SALT$BASE64_HASH
 	// This format allows us to retrieve the parameters when verifying
 	encodedSalt := base64.StdEncoding.EncodeToString(salt)
 	encodedHash := base64.StdEncoding.EncodeToString(hash)
 
-	return fmt.Sprintf("time=%d,memory=%d,threads=%d,keyLen=%d$%s$%s",
-		a.Time, a.Memory, a.Threads, a.KeyLen, encodedSalt, encodedHash), nil
+	params := fmt.Sprintf("time=%d,memory=%d,threads=%d,keyLen=%d,v=%d", a.Time, a.Memory, a.Threads, a.KeyLen, argon2.Version)
+	if a.Variant != Argon2id {
+		params += ",variant=" + a.Variant.String()
+	}
+	return params + "$" + encodedSalt + "$" + encodedHash
+}
+
+// EncodeWith hashes the raw password with the options applied for this call only, e.g. more memory for admins
+func (a *Argon2PasswordEncoder) EncodeWith(rawPassword string, opts ...Argon2Option) (string, error) {
+	encoder := *a
+	for _, opt := range opts {
+		opt(&encoder)
+	}
+	return encoder.Encode(rawPassword)
+}
+
+// DeriveKey derives a key from the password and salt with the configured variant, time, memory and threads
+func (a *Argon2PasswordEncoder) DeriveKey(password string, salt []byte, length int) ([]byte, error) {
+	if length < 1 || uint64(length) > math.MaxUint32 {
+		return nil, ErrInvalidKeyLength
+	}
+	return a.Variant.key([]byte(password), salt, a.Secret, a.AssociatedData, a.Time, a.Memory, a.Threads, uint32(length)), nil
 }
 
 // Verify checks if the raw password matches the encoded password
 func (a *Argon2PasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseArgon2(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	// Compute hash with the same parameters and salt
+	computedHash := stored.variant.key([]byte(rawPassword), stored.salt, a.Secret, a.AssociatedData, stored.time, stored.memory, stored.threads, stored.keyLen)
+
+	// Compare hashes using constant-time comparison to prevent timing attacks
+	return subtle.ConstantTimeCompare(stored.hash, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password against the Argon2 parameter constraints without verifying it
+func (a *Argon2PasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseArgon2(encodedPassword)
+	if err != nil {
+		return err
+	}
+	switch {
+	case stored.memory < 8*uint32(stored.threads):
+		return newFormatError("argon2", "memory must be at least 8 KiB per thread", encodedPassword)
+	case stored.keyLen < 4:
+		return newFormatError("argon2", "key length too short", encodedPassword)
+	case len(stored.salt) < minSaltLen:
+		return newFormatError("argon2", "salt too short", encodedPassword)
+	case len(stored.hash) != int(stored.keyLen):
+		return newFormatError("argon2", "hash length does not match key length", encodedPassword)
+	}
+	return nil
+}
+
+// argon2Hash is a parsed Argon2 encoded password
+type argon2Hash struct {
+	time, memory, keyLen uint32
+	threads              uint8
+	variant              Argon2Variant
+	version              int
+	salt, hash           []byte
+}
+
+// checkArgon2Version rejects versions other than 19 (0x13), the only one computed by this package.
+// Version 16 (0x10) hashes from old libraries would verify incorrectly, so they are refused outright.
+func checkArgon2Version(version int, encodedPassword string) error {
+	if version != argon2.Version {
+		err := newFormatError("argon2", "unsupported version", encodedPassword)
+		err.Err = ErrArgon2Version
+		return err
+	}
+	return nil
+}
+
+// parseArgon2 parses an encoded password of the form
+// time=TIME,memory=MEMORY,threads=THREADS,keyLen=KEYLEN[,v=VERSION][,variant=VARIANT]$BASE64_SALT$BASE64_HASH
+// or a PHC string. Parameters may come in any order and base64 may be unpadded, as written by other libraries.
+func parseArgon2(encodedPassword string) (*argon2Hash, error) {
+	if strings.HasPrefix(encodedPassword, "$argon2") {
+		return parseArgon2PHC(encodedPassword)
+	}
+
 	// Split the encoded password into parts
 	parts := strings.Split(encodedPassword, "$")
 	if len(parts) != 3 {
-		return false, fmt.Errorf("invalid encoded password format")
+		return nil, newFormatError("argon2", "invalid encoded password format", encodedPassword)
 	}
 
-	// Parse parameters
-	var time, memory, keyLen uint32
-	var threads uint8
-	_, err := fmt.Sscanf(parts[0], "time=%d,memory=%d,threads=%d,keyLen=%d",
-		&time, &memory, &threads, &keyLen)
-	if err != nil {
-		return false, fmt.Errorf("invalid parameter format: %v", err)
+	// Hashes without a version predate it and were computed with version 19, like all output of x/crypto
+	stored := &argon2Hash{version: argon2.Version}
+	if err := stored.parseParams(parts[0], false, encodedPassword); err != nil {
+		return nil, err
+	}
+	if err := stored.decode(parts[1], parts[2], encodedPassword); err != nil {
+		return nil, err
 	}
+	return stored, nil
+}
 
-	// Decode salt and hash
-	salt, err := base64.StdEncoding.DecodeString(parts[1])
+// parseArgon2PHC parses an encoded password in the PHC string format $VARIANT[$v=VERSION]$m=MEMORY,t=TIME,p=THREADS$SALT$HASH
+func parseArgon2PHC(encodedPassword string) (*argon2Hash, error) {
+	parts := strings.Split(encodedPassword, "$")
+
+	// A missing version field means version 16 in PHC strings
+	stored := &argon2Hash{version: 0x10}
+	if len(parts) == 6 {
+		version, found := strings.CutPrefix(parts[2], "v=")
+		var err error
+		if stored.version, err = strconv.Atoi(version); !found || err != nil {
+			return nil, newFormatError("argon2", "invalid version", encodedPassword)
+		}
+		parts = append(parts[:2], parts[3:]...)
+	}
+	if len(parts) != 5 {
+		return nil, newFormatError("argon2", "invalid PHC string format", encodedPassword)
+	}
+
+	var ok bool
+	if stored.variant, ok = parseArgon2Variant(parts[1]); !ok {
+		return nil, newFormatError("argon2", "unknown variant", encodedPassword)
+	}
+	if err := stored.parseParams(parts[2], true, encodedPassword); err != nil {
+		return nil, err
+	}
+	if err := stored.decode(parts[3], parts[4], encodedPassword); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// parseParams parses comma-separated KEY=VALUE parameters in any order: m, t and p in PHC strings,
+// time, memory, threads, keyLen, v and variant in the passforge format
+func (stored *argon2Hash) parseParams(params string, phc bool, encodedPassword string) error {
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(field, "=")
+		if !found || seen[key] {
+			return newFormatError("argon2", "invalid parameter format", encodedPassword)
+		}
+		seen[key] = true
+
+		var number uint64
+		var err error
+		switch key {
+		case "variant", "v":
+		default:
+			number, err = strconv.ParseUint(value, 10, 32)
+		}
+		switch {
+		case err != nil:
+			return newFormatError("argon2", "invalid parameter format", encodedPassword)
+		case phc && key == "m", !phc && key == "memory":
+			stored.memory = uint32(number)
+		case phc && key == "t", !phc && key == "time":
+			stored.time = uint32(number)
+		case phc && key == "p", !phc && key == "threads":
+			if number > math.MaxUint8 {
+				return newFormatError("argon2", "invalid parameters", encodedPassword)
+			}
+			stored.threads = uint8(number)
+		case !phc && key == "keyLen":
+			stored.keyLen = uint32(number)
+		case !phc && key == "v":
+			if stored.version, err = strconv.Atoi(value); err != nil {
+				return newFormatError("argon2", "invalid version", encodedPassword)
+			}
+		case !phc && key == "variant":
+			var ok bool
+			if stored.variant, ok = parseArgon2Variant(value); !ok {
+				return newFormatError("argon2", "unknown variant", encodedPassword)
+			}
+		default:
+			return newFormatError("argon2", "unknown parameter", encodedPassword)
+		}
+	}
+
+	required := []string{"time", "memory", "threads"}
+	if phc {
+		required = []string{"t", "m", "p"}
+	}
+	for _, key := range required {
+		if !seen[key] {
+			return newFormatError("argon2", "missing parameter", encodedPassword)
+		}
+	}
+	if stored.time < 1 || stored.threads < 1 {
+		return newFormatError("argon2", "invalid parameters", encodedPassword)
+	}
+	return checkArgon2Version(stored.version, encodedPassword)
+}
+
+// decode decodes the salt and hash, padded or not. A missing key length is taken from the hash.
+func (stored *argon2Hash) decode(salt, hash, encodedPassword string) error {
+	var saltPadded, hashPadded bool
+	var err error
+	stored.salt, saltPadded, err = decodeBase64(salt)
 	if err != nil {
-		return false, fmt.Errorf("invalid salt encoding: %v", err)
+		return newFormatError("argon2", "invalid salt encoding", encodedPassword)
+	}
+	stored.hash, hashPadded, err = decodeBase64(hash)
+	if err != nil || len(stored.hash) == 0 {
+		return newFormatError("argon2", "invalid hash encoding", encodedPassword)
+	}
+	// Encoders pad both values or neither, so mixed padding means a truncated value
+	if len(stored.salt)%3 != 0 && len(stored.hash)%3 != 0 && saltPadded != hashPadded {
+		return newFormatError("argon2", "inconsistent base64 padding", encodedPassword)
 	}
+	if stored.keyLen == 0 {
+		stored.keyLen = uint32(len(stored.hash))
+	}
+	return nil
+}
 
-	storedHash, err := base64.StdEncoding.DecodeString(parts[2])
+// decodeBase64 decodes standard base64 with or without padding and reports whether it was padded
+func decodeBase64(s string) ([]byte, bool, error) {
+	if strings.HasSuffix(s, "=") {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		return decoded, true, err
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(s)
+	return decoded, false, err
+}
+
+// UpgradeEncoding reports whether the encoded password uses another variant than the configured one, or
+// fewer iterations, less memory or a shorter key. Fewer threads don't lower the cost and aren't reported.
+func (a *Argon2PasswordEncoder) UpgradeEncoding(encodedPassword string) bool {
+	stored, err := parseArgon2(encodedPassword)
 	if err != nil {
-		return false, fmt.Errorf("invalid hash encoding: %v", err)
+		return false
 	}
+	return stored.variant != a.Variant || stored.time < a.Time || stored.memory < a.Memory || stored.keyLen < a.KeyLen
+}
 
-	// Compute hash with the same parameters and salt
-	computedHash := argon2.IDKey([]byte(rawPassword), salt, time, memory, threads, keyLen)
+func init() {
+	RegisterHashFormat(&Argon2PasswordEncoder{})
+	RegisterTestVectors("argon2", true, func(rand io.Reader) PasswordEncoder {
+		return NewArgon2PasswordEncoder(WithArgon2Rand(rand))
+	})
+	RegisterTestVectors("argon2-phc", true, func(rand io.Reader) PasswordEncoder {
+		return NewArgon2PasswordEncoder(WithArgon2PHC(), WithArgon2Rand(rand))
+	})
+	RegisterTestVectors("argon2-spring", true, func(rand io.Reader) PasswordEncoder {
+		return NewArgon2PasswordEncoder(WithArgon2Spring(), WithArgon2Rand(rand))
+	})
+}
 
-	// Compare hashes using constant-time comparison to prevent timing attacks
-	return subtle.ConstantTimeCompare(storedHash, computedHash) == 1, nil
+// Identify reports whether the encoded password is an Argon2 hash in the passforge or PHC format
+func (a *Argon2PasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "time=") || strings.HasPrefix(encodedPassword, "$argon2")
+}
+
+// EncodedParams returns the parameters of the hash; PHC strings are reported with the names of the passforge format
+func (a *Argon2PasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	if !strings.HasPrefix(encodedPassword, "$argon2") {
+		return parseParamsHead(encodedPassword)
+	}
+	stored, err := parseArgon2PHC(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{
+		"time":    strconv.FormatUint(uint64(stored.time), 10),
+		"memory":  strconv.FormatUint(uint64(stored.memory), 10),
+		"threads": strconv.Itoa(int(stored.threads)),
+		"keyLen":  strconv.FormatUint(uint64(stored.keyLen), 10),
+		"v":       strconv.Itoa(stored.version),
+		"variant": stored.variant.String(),
+	}
+}
+
+// AdminStatus reports the Argon2 parameters; the secret is only reported as present
+func (a *Argon2PasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{
+		"time": a.Time, "memory": a.Memory, "threads": a.Threads, "keyLen": a.KeyLen, "saltLen": a.SaltLen,
+		"variant": a.Variant.String(), "phc": a.PHC, "keyed": len(a.Secret) > 0}}
 }
 
 // Name returns the name of the encoder.
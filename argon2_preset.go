@@ -0,0 +1,53 @@
+package passforge
+
+import (
+	"fmt"
+)
+
+// Argon2Preset is a named, versioned set of Argon2id cost parameters. A preset version never changes
+// once released; stronger recommendations are published as a new version, so upgrading passforge
+// never silently changes the parameters of an existing deployment.
+type Argon2Preset struct {
+	Name    string
+	Version int
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// String returns the preset as "name/vN"
+func (p Argon2Preset) String() string {
+	return fmt.Sprintf("%s/v%d", p.Name, p.Version)
+}
+
+// Version 1 presets mirror libsodium's crypto_pwhash limits
+var (
+	// Argon2InteractiveV1 suits online logins: 2 passes over 64 MiB
+	Argon2InteractiveV1 = Argon2Preset{Name: "interactive", Version: 1, Time: 2, Memory: 64 * 1024, Threads: 1}
+	// Argon2ModerateV1 suits less frequent operations: 3 passes over 256 MiB
+	Argon2ModerateV1 = Argon2Preset{Name: "moderate", Version: 1, Time: 3, Memory: 256 * 1024, Threads: 1}
+	// Argon2SensitiveV1 suits highly sensitive, non-interactive use: 4 passes over 1 GiB
+	Argon2SensitiveV1 = Argon2Preset{Name: "sensitive", Version: 1, Time: 4, Memory: 1024 * 1024, Threads: 1}
+)
+
+// argon2Presets lists all released presets
+var argon2Presets = []Argon2Preset{Argon2InteractiveV1, Argon2ModerateV1, Argon2SensitiveV1}
+
+// LookupArgon2Preset returns the preset with the name and version, e.g. from configuration files
+func LookupArgon2Preset(name string, version int) (Argon2Preset, bool) {
+	for _, preset := range argon2Presets {
+		if preset.Name == name && preset.Version == version {
+			return preset, true
+		}
+	}
+	return Argon2Preset{}, false
+}
+
+// WithArgon2Preset sets the time, memory and threads of a preset. Options after it override single values.
+func WithArgon2Preset(preset Argon2Preset) Argon2Option {
+	return func(a *Argon2PasswordEncoder) {
+		a.Time = preset.Time
+		a.Memory = preset.Memory
+		a.Threads = preset.Threads
+	}
+}
@@ -0,0 +1,40 @@
+package passforge
+
+import (
+	"testing"
+)
+
+func TestWithArgon2Preset(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Preset(Argon2ModerateV1), WithArgon2Threads(2))
+	if encoder.Time != 3 || encoder.Memory != 256*1024 || encoder.Threads != 2 {
+		t.Errorf("WithArgon2Preset() = time %d, memory %d, threads %d", encoder.Time, encoder.Memory, encoder.Threads)
+	}
+	if encoder.KeyLen != 32 || encoder.SaltLen != 16 {
+		t.Errorf("WithArgon2Preset() changed key or salt length")
+	}
+}
+
+func TestLookupArgon2Preset(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		want    Argon2Preset
+		wantOK  bool
+	}{
+		{"interactive", 1, Argon2InteractiveV1, true},
+		{"sensitive", 1, Argon2SensitiveV1, true},
+		{"interactive", 2, Argon2Preset{}, false},
+		{"paranoid", 1, Argon2Preset{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupArgon2Preset(tt.name, tt.version)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("LookupArgon2Preset() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+	if got := Argon2InteractiveV1.String(); got != "interactive/v1" {
+		t.Errorf("String() = %v, want interactive/v1", got)
+	}
+}
@@ -1,6 +1,8 @@
 package passforge
 
 import (
+	"bytes"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -189,3 +191,309 @@ func TestArgon2PasswordEncoder_Name(t *testing.T) {
 		t.Errorf("Name() = %v, want %v", actual, expected)
 	}
 }
+
+func TestArgon2PasswordEncoder_EncodeWith(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+
+	encoded, err := encoder.EncodeWith("password123", WithArgon2Time(2))
+	if err != nil {
+		t.Fatalf("EncodeWith() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "time=2,memory=1024,threads=1,") {
+		t.Errorf("EncodeWith() = %v, want overridden time recorded", encoded)
+	}
+	if encoder.Time != 1 {
+		t.Errorf("EncodeWith() modified the encoder: Time = %v", encoder.Time)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestArgon2PasswordEncoder_Argon2i(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1), WithArgon2Variant(Argon2i))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "time=1,memory=1024,threads=1,keyLen=32,v=19,variant=argon2i$") {
+		t.Errorf("Encode() = %v, want the variant recorded", encoded)
+	}
+
+	// Any encoder verifies with the recorded variant
+	idEncoder := NewArgon2PasswordEncoder()
+	if match, err := idEncoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	stripped := strings.Replace(encoded, ",variant=argon2i", "", 1)
+	if match, _ := idEncoder.Verify("password123", stripped); match {
+		t.Errorf("Verify() of the Argon2i hash as Argon2id should not match")
+	}
+}
+
+func TestArgon2PasswordEncoder_Argon2iVector(t *testing.T) {
+	// Reference implementation test vector: argon2i v=19, t=2, m=2^16, p=1, password "password", salt "somesalt"
+	encoded := "time=2,memory=65536,threads=1,keyLen=32,variant=argon2i$c29tZXNhbHQ=$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA="
+	encoder := NewArgon2PasswordEncoder()
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+		wantErr bool
+	}{
+		{"reference vector", encoded, true, false},
+		{"explicit argon2id", strings.Replace(encoded, "argon2i$", "argon2id$", 1), false, false},
+		{"unknown variant", strings.Replace(encoded, "argon2i$", "argon2x$", 1), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password", tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgon2PasswordEncoder_Argon2d(t *testing.T) {
+	// Reference implementation test vector: argon2d v=19, t=2, m=64, p=1, password "password", salt "somesalt"
+	vector := "time=2,memory=64,threads=1,keyLen=24,variant=argon2d$c29tZXNhbHQ=$O+nseaabddN1KstZofu4spWkZSnEj7t1"
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(64), WithArgon2Threads(1), WithArgon2Variant(Argon2d))
+
+	if match, err := encoder.Verify("password", vector); err != nil || !match {
+		t.Errorf("Verify() of reference vector = %v, %v, want true, nil", match, err)
+	}
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.Contains(encoded, ",variant=argon2d$") {
+		t.Errorf("Encode() = %v, want the variant recorded", encoded)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestArgon2PasswordEncoder_PHC(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(2), WithArgon2Time(2), WithArgon2PHC())
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=2,p=2$") || strings.Contains(encoded, "=$") {
+		t.Errorf("Encode() = %v, want an unpadded PHC string", encoded)
+	}
+	if match, err := NewArgon2PasswordEncoder().Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestArgon2PasswordEncoder_VerifyPHC(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder()
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+		wantErr bool
+	}{
+		// Example output of the reference implementation's README
+		{"reference argon2i", "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", true, false},
+		{"argon2i p=1", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, false},
+		{"wrong variant", "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, false},
+		{"unsupported version", "$argon2i$v=16$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+		{"missing version", "$argon2i$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+		{"parameter order", "$argon2i$v=19$t=2,p=4,m=65536$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", true, false},
+		{"duplicate parameter", "$argon2i$v=19$m=65536,t=2,t=3,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+		{"missing parameter", "$argon2i$v=19$m=65536,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+		{"too many threads", "$argon2i$v=19$m=65536,t=2,p=256$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+		{"padded salt", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ=$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA=", true, false},
+		{"mixed padding", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ=$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, true},
+		{"invalid salt", "$argon2i$v=19$m=65536,t=2,p=4$c29t!XNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password", tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgon2PasswordEncoder_SecretAndAssociatedData(t *testing.T) {
+	// RFC 9106 section 5.3 test vector: Argon2id with secret and associated data
+	password := strings.Repeat("\x01", 32)
+	secret, data := []byte(strings.Repeat("\x03", 8)), []byte(strings.Repeat("\x04", 12))
+	vector := "time=3,memory=32,threads=4,keyLen=32$AgICAgICAgICAgICAgICAg==$DWQN9Y14dmwIwDejSotTydAe8EUtdbZetSUg6WsB5lk="
+
+	tests := []struct {
+		name    string
+		encoder *Argon2PasswordEncoder
+		want    bool
+	}{
+		{"secret and data", NewArgon2PasswordEncoder(WithArgon2Secret(secret), WithArgon2AssociatedData(data)), true},
+		{"missing secret", NewArgon2PasswordEncoder(WithArgon2AssociatedData(data)), false},
+		{"missing data", NewArgon2PasswordEncoder(WithArgon2Secret(secret)), false},
+		{"other secret", NewArgon2PasswordEncoder(WithArgon2Secret([]byte("other")), WithArgon2AssociatedData(data)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.encoder.Verify(password, vector)
+			if err != nil || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v", match, err, tt.want)
+			}
+		})
+	}
+
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1), WithArgon2Secret(secret))
+	encoded, _ := encoder.Encode("password123")
+	if strings.Contains(encoded, "AwMDAwMDAwM") {
+		t.Errorf("Encode() = %v, must not contain the secret", encoded)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestArgon2PasswordEncoder_Version(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	encoded, _ := encoder.Encode("password123")
+	if !strings.Contains(encoded, ",keyLen=32,v=19$") {
+		t.Fatalf("Encode() = %v, want the version recorded", encoded)
+	}
+
+	legacy := strings.Replace(encoded, ",v=19", "", 1)
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+		wantErr error
+	}{
+		{"version 19", encoded, true, nil},
+		{"legacy without version", legacy, true, nil},
+		{"version 16", strings.Replace(encoded, "v=19", "v=16", 1), false, ErrArgon2Version},
+		{"future version", strings.Replace(encoded, "v=19", "v=20", 1), false, ErrArgon2Version},
+		{"invalid version", strings.Replace(encoded, "v=19", "v=x", 1), false, ErrInvalidFormat},
+		{"unknown parameter", strings.Replace(encoded, "v=19", "z=1", 1), false, ErrInvalidFormat},
+		{"PHC version 16", "$argon2i$v=16$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, ErrArgon2Version},
+		{"PHC without version", "$argon2i$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG", false, ErrArgon2Version},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password123", tt.encoded)
+			if !errors.Is(err, tt.wantErr) || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithArgon2AutoThreads(t *testing.T) {
+	defer func(cpus func() int) { availableCPUs = cpus }(availableCPUs)
+
+	tests := []struct {
+		name       string
+		cpus       int
+		maxThreads uint8
+		want       uint8
+	}{
+		{"below cap", 2, 4, 2},
+		{"capped", 16, 4, 4},
+		{"no cap", 16, 0, 16},
+		{"more CPUs than threads fit", 1024, 0, 255},
+		{"no CPU reported", 0, 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			availableCPUs = func() int { return tt.cpus }
+			if got := NewArgon2PasswordEncoder(WithArgon2AutoThreads(tt.maxThreads)).Threads; got != tt.want {
+				t.Errorf("Threads = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgon2PasswordEncoder_VerifyForeignFormat(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder()
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+		wantErr bool
+	}{
+		{"padded", "time=2,memory=65536,threads=1,keyLen=32,v=19,variant=argon2i$c29tZXNhbHQ=$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA=", true, false},
+		{"unpadded", "time=2,memory=65536,threads=1,keyLen=32,v=19,variant=argon2i$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, false},
+		{"reordered", "variant=argon2i,threads=1,memory=65536,time=2,keyLen=32$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, false},
+		{"key length from hash", "memory=65536,time=2,threads=1,variant=argon2i$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, false},
+		{"wrong password", "time=2,memory=65536,threads=1,variant=argon2i$c29tZXNhbHQ$AAAAMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, false},
+		{"duplicate parameter", "time=2,time=3,memory=65536,threads=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, true},
+		{"missing memory", "time=2,threads=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, true},
+		{"PHC name", "t=2,memory=65536,threads=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, true},
+		{"not a number", "time=two,memory=65536,threads=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password", tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithArgon2Spring(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Spring(), WithArgon2Rand(bytes.NewReader(make([]byte, 16))))
+	delegating, err := NewDelegatingPasswordEncoder("argon2", encoder)
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+
+	encoded, err := delegating.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	// Spring writes the same layout: 22 salt and 43 hash characters, no padding
+	prefix := "{argon2}$argon2id$v=19$m=16384,t=2,p=1$AAAAAAAAAAAAAAAAAAAAAA$"
+	if !strings.HasPrefix(encoded, prefix) || len(encoded) != len(prefix)+43 {
+		t.Errorf("Encode() = %v, want the Spring Security format", encoded)
+	}
+	if match, err := delegating.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+
+	// Hashes of Spring's pre-5.8 defaults verify as well
+	legacy, _ := NewArgon2PasswordEncoder(WithArgon2Spring(), WithArgon2Memory(1<<12), WithArgon2Time(3)).Encode("password123")
+	if !strings.HasPrefix(legacy, "$argon2id$v=19$m=4096,t=3,p=1$") {
+		t.Errorf("Encode() = %v, want the overridden parameters", legacy)
+	}
+	if match, err := delegating.Verify("password123", "{argon2}"+legacy); err != nil || !match {
+		t.Errorf("Verify() legacy = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestArgon2PasswordEncoder_EncodeWithSalt(t *testing.T) {
+	// Reference implementation test vector: argon2i v=19, t=2, m=2^16, p=1, password "password", salt "somesalt"
+	encoder := NewArgon2PasswordEncoder(WithArgon2Variant(Argon2i), WithArgon2Time(2), WithArgon2Memory(65536),
+		WithArgon2Threads(1), WithArgon2PHC())
+
+	encoded, err := encoder.EncodeWithSalt("password", []byte("somesalt"))
+	if err != nil {
+		t.Fatalf("EncodeWithSalt() error = %v", err)
+	}
+	if want := "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"; encoded != want {
+		t.Errorf("EncodeWithSalt() = %v, want %v", encoded, want)
+	}
+	if _, err := encoder.EncodeWithSalt("password", []byte("short")); err == nil {
+		t.Errorf("EncodeWithSalt() with a 5-byte salt should fail")
+	}
+}
@@ -0,0 +1,58 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AuditEventType identifies the kind of security event
+type AuditEventType string
+
+const (
+	// EventVerificationFailureSpike reports an unusual number of failed verifications
+	EventVerificationFailureSpike AuditEventType = "verification_failure_spike"
+	// EventHoneywordHit reports a login attempt using a decoy password
+	EventHoneywordHit AuditEventType = "honeyword_hit"
+	// EventForbiddenAlgorithm reports a hash using an algorithm that is no longer allowed
+	EventForbiddenAlgorithm AuditEventType = "forbidden_algorithm"
+)
+
+// AuditEvent is a security-relevant event published to an AuditSink.
+// Events must never carry raw passwords, salts or hashes.
+type AuditEvent struct {
+	Type      AuditEventType    `json:"type"`
+	Time      time.Time         `json:"time"`
+	Algorithm string            `json:"algorithm,omitempty"`
+	UserID    string            `json:"user,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// AuditSink publishes audit events to an external system
+type AuditSink interface {
+	Emit(ctx context.Context, event AuditEvent) error
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface
+type AuditSinkFunc func(ctx context.Context, event AuditEvent) error
+
+// Emit calls f(ctx, event)
+func (f AuditSinkFunc) Emit(ctx context.Context, event AuditEvent) error {
+	return f(ctx, event)
+}
+
+// MultiAuditSink fans events out to several sinks. Every sink is tried; errors are joined.
+type MultiAuditSink []AuditSink
+
+// Emit publishes the event to every sink
+func (m MultiAuditSink) Emit(ctx context.Context, event AuditEvent) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Emit(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package passforge
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookAuditSink posts audit events as JSON to a webhook URL
+type WebhookAuditSink struct {
+	URL     string            // Webhook endpoint
+	Client  *http.Client      // HTTP client used to deliver events
+	Secret  []byte            // Optional key used to sign the payload with HMAC-SHA256
+	Headers map[string]string // Additional request headers, e.g. authorization
+}
+
+// WebhookOption is a functional option used to configure a WebhookAuditSink instance.
+type WebhookOption func(*WebhookAuditSink)
+
+// WithWebhookClient sets the HTTP client
+// Default: a client with a 5 second timeout
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(w *WebhookAuditSink) {
+		w.Client = client
+	}
+}
+
+// WithWebhookSecret signs every payload with HMAC-SHA256, sent as "X-Passforge-Signature: sha256=<hex>"
+func WithWebhookSecret(secret []byte) WebhookOption {
+	return func(w *WebhookAuditSink) {
+		w.Secret = secret
+	}
+}
+
+// WithWebhookHeader adds a header to every request
+func WithWebhookHeader(key, value string) WebhookOption {
+	return func(w *WebhookAuditSink) {
+		w.Headers[key] = value
+	}
+}
+
+// NewWebhookAuditSink creates a new WebhookAuditSink posting to url
+func NewWebhookAuditSink(url string, opts ...WebhookOption) *WebhookAuditSink {
+	sink := &WebhookAuditSink{
+		URL:     url,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+		Headers: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(sink)
+	}
+	return sink
+}
+
+// Emit posts the event to the webhook. Any non-2xx response is reported as an error.
+func (w *WebhookAuditSink) Emit(ctx context.Context, event AuditEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+	if len(w.Secret) > 0 {
+		mac := hmac.New(sha256.New, w.Secret)
+		mac.Write(payload)
+		req.Header.Set("X-Passforge-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook audit sink: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// KafkaProducer is the subset of a Kafka client used by KafkaAuditSink.
+// It is satisfied by a thin adapter around clients such as segmentio/kafka-go or franz-go.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaAuditSink publishes audit events as JSON messages to a Kafka topic.
+// Messages are keyed by user ID when present (falling back to the event type) to keep per-user ordering.
+type KafkaAuditSink struct {
+	Producer KafkaProducer
+	Topic    string
+}
+
+// NewKafkaAuditSink creates a new KafkaAuditSink publishing to topic
+func NewKafkaAuditSink(producer KafkaProducer, topic string) *KafkaAuditSink {
+	return &KafkaAuditSink{Producer: producer, Topic: topic}
+}
+
+// Emit publishes the event to the topic
+func (k *KafkaAuditSink) Emit(ctx context.Context, event AuditEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	key := event.UserID
+	if key == "" {
+		key = string(event.Type)
+	}
+	return k.Producer.Produce(ctx, k.Topic, []byte(key), payload)
+}
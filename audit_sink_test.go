@@ -0,0 +1,114 @@
+package passforge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookAuditSink_Emit(t *testing.T) {
+	secret := []byte("webhook-secret")
+	var received AuditEvent
+	var signature, token string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if r.Header.Get("X-Passforge-Signature") == "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			signature = "valid"
+		}
+		token = r.Header.Get("Authorization")
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookAuditSink(server.URL, WithWebhookSecret(secret), WithWebhookHeader("Authorization", "Bearer token"))
+	event := AuditEvent{
+		Type:      EventHoneywordHit,
+		Time:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		UserID:    "alice",
+		Algorithm: "bcrypt",
+	}
+
+	if err := sink.Emit(context.Background(), event); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	if received.Type != EventHoneywordHit || received.UserID != "alice" || !received.Time.Equal(event.Time) {
+		t.Errorf("Emit() delivered = %+v", received)
+	}
+	if signature != "valid" {
+		t.Errorf("Emit() did not sign the payload")
+	}
+	if token != "Bearer token" {
+		t.Errorf("Emit() did not send custom header, got = %v", token)
+	}
+}
+
+func TestWebhookAuditSink_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookAuditSink(server.URL)
+	if err := sink.Emit(context.Background(), AuditEvent{Type: EventForbiddenAlgorithm}); err == nil {
+		t.Errorf("Emit() with 500 response should return error")
+	}
+}
+
+type fakeProducer struct {
+	topic      string
+	key, value []byte
+}
+
+func (f *fakeProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	f.topic, f.key, f.value = topic, key, value
+	return nil
+}
+
+func TestKafkaAuditSink_Emit(t *testing.T) {
+	producer := &fakeProducer{}
+	sink := NewKafkaAuditSink(producer, "security-events")
+
+	if err := sink.Emit(context.Background(), AuditEvent{Type: EventVerificationFailureSpike}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if producer.topic != "security-events" || string(producer.key) != string(EventVerificationFailureSpike) {
+		t.Errorf("Emit() produced topic = %v, key = %s", producer.topic, producer.key)
+	}
+
+	_ = sink.Emit(context.Background(), AuditEvent{Type: EventHoneywordHit, UserID: "alice"})
+	if string(producer.key) != "alice" {
+		t.Errorf("Emit() key = %s, want alice", producer.key)
+	}
+
+	var event AuditEvent
+	if err := json.Unmarshal(producer.value, &event); err != nil || event.Type != EventHoneywordHit {
+		t.Errorf("Emit() value = %s", producer.value)
+	}
+}
+
+func TestMultiAuditSink_Emit(t *testing.T) {
+	var calls int
+	ok := AuditSinkFunc(func(context.Context, AuditEvent) error { calls++; return nil })
+	failing := AuditSinkFunc(func(context.Context, AuditEvent) error { calls++; return errors.New("down") })
+
+	err := MultiAuditSink{failing, ok}.Emit(context.Background(), AuditEvent{Type: EventHoneywordHit})
+	if err == nil {
+		t.Errorf("Emit() should report the failing sink")
+	}
+	if calls != 2 {
+		t.Errorf("Emit() called %d sinks, want 2", calls)
+	}
+}
@@ -0,0 +1,253 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// dummyPassword is encoded once and verified for unknown users so that
+// response times do not reveal whether an account exists
+const dummyPassword = "passforge-dummy-password"
+
+// AuthService combines a UserCredentialStore with a DelegatingPasswordEncoder and implements the usual
+// login plumbing: password policy on change, attempt limiting and transparent upgrade-on-login.
+type AuthService struct {
+	Store   UserCredentialStore
+	Encoder *DelegatingPasswordEncoder
+	Policy  PasswordPolicy // Optional policy applied when setting passwords
+	Limiter AttemptLimiter // Optional limiter applied when authenticating
+
+	// HistoryDepth is the number of previous passwords a new password must differ from.
+	// It only applies when the store implements CredentialHistory.
+	HistoryDepth int
+
+	// OnUpgradeError is called when re-hashing an outdated password fails.
+	// Upgrade failures never fail the login itself.
+	OnUpgradeError func(ctx context.Context, userID string, err error)
+
+	// DoubleVerifier, when set, re-reads every upgraded hash and checks it against the legacy one.
+	// On divergence the legacy hash is restored, and the user's hash is no longer upgraded.
+	DoubleVerifier *DoubleVerifier
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// AuthOption is a functional option used to configure an AuthService instance.
+type AuthOption func(*AuthService)
+
+// WithAuthPolicy sets the policy new passwords must satisfy
+func WithAuthPolicy(policy PasswordPolicy) AuthOption {
+	return func(s *AuthService) {
+		s.Policy = policy
+	}
+}
+
+// WithAuthLimiter sets the limiter used to throttle failed logins.
+// Attempts are keyed by the user and, when present in the context (see ContextWithAttempt), the client IP.
+func WithAuthLimiter(limiter AttemptLimiter) AuthOption {
+	return func(s *AuthService) {
+		s.Limiter = limiter
+	}
+}
+
+// WithAuthHistory rejects new passwords matching the current or one of the last depth passwords
+// with ErrPasswordReused. The store must implement CredentialHistory.
+func WithAuthHistory(depth int) AuthOption {
+	return func(s *AuthService) {
+		s.HistoryDepth = depth
+	}
+}
+
+// WithAuthUpgradeErrorHandler sets the callback invoked when upgrading an outdated hash fails
+func WithAuthUpgradeErrorHandler(handler func(ctx context.Context, userID string, err error)) AuthOption {
+	return func(s *AuthService) {
+		s.OnUpgradeError = handler
+	}
+}
+
+// WithAuthDoubleVerify checks every hash upgraded at login against the legacy hash, see DoubleVerifier
+func WithAuthDoubleVerify(verifier *DoubleVerifier) AuthOption {
+	return func(s *AuthService) {
+		s.DoubleVerifier = verifier
+	}
+}
+
+// NewAuthService creates a new AuthService
+func NewAuthService(store UserCredentialStore, encoder *DelegatingPasswordEncoder, opts ...AuthOption) *AuthService {
+	s := &AuthService{
+		Store:   store,
+		Encoder: encoder,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Authenticate verifies the raw password of the user. Unknown users are reported as a mismatch.
+// When the password matches but the encoder reports an upgrade (see DelegatingPasswordEncoder.UpgradeEncoding),
+// e.g. it was encoded with a non-default encoder or a lower cost, it is re-encoded and stored.
+func (s *AuthService) Authenticate(ctx context.Context, userID, rawPassword string) (bool, error) {
+	encoded, err := s.Store.FindHash(ctx, userID)
+	unknownUser := errors.Is(err, ErrCredentialNotFound)
+	if err != nil && !unknownUser {
+		return false, err
+	}
+	if unknownUser {
+		if encoded = s.dummy(); encoded == "" {
+			return false, nil
+		}
+	}
+
+	match, err := s.verify(ctx, userID, rawPassword, encoded)
+	if err != nil || !match || unknownUser {
+		return false, err
+	}
+
+	if s.Encoder.UpgradeEncoding(encoded) && (s.DoubleVerifier == nil || !s.DoubleVerifier.Diverged(userID)) {
+		if err := s.upgrade(ctx, userID, rawPassword, encoded); err != nil && s.OnUpgradeError != nil {
+			s.OnUpgradeError(ctx, userID, err)
+		}
+	}
+	return true, nil
+}
+
+// SetPassword validates the raw password against the policy and the password history,
+// encodes it with the default encoder and stores it
+func (s *AuthService) SetPassword(ctx context.Context, userID, rawPassword string) error {
+	if s.Policy != nil {
+		if err := s.Policy.Validate(rawPassword); err != nil {
+			return err
+		}
+	}
+	if err := s.checkHistory(ctx, userID, rawPassword); err != nil {
+		return err
+	}
+	encoded, err := s.encode(rawPassword)
+	if err != nil {
+		return err
+	}
+	return s.Store.UpdateHash(ctx, userID, encoded)
+}
+
+// encode encodes the raw password with the default encoder and validates the result
+func (s *AuthService) encode(rawPassword string) (string, error) {
+	encoded, err := s.Encoder.Encode(rawPassword)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Encoder.ValidateEncoded(encoded); err != nil {
+		return "", err
+	}
+	return encoded, nil
+}
+
+// upgrade re-encodes the password with the default encoder and, in double-verification mode,
+// restores the legacy hash when the written hash disagrees with it. Stores implementing HashReplacer
+// swap the hashes in place, so neither the upgrade nor the restore is recorded in the history, and
+// a password changed concurrently is left alone.
+func (s *AuthService) upgrade(ctx context.Context, userID, rawPassword, legacyHash string) error {
+	encoded, err := s.encode(rawPassword)
+	if err != nil {
+		return err
+	}
+	if replacer, ok := s.Store.(HashReplacer); ok {
+		replaced, err := replacer.ReplaceHash(ctx, userID, legacyHash, encoded)
+		if err != nil || !replaced {
+			return err
+		}
+	} else if err := s.Store.UpdateHash(ctx, userID, encoded); err != nil {
+		return err
+	}
+	if s.DoubleVerifier == nil {
+		return nil
+	}
+
+	written, err := s.Store.FindHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.DoubleVerifier.Check(ctx, userID, rawPassword, legacyHash, written); err != nil {
+		return errors.Join(err, s.restore(ctx, userID, written, legacyHash))
+	}
+	return nil
+}
+
+// restore puts the legacy hash back in place of the written one
+func (s *AuthService) restore(ctx context.Context, userID, written, legacyHash string) error {
+	replacer, ok := s.Store.(HashReplacer)
+	if !ok {
+		return s.Store.UpdateHash(ctx, userID, legacyHash)
+	}
+	_, err := replacer.ReplaceHash(ctx, userID, written, legacyHash)
+	return err
+}
+
+// ChangePassword replaces the password of the user after verifying the current one.
+// It returns ErrInvalidCredentials when the current password does not match.
+func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	match, err := s.Authenticate(ctx, userID, currentPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+	return s.SetPassword(ctx, userID, newPassword)
+}
+
+// checkHistory returns ErrPasswordReused if the raw password matches the current or a recent password
+func (s *AuthService) checkHistory(ctx context.Context, userID, rawPassword string) error {
+	history, ok := s.Store.(CredentialHistory)
+	if s.HistoryDepth <= 0 || !ok {
+		return nil
+	}
+
+	previous, err := history.HashHistory(ctx, userID, s.HistoryDepth)
+	if err != nil {
+		return err
+	}
+	current, err := s.Store.FindHash(ctx, userID)
+	if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+		return err
+	}
+	if current != "" {
+		previous = append([]string{current}, previous...)
+	}
+
+	for _, encoded := range previous {
+		match, err := s.Encoder.Verify(rawPassword, encoded)
+		if err != nil {
+			// Hashes of encoders that are no longer configured cannot be compared
+			if errors.Is(err, ErrUnknownEncoding) {
+				continue
+			}
+			return err
+		}
+		if match {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// verify checks the password, going through the limiter when one is configured
+func (s *AuthService) verify(ctx context.Context, userID, rawPassword, encoded string) (bool, error) {
+	if s.Limiter == nil {
+		return s.Encoder.Verify(rawPassword, encoded)
+	}
+	attempt, _ := AttemptFromContext(ctx)
+	attempt.User = userID
+	limited := NewLimitedPasswordEncoder(s.Encoder, s.Limiter)
+	return limited.VerifyContext(ContextWithAttempt(ctx, attempt), rawPassword, encoded)
+}
+
+// dummy returns an encoded password used to spend the same work on unknown users
+func (s *AuthService) dummy() string {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, _ = s.Encoder.Encode(dummyPassword)
+	})
+	return s.dummyHash
+}
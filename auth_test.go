@@ -0,0 +1,162 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mapStore is a minimal UserCredentialStore used by the tests
+type mapStore map[string]string
+
+func (m mapStore) FindHash(_ context.Context, userID string) (string, error) {
+	encoded, ok := m[userID]
+	if !ok {
+		return "", ErrCredentialNotFound
+	}
+	return encoded, nil
+}
+
+func (m mapStore) UpdateHash(_ context.Context, userID, encodedPassword string) error {
+	m[userID] = encodedPassword
+	return nil
+}
+
+func newTestAuthService(t *testing.T, store UserCredentialStore, opts ...AuthOption) *AuthService {
+	t.Helper()
+	encoder, err := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)), NewNoOpPasswordEncoder())
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+	return NewAuthService(store, encoder, opts...)
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{}
+	service := newTestAuthService(t, store)
+
+	if err := service.SetPassword(ctx, "alice", "password123"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		userID      string
+		rawPassword string
+		wantMatch   bool
+	}{
+		{
+			name:        "matching password",
+			userID:      "alice",
+			rawPassword: "password123",
+			wantMatch:   true,
+		},
+		{
+			name:        "wrong password",
+			userID:      "alice",
+			rawPassword: "wrong",
+			wantMatch:   false,
+		},
+		{
+			name:        "unknown user",
+			userID:      "bob",
+			rawPassword: "password123",
+			wantMatch:   false,
+		},
+		{
+			name:        "unknown user with dummy password",
+			userID:      "bob",
+			rawPassword: dummyPassword,
+			wantMatch:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := service.Authenticate(ctx, tc.userID, tc.rawPassword)
+			if err != nil {
+				t.Errorf("Authenticate() error = %v", err)
+				return
+			}
+
+			if match != tc.wantMatch {
+				t.Errorf("Authenticate() got = %v, want %v", match, tc.wantMatch)
+			}
+		})
+	}
+}
+
+func TestAuthService_UpgradeOnLogin(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{"alice": "{noop}password123"}
+	service := newTestAuthService(t, store)
+
+	match, err := service.Authenticate(ctx, "alice", "password123")
+	if err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+
+	if !strings.HasPrefix(store["alice"], "{bcrypt}") {
+		t.Errorf("Authenticate() did not upgrade the hash, got = %v", store["alice"])
+	}
+
+	match, err = service.Authenticate(ctx, "alice", "password123")
+	if err != nil || !match {
+		t.Errorf("Authenticate() after upgrade got = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestAuthService_UpgradeWeakerParameters(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{"alice": "{bcrypt}$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm"}
+	encoder, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(5)))
+	service := NewAuthService(store, encoder)
+
+	match, err := service.Authenticate(ctx, "alice", "password")
+	if err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+	if !strings.HasPrefix(store["alice"], "{bcrypt}$2a$05$") {
+		t.Errorf("Authenticate() did not upgrade the cost, got = %v", store["alice"])
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{}
+	service := newTestAuthService(t, store, WithAuthPolicy(LengthPolicy{Min: 8}))
+
+	if err := service.SetPassword(ctx, "alice", "short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Fatalf("SetPassword() error = %v, want ErrPasswordTooShort", err)
+	}
+	if err := service.SetPassword(ctx, "alice", "password123"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+
+	if err := service.ChangePassword(ctx, "alice", "wrong", "newpassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("ChangePassword() with wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	if err := service.ChangePassword(ctx, "alice", "password123", "newpassword"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+
+	if match, _ := service.Authenticate(ctx, "alice", "newpassword"); !match {
+		t.Errorf("Authenticate() with new password got = false, want true")
+	}
+}
+
+func TestAuthService_Limiter(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{"alice": "{noop}password123"}
+	service := newTestAuthService(t, store, WithAuthLimiter(NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(1))))
+
+	if match, _ := service.Authenticate(ctx, "alice", "wrong"); match {
+		t.Fatalf("Authenticate() with wrong password got = true")
+	}
+
+	if _, err := service.Authenticate(ctx, "alice", "password123"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("Authenticate() error = %v, want ErrTooManyAttempts", err)
+	}
+}
@@ -1,14 +1,87 @@
 package passforge
 
 import (
+	"crypto/sha512"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// bcryptHashLen is the length of an encoded bcrypt hash, e.g. $2a$10$ followed by 22 salt and 31 hash characters
+const bcryptHashLen = 60
+
+// ErrInvalidBcryptCost is returned for bcrypt costs outside 4..31
+var ErrInvalidBcryptCost = errors.New("bcrypt: invalid cost")
+
+// ErrBcryptBrokenVariant is returned for $2x$ hashes, produced by crypt_blowfish before 2011 with a
+// sign extension bug that weakened passwords containing non-ASCII bytes
+var ErrBcryptBrokenVariant = errors.New("bcrypt: $2x$ hashes use a broken algorithm")
+
+// bcryptMaxPasswordLen is the number of password bytes bcrypt uses; the rest is ignored
+const bcryptMaxPasswordLen = 72
+
 // BcryptPasswordEncoder is a password encoder that uses the bcrypt algorithm
 type BcryptPasswordEncoder struct {
-	Cost int
+	Cost         int
+	LongPassword BcryptLongPassword // Handling of passwords longer than 72 bytes
+	Variant      BcryptVariant      // Prefix of encoded passwords; Verify accepts $2a$, $2b$ and $2y$
+}
+
+// BcryptVariant selects the prefix of encoded bcrypt passwords. All compute the same hash for
+// passwords up to 72 bytes; $2b$ marks hashes from implementations without the OpenBSD length bug.
+type BcryptVariant int
+
+const (
+	// Bcrypt2a emits $2a$, understood by every bcrypt implementation
+	Bcrypt2a BcryptVariant = iota
+	// Bcrypt2b emits $2b$, the current OpenBSD and passlib default
+	Bcrypt2b
+	// Bcrypt2y emits $2y$, the prefix of PHP's password_hash
+	Bcrypt2y
+)
+
+// String returns the prefix of the variant without dollar signs, e.g. "2a"
+func (v BcryptVariant) String() string {
+	switch v {
+	case Bcrypt2b:
+		return "2b"
+	case Bcrypt2y:
+		return "2y"
+	}
+	return "2a"
+}
+
+// BcryptLongPassword selects how passwords longer than bcrypt's 72-byte limit are handled
+type BcryptLongPassword int
+
+const (
+	// BcryptRejectLong makes Encode return ErrPasswordTooLong, so long passphrases sharing their first
+	// 72 bytes can't match each other. Verify truncates them, so imported hashes of long passwords verify.
+	BcryptRejectLong BcryptLongPassword = iota
+	// BcryptPrehashLong replaces passwords longer than 72 bytes with the base64 of their SHA-384 digest,
+	// 64 characters, before bcrypt. Shorter passwords are hashed unchanged, so existing hashes keep
+	// verifying, but other bcrypt implementations can't verify the hashes of long passwords.
+	BcryptPrehashLong
+	// BcryptTruncateLong ignores everything after the first 72 bytes like most bcrypt implementations,
+	// for hashes imported from systems that allowed long passwords
+	BcryptTruncateLong
+)
+
+// String returns the name of the long password handling as reported by the admin endpoint
+func (l BcryptLongPassword) String() string {
+	switch l {
+	case BcryptPrehashLong:
+		return "prehash"
+	case BcryptTruncateLong:
+		return "truncate"
+	default:
+		return "reject"
+	}
 }
 
 // BcryptOption is a function that configures a BcryptPasswordEncoder.
@@ -35,6 +108,22 @@ func WithCost(cost int) BcryptOption {
 	}
 }
 
+// WithBcryptLongPassword sets how passwords longer than 72 bytes are handled
+// Default: BcryptRejectLong
+func WithBcryptLongPassword(mode BcryptLongPassword) BcryptOption {
+	return func(b *BcryptPasswordEncoder) {
+		b.LongPassword = mode
+	}
+}
+
+// WithBcryptVariant sets the prefix of encoded passwords
+// Default: Bcrypt2a
+func WithBcryptVariant(variant BcryptVariant) BcryptOption {
+	return func(b *BcryptPasswordEncoder) {
+		b.Variant = variant
+	}
+}
+
 // NewBcryptPasswordEncoder creates a new BcryptPasswordEncoder with default parameters if not specified.
 func NewBcryptPasswordEncoder(opts ...BcryptOption) *BcryptPasswordEncoder {
 	encoder := &BcryptPasswordEncoder{Cost: bcrypt.DefaultCost}
@@ -44,28 +133,165 @@ func NewBcryptPasswordEncoder(opts ...BcryptOption) *BcryptPasswordEncoder {
 	return encoder
 }
 
+// NewBcryptPasswordEncoderE is like NewBcryptPasswordEncoder but rejects costs outside 4..31 with ErrInvalidBcryptCost
+func NewBcryptPasswordEncoderE(opts ...BcryptOption) (*BcryptPasswordEncoder, error) {
+	encoder := NewBcryptPasswordEncoder(opts...)
+	if err := encoder.Validate(); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// Validate checks the configured cost, the returned error wraps ErrInvalidBcryptCost
+func (b *BcryptPasswordEncoder) Validate() error {
+	if b.Cost < bcrypt.MinCost || b.Cost > bcrypt.MaxCost {
+		return fmt.Errorf("%w: %d is outside %d..%d", ErrInvalidBcryptCost, b.Cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+// EffectiveCost returns the cost Encode uses. x/crypto/bcrypt replaces costs below 4 with its default
+// of 10, while costs above 31 make Encode fail.
+func (b *BcryptPasswordEncoder) EffectiveCost() int {
+	if b.Cost < bcrypt.MinCost {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
 // Encode hashes the raw password using bcrypt.
 func (b *BcryptPasswordEncoder) Encode(rawPassword string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(rawPassword), b.Cost)
+	password, err := b.password(rawPassword)
+	if err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword(password, b.Cost)
 	if err != nil {
 		return "", err
 	}
+	// x/crypto/bcrypt always emits $2a$
+	copy(hashed[1:3], b.Variant.String())
 	return string(hashed), nil
 }
 
+// EncodeWith hashes the raw password with the options applied for this call only, e.g. a higher cost for admins
+func (b *BcryptPasswordEncoder) EncodeWith(rawPassword string, opts ...BcryptOption) (string, error) {
+	encoder := *b
+	for _, opt := range opts {
+		opt(&encoder)
+	}
+	return encoder.Encode(rawPassword)
+}
+
 // Verify checks if the raw password matches the encoded password.
 func (b *BcryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword([]byte(encodedPassword), []byte(rawPassword))
+	if err := checkBcryptPrefix(encodedPassword); err != nil {
+		return false, err
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encodedPassword), b.verifyPassword(rawPassword))
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return false, nil
 		}
-		return false, err
+		// bcrypt errors can quote parts of the stored hash; report a redacted format error instead
+		return false, newFormatError("bcrypt", "invalid bcrypt hash", encodedPassword)
+	}
+	if cost, err := bcrypt.Cost([]byte(encodedPassword)); err == nil && cost < MinBcryptCost {
+		notifyWeak(b.Name(), "cost below minimum")
 	}
 	return true, nil
 }
 
+// ValidateEncoded checks that the encoded password is a complete bcrypt hash with a valid cost
+func (b *BcryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if err := checkBcryptPrefix(encodedPassword); err != nil {
+		return err
+	}
+	if _, err := bcrypt.Cost([]byte(encodedPassword)); err != nil {
+		return newFormatError("bcrypt", "invalid bcrypt hash", encodedPassword)
+	}
+	return nil
+}
+
+// UpgradeEncoding reports whether the encoded password has a lower cost than the configured one
+func (b *BcryptPasswordEncoder) UpgradeEncoding(encodedPassword string) bool {
+	if checkBcryptPrefix(encodedPassword) != nil {
+		return false
+	}
+	cost, err := bcrypt.Cost([]byte(encodedPassword))
+	return err == nil && cost < b.EffectiveCost()
+}
+
+func init() {
+	RegisterHashFormat(&BcryptPasswordEncoder{})
+	RegisterTestVectors("bcrypt", false, func(rand io.Reader) PasswordEncoder {
+		return NewBcryptPasswordEncoder()
+	})
+}
+
+// Identify reports whether the encoded password is a bcrypt hash of any version
+func (b *BcryptPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$2a$") ||
+		strings.HasPrefix(encodedPassword, "$2b$") ||
+		strings.HasPrefix(encodedPassword, "$2y$") ||
+		strings.HasPrefix(encodedPassword, "$2x$")
+}
+
+// EncodedParams returns the cost of the bcrypt hash
+func (b *BcryptPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	cost, err := bcrypt.Cost([]byte(encodedPassword))
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"cost": strconv.Itoa(cost)}
+}
+
+// AdminStatus reports the cost, variant and long password handling
+func (b *BcryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"cost": b.Cost, "longPassword": b.LongPassword.String(),
+		"variant": b.Variant.String()}}
+}
+
 // Name returns the name of the encoder.
 func (b *BcryptPasswordEncoder) Name() string {
 	return "bcrypt"
 }
+
+// checkBcryptPrefix checks the length and variant of an encoded password. x/crypto/bcrypt accepts
+// any variant letter, which would verify $2x$ hashes with the fixed algorithm and report mismatches.
+func checkBcryptPrefix(encodedPassword string) error {
+	// x/crypto/bcrypt also ignores trailing data after the hash
+	if len(encodedPassword) != bcryptHashLen {
+		return newFormatError("bcrypt", "invalid bcrypt hash length", encodedPassword)
+	}
+	switch encodedPassword[:4] {
+	case "$2a$", "$2b$", "$2y$":
+		return nil
+	case "$2x$":
+		err := newFormatError("bcrypt", "$2x$ hashes were produced by a buggy crypt_blowfish and must be reset", encodedPassword)
+		err.Err = ErrBcryptBrokenVariant
+		return err
+	}
+	return newFormatError("bcrypt", "unsupported bcrypt variant", encodedPassword)
+}
+
+// password returns the bytes Encode passes to bcrypt for the raw password, applying the long password handling
+func (b *BcryptPasswordEncoder) password(rawPassword string) ([]byte, error) {
+	if len(rawPassword) > bcryptMaxPasswordLen && b.LongPassword == BcryptRejectLong {
+		return nil, ErrPasswordTooLong
+	}
+	return b.verifyPassword(rawPassword), nil
+}
+
+// verifyPassword returns the bytes Verify passes to bcrypt for the raw password. Long passwords are
+// pre-hashed in BcryptPrehashLong mode and truncated otherwise, as other bcrypt implementations do.
+func (b *BcryptPasswordEncoder) verifyPassword(rawPassword string) []byte {
+	if len(rawPassword) <= bcryptMaxPasswordLen {
+		return []byte(rawPassword)
+	}
+	if b.LongPassword == BcryptPrehashLong {
+		digest := sha512.Sum384([]byte(rawPassword))
+		return []byte(base64.StdEncoding.EncodeToString(digest[:]))
+	}
+	return []byte(rawPassword[:bcryptMaxPasswordLen])
+}
@@ -0,0 +1,194 @@
+package passforge
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nduyhai/passforge/internal/bcryptpbkdf"
+)
+
+// bcryptPBKDFMaxKeyLen is the longest key bcrypt_pbkdf derives
+const bcryptPBKDFMaxKeyLen = 1024
+
+// BcryptPBKDFPasswordEncoder is a password encoder and key deriver using bcrypt_pbkdf, the KDF OpenBSD
+// built from bcrypt for OpenSSH private keys and signify. DeriveKey produces the same keys as ssh-keygen
+// with the rounds and salt of a key's kdfoptions; Encode stores rounds=ROUNDS,keyLen=KEYLEN$BASE64_SALT$BASE64_HASH.
+// Empty passwords are rejected, as in OpenBSD. For login passwords prefer the bcrypt encoder.
+type BcryptPBKDFPasswordEncoder struct {
+	Rounds  int       // Number of rounds
+	KeyLen  int       // Length of the derived key
+	SaltLen int       // Length of the salt
+	Rand    io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// BcryptPBKDFOption is a functional option used to configure a BcryptPBKDFPasswordEncoder instance.
+type BcryptPBKDFOption func(*BcryptPBKDFPasswordEncoder)
+
+// WithBcryptPBKDFRounds sets the number of rounds
+// Default: 16, as in ssh-keygen
+func WithBcryptPBKDFRounds(rounds int) BcryptPBKDFOption {
+	return func(b *BcryptPBKDFPasswordEncoder) {
+		b.Rounds = rounds
+	}
+}
+
+// WithBcryptPBKDFKeyLen sets the length of the derived key, at most 1024
+// Default: 32
+func WithBcryptPBKDFKeyLen(keyLen int) BcryptPBKDFOption {
+	return func(b *BcryptPBKDFPasswordEncoder) {
+		b.KeyLen = keyLen
+	}
+}
+
+// WithBcryptPBKDFSaltLen sets the length of the salt
+// Default: 16, as in ssh-keygen
+func WithBcryptPBKDFSaltLen(saltLen int) BcryptPBKDFOption {
+	return func(b *BcryptPBKDFPasswordEncoder) {
+		b.SaltLen = saltLen
+	}
+}
+
+// WithBcryptPBKDFRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithBcryptPBKDFRand(r io.Reader) BcryptPBKDFOption {
+	return func(b *BcryptPBKDFPasswordEncoder) {
+		b.Rand = r
+	}
+}
+
+// NewBcryptPBKDFPasswordEncoder creates a new BcryptPBKDFPasswordEncoder with default parameters if not specified
+func NewBcryptPBKDFPasswordEncoder(opts ...BcryptPBKDFOption) *BcryptPBKDFPasswordEncoder {
+	encoder := &BcryptPBKDFPasswordEncoder{
+		Rounds:  16,
+		KeyLen:  32,
+		SaltLen: 16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode derives a key from the raw password and a random salt
+func (b *BcryptPBKDFPasswordEncoder) Encode(rawPassword string) (string, error) {
+	if b.SaltLen < 1 {
+		return "", fmt.Errorf("bcrypt-pbkdf: saltLen must be positive")
+	}
+	salt := make([]byte, b.SaltLen)
+	if _, err := io.ReadFull(randReader(b.Rand), salt); err != nil {
+		return "", err
+	}
+	hash, err := b.DeriveKey(rawPassword, salt, b.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rounds=%d,keyLen=%d$%s$%s", b.Rounds, b.KeyLen, base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(hash)), nil
+}
+
+// DeriveKey derives a key from the password and salt with bcrypt_pbkdf and the configured rounds
+func (b *BcryptPBKDFPasswordEncoder) DeriveKey(password string, salt []byte, length int) ([]byte, error) {
+	return bcryptPBKDFKey(password, salt, b.Rounds, length)
+}
+
+// Verify checks if the raw password matches the encoded password
+func (b *BcryptPBKDFPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseBcryptPBKDF(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if rawPassword == "" {
+		return false, nil
+	}
+	computedHash, err := bcryptPBKDFKey(rawPassword, stored.salt, stored.rounds, len(stored.hash))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(stored.hash, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (b *BcryptPBKDFPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseBcryptPBKDF(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("bcrypt-pbkdf", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&BcryptPBKDFPasswordEncoder{})
+	RegisterTestVectors("bcrypt-pbkdf", true, func(rand io.Reader) PasswordEncoder {
+		return NewBcryptPBKDFPasswordEncoder(WithBcryptPBKDFRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a bcrypt_pbkdf hash
+func (b *BcryptPBKDFPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "rounds=")
+}
+
+// AdminStatus reports the rounds, key length and salt length
+func (b *BcryptPBKDFPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"rounds": b.Rounds, "keyLen": b.KeyLen, "saltLen": b.SaltLen}}
+}
+
+// Name returns the name of the encoder.
+func (b *BcryptPBKDFPasswordEncoder) Name() string {
+	return "bcrypt-pbkdf"
+}
+
+// bcryptPBKDFKey checks the key length and derives the key
+func bcryptPBKDFKey(password string, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if keyLen < 1 || keyLen > bcryptPBKDFMaxKeyLen {
+		return nil, ErrInvalidKeyLength
+	}
+	return bcryptpbkdf.Key([]byte(password), salt, rounds, keyLen)
+}
+
+// bcryptPBKDFHash is a parsed bcrypt_pbkdf encoded password
+type bcryptPBKDFHash struct {
+	rounds     int
+	salt, hash []byte
+}
+
+// parseBcryptPBKDF parses an encoded password of the form rounds=ROUNDS,keyLen=KEYLEN$BASE64_SALT$BASE64_HASH
+func parseBcryptPBKDF(encodedPassword string) (*bcryptPBKDFHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 3 {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid encoded password format", encodedPassword)
+	}
+	rounds, keyLen, found := strings.Cut(parts[0], ",keyLen=")
+	rounds, roundsFound := strings.CutPrefix(rounds, "rounds=")
+	if !found || !roundsFound {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &bcryptPBKDFHash{}
+	var err error
+	stored.rounds, err = strconv.Atoi(rounds)
+	if err != nil || rounds[0] < '1' || rounds[0] > '9' {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid rounds", encodedPassword)
+	}
+	length, err := strconv.Atoi(keyLen)
+	if err != nil || keyLen[0] < '1' || keyLen[0] > '9' || length > bcryptPBKDFMaxKeyLen {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid keyLen", encodedPassword)
+	}
+	stored.salt, err = base64.StdEncoding.Strict().DecodeString(parts[1])
+	if err != nil || len(stored.salt) == 0 {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid salt encoding", encodedPassword)
+	}
+	stored.hash, err = base64.StdEncoding.Strict().DecodeString(parts[2])
+	if err != nil || len(stored.hash) != length {
+		return nil, newFormatError("bcrypt-pbkdf", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
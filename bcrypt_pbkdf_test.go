@@ -0,0 +1,90 @@
+package passforge
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBcryptPBKDFPasswordEncoder_Verify(t *testing.T) {
+	// OpenBSD's bcrypt_pbkdf regression vector: "password", "salt", 12 rounds
+	vector := "rounds=12,keyLen=32$c2FsdA==$GuQsBdSHvAL2SSGk6+Tqk7ys/hNf2pmXTAa3sB+uFJo="
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"vector", "password", vector, true, false},
+		{"wrong password", "Password", vector, false, false},
+		{"empty password", "", vector, false, false},
+		{"wrong rounds", "password", strings.Replace(vector, "rounds=12", "rounds=11", 1), false, false},
+		{"keyLen mismatch", "password", strings.Replace(vector, "keyLen=32", "keyLen=16", 1), false, true},
+		{"keyLen too large", "password", strings.Replace(vector, "keyLen=32", "keyLen=1025", 1), false, true},
+		{"zero rounds", "password", strings.Replace(vector, "rounds=12", "rounds=0", 1), false, true},
+		{"signed rounds", "password", strings.Replace(vector, "rounds=12", "rounds=+12", 1), false, true},
+		{"parameters out of order", "password", "keyLen=32,rounds=12$c2FsdA==$GuQsBdSHvAL2SSGk6+Tqk7ys/hNf2pmXTAa3sB+uFJo=", false, true},
+		{"missing salt", "password", "rounds=12,keyLen=32$$GuQsBdSHvAL2SSGk6+Tqk7ys/hNf2pmXTAa3sB+uFJo=", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewBcryptPBKDFPasswordEncoder().Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcryptPBKDFPasswordEncoder_DeriveKey(t *testing.T) {
+	// OpenBSD's bcrypt_pbkdf regression vector with a key longer than one block
+	encoder := NewBcryptPBKDFPasswordEncoder(WithBcryptPBKDFRounds(8))
+	key, err := encoder.DeriveKey("секретное слово", []byte("посолить немножко"), 88)
+	want := "8df43fc6fe131fc47f0c9e39224bd94c70b6fcc8ee8135faddf61156e6cb2733ea765f315a3e1e4afc35bf8687d189254c1e05a6fe80c0617f9183d67260d6a115c6c94e3603e2303fbb43a76a64523ffda686b1d4518543"
+	if err != nil || hex.EncodeToString(key) != want {
+		t.Errorf("DeriveKey() = %x, %v, want %v", key, err, want)
+	}
+
+	for _, length := range []int{0, 1025} {
+		if _, err := encoder.DeriveKey("password", []byte("salt"), length); !errors.Is(err, ErrInvalidKeyLength) {
+			t.Errorf("DeriveKey() of %d bytes error = %v, want ErrInvalidKeyLength", length, err)
+		}
+	}
+	if _, err := encoder.DeriveKey("", []byte("salt"), 32); err == nil {
+		t.Errorf("DeriveKey() of an empty password error = nil, want an error")
+	}
+}
+
+func TestBcryptPBKDFPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewBcryptPBKDFPasswordEncoder(WithBcryptPBKDFRounds(4), WithBcryptPBKDFKeyLen(48), WithBcryptPBKDFSaltLen(24))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "rounds=4,keyLen=48$") {
+		t.Errorf("Encode() = %v, want the rounds=4,keyLen=48 parameters", encoded)
+	}
+	if match, err := NewBcryptPBKDFPasswordEncoder().Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+	if err := encoder.ValidateEncoded("rounds=12,keyLen=32$c2FsdA==$GuQsBdSHvAL2SSGk6+Tqk7ys/hNf2pmXTAa3sB+uFJo="); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() of a 4-byte salt error = %v, want ErrInvalidFormat", err)
+	}
+	if _, err := NewBcryptPBKDFPasswordEncoder(WithBcryptPBKDFSaltLen(0)).Encode("password"); err == nil {
+		t.Errorf("Encode() without salt error = nil, want an error")
+	}
+}
+
+func TestBcryptPBKDFPasswordEncoder_Name(t *testing.T) {
+	if name := NewBcryptPBKDFPasswordEncoder().Name(); name != "bcrypt-pbkdf" {
+		t.Errorf("Name() = %v, want bcrypt-pbkdf", name)
+	}
+}
@@ -0,0 +1,89 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBcryptSHA256PasswordEncoder_Encode(t *testing.T) {
+	encoder := NewBcryptSHA256PasswordEncoder(WithBcryptSHA256Cost(4), WithBcryptSHA256Rand(bytes.NewReader(make([]byte, 16))))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if want := "$bcrypt-sha256$v=2,t=2b,r=4$......................$"; !strings.HasPrefix(encoded, want) || len(encoded) != len(want)+31 {
+		t.Errorf("Encode() = %v, want a %s hash", encoded, want)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	if _, err := NewBcryptSHA256PasswordEncoder(WithBcryptSHA256Cost(3)).Encode("password123"); err == nil {
+		t.Errorf("Encode() with cost 3 expected an error")
+	}
+}
+
+func TestBcryptSHA256PasswordEncoder_Verify(t *testing.T) {
+	encoder := NewBcryptSHA256PasswordEncoder()
+
+	// Hashes from passlib's test suite
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"v2", "password", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", true, false},
+		{"v2 empty password", "", "$bcrypt-sha256$v=2,t=2b,r=5$E/e/2AOhqM5W/KJTFQzLce$WFPIZKtDDTriqWwlmRFfHiOTeheAZWe", true, false},
+		{"v1", "password", "$bcrypt-sha256$2a,5$5Hg1DKFqPE8C2aflZ5vVoe$12BjNE0p7axMg55.Y/mHsYiVuFBDQyu", true, false},
+		{"v1 empty password", "", "$bcrypt-sha256$2a,5$E/e/2AOhqM5W/KJTFQzLce$F6dYSxOdAEoJZO2eoHUZWZljW/e0TXO", true, false},
+		{"wrong password", "wrong", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, false},
+		{"v1 checksum as v2", "password", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$12BjNE0p7axMg55.Y/mHsYiVuFBDQyu", false, false},
+		{"unknown version", "password", "$bcrypt-sha256$v=3,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, true},
+		{"unknown ident", "password", "$bcrypt-sha256$2y,5$5Hg1DKFqPE8C2aflZ5vVoe$12BjNE0p7axMg55.Y/mHsYiVuFBDQyu", false, true},
+		{"cost too low", "password", "$bcrypt-sha256$v=2,t=2b,r=3$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, true},
+		{"signed cost", "password", "$bcrypt-sha256$v=2,t=2b,r=+5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, true},
+		{"short salt", "password", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVo$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, true},
+		{"truncated hash", "password", "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPb", false, true},
+		{"plain bcrypt", "password", "$2b$05$5Hg1DKFqPE8C2aflZ5vVoewOK1VFFtS8IGTrGa7.h5fs0u84qyPbS", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcryptSHA256PasswordEncoder_NoTruncation(t *testing.T) {
+	encoder := NewBcryptSHA256PasswordEncoder(WithBcryptSHA256Cost(4))
+
+	prefix := strings.Repeat("a", 72)
+	encoded, err := encoder.Encode(prefix + "b")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if match, _ := encoder.Verify(prefix+"c", encoded); match {
+		t.Errorf("Verify() matched a password differing after 72 bytes")
+	}
+	if match, _ := encoder.Verify(prefix+"b", encoded); !match {
+		t.Errorf("Verify() = false, want true")
+	}
+}
+
+func TestBcryptSHA256PasswordEncoder_Name(t *testing.T) {
+	if name := NewBcryptSHA256PasswordEncoder().Name(); name != "bcrypt-sha256" {
+		t.Errorf("Name() = %v, want bcrypt-sha256", name)
+	}
+}
@@ -1,7 +1,11 @@
 package passforge
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestBcryptPasswordEncoder_Encode(t *testing.T) {
@@ -164,3 +168,162 @@ func TestBcryptPasswordEncoder_Name(t *testing.T) {
 		t.Errorf("Name() = %v, want %v", actual, expected)
 	}
 }
+
+func TestBcryptPasswordEncoder_EncodeWith(t *testing.T) {
+	encoder := NewBcryptPasswordEncoder(WithCost(4))
+
+	encoded, err := encoder.EncodeWith("password123", WithCost(5))
+	if err != nil {
+		t.Fatalf("EncodeWith() error = %v", err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(encoded)); cost != 5 || encoder.Cost != 4 {
+		t.Errorf("EncodeWith() cost = %v with encoder cost %v, want 5 and 4", cost, encoder.Cost)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestBcryptPasswordEncoder_LongPassword(t *testing.T) {
+	prefix := strings.Repeat("a", 72)
+	// A hash of the first 72 bytes, as produced by implementations that truncate
+	truncated, _ := bcrypt.GenerateFromPassword([]byte(prefix), 4)
+
+	tests := []struct {
+		name          string
+		mode          BcryptLongPassword
+		wantEncodeErr error
+		wantTruncated bool // whether a long password verifies against the truncated hash
+	}{
+		{"reject", BcryptRejectLong, ErrPasswordTooLong, true},
+		{"prehash", BcryptPrehashLong, nil, false},
+		{"truncate", BcryptTruncateLong, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewBcryptPasswordEncoder(WithCost(4), WithBcryptLongPassword(tt.mode))
+
+			encoded, err := encoder.Encode(prefix + "b")
+			if !errors.Is(err, tt.wantEncodeErr) {
+				t.Fatalf("Encode() error = %v, want %v", err, tt.wantEncodeErr)
+			}
+			if err == nil {
+				if match, _ := encoder.Verify(prefix+"b", encoded); !match {
+					t.Errorf("Verify() = false, want true")
+				}
+				if match, _ := encoder.Verify(prefix+"c", encoded); match != (tt.mode == BcryptTruncateLong) {
+					t.Errorf("Verify() of a password differing after 72 bytes = %v", match)
+				}
+			}
+
+			match, err := encoder.Verify(prefix+"b", string(truncated))
+			if err != nil || match != tt.wantTruncated {
+				t.Errorf("Verify() against truncated hash = %v, %v, want %v, nil", match, err, tt.wantTruncated)
+			}
+
+			// Passwords up to 72 bytes are hashed unchanged in every mode
+			if match, err := encoder.Verify(prefix, string(truncated)); err != nil || !match {
+				t.Errorf("Verify() of a 72-byte password = %v, %v, want true, nil", match, err)
+			}
+		})
+	}
+}
+
+func TestBcryptPasswordEncoder_Variant(t *testing.T) {
+	tests := []struct {
+		variant    BcryptVariant
+		wantPrefix string
+	}{
+		{Bcrypt2a, "$2a$04$"},
+		{Bcrypt2b, "$2b$04$"},
+		{Bcrypt2y, "$2y$04$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.variant.String(), func(t *testing.T) {
+			encoder := NewBcryptPasswordEncoder(WithCost(4), WithBcryptVariant(tt.variant))
+			encoded, err := encoder.Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.wantPrefix) {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.wantPrefix)
+			}
+			// Verify doesn't depend on the configured variant
+			if match, err := NewBcryptPasswordEncoder().Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+		})
+	}
+}
+
+func TestBcryptPasswordEncoder_BrokenVariant(t *testing.T) {
+	encoder := NewBcryptPasswordEncoder()
+	// A valid $2a$ hash of "password123" relabelled with other variants
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), 4)
+	suffix := string(hashed[3:])
+
+	tests := []struct {
+		name         string
+		encoded      string
+		want         bool
+		wantErr      bool
+		wantSentinel error
+	}{
+		{"2y", "$2y" + suffix, true, false, nil},
+		{"2x", "$2x" + suffix, false, true, ErrBcryptBrokenVariant},
+		{"unknown variant", "$2q" + suffix, false, true, ErrInvalidFormat},
+		{"wrong major version", "$1a" + suffix, false, true, ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password123", tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Fatalf("Verify() = %v, %v, want %v, wantErr %v", match, err, tt.want, tt.wantErr)
+			}
+			if tt.wantSentinel != nil && !errors.Is(err, tt.wantSentinel) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantSentinel)
+			}
+			if err := encoder.ValidateEncoded(tt.encoded); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEncoded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewBcryptPasswordEncoderE(t *testing.T) {
+	tests := []struct {
+		name    string
+		cost    int
+		wantErr bool
+	}{
+		{"minimum", bcrypt.MinCost, false},
+		{"default", bcrypt.DefaultCost, false},
+		{"maximum", bcrypt.MaxCost, false},
+		{"below minimum", 3, true},
+		{"zero", 0, true},
+		{"above maximum", 50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewBcryptPasswordEncoderE(WithCost(tt.cost))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBcryptPasswordEncoderE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidBcryptCost) {
+				t.Errorf("NewBcryptPasswordEncoderE() error = %v, want ErrInvalidBcryptCost", err)
+			}
+			if !tt.wantErr && encoder.EffectiveCost() != tt.cost {
+				t.Errorf("EffectiveCost() = %v, want %v", encoder.EffectiveCost(), tt.cost)
+			}
+		})
+	}
+}
+
+func TestBcryptPasswordEncoder_EffectiveCost(t *testing.T) {
+	if cost := NewBcryptPasswordEncoder(WithCost(0)).EffectiveCost(); cost != bcrypt.DefaultCost {
+		t.Errorf("EffectiveCost() = %v, want %v", cost, bcrypt.DefaultCost)
+	}
+	if cost := NewBcryptPasswordEncoder(WithCost(12)).EffectiveCost(); cost != 12 {
+		t.Errorf("EffectiveCost() = %v, want 12", cost)
+	}
+}
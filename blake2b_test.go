@@ -0,0 +1,82 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testBlake2bKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestBlake2bPasswordEncoder_Verify(t *testing.T) {
+	// Hashes computed with Python's hashlib.blake2b(salt + password, key=key, digest_size=keyLen)
+	tests := []struct {
+		name     string
+		key      []byte
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"32 bytes", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=32$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", true, false},
+		{"64 bytes", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=64$cGVwcGVycGVwcGVycGVwcA==$FTVZQfFbDflUi+Jf+b6GK/F4Rh78hT1lhQIPB048NOi5vevciL6Mf14t3HXMN90djlPXEowmxQ8KnvNf/xg3qA==", true, false},
+		{"wrong password", testBlake2bKey, "Password", "hashFunc=blake2b,keyLen=32$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, false},
+		{"wrong key", []byte("another key"), "password", "hashFunc=blake2b,keyLen=32$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, false},
+		{"missing key", nil, "password", "hashFunc=blake2b,keyLen=32$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, true},
+		{"keyLen mismatch", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=16$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, true},
+		{"keyLen too large", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=65$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, true},
+		{"leading zero", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=032$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, true},
+		{"other hash function", testBlake2bKey, "password", "iterations=1000,keyLen=32,hashFunc=sha256$c2FsdHNhbHQ=$ObPh0OPubIP0Wdv6Mxr5XnC3oIwS6I1/IYPqX6UuDXo=", false, true},
+		{"missing salt", testBlake2bKey, "password", "hashFunc=blake2b,keyLen=32$$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewBlake2bPasswordEncoder(tt.key).Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlake2bPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewBlake2bPasswordEncoder(testBlake2bKey, WithBlake2bKeyLen(64), WithBlake2bSaltLen(24))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "hashFunc=blake2b,keyLen=64$") {
+		t.Errorf("Encode() = %v, want the keyLen=64 parameters", encoded)
+	}
+	if match, err := NewBlake2bPasswordEncoder(testBlake2bKey).Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	for _, invalid := range []*Blake2bPasswordEncoder{
+		NewBlake2bPasswordEncoder(nil),
+		NewBlake2bPasswordEncoder(make([]byte, 65)),
+		NewBlake2bPasswordEncoder(testBlake2bKey, WithBlake2bKeyLen(65)),
+		NewBlake2bPasswordEncoder(testBlake2bKey, WithBlake2bSaltLen(0)),
+	} {
+		if _, err := invalid.Encode("password"); err == nil {
+			t.Errorf("Encode() with %+v error = nil, want an error", invalid)
+		}
+	}
+
+	short := "hashFunc=blake2b,keyLen=32$c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0="
+	if err := encoder.ValidateEncoded(short); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestBlake2bPasswordEncoder_Name(t *testing.T) {
+	if name := NewBlake2bPasswordEncoder(testBlake2bKey).Name(); name != "blake2b" {
+		t.Errorf("Name() = %v, want blake2b", name)
+	}
+}
@@ -0,0 +1,110 @@
+package passforge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// BlindIndex derives deterministic tokens from sensitive values such as emails or usernames, so an
+// encrypted column can be searched for equality through a companion index column. A token is
+// "VERSION$BASE64URL(HMAC-SHA256(key, context || 0x00 || value))", truncated to Len bytes. Keys are
+// versioned as peppers are: Index uses the current key and Indexes also covers the older ones, so lookups
+// keep working while rows are reindexed. Use a dedicated key, never a pepper or an encryption key.
+type BlindIndex struct {
+	Keyring                       // Key versions => HMAC keys of at least 32 bytes; the current one is used by Index
+	Context   string              // Separates the indexes of different columns sharing a key
+	Len       int                 // Length of the token in bytes, 1 to 32
+	Normalize func(string) string // Applied to values before hashing, nil to hash them as is
+}
+
+// BlindIndexOption is a functional option used to configure a BlindIndex instance.
+type BlindIndexOption func(*BlindIndex)
+
+// WithBlindIndexKey adds an older key version, still covered by Indexes
+func WithBlindIndexKey(version string, key []byte) BlindIndexOption {
+	return func(b *BlindIndex) {
+		b.Keys[version] = key
+	}
+}
+
+// WithBlindIndexContext sets the context mixed into every token, e.g. the table and column name,
+// so equal values in different columns get unrelated tokens
+// Default: ""
+func WithBlindIndexContext(context string) BlindIndexOption {
+	return func(b *BlindIndex) {
+		b.Context = context
+	}
+}
+
+// WithBlindIndexLen sets the length of the tokens in bytes. Shorter tokens collide more often, so an
+// index lookup returns some unrelated rows, but they reveal less about which rows share a value.
+// Default: 32
+func WithBlindIndexLen(length int) BlindIndexOption {
+	return func(b *BlindIndex) {
+		b.Len = length
+	}
+}
+
+// WithBlindIndexNormalize sets a function applied to values before hashing, e.g. strings.ToLower for emails
+func WithBlindIndexNormalize(normalize func(string) string) BlindIndexOption {
+	return func(b *BlindIndex) {
+		b.Normalize = normalize
+	}
+}
+
+// NewBlindIndex creates a BlindIndex deriving tokens with the current key.
+// Versions must not contain '$' and every key must be at least 32 bytes long.
+func NewBlindIndex(currentVersion string, currentKey []byte, opts ...BlindIndexOption) (*BlindIndex, error) {
+	index := &BlindIndex{
+		Keyring: newKeyring(currentVersion, currentKey),
+		Len:     sha256.Size,
+	}
+	for _, opt := range opts {
+		opt(index)
+	}
+
+	if index.Len < 1 || index.Len > sha256.Size {
+		return nil, fmt.Errorf("blind index length must be between 1 and %d", sha256.Size)
+	}
+	if err := index.validate("blind index key", minKeyLen(sha256.Size)); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// Index returns the token of the value under the current key, to store alongside the encrypted value
+func (b *BlindIndex) Index(value string) string {
+	return b.token(b.CurrentVersion, value)
+}
+
+// Indexes returns the tokens of the value under every key, current key first, to look rows up with
+// WHERE index IN (...) while some are still indexed with an older key
+func (b *BlindIndex) Indexes(value string) []string {
+	versions := b.Versions()
+	tokens := make([]string, 0, len(versions))
+	for _, version := range versions {
+		tokens = append(tokens, b.token(version, value))
+	}
+	return tokens
+}
+
+// NeedsReindex reports whether the token was derived with a key other than the current one.
+// Tokens can't be moved to another key without the value: reindex rows from their decrypted value.
+func (b *BlindIndex) NeedsReindex(token string) bool {
+	return !b.IsCurrent(token)
+}
+
+// token returns the token of the value under the key version
+func (b *BlindIndex) token(version, value string) string {
+	if b.Normalize != nil {
+		value = b.Normalize(value)
+	}
+	key, _ := b.Key(version)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(b.Context))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return version + "$" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:b.Len])
+}
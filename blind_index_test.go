@@ -0,0 +1,87 @@
+package passforge
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewBlindIndex(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	tests := []struct {
+		name    string
+		version string
+		key     []byte
+		opts    []BlindIndexOption
+		wantErr bool
+	}{
+		{"valid", "v1", key, nil, false},
+		{"older key", "v2", key, []BlindIndexOption{WithBlindIndexKey("v1", key)}, false},
+		{"short key", "v1", key[:31], nil, true},
+		{"version with separator", "v$1", key, nil, true},
+		{"empty version", "", key, nil, true},
+		{"zero length", "v1", key, []BlindIndexOption{WithBlindIndexLen(0)}, true},
+		{"length above the hmac size", "v1", key, []BlindIndexOption{WithBlindIndexLen(33)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBlindIndex(tt.version, tt.key, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBlindIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBlindIndex_Index(t *testing.T) {
+	// Tokens computed with Python's hmac module
+	key := bytes.Repeat([]byte("k"), 32)
+	tests := []struct {
+		name  string
+		opts  []BlindIndexOption
+		value string
+		want  string
+	}{
+		{"context", []BlindIndexOption{WithBlindIndexContext("users.email")}, "alice@example.com", "v1$9gGowRcukl6eAis4oFxd3EaDaiQ8_6VABtJtZP_CCwA"},
+		{"truncated", []BlindIndexOption{WithBlindIndexContext("users.email"), WithBlindIndexLen(8)}, "alice@example.com", "v1$9gGowRcukl4"},
+		{"normalized", []BlindIndexOption{WithBlindIndexContext("users.email"), WithBlindIndexNormalize(strings.ToLower)}, "Alice@Example.com", "v1$9gGowRcukl6eAis4oFxd3EaDaiQ8_6VABtJtZP_CCwA"},
+		{"no context", nil, "alice", "v1$B8wgYPSPzLgqCYIbn5AWcKcUOFK8O4FtBUY4RYdNyAU"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := NewBlindIndex("v1", key, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewBlindIndex() error = %v", err)
+			}
+			if got := index.Index(tt.value); got != tt.want {
+				t.Errorf("Index() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	index, _ := NewBlindIndex("v1", key, WithBlindIndexContext("users.username"))
+	if index.Index("alice@example.com") == "v1$9gGowRcukl6eAis4oFxd3EaDaiQ8_6VABtJtZP_CCwA" {
+		t.Errorf("Index() is the same in another context")
+	}
+}
+
+func TestBlindIndex_Rotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte("k"), 32)
+	index, _ := NewBlindIndex("v2", []byte("0123456789abcdef0123456789abcdef"),
+		WithBlindIndexKey("v1", oldKey), WithBlindIndexContext("users.email"))
+
+	want := []string{
+		"v2$fO1I6p16Trr5c-iHtredvkN08if4DcEoLdTKZoMsDJo",
+		"v1$9gGowRcukl6eAis4oFxd3EaDaiQ8_6VABtJtZP_CCwA",
+	}
+	if got := index.Indexes("alice@example.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexes() = %v, want %v", got, want)
+	}
+	if !index.NeedsReindex(want[1]) {
+		t.Errorf("NeedsReindex() = false for a token of an older key")
+	}
+	if index.NeedsReindex(want[0]) {
+		t.Errorf("NeedsReindex() = true for a token of the current key")
+	}
+}
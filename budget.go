@@ -0,0 +1,194 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOverBudget is returned when verifying would exceed the cost budget of the request
+var ErrOverBudget = errors.New("cost budget exceeded")
+
+// CostBudget bounds the resources a single verification may use. Zero fields are unbounded.
+type CostBudget struct {
+	MaxLatency time.Duration // Longest acceptable verification, compared to the observed average
+	MaxMemory  int64         // Most memory in bytes, compared to the parameters of the stored hash
+}
+
+// budgetContextKey is the context key of the cost budget
+type budgetContextKey struct{}
+
+// ContextWithBudget returns a copy of ctx carrying the cost budget, typically tightened by
+// the HTTP layer while the service is overloaded
+func ContextWithBudget(ctx context.Context, budget CostBudget) context.Context {
+	return context.WithValue(ctx, budgetContextKey{}, budget)
+}
+
+// BudgetFromContext returns the cost budget carried by ctx, if any
+func BudgetFromContext(ctx context.Context) (CostBudget, bool) {
+	budget, ok := ctx.Value(budgetContextKey{}).(CostBudget)
+	return budget, ok
+}
+
+// ContextVerifier is implemented by encoders whose verification depends on the request context
+type ContextVerifier interface {
+	VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error)
+}
+
+// verifyContext calls VerifyContext when the encoder supports it and Verify otherwise
+func verifyContext(ctx context.Context, encoder PasswordEncoder, rawPassword, encodedPassword string) (bool, error) {
+	if verifier, ok := encoder.(ContextVerifier); ok {
+		return verifier.VerifyContext(ctx, rawPassword, encodedPassword)
+	}
+	return encoder.Verify(rawPassword, encodedPassword)
+}
+
+// BudgetedPasswordEncoder sheds load by refusing verifications that would exceed the cost budget
+// carried by the context, or the context deadline. Memory is estimated from the stored parameters,
+// latency from a moving average of previous verifications. While shedding on latency, one probe per
+// ProbeInterval is still verified so the average can recover once the service catches up. Wrap it in
+// a LimitedPasswordEncoder to combine it with brute-force protection.
+type BudgetedPasswordEncoder struct {
+	Encoder       PasswordEncoder
+	OverBudget    func(ctx context.Context, rawPassword, encodedPassword string) (bool, error) // Fallback path, e.g. a queue; nil fails fast with ErrOverBudget
+	ProbeInterval time.Duration                                                                // Minimum time between probes while over the latency budget; 0 disables probes
+
+	mu        sync.Mutex
+	latency   time.Duration // Moving average of verification latency
+	lastProbe time.Time     // When the last probe was let through
+}
+
+// BudgetOption is a functional option used to configure a BudgetedPasswordEncoder instance.
+type BudgetOption func(*BudgetedPasswordEncoder)
+
+// WithOverBudgetHandler routes verifications over budget to handler instead of failing with ErrOverBudget,
+// e.g. to enqueue them or verify against a cheaper path
+func WithOverBudgetHandler(handler func(ctx context.Context, rawPassword, encodedPassword string) (bool, error)) BudgetOption {
+	return func(b *BudgetedPasswordEncoder) {
+		b.OverBudget = handler
+	}
+}
+
+// WithBudgetProbeInterval sets how often a verification over the latency budget is let through to refresh
+// the latency estimate. 0 disables probes.
+// Default: 1s
+func WithBudgetProbeInterval(interval time.Duration) BudgetOption {
+	return func(b *BudgetedPasswordEncoder) {
+		b.ProbeInterval = interval
+	}
+}
+
+// NewBudgetedPasswordEncoder creates a new BudgetedPasswordEncoder wrapping the given encoder
+func NewBudgetedPasswordEncoder(encoder PasswordEncoder, opts ...BudgetOption) *BudgetedPasswordEncoder {
+	b := &BudgetedPasswordEncoder{Encoder: encoder, ProbeInterval: time.Second}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Encode encodes the raw password using the wrapped encoder
+func (b *BudgetedPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return b.Encoder.Encode(rawPassword)
+}
+
+// Verify verifies the password without a budget
+func (b *BudgetedPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return b.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password unless it would exceed the budget or deadline of ctx
+func (b *BudgetedPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	if b.overBudget(ctx, encodedPassword) {
+		if b.OverBudget != nil {
+			return b.OverBudget(ctx, rawPassword, encodedPassword)
+		}
+		return false, ErrOverBudget
+	}
+
+	start := time.Now()
+	match, err := verifyContext(ctx, b.Encoder, rawPassword, encodedPassword)
+	b.observe(time.Since(start))
+	return match, err
+}
+
+// AdminStatus reports the latency estimate and the wrapped encoder
+func (b *BudgetedPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"estimatedLatencyMs": float64(b.EstimatedLatency().Microseconds()) / 1000},
+		Encoders: map[string]EncoderStatus{"inner": describe(b.Encoder)},
+	}
+}
+
+// Name returns the name of the wrapped encoder.
+func (b *BudgetedPasswordEncoder) Name() string {
+	return b.Encoder.Name()
+}
+
+// EstimatedLatency returns the moving average of verification latency, 0 before the first verification
+func (b *BudgetedPasswordEncoder) EstimatedLatency() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.latency
+}
+
+// observe folds a verification latency into the moving average
+func (b *BudgetedPasswordEncoder) observe(latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.latency == 0 {
+		b.latency = latency
+		return
+	}
+	b.latency = (b.latency*7 + latency) / 8
+}
+
+// probe reports whether a verification over the latency budget may go through to refresh the estimate
+func (b *BudgetedPasswordEncoder) probe() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ProbeInterval <= 0 || time.Since(b.lastProbe) < b.ProbeInterval {
+		return false
+	}
+	b.lastProbe = time.Now()
+	return true
+}
+
+// overBudget reports whether verifying the encoded password would exceed the budget or deadline of ctx
+func (b *BudgetedPasswordEncoder) overBudget(ctx context.Context, encodedPassword string) bool {
+	budget, hasBudget := BudgetFromContext(ctx)
+	if hasBudget && budget.MaxMemory > 0 && estimatedMemory(encodedPassword) > budget.MaxMemory {
+		return true
+	}
+
+	latency := b.EstimatedLatency()
+	if latency == 0 {
+		return false
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	slow := (hasDeadline && time.Until(deadline) < latency) || (hasBudget && budget.MaxLatency > 0 && latency > budget.MaxLatency)
+	return slow && !b.probe()
+}
+
+// estimatedMemory returns the memory in bytes needed to verify an Argon2 or scrypt hash, 0 for other algorithms
+func estimatedMemory(encodedPassword string) int64 {
+	h := PasswordHash{encoded: encodedPassword}
+	params := h.Params()
+	param := func(name string) int64 {
+		value, _ := strconv.ParseInt(params[name], 10, 64)
+		return value
+	}
+
+	switch h.Algorithm() {
+	case "argon2":
+		return param("memory") * 1024
+	case "scrypt":
+		return 128 * param("N") * param("r")
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudgetedPasswordEncoder_MaxMemory(t *testing.T) {
+	argon2 := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	delegating, _ := NewDelegatingPasswordEncoder("argon2", argon2, NewNoOpPasswordEncoder())
+	encoder := NewBudgetedPasswordEncoder(delegating)
+	encoded, _ := encoder.Encode("password123")
+
+	testCases := []struct {
+		name            string
+		budget          *CostBudget
+		encodedPassword string
+		wantErr         error
+	}{
+		{name: "no budget", encodedPassword: encoded},
+		{name: "within budget", budget: &CostBudget{MaxMemory: 1024 * 1024}, encodedPassword: encoded},
+		{name: "over budget", budget: &CostBudget{MaxMemory: 1024*1024 - 1}, encodedPassword: encoded, wantErr: ErrOverBudget},
+		{name: "cheap algorithm", budget: &CostBudget{MaxMemory: 1}, encodedPassword: "{noop}password123"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.budget != nil {
+				ctx = ContextWithBudget(ctx, *tc.budget)
+			}
+			match, err := encoder.VerifyContext(ctx, "password123", tc.encodedPassword)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("VerifyContext() error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && !match {
+				t.Errorf("VerifyContext() = false, want true")
+			}
+		})
+	}
+}
+
+func TestBudgetedPasswordEncoder_Latency(t *testing.T) {
+	var routed int
+	encoder := NewBudgetedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)),
+		WithOverBudgetHandler(func(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+			routed++
+			return false, nil
+		}),
+		WithBudgetProbeInterval(0))
+	encoded, _ := encoder.Encode("password123")
+
+	// Nothing is known before the first verification
+	if match, _ := encoder.VerifyContext(ContextWithBudget(context.Background(), CostBudget{MaxLatency: time.Nanosecond}), "password123", encoded); !match {
+		t.Fatalf("VerifyContext() = false before any latency was observed")
+	}
+	if encoder.EstimatedLatency() <= 0 {
+		t.Fatalf("EstimatedLatency() = %v, want observed latency", encoder.EstimatedLatency())
+	}
+
+	// The observed latency now exceeds the budget and an expiring deadline
+	_, _ = encoder.VerifyContext(ContextWithBudget(context.Background(), CostBudget{MaxLatency: time.Nanosecond}), "password123", encoded)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	_, _ = encoder.VerifyContext(ctx, "password123", encoded)
+	if routed != 2 {
+		t.Errorf("over-budget handler called %d times, want 2", routed)
+	}
+}
+
+func TestBudgetedPasswordEncoder_WithLimiter(t *testing.T) {
+	limiter := NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(1))
+	encoder := NewLimitedPasswordEncoder(NewBudgetedPasswordEncoder(NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))), limiter)
+	encoded, _ := encoder.Encode("password123")
+
+	ctx := ContextWithAttempt(context.Background(), Attempt{User: "alice"})
+	ctx = ContextWithBudget(ctx, CostBudget{MaxMemory: 1})
+	for i := 0; i < 3; i++ {
+		if _, err := encoder.VerifyContext(ctx, "wrong", encoded); !errors.Is(err, ErrOverBudget) {
+			t.Fatalf("VerifyContext() error = %v, want ErrOverBudget", err)
+		}
+	}
+
+	// Shed attempts are not counted as failures
+	if match, err := encoder.VerifyContext(ContextWithAttempt(context.Background(), Attempt{User: "alice"}), "password123", encoded); err != nil || !match {
+		t.Errorf("VerifyContext() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestBudgetedPasswordEncoder_Probe(t *testing.T) {
+	encoder := NewBudgetedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), WithBudgetProbeInterval(time.Hour))
+	encoded, _ := encoder.Encode("password123")
+	ctx := ContextWithBudget(context.Background(), CostBudget{MaxLatency: time.Nanosecond})
+
+	// The first verification sets the estimate over budget, the next one is let through as a probe
+	for i := 0; i < 2; i++ {
+		if match, err := encoder.VerifyContext(ctx, "password123", encoded); err != nil || !match {
+			t.Fatalf("VerifyContext() #%d = %v, %v, want true, nil", i, match, err)
+		}
+	}
+	if _, err := encoder.VerifyContext(ctx, "password123", encoded); !errors.Is(err, ErrOverBudget) {
+		t.Errorf("VerifyContext() error = %v, want ErrOverBudget until the next probe", err)
+	}
+}
@@ -0,0 +1,164 @@
+package passforge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// CachingPasswordEncoder remembers successful verifications for a short time, so that clients that
+// re-authenticate on every request (e.g. HTTP basic auth) don't pay the full hashing cost each time.
+//
+// Entries are keyed by HMAC-SHA256 of the password and encoded password under a random per-process key,
+// so the cache never holds anything that can be checked offline. Failed verifications are never cached,
+// and a changed hash misses the cache because the encoded password is part of the key.
+type CachingPasswordEncoder struct {
+	Encoder    PasswordEncoder
+	TTL        time.Duration    // How long a successful verification is remembered
+	MaxEntries int              // Upper bound of the cache size
+	Now        func() time.Time // Time source
+
+	key     []byte
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]time.Time // expiry by key
+}
+
+// CacheOption is a function that configures a CachingPasswordEncoder
+type CacheOption func(*CachingPasswordEncoder)
+
+// WithCacheMaxEntries sets the upper bound of the cache size
+// Default: 10000
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(c *CachingPasswordEncoder) {
+		c.MaxEntries = maxEntries
+	}
+}
+
+// NewCachingPasswordEncoder creates a CachingPasswordEncoder remembering successful verifications for ttl
+func NewCachingPasswordEncoder(encoder PasswordEncoder, ttl time.Duration, opts ...CacheOption) (*CachingPasswordEncoder, error) {
+	if ttl <= 0 {
+		return nil, errors.New("cache: ttl must be positive")
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(randReader(nil), key); err != nil {
+		return nil, err
+	}
+
+	cache := &CachingPasswordEncoder{
+		Encoder:    encoder,
+		TTL:        ttl,
+		MaxEntries: 10000,
+		Now:        time.Now,
+		key:        key,
+		entries:    make(map[[sha256.Size]byte]time.Time),
+	}
+	for _, opt := range opts {
+		opt(cache)
+	}
+	return cache, nil
+}
+
+// Encode encodes the password using the wrapped encoder
+func (c *CachingPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return c.Encoder.Encode(rawPassword)
+}
+
+// Verify verifies the password, answering from the cache when possible
+func (c *CachingPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return c.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password, passing ctx to the wrapped encoder on a cache miss
+func (c *CachingPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	key := c.cacheKey(rawPassword, encodedPassword)
+	now := c.Now()
+
+	c.mu.Lock()
+	expiry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(expiry) {
+		return true, nil
+	}
+
+	match, err := verifyContext(ctx, c.Encoder, rawPassword, encodedPassword)
+	if err != nil || !match {
+		return match, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.MaxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = now.Add(c.TTL)
+	return true, nil
+}
+
+// ValidateEncoded validates the encoded password with the wrapped encoder
+func (c *CachingPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	return ValidateEncoded(c.Encoder, encodedPassword)
+}
+
+// AdminStatus reports the cache settings and size, and the wrapped encoder
+func (c *CachingPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"ttlMs": c.TTL.Milliseconds(), "maxEntries": c.MaxEntries, "entries": c.Len()},
+		Encoders: map[string]EncoderStatus{"inner": describe(c.Encoder)},
+	}
+}
+
+// Name returns the name of the wrapped encoder.
+func (c *CachingPasswordEncoder) Name() string {
+	return c.Encoder.Name()
+}
+
+// Purge empties the cache, e.g. after a password change
+func (c *CachingPasswordEncoder) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// Len returns the number of cached verifications, including expired ones not yet evicted
+func (c *CachingPasswordEncoder) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// evict removes expired entries, or an arbitrary half of the cache when none have expired.
+// The caller must hold c.mu.
+func (c *CachingPasswordEncoder) evict(now time.Time) {
+	for key, expiry := range c.entries {
+		if !now.Before(expiry) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.MaxEntries/2+1 {
+			break
+		}
+		delete(c.entries, key)
+	}
+}
+
+// cacheKey returns the HMAC of the length-prefixed password and encoded password
+func (c *CachingPasswordEncoder) cacheKey(rawPassword, encodedPassword string) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, c.key)
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(rawPassword)))
+	mac.Write(length[:])
+	mac.Write([]byte(rawPassword))
+	mac.Write([]byte(encodedPassword))
+
+	var key [sha256.Size]byte
+	copy(key[:], mac.Sum(nil))
+	return key
+}
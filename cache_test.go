@@ -0,0 +1,64 @@
+package passforge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachingPasswordEncoder(t *testing.T) {
+	metrics := &MemoryMetrics{}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache, err := NewCachingPasswordEncoder(NewMeteredPasswordEncoder(NewNoOpPasswordEncoder(), metrics), time.Minute)
+	if err != nil {
+		t.Fatalf("NewCachingPasswordEncoder() error = %v", err)
+	}
+	cache.Now = func() time.Time { return now }
+
+	verify := func(raw, encoded string, want bool) {
+		t.Helper()
+		if match, err := cache.Verify(raw, encoded); err != nil || match != want {
+			t.Errorf("Verify(%q) got = %v, %v, want %v", raw, match, err, want)
+		}
+	}
+
+	verify("password", "password", true)
+	verify("password", "password", true)
+	if got := metrics.Snapshot().Verifies; got != 1 {
+		t.Errorf("inner verifications = %d, want 1 after a cache hit", got)
+	}
+
+	// Failures are not cached
+	verify("wrong", "password", false)
+	verify("wrong", "password", false)
+	if got := metrics.Snapshot().Verifies; got != 3 {
+		t.Errorf("inner verifications = %d, want 3", got)
+	}
+
+	// A different hash misses the cache
+	verify("password", "other", false)
+
+	now = now.Add(time.Minute)
+	verify("password", "password", true)
+	if got := metrics.Snapshot().Verifies; got != 5 {
+		t.Errorf("inner verifications = %d, want 5 after expiry", got)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after Purge, want 0", cache.Len())
+	}
+}
+
+func TestCachingPasswordEncoder_Bounded(t *testing.T) {
+	cache, _ := NewCachingPasswordEncoder(NewNoOpPasswordEncoder(), time.Hour, WithCacheMaxEntries(4))
+	for _, password := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		_, _ = cache.Verify(password, password)
+	}
+	if cache.Len() > 4 {
+		t.Errorf("Len() = %d, want at most 4", cache.Len())
+	}
+
+	if _, err := NewCachingPasswordEncoder(NewNoOpPasswordEncoder(), 0); err == nil {
+		t.Errorf("NewCachingPasswordEncoder() with zero ttl should fail")
+	}
+}
@@ -0,0 +1,80 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCiscoType8PasswordEncoder_Verify(t *testing.T) {
+	// Example hash of hashcat mode 9200
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"hashcat example", "hashcat", "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk", true, false},
+		{"wrong password", "Hashcat", "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk", false, false},
+		{"wrong salt", "hashcat", "$8$TnGX/fE4KGHOVV$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk", false, false},
+		{"type 9", "hashcat", "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6", false, true},
+		{"short salt", "hashcat", "$8$TnGX/fE4KGHOV$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk", false, true},
+		{"standard base64", "hashcat", "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f+EMHr6M+FzU8xnZnBr/tJdFWk", false, true},
+		{"truncated hash", "hashcat", "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFW", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewCiscoType8PasswordEncoder().Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestCiscoType9PasswordEncoder_Verify(t *testing.T) {
+	// Example hash of hashcat mode 9300
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"hashcat example", "hashcat", "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6", true, false},
+		{"wrong password", "Hashcat", "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6", false, false},
+		{"type 8", "hashcat", "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk", false, true},
+		{"missing hash", "hashcat", "$9$2MJBozw/9R3UsU", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewCiscoType9PasswordEncoder().Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestCiscoPasswordEncoders_Encode(t *testing.T) {
+	for _, encoder := range []PasswordEncoder{NewCiscoType8PasswordEncoder(), NewCiscoType9PasswordEncoder()} {
+		if _, err := encoder.Encode("hashcat"); !errors.Is(err, ErrEncodeNotSupported) {
+			t.Errorf("%s Encode() error = %v, want ErrEncodeNotSupported", encoder.Name(), err)
+		}
+	}
+}
+
+func TestCiscoPasswordEncoders_Name(t *testing.T) {
+	if name := NewCiscoType8PasswordEncoder().Name(); name != "cisco-type8" {
+		t.Errorf("Name() = %v, want cisco-type8", name)
+	}
+	if name := NewCiscoType9PasswordEncoder().Name(); name != "cisco-type9" {
+		t.Errorf("Name() = %v, want cisco-type9", name)
+	}
+}
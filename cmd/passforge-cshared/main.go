@@ -0,0 +1,103 @@
+//go:build cgo
+
+// Command passforge-cshared builds passforge as a C shared library so services written in other
+// languages can call the exact same hashing policy through FFI:
+//
+//	go build -buildmode=c-shared -o libpassforge.so ./cmd/passforge-cshared
+//
+// The generated libpassforge.h declares
+//
+//	char* passforge_encode(char* password, char** err);
+//	int   passforge_verify(char* password, char* hash, char** err);
+//	char* passforge_detect(char* hash, char** err);
+//	void  passforge_free(char* p);
+//
+// Strings returned by the library, including error messages, are allocated with malloc and must
+// be released with passforge_free. On failure passforge_encode and passforge_detect return NULL
+// and passforge_verify returns -1, with *err set when err is not NULL.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/nduyhai/passforge"
+)
+
+var encoder *passforge.DelegatingPasswordEncoder
+
+func init() {
+	var err error
+	encoder, err = passforge.NewDelegatingPasswordEncoder("argon2",
+		passforge.NewArgon2PasswordEncoder(),
+		passforge.NewBcryptPasswordEncoder(),
+		passforge.NewScryptPasswordEncoder(),
+		passforge.NewPBKDF2PasswordEncoder(),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+//export passforge_encode
+func passforge_encode(password *C.char, errOut **C.char) *C.char {
+	if password == nil {
+		setError(errOut, "password is NULL")
+		return nil
+	}
+	hash, err := encoder.Encode(C.GoString(password))
+	if err != nil {
+		setError(errOut, err.Error())
+		return nil
+	}
+	return C.CString(hash)
+}
+
+//export passforge_verify
+func passforge_verify(password, hash *C.char, errOut **C.char) C.int {
+	if password == nil || hash == nil {
+		setError(errOut, "password or hash is NULL")
+		return -1
+	}
+	match, err := encoder.Verify(C.GoString(password), C.GoString(hash))
+	if err != nil {
+		setError(errOut, err.Error())
+		return -1
+	}
+	if match {
+		return 1
+	}
+	return 0
+}
+
+//export passforge_detect
+func passforge_detect(hash *C.char, errOut **C.char) *C.char {
+	if hash == nil {
+		setError(errOut, "hash is NULL")
+		return nil
+	}
+	id, err := passforge.DetectEncoding(C.GoString(hash))
+	if err != nil {
+		setError(errOut, err.Error())
+		return nil
+	}
+	return C.CString(id)
+}
+
+//export passforge_free
+func passforge_free(p *C.char) {
+	C.free(unsafe.Pointer(p))
+}
+
+// setError stores a malloc'd copy of msg in *errOut when the caller asked for it
+func setError(errOut **C.char, msg string) {
+	if errOut != nil {
+		*errOut = C.CString(msg)
+	}
+}
+
+func main() {}
@@ -0,0 +1,95 @@
+// Command passforge-rewrap moves peppered hashes to a new pepper without any plaintext passwords.
+// It reads one encoded password per line from stdin and writes the rewrapped value to stdout:
+//
+//	PASSFORGE_PEPPERS=v1=<hex key>,v2=<hex key> PASSFORGE_PEPPER_CURRENT=v2 passforge-rewrap < hashes.txt
+//
+// Keys are taken from the environment rather than flags so they don't show up in process listings.
+// A leading "{id}" prefix written by a DelegatingPasswordEncoder is kept as is. Lines that cannot be
+// rewrapped are copied unchanged and reported on stderr, and the command then exits with status 1.
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nduyhai/passforge"
+)
+
+func main() {
+	encoder, err := newEncoder(os.Getenv("PASSFORGE_PEPPERS"), os.Getenv("PASSFORGE_PEPPER_CURRENT"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "passforge-rewrap:", err)
+		os.Exit(2)
+	}
+
+	failed := false
+	scanner := bufio.NewScanner(os.Stdin)
+	out := bufio.NewWriter(os.Stdout)
+	line := 0
+	for scanner.Scan() {
+		line++
+		rewrapped, err := rewrap(encoder, scanner.Text())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "passforge-rewrap: line %d: %v\n", line, err)
+			failed = true
+			rewrapped = scanner.Text()
+		}
+		fmt.Fprintln(out, rewrapped)
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "passforge-rewrap:", err)
+		os.Exit(2)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "passforge-rewrap:", err)
+		os.Exit(2)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// newEncoder builds a peppered encoder from "version=hexkey,..." and the current version.
+// Rewrapping never touches the inner hash, so the inner encoder is irrelevant.
+func newEncoder(peppers, current string) (*passforge.PepperedPasswordEncoder, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(peppers, ",") {
+		version, hexKey, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			return nil, fmt.Errorf("PASSFORGE_PEPPERS: expected version=hexkey entries")
+		}
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("PASSFORGE_PEPPERS: pepper '%s' is not hex encoded", version)
+		}
+		keys[version] = key
+	}
+
+	currentKey, ok := keys[current]
+	if !ok {
+		return nil, fmt.Errorf("PASSFORGE_PEPPER_CURRENT: pepper '%s' not found in PASSFORGE_PEPPERS", current)
+	}
+	var opts []passforge.PepperOption
+	for version, key := range keys {
+		opts = append(opts, passforge.WithPepper(version, key))
+	}
+	return passforge.NewPepperedPasswordEncoder(passforge.NewNoOpPasswordEncoder(), current, currentKey, opts...)
+}
+
+// rewrap rewraps a single value, keeping an optional "{id}" prefix
+func rewrap(encoder *passforge.PepperedPasswordEncoder, value string) (string, error) {
+	prefix := ""
+	if strings.HasPrefix(value, "{") {
+		if idx := strings.Index(value, "}"); idx != -1 {
+			prefix, value = value[:idx+1], value[idx+1:]
+		}
+	}
+	rewrapped, err := encoder.Rewrap(value)
+	if err != nil {
+		return "", err
+	}
+	return prefix + rewrapped, nil
+}
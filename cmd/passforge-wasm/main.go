@@ -0,0 +1,78 @@
+//go:build js && wasm
+
+// Command passforge-wasm exposes passforge to JavaScript when compiled to WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o passforge.wasm ./cmd/passforge-wasm
+//
+// After instantiating the module with wasm_exec.js, a global "passforge" object provides
+//
+//	passforge.encode(password)       -> {hash} | {error}
+//	passforge.verify(password, hash) -> {match} | {error}
+//	passforge.strength(password)     -> {score, entropy, warnings}
+//
+// Hashing is CPU and memory intensive and blocks the calling thread; call it from a Web Worker.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/nduyhai/passforge"
+)
+
+func main() {
+	encoder, err := passforge.NewDelegatingPasswordEncoder("argon2",
+		passforge.NewArgon2PasswordEncoder(),
+		passforge.NewBcryptPasswordEncoder(),
+		passforge.NewScryptPasswordEncoder(),
+		passforge.NewPBKDF2PasswordEncoder(),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	js.Global().Set("passforge", js.ValueOf(map[string]interface{}{
+		"encode": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) != 1 {
+				return result("error", "encode expects (password)")
+			}
+			hash, err := encoder.Encode(args[0].String())
+			if err != nil {
+				return result("error", err.Error())
+			}
+			return result("hash", hash)
+		}),
+		"verify": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) != 2 {
+				return result("error", "verify expects (password, hash)")
+			}
+			match, err := encoder.Verify(args[0].String(), args[1].String())
+			if err != nil {
+				return result("error", err.Error())
+			}
+			return result("match", match)
+		}),
+		"strength": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) != 1 {
+				return result("error", "strength expects (password)")
+			}
+			strength := passforge.EstimateStrength(args[0].String())
+			warnings := make([]interface{}, len(strength.Warnings))
+			for i, w := range strength.Warnings {
+				warnings[i] = w
+			}
+			return js.ValueOf(map[string]interface{}{
+				"score":    strength.Score,
+				"entropy":  strength.Entropy,
+				"warnings": warnings,
+			})
+		}),
+	}))
+
+	// Keep the Go runtime alive so the exported functions remain callable
+	select {}
+}
+
+// result builds a single-field JavaScript object
+func result(key string, value interface{}) js.Value {
+	return js.ValueOf(map[string]interface{}{key: value})
+}
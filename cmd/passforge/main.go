@@ -0,0 +1,53 @@
+// Command passforge provides operational tooling around the passforge library.
+//
+//	passforge vectors [-seed SEED]    print deterministic test vectors for every encoder and format as JSON
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nduyhai/passforge"
+	"github.com/nduyhai/passforge/passforgetesting"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "vectors":
+		err = vectors(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "passforge:", err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the available commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: passforge vectors [-seed SEED]")
+	os.Exit(2)
+}
+
+// vectors prints test vectors generated from a deterministic seed
+func vectors(args []string) error {
+	flags := flag.NewFlagSet("vectors", flag.ExitOnError)
+	seed := flags.String("seed", "passforge", "seed of the deterministic salt source")
+	_ = flags.Parse(args)
+
+	vectors, err := passforge.GenerateTestVectors(passforgetesting.NewDeterministicReader(*seed))
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(vectors)
+}
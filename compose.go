@@ -0,0 +1,67 @@
+package passforge
+
+import (
+	"time"
+)
+
+// Decorator wraps an encoder with cross-cutting behavior
+type Decorator func(PasswordEncoder) (PasswordEncoder, error)
+
+// Compose layers decorators around a base encoder. Decorators are listed outermost first, so
+//
+//	Compose(argon2, Metrics(m), LimitLength(1024), Normalize(norm.NFKC.String))
+//
+// measures every call, rejects oversized passwords and normalizes the rest before Argon2 sees them.
+func Compose(base PasswordEncoder, decorators ...Decorator) (PasswordEncoder, error) {
+	encoder := base
+	for i := len(decorators) - 1; i >= 0; i-- {
+		var err error
+		encoder, err = decorators[i](encoder)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encoder, nil
+}
+
+// Pepper encrypts the encoded output with versioned keys, see PepperedPasswordEncoder
+func Pepper(currentVersion string, currentKey []byte, opts ...PepperOption) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewPepperedPasswordEncoder(encoder, currentVersion, currentKey, opts...)
+	}
+}
+
+// Normalize transforms raw passwords before they are encoded or verified, see NormalizingPasswordEncoder
+func Normalize(normalize func(string) string) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewNormalizingPasswordEncoder(encoder, normalize), nil
+	}
+}
+
+// LimitLength rejects raw passwords longer than maxBytes, see LengthLimitedPasswordEncoder
+func LimitLength(maxBytes int) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewLengthLimitedPasswordEncoder(encoder, maxBytes), nil
+	}
+}
+
+// Metrics reports the outcome and duration of every call, see MeteredPasswordEncoder
+func Metrics(metrics EncoderMetrics) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewMeteredPasswordEncoder(encoder, metrics), nil
+	}
+}
+
+// RateLimit limits verification attempts, see LimitedPasswordEncoder
+func RateLimit(limiter AttemptLimiter, opts ...LimitedOption) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewLimitedPasswordEncoder(encoder, limiter, opts...), nil
+	}
+}
+
+// Cache remembers successful verifications for ttl, see CachingPasswordEncoder
+func Cache(ttl time.Duration, opts ...CacheOption) Decorator {
+	return func(encoder PasswordEncoder) (PasswordEncoder, error) {
+		return NewCachingPasswordEncoder(encoder, ttl, opts...)
+	}
+}
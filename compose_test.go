@@ -0,0 +1,89 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompose(t *testing.T) {
+	metrics := &MemoryMetrics{}
+	encoder, err := Compose(NewNoOpPasswordEncoder(),
+		Metrics(metrics),
+		LimitLength(16),
+		Normalize(strings.ToLower),
+		Cache(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+
+	// Outermost first
+	if _, ok := encoder.(*MeteredPasswordEncoder); !ok {
+		t.Fatalf("Compose() outer encoder = %T, want *MeteredPasswordEncoder", encoder)
+	}
+	if encoder.Name() != "noop" {
+		t.Errorf("Name() = %v, want noop", encoder.Name())
+	}
+
+	encoded, err := encoder.Encode("PassWord")
+	if err != nil || encoded != "password" {
+		t.Errorf("Encode() got = %v, %v, want password", encoded, err)
+	}
+	if match, err := encoder.Verify("PASSWORD", encoded); err != nil || !match {
+		t.Errorf("Verify() got = %v, %v, want true", match, err)
+	}
+	if _, err := encoder.Verify(strings.Repeat("x", 17), encoded); !errors.Is(err, ErrPasswordTooLong) {
+		t.Errorf("Verify() error = %v, want ErrPasswordTooLong", err)
+	}
+
+	if snapshot := metrics.Snapshot(); snapshot.Encodes != 1 || snapshot.Verifies != 2 || snapshot.VerifyErrors != 1 {
+		t.Errorf("Snapshot() = %+v", snapshot)
+	}
+}
+
+func TestCompose_Error(t *testing.T) {
+	_, err := Compose(NewNoOpPasswordEncoder(), Metrics(&MemoryMetrics{}), Pepper("", make([]byte, 32)))
+	if err == nil {
+		t.Errorf("Compose() should return the decorator error")
+	}
+}
+
+func TestCompose_Pepper(t *testing.T) {
+	encoder, err := Compose(NewNoOpPasswordEncoder(), Pepper("v1", make([]byte, 32)), LimitLength(64))
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	encoded, _ := encoder.Encode("password")
+	if !strings.HasPrefix(encoded, "v1$") {
+		t.Errorf("Encode() got = %v, want a peppered value", encoded)
+	}
+	if match, err := encoder.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() got = %v, %v, want true", match, err)
+	}
+}
+
+func TestCompose_PepperRateLimitContext(t *testing.T) {
+	encoder, err := Compose(NewNoOpPasswordEncoder(),
+		Pepper("v1", make([]byte, 32)),
+		RateLimit(NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(1))),
+	)
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	verifier, ok := encoder.(ContextVerifier)
+	if !ok {
+		t.Fatalf("Compose() encoder %T does not implement ContextVerifier", encoder)
+	}
+
+	encoded, _ := encoder.Encode("password")
+	ctx := ContextWithAttempt(context.Background(), Attempt{User: "alice"})
+	if match, err := verifier.VerifyContext(ctx, "wrong", encoded); err != nil || match {
+		t.Errorf("VerifyContext() got = %v, %v, want false", match, err)
+	}
+	if _, err := verifier.VerifyContext(ctx, "password", encoded); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("VerifyContext() error = %v, want ErrTooManyAttempts", err)
+	}
+}
@@ -0,0 +1,238 @@
+package passforge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventConfigChange reports a change of the hashing configuration
+const EventConfigChange AuditEventType = "config_change"
+
+// ErrConfigChangeSignature is returned when a config change event was altered or signed with another key
+var ErrConfigChangeSignature = errors.New("config change: invalid signature")
+
+// ConfigChangeKind describes why the hashing configuration changed
+type ConfigChangeKind string
+
+const (
+	// ConfigReload is a hot reload of the encoder configuration
+	ConfigReload ConfigChangeKind = "reload"
+	// ConfigParamsBump is an increase of cost parameters
+	ConfigParamsBump ConfigChangeKind = "params_bump"
+	// ConfigPepperRotation is the introduction of a new pepper version
+	ConfigPepperRotation ConfigChangeKind = "pepper_rotation"
+)
+
+// ParamChange is one configuration value that differs between the old and new encoder
+type ParamChange struct {
+	Path string `json:"path"` // e.g. "encoders.argon2.params.memory"
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// ConfigChangeRecord is the signed evidence of a configuration change. Previous is the signature
+// of the preceding record, chaining records so that a removed entry breaks verification of the next.
+type ConfigChangeRecord struct {
+	Kind     ConfigChangeKind `json:"kind"`
+	Actor    string           `json:"actor"`
+	Time     time.Time        `json:"time"`
+	Old      EncoderStatus    `json:"old"`
+	New      EncoderStatus    `json:"new"`
+	Changes  []ParamChange    `json:"changes"`
+	Previous string           `json:"previous,omitempty"`
+}
+
+// ConfigAuditor emits a signed ConfigChangeRecord through an AuditSink whenever the hashing
+// configuration changes. Records describe encoders the way the AdminHandler does, so they never
+// contain pepper or server keys.
+type ConfigAuditor struct {
+	Sink AuditSink
+	Key  []byte           // HMAC-SHA256 key signing the records, at least 16 bytes
+	Now  func() time.Time // Time source
+
+	mu   sync.Mutex
+	last string // Signature of the last emitted record
+}
+
+// ConfigAuditOption is a function that configures a ConfigAuditor
+type ConfigAuditOption func(*ConfigAuditor)
+
+// WithConfigAuditPrevious continues the signature chain from the last record emitted before a restart
+func WithConfigAuditPrevious(signature string) ConfigAuditOption {
+	return func(c *ConfigAuditor) {
+		c.last = signature
+	}
+}
+
+// NewConfigAuditor creates a ConfigAuditor signing records with key
+func NewConfigAuditor(sink AuditSink, key []byte, opts ...ConfigAuditOption) (*ConfigAuditor, error) {
+	if len(key) < 16 {
+		return nil, errors.New("config audit: key must be at least 16 bytes")
+	}
+	auditor := &ConfigAuditor{
+		Sink: sink,
+		Key:  key,
+		Now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(auditor)
+	}
+	return auditor, nil
+}
+
+// Record emits a signed record of the change from oldEncoder to newEncoder made by actor.
+// A record is emitted even when nothing differs, as evidence that the configuration was reviewed.
+func (c *ConfigAuditor) Record(ctx context.Context, kind ConfigChangeKind, actor string, oldEncoder, newEncoder PasswordEncoder) (*ConfigChangeRecord, error) {
+	admin := &AdminHandler{}
+	record := &ConfigChangeRecord{
+		Kind:  kind,
+		Actor: actor,
+		Time:  c.Now(),
+		New:   admin.describe(newEncoder),
+	}
+	if oldEncoder != nil {
+		record.Old = admin.describe(oldEncoder)
+	}
+	record.Changes = diffStatus(record.Old, record.New)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record.Previous = c.last
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	signature := signConfigChange(c.Key, payload)
+
+	changes := make([]string, len(record.Changes))
+	for i, change := range record.Changes {
+		changes[i] = fmt.Sprintf("%s: %s -> %s", change.Path, change.Old, change.New)
+	}
+	event := AuditEvent{
+		Type:      EventConfigChange,
+		Time:      record.Time,
+		Algorithm: record.New.Name,
+		Message:   fmt.Sprintf("hashing configuration %s by %s", kind, actor),
+		Details: map[string]string{
+			"actor":     actor,
+			"kind":      string(kind),
+			"changes":   strings.Join(changes, "; "),
+			"record":    string(payload),
+			"signature": signature,
+		},
+	}
+	if err := c.Sink.Emit(ctx, event); err != nil {
+		return nil, err
+	}
+	c.last = signature
+	return record, nil
+}
+
+// Last returns the signature of the last emitted record, to be passed to WithConfigAuditPrevious after a restart
+func (c *ConfigAuditor) Last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.last
+}
+
+// VerifyConfigChange checks the signature of a config change event and returns its record.
+// Callers verifying a sequence should also check that each Previous equals the prior event's signature.
+func VerifyConfigChange(key []byte, event AuditEvent) (*ConfigChangeRecord, error) {
+	payload := event.Details["record"]
+	expected := signConfigChange(key, []byte(payload))
+	if event.Type != EventConfigChange || !hmac.Equal([]byte(expected), []byte(event.Details["signature"])) {
+		return nil, ErrConfigChangeSignature
+	}
+
+	var record ConfigChangeRecord
+	if err := json.Unmarshal([]byte(payload), &record); err != nil {
+		return nil, ErrConfigChangeSignature
+	}
+	return &record, nil
+}
+
+// signConfigChange returns the hex HMAC-SHA256 of the payload
+func signConfigChange(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// volatileStatus lists status values that change at runtime without a configuration change
+var volatileStatus = map[string]bool{
+	"limiter":                   true,
+	"calibration_ms":            true,
+	"params.estimatedLatencyMs": true,
+	"params.entries":            true,
+}
+
+// diffStatus returns the configuration values that differ between two statuses, sorted by path
+func diffStatus(oldStatus, newStatus EncoderStatus) []ParamChange {
+	oldValues, newValues := flattenStatus(oldStatus), flattenStatus(newStatus)
+
+	changes := []ParamChange{}
+	for path, value := range newValues {
+		if oldValues[path] != value {
+			changes = append(changes, ParamChange{Path: path, Old: oldValues[path], New: value})
+		}
+	}
+	for path, value := range oldValues {
+		if _, ok := newValues[path]; !ok {
+			changes = append(changes, ParamChange{Path: path, Old: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// flattenStatus maps the dotted path of every configuration value of the status to its JSON text
+func flattenStatus(status EncoderStatus) map[string]string {
+	values := make(map[string]string)
+	if status.Name == "" {
+		return values
+	}
+	data, _ := json.Marshal(status)
+	var tree map[string]interface{}
+	_ = json.Unmarshal(data, &tree)
+	flattenInto(values, "", tree)
+	return values
+}
+
+// flattenInto adds the leaves of tree below prefix to values
+func flattenInto(values map[string]string, prefix string, tree map[string]interface{}) {
+	for key, value := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if volatileStatus[key] || volatileStatus[lastTwo(path)] {
+			continue
+		}
+		if subtree, ok := value.(map[string]interface{}); ok {
+			flattenInto(values, path, subtree)
+			continue
+		}
+		text, _ := json.Marshal(value)
+		values[path] = string(text)
+	}
+}
+
+// lastTwo returns the last two elements of a dotted path
+func lastTwo(path string) string {
+	parts := strings.Split(path, ".")
+	if len(parts) < 2 {
+		return path
+	}
+	return strings.Join(parts[len(parts)-2:], ".")
+}
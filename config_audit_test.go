@@ -0,0 +1,104 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigAuditor_Record(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("0123456789abcdef")
+	var events []AuditEvent
+	sink := AuditSinkFunc(func(_ context.Context, event AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	auditor, err := NewConfigAuditor(sink, key)
+	if err != nil {
+		t.Fatalf("NewConfigAuditor() error = %v", err)
+	}
+	auditor.Now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	oldEncoder, _ := NewDelegatingPasswordEncoder("argon2", NewArgon2PasswordEncoder(), NewBcryptPasswordEncoder())
+	newEncoder, _ := NewDelegatingPasswordEncoder("argon2", NewArgon2PasswordEncoder(WithArgon2Memory(128*1024)), NewBcryptPasswordEncoder())
+
+	first, err := auditor.Record(ctx, ConfigReload, "deploy", nil, oldEncoder)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if first.Previous != "" || len(first.Changes) == 0 {
+		t.Errorf("first Record() = %+v, want no previous and all values as changes", first)
+	}
+
+	second, err := auditor.Record(ctx, ConfigParamsBump, "alice", oldEncoder, newEncoder)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	want := []ParamChange{{Path: "encoders.argon2.params.memory", Old: "65536", New: "131072"}}
+	if len(second.Changes) != 1 || second.Changes[0] != want[0] {
+		t.Errorf("Record() changes = %+v, want %+v", second.Changes, want)
+	}
+	if second.Previous != events[0].Details["signature"] || auditor.Last() != events[1].Details["signature"] {
+		t.Errorf("Record() did not chain signatures")
+	}
+	if !strings.Contains(events[1].Details["changes"], "memory: 65536 -> 131072") {
+		t.Errorf("event changes = %q", events[1].Details["changes"])
+	}
+
+	record, err := VerifyConfigChange(key, events[1])
+	if err != nil || record.Actor != "alice" || record.Kind != ConfigParamsBump {
+		t.Errorf("VerifyConfigChange() got = %+v, %v", record, err)
+	}
+}
+
+func TestVerifyConfigChange_Tampered(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	var event AuditEvent
+	auditor, _ := NewConfigAuditor(AuditSinkFunc(func(_ context.Context, e AuditEvent) error {
+		event = e
+		return nil
+	}), key)
+	_, _ = auditor.Record(context.Background(), ConfigPepperRotation, "ops", nil, NewBcryptPasswordEncoder())
+
+	tampered := event
+	tampered.Details = map[string]string{
+		"record":    strings.Replace(event.Details["record"], "ops", "eve", 1),
+		"signature": event.Details["signature"],
+	}
+
+	tests := []struct {
+		name  string
+		key   []byte
+		event AuditEvent
+	}{
+		{"altered record", key, tampered},
+		{"other key", []byte("fedcba9876543210"), event},
+		{"other event type", key, AuditEvent{Type: EventHoneywordHit, Details: event.Details}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyConfigChange(tt.key, tt.event); !errors.Is(err, ErrConfigChangeSignature) {
+				t.Errorf("VerifyConfigChange() error = %v, want ErrConfigChangeSignature", err)
+			}
+		})
+	}
+}
+
+func TestConfigAuditor_SinkError(t *testing.T) {
+	sinkErr := errors.New("sink down")
+	auditor, _ := NewConfigAuditor(AuditSinkFunc(func(context.Context, AuditEvent) error { return sinkErr }), []byte("0123456789abcdef"))
+
+	if _, err := auditor.Record(context.Background(), ConfigReload, "ops", nil, NewBcryptPasswordEncoder()); !errors.Is(err, sinkErr) {
+		t.Errorf("Record() error = %v, want sink error", err)
+	}
+	if auditor.Last() != "" {
+		t.Errorf("Last() = %q, a failed emit must not advance the chain", auditor.Last())
+	}
+	if _, err := NewConfigAuditor(nil, []byte("short")); err == nil {
+		t.Errorf("NewConfigAuditor() with a short key should fail")
+	}
+}
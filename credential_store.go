@@ -0,0 +1,49 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCredentialNotFound is returned by a UserCredentialStore when the user has no stored hash
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// ErrPasswordReused is returned when a new password matches one of the user's previous passwords
+var ErrPasswordReused = errors.New("password reused")
+
+// UserCredentialStore loads and persists encoded passwords for users
+type UserCredentialStore interface {
+	// FindHash returns the encoded password of the user, or ErrCredentialNotFound
+	FindHash(ctx context.Context, userID string) (string, error)
+
+	// UpdateHash stores a new encoded password for the user
+	UpdateHash(ctx context.Context, userID, encodedPassword string) error
+}
+
+// HashReplacer is implemented by stores that can swap the current hash without recording history
+type HashReplacer interface {
+	// ReplaceHash stores newHash only if the current encoded password of the user is still oldHash, and
+	// reports whether it did. Unlike UpdateHash, the replaced hash is not kept in the history.
+	ReplaceHash(ctx context.Context, userID, oldHash, newHash string) (bool, error)
+}
+
+// CredentialHistory is implemented by stores that keep the hashes a user had before the current one
+type CredentialHistory interface {
+	// HashHistory returns up to limit previous encoded passwords of the user, most recent first.
+	// The current encoded password is not included.
+	HashHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+// CredentialRecord is a stored encoded password together with its metadata
+type CredentialRecord struct {
+	UserID    string    `json:"user"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CredentialLister is implemented by stores that can enumerate the current credential of every user
+type CredentialLister interface {
+	// ListCredentials returns the current record of every user, ordered by user ID
+	ListCredentials(ctx context.Context) ([]CredentialRecord, error)
+}
@@ -0,0 +1,54 @@
+package passforge
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultEncoder     atomic.Pointer[PasswordEncoder]
+	builtinDefault     PasswordEncoder
+	builtinDefaultOnce sync.Once
+)
+
+// newBuiltinDefault creates the encoder used by Hash and Check until SetDefault is called:
+// Argon2id for new hashes, with bcrypt accepted for verifying existing ones
+func newBuiltinDefault() PasswordEncoder {
+	encoder, err := NewDelegatingPasswordEncoder("argon2", NewArgon2PasswordEncoder(), NewBcryptPasswordEncoder())
+	if err != nil {
+		panic(err) // unreachable: the default ID is among the encoders
+	}
+	return encoder
+}
+
+// Default returns the package-level encoder used by Hash and Check
+func Default() PasswordEncoder {
+	if encoder := defaultEncoder.Load(); encoder != nil {
+		return *encoder
+	}
+	builtinDefaultOnce.Do(func() {
+		builtinDefault = newBuiltinDefault()
+	})
+	return builtinDefault
+}
+
+// SetDefault replaces the package-level encoder used by Hash and Check.
+// Passing nil restores the built-in default, a DelegatingPasswordEncoder producing "{argon2}" hashes
+// and verifying "{argon2}" and "{bcrypt}" ones.
+func SetDefault(encoder PasswordEncoder) {
+	if encoder == nil {
+		defaultEncoder.Store(nil)
+		return
+	}
+	defaultEncoder.Store(&encoder)
+}
+
+// Hash encodes the raw password with the default encoder
+func Hash(rawPassword string) (string, error) {
+	return Default().Encode(rawPassword)
+}
+
+// Check verifies the raw password against an encoded password with the default encoder
+func Check(rawPassword, encodedPassword string) (bool, error) {
+	return Default().Verify(rawPassword, encodedPassword)
+}
@@ -0,0 +1,45 @@
+package passforge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashCheck_BuiltinDefault(t *testing.T) {
+	encoded, err := Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "{argon2}") {
+		t.Errorf("Hash() = %v, want {argon2} prefix", encoded)
+	}
+	if match, err := Check("password123", encoded); err != nil || !match {
+		t.Errorf("Check() = %v, %v, want true, nil", match, err)
+	}
+	if match, _ := Check("password124", encoded); match {
+		t.Errorf("Check() = true for wrong password")
+	}
+
+	// Existing bcrypt hashes still verify
+	bcryptHash, _ := NewBcryptPasswordEncoder(WithCost(4)).Encode("password123")
+	if match, err := Check("password123", "{bcrypt}"+bcryptHash); err != nil || !match {
+		t.Errorf("Check() of bcrypt hash = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	defer SetDefault(nil)
+
+	SetDefault(NewNoOpPasswordEncoder())
+	if encoded, _ := Hash("password123"); encoded != "password123" {
+		t.Errorf("Hash() = %v, want the custom default to be used", encoded)
+	}
+	if match, _ := Check("password123", "password123"); !match {
+		t.Errorf("Check() = false, want the custom default to be used")
+	}
+
+	SetDefault(nil)
+	if Default().Name() != "delegating" {
+		t.Errorf("Default().Name() = %v after reset, want delegating", Default().Name())
+	}
+}
@@ -1,15 +1,28 @@
 package passforge
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// DelegatingPasswordEncoder delegates encoding to a default encoder and a map of encoders
+// ErrUnregisterDefault is returned by Unregister for the ID of the default encoder
+var ErrUnregisterDefault = errors.New("cannot unregister the default encoder")
+
+// DelegatingPasswordEncoder delegates encoding to a default encoder and a map of encoders.
+// Its methods are safe for concurrent use. The exported fields are only for construction: once the encoder
+// is shared, add and remove encoders with Register and Unregister, and read them with RegisteredEncoders.
 type DelegatingPasswordEncoder struct {
-	DefaultEncoder   PasswordEncoder
-	DefaultEncoderID string
-	Encoders         map[string]PasswordEncoder // e.g., "bcrypt" => bcrypt encoder
+	DefaultEncoder   PasswordEncoder            // Construction only, see Register
+	DefaultEncoderID string                     // Construction only
+	Encoders         map[string]PasswordEncoder // Construction only, e.g., "bcrypt" => bcrypt encoder
+
+	mu                 sync.RWMutex
+	cryptPrefixRouting bool            // Route "$id$" hashes without an "{id}" prefix by their crypt(3) prefix
+	matchesFallback    PasswordEncoder // Verifies the other hashes without an "{id}" prefix
 }
 
 // NewDelegatingPasswordEncoder creates a DelegatingPasswordEncoder with a default encoder and additional encoders. Additional encoders support backward compatibility with existing passwords.
@@ -21,8 +34,37 @@ func NewDelegatingPasswordEncoder(defaultEncoderID string, encoders ...PasswordE
 	if len(encoders) == 0 {
 		return nil, fmt.Errorf("at least one encoder must be provided")
 	}
+	for i, encoder := range encoders {
+		if encoder == nil {
+			return nil, fmt.Errorf("encoder %d cannot be nil", i)
+		}
+	}
+
+	return NewDelegatingPasswordEncoderFromMap(defaultEncoderID, buildEncoderMap(encoders))
+}
 
-	encoderMap := buildEncoderMap(encoders)
+// NewDelegatingPasswordEncoderFromMap creates a DelegatingPasswordEncoder from encoders keyed by ID, for IDs
+// other than the encoders' names, e.g. {"SSHA": ...} for LDAP exports. It rejects an empty default ID, an
+// empty map, empty IDs, nil encoders and a default ID missing from the map. The map is copied.
+func NewDelegatingPasswordEncoderFromMap(defaultEncoderID string, encoders map[string]PasswordEncoder) (*DelegatingPasswordEncoder, error) {
+	if defaultEncoderID == "" {
+		return nil, fmt.Errorf("default encoder ID cannot be empty")
+	}
+
+	if len(encoders) == 0 {
+		return nil, fmt.Errorf("at least one encoder must be provided")
+	}
+
+	encoderMap := make(map[string]PasswordEncoder, len(encoders))
+	for id, encoder := range encoders {
+		if id == "" {
+			return nil, fmt.Errorf("encoder ID cannot be empty")
+		}
+		if encoder == nil {
+			return nil, fmt.Errorf("encoder '%s' cannot be nil", id)
+		}
+		encoderMap[id] = encoder
+	}
 
 	defaultEncoder, exists := encoderMap[defaultEncoderID]
 	if !exists {
@@ -46,32 +88,224 @@ func buildEncoderMap(encoders []PasswordEncoder) map[string]PasswordEncoder {
 }
 
 // Encode encodes the given raw password using the default encoder and prefixes it with the default encoder's ID.
+// An encoder built without a constructor and no DefaultEncoder fails instead of panicking.
 func (d *DelegatingPasswordEncoder) Encode(rawPassword string) (string, error) {
-	encoded, err := d.DefaultEncoder.Encode(rawPassword)
+	id, encoder := d.defaultEncoder()
+	if encoder == nil {
+		return "", fmt.Errorf("default encoder '%s' not set", id)
+	}
+	encoded, err := encoder.Encode(rawPassword)
 	if err != nil {
 		return "", err
 	}
-	return "{" + d.getDefaultID() + "}" + encoded, nil
+	return "{" + id + "}" + encoded, nil
+}
+
+// Register adds the encoder under the ID, or replaces the one registered under it, including the default
+// encoder. It is safe to call while passwords are being verified.
+func (d *DelegatingPasswordEncoder) Register(id string, encoder PasswordEncoder) error {
+	if id == "" {
+		return fmt.Errorf("encoder ID cannot be empty")
+	}
+	if encoder == nil {
+		return fmt.Errorf("encoder '%s' cannot be nil", id)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Encoders == nil {
+		d.Encoders = make(map[string]PasswordEncoder)
+	}
+	d.Encoders[id] = encoder
+	if id == d.DefaultEncoderID {
+		d.DefaultEncoder = encoder
+	}
+	return nil
+}
+
+// Unregister removes the encoder registered under the ID; hashes of that ID then fail with
+// ErrUnknownEncoding. The default encoder can't be removed.
+func (d *DelegatingPasswordEncoder) Unregister(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if id == d.DefaultEncoderID {
+		return ErrUnregisterDefault
+	}
+	delete(d.Encoders, id)
+	return nil
 }
 
 // Verify checks if the provided raw password matches the encoded password using the appropriate encoder.
 // It identifies the encoder by extracting the prefix from the encoded password.
 // Returns a boolean indicating a match and an error if verification fails or the encoding is unknown.
 func (d *DelegatingPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
-	id, realEncoded, err := extractIDAndHash(encodedPassword)
+	return d.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password with the encoder of its prefix, passing ctx to it
+func (d *DelegatingPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	encoder, realEncoded, err := d.resolve(encodedPassword)
 	if err != nil {
 		return false, err
 	}
+	return verifyContext(ctx, encoder, rawPassword, realEncoded)
+}
+
+// ValidateEncoded checks that the encoded password has a known "{id}" prefix, or a routed crypt(3) prefix,
+// and is valid for that encoder; hashes without either are checked by the encoder for matches, if any
+func (d *DelegatingPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	encoder, realEncoded, err := d.resolve(encodedPassword)
+	if err != nil {
+		return err
+	}
+	return ValidateEncoded(encoder, realEncoded)
+}
+
+// VerifyAndUpgrade verifies the raw password and, when it matches and UpgradeEncoding reports the hash as
+// outdated, also encodes it with the default encoder. newEncoded is empty when the stored hash is current;
+// otherwise the caller stores it in place of encodedPassword. A failed re-encoding is returned as the error
+// along with the match.
+func (d *DelegatingPasswordEncoder) VerifyAndUpgrade(rawPassword, encodedPassword string) (match bool, newEncoded string, err error) {
+	match, err = d.Verify(rawPassword, encodedPassword)
+	if err != nil || !match || !d.UpgradeEncoding(encodedPassword) {
+		return match, "", err
+	}
+	newEncoded, err = d.Encode(rawPassword)
+	if err != nil {
+		return true, "", err
+	}
+	return true, newEncoded, nil
+}
+
+// SetCryptPrefixRouting enables or disables the routing of hashes stored without an "{id}" prefix by their
+// crypt(3) style prefix, e.g. "$2a$..." to the "bcrypt" encoder, "$argon2id$..." to "argon2" and "$6$..."
+// to "sha512-crypt". The encoder is looked up by ID, then by name, so encoders registered under other IDs
+// are found too. Routed hashes always need an upgrade, which stores them with an "{id}" prefix.
+func (d *DelegatingPasswordEncoder) SetCryptPrefixRouting(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cryptPrefixRouting = enabled
+}
+
+// SetDefaultEncoderForMatches sets the encoder verifying hashes stored without an "{id}" prefix, e.g. legacy
+// rows written before the application adopted the delegating encoder, instead of failing with
+// ErrInvalidFormat. Hashes routed by SetCryptPrefixRouting take precedence; unknown "{id}" prefixes still
+// fail with ErrUnknownEncoding. As they have no "{id}" prefix, these hashes always need an upgrade.
+// Passing nil removes the fallback.
+func (d *DelegatingPasswordEncoder) SetDefaultEncoderForMatches(encoder PasswordEncoder) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.matchesFallback = encoder
+}
+
+// resolve returns the encoder of the encoded password and the hash to hand to it
+func (d *DelegatingPasswordEncoder) resolve(encodedPassword string) (PasswordEncoder, string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	id, realEncoded, err := extractIDAndHash(encodedPassword)
+	if err != nil {
+		if encoder, ok := d.cryptPrefixEncoder(encodedPassword); ok {
+			return encoder, encodedPassword, nil
+		}
+		if d.matchesFallback != nil {
+			return d.matchesFallback, encodedPassword, nil
+		}
+		return nil, "", err
+	}
 	encoder, ok := d.Encoders[id]
 	if !ok {
-		return false, ErrUnknownEncoding
+		return nil, "", ErrUnknownEncoding
+	}
+	return encoder, realEncoded, nil
+}
+
+// cryptPrefixEncoder returns the encoder of a "$id$" hash when crypt prefix routing is enabled.
+// The caller holds the read lock.
+func (d *DelegatingPasswordEncoder) cryptPrefixEncoder(encodedPassword string) (PasswordEncoder, bool) {
+	if !d.cryptPrefixRouting || !strings.HasPrefix(encodedPassword, "$") {
+		return nil, false
+	}
+	algorithm := identifyAlgorithm(encodedPassword)
+	if algorithm == "" {
+		return nil, false
+	}
+	if encoder, ok := d.Encoders[algorithm]; ok {
+		return encoder, true
+	}
+	ids := make([]string, 0, len(d.Encoders))
+	for id := range d.Encoders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if d.Encoders[id].Name() == algorithm {
+			return d.Encoders[id], true
+		}
+	}
+	return nil, false
+}
+
+// AdminStatus reports the default ID and every registered encoder by ID
+func (d *DelegatingPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	status := EncoderStatus{Default: d.getDefaultID()}
+	encoders := d.RegisteredEncoders()
+	status.Encoders = make(map[string]EncoderStatus, len(encoders))
+	for id, inner := range encoders {
+		status.Encoders[id] = describe(inner)
+	}
+	return status
+}
+
+// Name returns the name of the encoder.
+func (d *DelegatingPasswordEncoder) Name() string {
+	return "delegating"
+}
+
+// UpgradeEncoding reports whether the encoded password should be re-encoded with the default encoder: it has
+// no "{id}" prefix, another encoder's ID, or the default encoder reports it as weaker than its configuration.
+func (d *DelegatingPasswordEncoder) UpgradeEncoding(encodedPassword string) bool {
+	id, realEncoded, err := extractIDAndHash(encodedPassword)
+	defaultID, defaultEncoder := d.defaultEncoder()
+	if err != nil || id != defaultID {
+		return true
 	}
-	return encoder.Verify(rawPassword, realEncoded)
+	return UpgradeEncoding(defaultEncoder, realEncoded)
 }
 
 // getDefaultID retrieves the ID of the default password encoder used for encoding.
 func (d *DelegatingPasswordEncoder) getDefaultID() string {
-	return d.DefaultEncoderID
+	id, _ := d.defaultEncoder()
+	return id
+}
+
+// defaultEncoder returns the ID and the default encoder as a consistent pair
+func (d *DelegatingPasswordEncoder) defaultEncoder() (string, PasswordEncoder) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.DefaultEncoderID, d.DefaultEncoder
+}
+
+// RegisteredEncoders returns a copy of the encoders by ID, safe to read while encoders are registered
+func (d *DelegatingPasswordEncoder) RegisteredEncoders() map[string]PasswordEncoder {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	encoders := make(map[string]PasswordEncoder, len(d.Encoders))
+	for id, encoder := range d.Encoders {
+		encoders[id] = encoder
+	}
+	return encoders
+}
+
+// DetectEncoding returns the ID of the encoder that produced the given "{id}hash" encoded password
+func DetectEncoding(encodedPassword string) (string, error) {
+	id, _, err := extractIDAndHash(encodedPassword)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
 }
 
 // extractIDAndHash extracts the ID and hash from an encoded password formatted as {id}hash.
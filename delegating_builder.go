@@ -0,0 +1,135 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DelegatingBuilder assembles a DelegatingPasswordEncoder step by step, e.g.
+//
+//	NewDelegatingBuilder().WithDefault("argon2", argon2Encoder).With("bcrypt", bcryptEncoder).AllowLegacy("md5", md5Encoder).Build()
+//
+// Mistakes are collected along the way and reported together by Build.
+type DelegatingBuilder struct {
+	defaultID string
+	encoders  map[string]PasswordEncoder
+	legacy    map[string]bool
+	errs      []error
+}
+
+// NewDelegatingBuilder creates an empty DelegatingBuilder
+func NewDelegatingBuilder() *DelegatingBuilder {
+	return &DelegatingBuilder{
+		encoders: make(map[string]PasswordEncoder),
+		legacy:   make(map[string]bool),
+	}
+}
+
+// WithDefault adds the encoder of new passwords under the ID
+func (b *DelegatingBuilder) WithDefault(id string, encoder PasswordEncoder) *DelegatingBuilder {
+	if b.defaultID != "" {
+		b.errs = append(b.errs, fmt.Errorf("default encoder already set to '%s'", b.defaultID))
+		return b
+	}
+	if b.add(id, encoder) {
+		b.defaultID = id
+	}
+	return b
+}
+
+// With adds an encoder verifying existing hashes under the ID
+func (b *DelegatingBuilder) With(id string, encoder PasswordEncoder) *DelegatingBuilder {
+	b.add(id, encoder)
+	return b
+}
+
+// AllowLegacy adds a deprecated encoder under the ID, explicitly opted in to verify hashes pending migration.
+// It can't be the default, and every successful verification is reported to the weak algorithm hook.
+func (b *DelegatingBuilder) AllowLegacy(id string, encoder PasswordEncoder) *DelegatingBuilder {
+	if b.add(id, encoder) {
+		b.legacy[id] = true
+	}
+	return b
+}
+
+// Build validates the configuration and returns a new DelegatingPasswordEncoder. It reports every mistake:
+// a missing default, empty or duplicate IDs and nil encoders. Each call returns a separate encoder, so later
+// calls on the builder leave it unchanged; like any DelegatingPasswordEncoder, it can still be changed with
+// Register and Unregister.
+func (b *DelegatingBuilder) Build() (*DelegatingPasswordEncoder, error) {
+	errs := append([]error(nil), b.errs...)
+	if b.defaultID == "" {
+		errs = append(errs, fmt.Errorf("default encoder not set"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	encoders := make(map[string]PasswordEncoder, len(b.encoders))
+	for id, encoder := range b.encoders {
+		if b.legacy[id] {
+			encoder = &legacyEncoder{PasswordEncoder: encoder, id: id}
+		}
+		encoders[id] = encoder
+	}
+	return &DelegatingPasswordEncoder{
+		DefaultEncoderID: b.defaultID,
+		DefaultEncoder:   encoders[b.defaultID],
+		Encoders:         encoders,
+	}, nil
+}
+
+// add records the encoder, reporting whether it was valid
+func (b *DelegatingBuilder) add(id string, encoder PasswordEncoder) bool {
+	switch {
+	case id == "":
+		b.errs = append(b.errs, fmt.Errorf("encoder ID cannot be empty"))
+	case encoder == nil:
+		b.errs = append(b.errs, fmt.Errorf("encoder '%s' cannot be nil", id))
+	case b.encoders[id] != nil:
+		b.errs = append(b.errs, fmt.Errorf("encoder '%s' added twice", id))
+	default:
+		b.encoders[id] = encoder
+		return true
+	}
+	return false
+}
+
+// legacyEncoder restricts an encoder added with AllowLegacy to verification
+type legacyEncoder struct {
+	PasswordEncoder
+	id string
+}
+
+// Encode returns ErrEncodeNotSupported
+func (l *legacyEncoder) Encode(string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks the raw password with the legacy encoder and reports successful verifications as weak
+func (l *legacyEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return l.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext is Verify passing ctx to the legacy encoder
+func (l *legacyEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	match, err := verifyContext(ctx, l.PasswordEncoder, rawPassword, encodedPassword)
+	if match && err == nil {
+		notifyWeak(l.id, "legacy encoder")
+	}
+	return match, err
+}
+
+// ValidateEncoded checks the encoded password with the legacy encoder
+func (l *legacyEncoder) ValidateEncoded(encodedPassword string) error {
+	return ValidateEncoded(l.PasswordEncoder, encodedPassword)
+}
+
+// AdminStatus marks the encoder as legacy and reports the encoder it restricts
+func (l *legacyEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"legacy": true},
+		Encoders: map[string]EncoderStatus{"inner": describe(l.PasswordEncoder)},
+	}
+}
@@ -0,0 +1,96 @@
+package passforge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDelegatingBuilder_Build(t *testing.T) {
+	bcryptEncoder := NewBcryptPasswordEncoder(WithCost(4))
+	encoder, err := NewDelegatingBuilder().
+		WithDefault("bcrypt", bcryptEncoder).
+		With("noop", NewNoOpPasswordEncoder()).
+		AllowLegacy("md5", NewMd5PasswordEncoder()).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	encoded, err := encoder.Encode("password")
+	if err != nil || !strings.HasPrefix(encoded, "{bcrypt}") {
+		t.Fatalf("Encode() = %q, %v, want a {bcrypt} hash", encoded, err)
+	}
+	for _, stored := range []string{encoded, "{noop}password", "{md5}5f4dcc3b5aa765d61d8327deb882cf99"} {
+		if match, err := encoder.Verify("password", stored); err != nil || !match {
+			t.Errorf("Verify(%q) = %v, %v, want true", stored, match, err)
+		}
+	}
+
+	if _, err := encoder.Encoders["md5"].Encode("password"); err != ErrEncodeNotSupported {
+		t.Errorf("Encode() with a legacy encoder error = %v, want ErrEncodeNotSupported", err)
+	}
+	var events []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) { events = append(events, event) })
+	defer SetWeakAlgorithmHook(nil)
+	if _, err := encoder.Verify("password", "{md5}5f4dcc3b5aa765d61d8327deb882cf99"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	found := false
+	for _, event := range events {
+		found = found || event == WeakAlgorithmEvent{Algorithm: "md5", Reason: "legacy encoder"}
+	}
+	if !found {
+		t.Errorf("Verify() with a legacy encoder reported %v, want a legacy encoder event", events)
+	}
+}
+
+func TestDelegatingBuilder_Independent(t *testing.T) {
+	builder := NewDelegatingBuilder().WithDefault("noop", NewNoOpPasswordEncoder())
+	encoder, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	builder.With("md5", NewMd5PasswordEncoder())
+	if _, ok := encoder.Encoders["md5"]; ok {
+		t.Error("Build() result changed after adding an encoder to the builder")
+	}
+	if again, err := builder.Build(); err != nil || again == encoder || len(again.Encoders) != 2 {
+		t.Errorf("Build() again = %v, %v, want a new encoder with 2 encoders", again, err)
+	}
+}
+
+func TestDelegatingBuilder_Errors(t *testing.T) {
+	noop := NewNoOpPasswordEncoder()
+	testCases := []struct {
+		name    string
+		builder *DelegatingBuilder
+		wantErr []string
+	}{
+		{"empty", NewDelegatingBuilder(), []string{"default encoder not set"}},
+		{"no default", NewDelegatingBuilder().With("noop", noop), []string{"default encoder not set"}},
+		{"empty ID", NewDelegatingBuilder().WithDefault("", noop), []string{"ID cannot be empty", "default encoder not set"}},
+		{"nil encoder", NewDelegatingBuilder().WithDefault("noop", noop).With("bcrypt", nil), []string{"'bcrypt' cannot be nil"}},
+		{"duplicate ID", NewDelegatingBuilder().WithDefault("noop", noop).AllowLegacy("noop", noop), []string{"'noop' added twice"}},
+		{"two defaults", NewDelegatingBuilder().WithDefault("noop", noop).WithDefault("md5", NewMd5PasswordEncoder()), []string{"already set to 'noop'"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder, err := tc.builder.Build()
+			if err == nil || encoder != nil {
+				t.Fatalf("Build() = %v, %v, want an error", encoder, err)
+			}
+			for _, want := range tc.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Build() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+
+	// Errors are reported together
+	_, err := NewDelegatingBuilder().With("", noop).With("md5", nil).Build()
+	if err == nil || len(strings.Split(err.Error(), "\n")) != 3 {
+		t.Errorf("Build() error = %v, want three errors", err)
+	}
+}
@@ -1,6 +1,9 @@
 package passforge
 
 import (
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -316,3 +319,299 @@ func TestDelegatingPasswordEncoder_Name(t *testing.T) {
 		t.Errorf("Expected %v encoders, got %v", len(encoders), len(names))
 	}
 }
+
+func TestDetectEncoding(t *testing.T) {
+	id, err := DetectEncoding("{bcrypt}$2a$10$abcdefghijklmnopqrstuv")
+	if err != nil || id != "bcrypt" {
+		t.Errorf("DetectEncoding() got = %v, %v, want bcrypt", id, err)
+	}
+
+	if _, err := DetectEncoding("$2a$10$abcdefghijklmnopqrstuv"); err != ErrInvalidFormat {
+		t.Errorf("DetectEncoding() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestDelegatingPasswordEncoder_CryptPrefixRouting(t *testing.T) {
+	argon2Encoded, err := NewArgon2PasswordEncoder(WithArgon2PHC()).Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	delegatingEncoder, _ := NewDelegatingPasswordEncoder("argon2",
+		NewArgon2PasswordEncoder(), NewBcryptPasswordEncoder(), NewNoOpPasswordEncoder())
+	// Registered under a Dovecot scheme name, found by its name
+	delegatingEncoder.Encoders["SHA512-CRYPT"] = NewSha512CryptPasswordEncoder()
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"bcrypt", "$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm"},
+		{"argon2id", argon2Encoded},
+		{"sha512-crypt", "$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delegatingEncoder.SetCryptPrefixRouting(false)
+			if _, err := delegatingEncoder.Verify("password", tt.encoded); err != ErrInvalidFormat {
+				t.Errorf("Verify() without routing error = %v, want ErrInvalidFormat", err)
+			}
+
+			delegatingEncoder.SetCryptPrefixRouting(true)
+			if match, err := delegatingEncoder.Verify("password", tt.encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+			if match, err := delegatingEncoder.Verify("wrong", tt.encoded); err != nil || match {
+				t.Errorf("Verify() with wrong password = %v, %v, want false", match, err)
+			}
+			if err := delegatingEncoder.ValidateEncoded(tt.encoded); err != nil {
+				t.Errorf("ValidateEncoded() error = %v", err)
+			}
+			if !delegatingEncoder.UpgradeEncoding(tt.encoded) {
+				t.Error("UpgradeEncoding() = false, want true for a hash without an {id} prefix")
+			}
+		})
+	}
+
+	// No encoder for the prefix, or not a crypt(3) style hash
+	for _, encoded := range []string{"$5$saltsaltsaltsalt$WsFBeg1qQ90JL3VkUTuM7xVV/5njhLngIVm6ftSnBR2", "password", "$unknown$"} {
+		if _, err := delegatingEncoder.Verify("password", encoded); err != ErrInvalidFormat {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidFormat", encoded, err)
+		}
+	}
+}
+
+func TestDelegatingPasswordEncoder_SetDefaultEncoderForMatches(t *testing.T) {
+	delegatingEncoder, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)))
+	legacy := "5f4dcc3b5aa765d61d8327deb882cf99" // MD5 of "password"
+
+	if _, err := delegatingEncoder.Verify("password", legacy); err != ErrInvalidFormat {
+		t.Errorf("Verify() without fallback error = %v, want ErrInvalidFormat", err)
+	}
+
+	delegatingEncoder.SetDefaultEncoderForMatches(NewMd5PasswordEncoder())
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  error
+	}{
+		{"legacy row", "password", legacy, true, nil},
+		{"legacy row, wrong password", "wrong", legacy, false, nil},
+		{"unknown id", "password", "{md5}" + legacy, false, ErrUnknownEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := delegatingEncoder.Verify(tt.password, tt.encoded)
+			if err != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if !delegatingEncoder.UpgradeEncoding(legacy) {
+		t.Error("UpgradeEncoding() = false, want true for a legacy row")
+	}
+
+	// Routed crypt(3) hashes take precedence over the fallback
+	delegatingEncoder.SetCryptPrefixRouting(true)
+	if match, err := delegatingEncoder.Verify("password", "$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm"); err != nil || !match {
+		t.Errorf("Verify() of a routed hash = %v, %v, want true", match, err)
+	}
+
+	delegatingEncoder.SetDefaultEncoderForMatches(nil)
+	if _, err := delegatingEncoder.Verify("password", legacy); err != ErrInvalidFormat {
+		t.Errorf("Verify() after removing the fallback error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestDelegatingPasswordEncoder_VerifyAndUpgrade(t *testing.T) {
+	delegatingEncoder, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(5)), NewNoOpPasswordEncoder())
+	current, err := delegatingEncoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		password    string
+		encoded     string
+		wantMatch   bool
+		wantUpgrade bool
+		wantErr     error
+	}{
+		{"current hash", "password", current, true, false, nil},
+		{"other encoder", "password", "{noop}password", true, true, nil},
+		{"lower cost", "password", "{bcrypt}$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm", true, true, nil},
+		{"wrong password", "wrong", "{noop}password", false, false, nil},
+		{"unknown encoder", "password", "{unknown}password", false, false, ErrUnknownEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, newEncoded, err := delegatingEncoder.VerifyAndUpgrade(tt.password, tt.encoded)
+			if err != tt.wantErr || match != tt.wantMatch {
+				t.Fatalf("VerifyAndUpgrade() = %v, %q, %v, want %v, %v", match, newEncoded, err, tt.wantMatch, tt.wantErr)
+			}
+			if (newEncoded != "") != tt.wantUpgrade {
+				t.Fatalf("VerifyAndUpgrade() newEncoded = %q, want upgrade %v", newEncoded, tt.wantUpgrade)
+			}
+			if !tt.wantUpgrade {
+				return
+			}
+			if !strings.HasPrefix(newEncoded, "{bcrypt}$2a$05$") || delegatingEncoder.UpgradeEncoding(newEncoded) {
+				t.Errorf("VerifyAndUpgrade() newEncoded = %q, want a current {bcrypt} hash", newEncoded)
+			}
+			if match, err := delegatingEncoder.Verify(tt.password, newEncoded); err != nil || !match {
+				t.Errorf("Verify() of the upgraded hash = %v, %v, want true", match, err)
+			}
+		})
+	}
+}
+
+func TestDelegatingPasswordEncoder_RegisterUnregister(t *testing.T) {
+	delegatingEncoder, _ := NewDelegatingPasswordEncoder("noop", NewNoOpPasswordEncoder())
+	md5Encoded := "{md5}5f4dcc3b5aa765d61d8327deb882cf99"
+
+	if _, err := delegatingEncoder.Verify("password", md5Encoded); err != ErrUnknownEncoding {
+		t.Errorf("Verify() before Register error = %v, want ErrUnknownEncoding", err)
+	}
+	if err := delegatingEncoder.Register("md5", NewMd5PasswordEncoder()); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if match, err := delegatingEncoder.Verify("password", md5Encoded); err != nil || !match {
+		t.Errorf("Verify() after Register = %v, %v, want true", match, err)
+	}
+	if err := delegatingEncoder.Unregister("md5"); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if _, err := delegatingEncoder.Verify("password", md5Encoded); err != ErrUnknownEncoding {
+		t.Errorf("Verify() after Unregister error = %v, want ErrUnknownEncoding", err)
+	}
+
+	// Registering under the default ID replaces the default encoder
+	bcryptEncoder := NewBcryptPasswordEncoder(WithCost(4))
+	if err := delegatingEncoder.Register("noop", bcryptEncoder); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if delegatingEncoder.DefaultEncoder != bcryptEncoder {
+		t.Error("Register() under the default ID should replace the default encoder")
+	}
+
+	if err := delegatingEncoder.Unregister("noop"); err != ErrUnregisterDefault {
+		t.Errorf("Unregister() of the default error = %v, want ErrUnregisterDefault", err)
+	}
+	if err := delegatingEncoder.Register("", bcryptEncoder); err == nil {
+		t.Error("Register() with an empty ID should fail")
+	}
+	if err := delegatingEncoder.Register("bcrypt", nil); err == nil {
+		t.Error("Register() with a nil encoder should fail")
+	}
+}
+
+func TestDelegatingPasswordEncoder_ConcurrentRegister(t *testing.T) {
+	delegatingEncoder, _ := NewDelegatingPasswordEncoder("noop", NewNoOpPasswordEncoder())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			id := "noop" + strconv.Itoa(i)
+			for j := 0; j < 100; j++ {
+				_ = delegatingEncoder.Register(id, NewNoOpPasswordEncoder())
+				_ = delegatingEncoder.Unregister(id)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if match, err := delegatingEncoder.Verify("password", "{noop}password"); err != nil || !match {
+					t.Errorf("Verify() = %v, %v, want true", match, err)
+					return
+				}
+				_, _ = delegatingEncoder.Verify("password", "{noop3}password")
+				_ = delegatingEncoder.UpgradeEncoding("{noop3}password")
+				if _, ok := delegatingEncoder.RegisteredEncoders()["noop"]; !ok {
+					t.Errorf("RegisteredEncoders() is missing the default encoder")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewDelegatingPasswordEncoder_Validation(t *testing.T) {
+	noop := NewNoOpPasswordEncoder()
+	testCases := []struct {
+		name     string
+		build    func() (*DelegatingPasswordEncoder, error)
+		wantErr  string
+		wantSize int
+	}{
+		{"valid", func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoder("noop", noop) }, "", 1},
+		{"empty default ID", func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoder("", noop) }, "default encoder ID cannot be empty", 0},
+		{"no encoders", func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoder("noop") }, "at least one encoder", 0},
+		{"nil encoder", func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoder("noop", noop, nil) }, "encoder 1 cannot be nil", 0},
+		{"missing default", func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoder("bcrypt", noop) }, "default encoder 'bcrypt' not found", 0},
+		{
+			name: "map",
+			build: func() (*DelegatingPasswordEncoder, error) {
+				return NewDelegatingPasswordEncoderFromMap("plain", map[string]PasswordEncoder{"plain": noop})
+			},
+			wantSize: 1,
+		},
+		{
+			name:    "empty map",
+			build:   func() (*DelegatingPasswordEncoder, error) { return NewDelegatingPasswordEncoderFromMap("plain", nil) },
+			wantErr: "at least one encoder",
+		},
+		{
+			name: "nil encoder in map",
+			build: func() (*DelegatingPasswordEncoder, error) {
+				return NewDelegatingPasswordEncoderFromMap("plain", map[string]PasswordEncoder{"plain": noop, "md5": nil})
+			},
+			wantErr: "encoder 'md5' cannot be nil",
+		},
+		{
+			name: "empty ID in map",
+			build: func() (*DelegatingPasswordEncoder, error) {
+				return NewDelegatingPasswordEncoderFromMap("plain", map[string]PasswordEncoder{"plain": noop, "": noop})
+			},
+			wantErr: "encoder ID cannot be empty",
+		},
+		{
+			name: "missing default in map",
+			build: func() (*DelegatingPasswordEncoder, error) {
+				return NewDelegatingPasswordEncoderFromMap("md5", map[string]PasswordEncoder{"plain": noop})
+			},
+			wantErr: "default encoder 'md5' not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder, err := tc.build()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) || encoder != nil {
+					t.Errorf("constructor = %v, %v, want error containing %q", encoder, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || encoder.DefaultEncoder == nil || len(encoder.Encoders) != tc.wantSize {
+				t.Errorf("constructor = %v, %v, want %d encoders and a default", encoder, err, tc.wantSize)
+			}
+		})
+	}
+
+	// The map is copied
+	encoders := map[string]PasswordEncoder{"plain": noop}
+	encoder, _ := NewDelegatingPasswordEncoderFromMap("plain", encoders)
+	encoders["md5"] = NewMd5PasswordEncoder()
+	if _, ok := encoder.Encoders["md5"]; ok {
+		t.Error("NewDelegatingPasswordEncoderFromMap() should copy the map")
+	}
+
+	// A hand-built encoder without a default fails instead of panicking
+	if _, err := (&DelegatingPasswordEncoder{DefaultEncoderID: "noop"}).Encode("password"); err == nil {
+		t.Error("Encode() without a default encoder should fail")
+	}
+}
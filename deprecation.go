@@ -0,0 +1,46 @@
+package passforge
+
+import (
+	"sync/atomic"
+)
+
+// MinBcryptCost is the lowest bcrypt cost that is not reported as weak
+const MinBcryptCost = 10
+
+// WeakAlgorithmEvent describes a successful verification against a deprecated or weak hash.
+// It never carries the password or the encoded value.
+type WeakAlgorithmEvent struct {
+	Algorithm string // Name of the encoder, e.g. "noop" or "bcrypt"
+	Reason    string // Why the hash is considered weak
+}
+
+// WeakAlgorithmHook is called after a successful verification against a weak hash
+type WeakAlgorithmHook func(event WeakAlgorithmEvent)
+
+// weakAlgorithmHook holds the registered hook, if any
+var weakAlgorithmHook atomic.Pointer[WeakAlgorithmHook]
+
+// SetWeakAlgorithmHook registers a hook that is called whenever a verification succeeds via a
+// deprecated or weak encoder (noop, bcrypt below MinBcryptCost, PBKDF2 below its MinIterations, ...). It is meant for migration
+// telemetry: the hook cannot fail the verification. The hook runs synchronously on the verifying
+// goroutine and must be safe for concurrent use. Passing nil removes the hook.
+func SetWeakAlgorithmHook(hook WeakAlgorithmHook) {
+	if hook == nil {
+		weakAlgorithmHook.Store(nil)
+		return
+	}
+	weakAlgorithmHook.Store(&hook)
+}
+
+// notifyWeak calls the registered hook, if any
+func notifyWeak(algorithm, reason string) {
+	if hook := weakAlgorithmHook.Load(); hook != nil {
+		(*hook)(WeakAlgorithmEvent{Algorithm: algorithm, Reason: reason})
+	}
+}
+
+// ReportWeakAlgorithm calls the registered hook, if any, so encoders outside this package, e.g. htpasswd and
+// shadow, can report successful verifications against weak hashes
+func ReportWeakAlgorithm(algorithm, reason string) {
+	notifyWeak(algorithm, reason)
+}
@@ -0,0 +1,86 @@
+package passforge
+
+import (
+	"testing"
+)
+
+func TestSetWeakAlgorithmHook(t *testing.T) {
+	var events []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		events = append(events, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	weakBcrypt := NewBcryptPasswordEncoder(WithCost(4))
+	weakHash, _ := weakBcrypt.Encode("password123")
+	strongBcrypt := NewBcryptPasswordEncoder(WithCost(MinBcryptCost))
+	strongHash, _ := strongBcrypt.Encode("password123")
+
+	testCases := []struct {
+		name            string
+		encoder         PasswordEncoder
+		rawPassword     string
+		encodedPassword string
+		wantAlgorithm   string
+	}{
+		{
+			name:            "noop match",
+			encoder:         NewNoOpPasswordEncoder(),
+			rawPassword:     "password123",
+			encodedPassword: "password123",
+			wantAlgorithm:   "noop",
+		},
+		{
+			name:            "noop mismatch",
+			encoder:         NewNoOpPasswordEncoder(),
+			rawPassword:     "password123",
+			encodedPassword: "password124",
+		},
+		{
+			name:            "low-cost bcrypt match",
+			encoder:         weakBcrypt,
+			rawPassword:     "password123",
+			encodedPassword: weakHash,
+			wantAlgorithm:   "bcrypt",
+		},
+		{
+			name:            "low-cost bcrypt mismatch",
+			encoder:         weakBcrypt,
+			rawPassword:     "password124",
+			encodedPassword: weakHash,
+		},
+		{
+			name:            "default-cost bcrypt match",
+			encoder:         strongBcrypt,
+			rawPassword:     "password123",
+			encodedPassword: strongHash,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events = nil
+			if _, err := tc.encoder.Verify(tc.rawPassword, tc.encodedPassword); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+
+			if tc.wantAlgorithm == "" {
+				if len(events) != 0 {
+					t.Errorf("hook called with %v, want no call", events)
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Algorithm != tc.wantAlgorithm || events[0].Reason == "" {
+				t.Errorf("hook called with %v, want one %v event", events, tc.wantAlgorithm)
+			}
+		})
+	}
+
+	// Removing the hook stops notifications
+	SetWeakAlgorithmHook(nil)
+	events = nil
+	_, _ = NewNoOpPasswordEncoder().Verify("password123", "password123")
+	if len(events) != 0 {
+		t.Errorf("hook called after removal: %v", events)
+	}
+}
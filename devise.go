@@ -0,0 +1,62 @@
+package passforge
+
+// DevisePasswordEncoder is a password encoder reading and writing the encrypted_password column of Rails
+// applications using Devise, which appends config.pepper to the password before bcrypt. Like the bcrypt
+// gem, only the first 72 bytes of password and pepper are used, so with Devise's 128-character generated
+// peppers, long passwords are cut short. Behind a DelegatingPasswordEncoder with another default encoder,
+// users are rehashed without the pepper on their next login.
+type DevisePasswordEncoder struct {
+	Pepper string                 // Devise's config.pepper, empty when it isn't set
+	Bcrypt *BcryptPasswordEncoder // Encoder of the peppered password
+}
+
+// DeviseOption is a functional option used to configure a DevisePasswordEncoder instance.
+type DeviseOption func(*DevisePasswordEncoder)
+
+// WithDeviseStretches sets the bcrypt cost of new hashes, Devise's config.stretches
+// Default: 12, as in Devise
+func WithDeviseStretches(stretches int) DeviseOption {
+	return func(d *DevisePasswordEncoder) {
+		d.Bcrypt.Cost = stretches
+	}
+}
+
+// NewDevisePasswordEncoder creates a new DevisePasswordEncoder with Devise's pepper and default stretches if not specified
+func NewDevisePasswordEncoder(pepper string, opts ...DeviseOption) *DevisePasswordEncoder {
+	encoder := &DevisePasswordEncoder{
+		Pepper: pepper,
+		Bcrypt: NewBcryptPasswordEncoder(WithCost(12), WithBcryptLongPassword(BcryptTruncateLong)),
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password followed by the pepper with bcrypt, as Devise::Encryptor.digest does
+func (d *DevisePasswordEncoder) Encode(rawPassword string) (string, error) {
+	return d.Bcrypt.Encode(rawPassword + d.Pepper)
+}
+
+// Verify checks if the raw password followed by the pepper matches the encoded password
+func (d *DevisePasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return d.Bcrypt.Verify(rawPassword+d.Pepper, encodedPassword)
+}
+
+// ValidateEncoded checks the encoded password as a bcrypt hash
+func (d *DevisePasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	return d.Bcrypt.ValidateEncoded(encodedPassword)
+}
+
+// AdminStatus reports whether a pepper is set, and the bcrypt encoder
+func (d *DevisePasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"pepper": d.Pepper != ""},
+		Encoders: map[string]EncoderStatus{"bcrypt": describe(d.Bcrypt)},
+	}
+}
+
+// Name returns the name of the encoder.
+func (d *DevisePasswordEncoder) Name() string {
+	return "devise"
+}
@@ -0,0 +1,79 @@
+package passforge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDevisePasswordEncoder_Verify(t *testing.T) {
+	// Hashes computed with libxcrypt's crypt(3) over the password followed by the pepper
+	longPepper := strings.Repeat("a3f2", 32)
+	tests := []struct {
+		name     string
+		pepper   string
+		password string
+		encoded  string
+		want     bool
+	}{
+		{"pepper", "pepper", "password", "$2a$04$ABCDEFGHIJKLMNOPQRSTUu9FsfJu7M5cNx3dLF9IAR6ky.bzTD18.", true},
+		{"wrong pepper", "Pepper", "password", "$2a$04$ABCDEFGHIJKLMNOPQRSTUu9FsfJu7M5cNx3dLF9IAR6ky.bzTD18.", false},
+		{"missing pepper", "", "password", "$2a$04$ABCDEFGHIJKLMNOPQRSTUu9FsfJu7M5cNx3dLF9IAR6ky.bzTD18.", false},
+		{"generated pepper", longPepper, "password", "$2a$04$abcdefghijklmnopqrstuu2wdXPpP1HhTXq8Hx31GtiedXHvJ8yru", true},
+		// Only the first 72 bytes of password and pepper count, as in the bcrypt gem
+		{"cut pepper", longPepper[:64], "password", "$2a$04$abcdefghijklmnopqrstuu2wdXPpP1HhTXq8Hx31GtiedXHvJ8yru", true},
+		{"wrong password", longPepper, "Password", "$2a$04$abcdefghijklmnopqrstuu2wdXPpP1HhTXq8Hx31GtiedXHvJ8yru", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewDevisePasswordEncoder(tt.pepper).Verify(tt.password, tt.encoded)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevisePasswordEncoder_Encode(t *testing.T) {
+	encoder := NewDevisePasswordEncoder("pepper", WithDeviseStretches(4))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$2a$04$") {
+		t.Errorf("Encode() = %v, want a $2a$ hash of cost 4", encoded)
+	}
+	if match, _ := encoder.Verify("password", encoded); !match {
+		t.Errorf("Verify() = false, want true")
+	}
+	if match, _ := NewBcryptPasswordEncoder().Verify("password", encoded); match {
+		t.Errorf("bcrypt Verify() without the pepper = true, want false")
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestDevisePasswordEncoder_UpgradeOnLogin(t *testing.T) {
+	ctx := context.Background()
+	delegating, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)), NewDevisePasswordEncoder("pepper"))
+	store := NewMemoryCredentialStore()
+	_ = store.UpdateHash(ctx, "alice", "{devise}$2a$04$ABCDEFGHIJKLMNOPQRSTUu9FsfJu7M5cNx3dLF9IAR6ky.bzTD18.")
+
+	service := NewAuthService(store, delegating)
+	if match, err := service.Authenticate(ctx, "alice", "password"); err != nil || !match {
+		t.Fatalf("Authenticate() = %v, %v, want true, nil", match, err)
+	}
+	if upgraded, _ := store.FindHash(ctx, "alice"); !strings.HasPrefix(upgraded, "{bcrypt}") {
+		t.Errorf("stored hash = %v, want a {bcrypt} hash", upgraded)
+	}
+}
+
+func TestDevisePasswordEncoder_Name(t *testing.T) {
+	if name := NewDevisePasswordEncoder("pepper").Name(); name != "devise" {
+		t.Errorf("Name() = %v, want devise", name)
+	}
+}
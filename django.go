@@ -0,0 +1,203 @@
+package passforge
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// djangoSaltChars is the alphabet of Django's get_random_string, used for salts
+const djangoSaltChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// DjangoPasswordEncoder is a password encoder reading and writing the password field of Django's user
+// table, e.g. pbkdf2_sha256$1000000$SALT$BASE64_HASH. Encode uses pbkdf2_sha256, Django's default hasher;
+// Verify also accepts pbkdf2_sha1. The salt is stored as text and used as is, so salts of other lengths verify.
+type DjangoPasswordEncoder struct {
+	Iterations int       // Number of iterations
+	SaltLen    int       // Length of the salt in characters
+	Rand       io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// DjangoOption is a functional option used to configure a DjangoPasswordEncoder instance.
+type DjangoOption func(*DjangoPasswordEncoder)
+
+// WithDjangoIterations sets the number of iterations
+// Default: 1000000, as in Django 5.2
+func WithDjangoIterations(iterations int) DjangoOption {
+	return func(d *DjangoPasswordEncoder) {
+		d.Iterations = iterations
+	}
+}
+
+// WithDjangoSaltLen sets the length of the salt in characters
+// Default: 22, 128 bits of entropy as in Django
+func WithDjangoSaltLen(saltLen int) DjangoOption {
+	return func(d *DjangoPasswordEncoder) {
+		d.SaltLen = saltLen
+	}
+}
+
+// WithDjangoRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithDjangoRand(r io.Reader) DjangoOption {
+	return func(d *DjangoPasswordEncoder) {
+		d.Rand = r
+	}
+}
+
+// NewDjangoPasswordEncoder creates a new DjangoPasswordEncoder with default parameters if not specified
+func NewDjangoPasswordEncoder(opts ...DjangoOption) *DjangoPasswordEncoder {
+	encoder := &DjangoPasswordEncoder{
+		Iterations: 1000000,
+		SaltLen:    22,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with pbkdf2_sha256
+func (d *DjangoPasswordEncoder) Encode(rawPassword string) (string, error) {
+	if d.Iterations < 1 || d.SaltLen < 1 {
+		return "", fmt.Errorf("django: iterations and saltLen must be positive")
+	}
+	salt, err := djangoSalt(randReader(d.Rand), d.SaltLen)
+	if err != nil {
+		return "", err
+	}
+	hash, err := pbkdf2Key(rawPassword, []byte(salt), d.Iterations, sha256.Size, sha256.New)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2_sha256$%d$%s$%s", d.Iterations, salt, base64.StdEncoding.EncodeToString(hash)), nil
+}
+
+// Verify checks if the raw password matches the encoded password
+func (d *DjangoPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseDjango(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	computedHash, err := pbkdf2Key(rawPassword, []byte(stored.salt), stored.iterations, len(stored.hash), stored.hashFunc)
+	if err != nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare(stored.hash, computedHash) != 1 {
+		return false, nil
+	}
+	if stored.algorithm == "pbkdf2_sha1" {
+		notifyWeak(d.Name(), "sha1 hash function")
+	}
+	return true, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (d *DjangoPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseDjango(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("django", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&DjangoPasswordEncoder{})
+	RegisterTestVectors("django", true, func(rand io.Reader) PasswordEncoder {
+		return NewDjangoPasswordEncoder(WithDjangoRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a Django PBKDF2 hash
+func (d *DjangoPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "pbkdf2_sha256$") || strings.HasPrefix(encodedPassword, "pbkdf2_sha1$")
+}
+
+// EncodedParams returns the iterations and algorithm of the hash
+func (d *DjangoPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseDjango(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations), "algorithm": stored.algorithm}
+}
+
+// AdminStatus reports the iterations and salt length of new hashes
+func (d *DjangoPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"iterations": d.Iterations, "saltLen": d.SaltLen}}
+}
+
+// Name returns the name of the encoder.
+func (d *DjangoPasswordEncoder) Name() string {
+	return "django"
+}
+
+// djangoHash is a parsed Django encoded password
+type djangoHash struct {
+	algorithm  string
+	iterations int
+	hashFunc   func() hash.Hash
+	salt       string
+	hash       []byte
+}
+
+// parseDjango parses an encoded password of the form ALGORITHM$ITERATIONS$SALT$BASE64_HASH
+func parseDjango(encodedPassword string) (*djangoHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 4 {
+		return nil, newFormatError("django", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &djangoHash{algorithm: parts[0], salt: parts[2]}
+	switch stored.algorithm {
+	case "pbkdf2_sha256":
+		stored.hashFunc = sha256.New
+	case "pbkdf2_sha1":
+		stored.hashFunc = sha1.New
+	default:
+		return nil, newFormatError("django", "unsupported algorithm", encodedPassword)
+	}
+
+	var err error
+	stored.iterations, err = strconv.Atoi(parts[1])
+	if err != nil || stored.iterations < 1 || strings.HasPrefix(parts[1], "+") {
+		return nil, newFormatError("django", "invalid iterations", encodedPassword)
+	}
+	if stored.salt == "" {
+		return nil, newFormatError("django", "missing salt", encodedPassword)
+	}
+	stored.hash, err = base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(stored.hash) != stored.hashFunc().Size() {
+		return nil, newFormatError("django", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// djangoSalt returns n random characters of djangoSaltChars, drawing uniformly by rejecting bytes above
+// the largest multiple of the alphabet size
+func djangoSalt(r io.Reader, n int) (string, error) {
+	const limit = 256 - 256%len(djangoSaltChars)
+	salt := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(salt) < n {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(salt) < n {
+				salt = append(salt, djangoSaltChars[int(b)%len(djangoSaltChars)])
+			}
+		}
+	}
+	return string(salt), nil
+}
@@ -0,0 +1,81 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDjangoPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewDjangoPasswordEncoder(WithDjangoIterations(1000), WithDjangoRand(bytes.NewReader(bytes.Repeat([]byte{0, 255}, 22))))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	// 255 is rejected to keep the salt uniform, 0 maps to "a"
+	if want := "pbkdf2_sha256$1000$" + strings.Repeat("a", 22) + "$"; !strings.HasPrefix(encoded, want) {
+		t.Errorf("Encode() = %v, want prefix %v", encoded, want)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	if _, err := NewDjangoPasswordEncoder(WithDjangoIterations(0)).Encode("password123"); err == nil {
+		t.Errorf("Encode() with 0 iterations expected an error")
+	}
+}
+
+func TestDjangoPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewDjangoPasswordEncoder()
+
+	// Hashes computed with Python's hashlib.pbkdf2_hmac, as Django does
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"pbkdf2_sha256", "password", "pbkdf2_sha256$870000$Xp7qWpmSt4WcaG9TKd3zNT$hRyZwHVhZYa1ikj0LT6CCvmvGMMCg9lUNh+B/YRsTjw=", true, false},
+		{"pbkdf2_sha1", "password", "pbkdf2_sha1$260000$Xp7qWpmSt4WcaG9TKd3zNT$NpMF+hqgnJg6MFbyrR/cQuTm+NQ=", true, false},
+		{"short salt", "password", "pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc=", true, false},
+		{"wrong password", "wrong", "pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc=", false, false},
+		{"unsupported algorithm", "password", "argon2$argon2id$v=19$m=102400,t=2,p=8$c29tZXNhbHQ$aGFzaA", false, true},
+		{"invalid iterations", "password", "pbkdf2_sha256$-1$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc=", false, true},
+		{"missing salt", "password", "pbkdf2_sha256$1000$$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc=", false, true},
+		{"truncated hash", "password", "pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9U", false, true},
+		{"sha1 length for sha256", "password", "pbkdf2_sha256$260000$Xp7qWpmSt4WcaG9TKd3zNT$NpMF+hqgnJg6MFbyrR/cQuTm+NQ=", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestDjangoPasswordEncoder_Delegating(t *testing.T) {
+	delegating, err := NewDelegatingPasswordEncoder("django", NewDjangoPasswordEncoder(WithDjangoIterations(1000)))
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+	encoded := "{django}pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc="
+	if match, err := delegating.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestDjangoPasswordEncoder_Name(t *testing.T) {
+	if name := NewDjangoPasswordEncoder().Name(); name != "django" {
+		t.Errorf("Name() = %v, want django", name)
+	}
+}
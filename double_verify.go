@@ -0,0 +1,100 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventMigrationDivergence reports a migrated hash that disagrees with the legacy hash it replaces
+const EventMigrationDivergence AuditEventType = "migration_divergence"
+
+// ErrMigrationDivergence is returned when the legacy and migrated hashes disagree for the same password
+var ErrMigrationDivergence = errors.New("migration divergence")
+
+// DoubleVerifier is a safety net for wrap and convert migrations: after a successful login it verifies
+// the password against both the legacy hash and the newly written one, and raises an
+// EventMigrationDivergence when they disagree. This catches conversion bugs, truncating columns and
+// misconfigured encoders while the legacy hashes still exist.
+type DoubleVerifier struct {
+	Encoder PasswordEncoder  // Encoder able to verify both the legacy and the migrated hashes
+	Sink    AuditSink        // Receives divergence events; optional
+	Now     func() time.Time // Time source of events
+
+	checks, divergences atomic.Uint64
+	diverged            sync.Map // User IDs with a recorded divergence
+}
+
+// NewDoubleVerifier creates a DoubleVerifier reporting divergences to sink, which may be nil
+func NewDoubleVerifier(encoder PasswordEncoder, sink AuditSink) *DoubleVerifier {
+	return &DoubleVerifier{
+		Encoder: encoder,
+		Sink:    sink,
+		Now:     time.Now,
+	}
+}
+
+// Check verifies the raw password against the legacy and migrated hashes of the user.
+// It returns ErrMigrationDivergence, after emitting an event, when exactly one of them matches or the
+// migrated hash can't be verified. The legacy result stays authoritative: callers should keep the
+// legacy hash and let the login proceed.
+func (d *DoubleVerifier) Check(ctx context.Context, userID, rawPassword, legacyHash, migratedHash string) error {
+	d.checks.Add(1)
+	legacyMatch, err := d.Encoder.Verify(rawPassword, legacyHash)
+	if err != nil {
+		return err
+	}
+	migratedMatch, migratedErr := d.Encoder.Verify(rawPassword, migratedHash)
+	if migratedErr == nil && legacyMatch == migratedMatch {
+		return nil
+	}
+
+	d.divergences.Add(1)
+	d.diverged.Store(userID, true)
+	details := map[string]string{
+		"legacy_algorithm":   PasswordHash{encoded: legacyHash}.Algorithm(),
+		"migrated_algorithm": PasswordHash{encoded: migratedHash}.Algorithm(),
+		"legacy_match":       strconv.FormatBool(legacyMatch),
+		"migrated_match":     strconv.FormatBool(migratedMatch),
+	}
+	if migratedErr != nil {
+		details["migrated_error"] = migratedErr.Error()
+	}
+	if d.Sink != nil {
+		event := AuditEvent{
+			Type:      EventMigrationDivergence,
+			Time:      d.Now(),
+			Algorithm: details["migrated_algorithm"],
+			UserID:    userID,
+			Message:   "migrated hash disagrees with legacy hash",
+			Details:   details,
+		}
+		if attempt, ok := AttemptFromContext(ctx); ok {
+			event.IP = attempt.IP
+		}
+		if err := d.Sink.Emit(ctx, event); err != nil {
+			return errors.Join(ErrMigrationDivergence, err)
+		}
+	}
+	return ErrMigrationDivergence
+}
+
+// Diverged reports whether a divergence was recorded for the user since the verifier was created.
+// AuthService no longer upgrades the hashes of such users, so the legacy hash is not rewritten on every login.
+func (d *DoubleVerifier) Diverged(userID string) bool {
+	_, ok := d.diverged.Load(userID)
+	return ok
+}
+
+// ClearDivergence forgets the divergence of the user, e.g. once the migration bug is fixed
+func (d *DoubleVerifier) ClearDivergence(userID string) {
+	d.diverged.Delete(userID)
+}
+
+// Stats returns the number of checks performed and divergences found
+func (d *DoubleVerifier) Stats() (checks, divergences uint64) {
+	return d.checks.Load(), d.divergences.Load()
+}
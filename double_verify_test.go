@@ -0,0 +1,125 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDoubleVerifier_Check(t *testing.T) {
+	encoder, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)), NewNoOpPasswordEncoder())
+	migrated, _ := encoder.Encode("password")
+
+	tests := []struct {
+		name     string
+		legacy   string
+		migrated string
+		wantErr  error
+	}{
+		{"agree", "{noop}password", migrated, nil},
+		{"migrated mismatch", "{noop}password", "{noop}other", ErrMigrationDivergence},
+		{"migrated unverifiable", "{noop}password", migrated[:30], ErrMigrationDivergence},
+		{"migrated unknown encoding", "{noop}password", "{md5}abc", ErrMigrationDivergence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []AuditEvent
+			verifier := NewDoubleVerifier(encoder, AuditSinkFunc(func(_ context.Context, event AuditEvent) error {
+				events = append(events, event)
+				return nil
+			}))
+			ctx := ContextWithAttempt(context.Background(), Attempt{IP: "203.0.113.7"})
+
+			err := verifier.Check(ctx, "alice", "password", tt.legacy, tt.migrated)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if checks, divergences := verifier.Stats(); checks != 1 || (divergences == 1) != (tt.wantErr != nil) {
+				t.Errorf("Stats() = %d, %d", checks, divergences)
+			}
+			if tt.wantErr == nil {
+				if len(events) != 0 {
+					t.Errorf("Check() emitted %d events, want none", len(events))
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Type != EventMigrationDivergence || events[0].UserID != "alice" || events[0].IP != "203.0.113.7" {
+				t.Errorf("Check() events = %+v", events)
+			}
+			if events[0].Details["legacy_algorithm"] != "noop" || events[0].Details["legacy_match"] != "true" {
+				t.Errorf("event details = %v", events[0].Details)
+			}
+		})
+	}
+}
+
+// truncatingStore simulates a hash column that is too narrow for the migrated hashes
+type truncatingStore struct {
+	mapStore
+	width int
+}
+
+func (s truncatingStore) UpdateHash(ctx context.Context, userID, encodedPassword string) error {
+	if len(encodedPassword) > s.width {
+		encodedPassword = encodedPassword[:s.width]
+	}
+	return s.mapStore.UpdateHash(ctx, userID, encodedPassword)
+}
+
+func TestAuthService_DoubleVerify(t *testing.T) {
+	ctx := context.Background()
+	store := truncatingStore{mapStore: mapStore{"alice": "{noop}password123"}, width: 40}
+
+	var upgradeErr error
+	service := newTestAuthService(t, store, WithAuthUpgradeErrorHandler(func(_ context.Context, _ string, err error) {
+		upgradeErr = err
+	}))
+	service.DoubleVerifier = NewDoubleVerifier(service.Encoder, nil)
+
+	match, err := service.Authenticate(ctx, "alice", "password123")
+	if err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+	if !errors.Is(upgradeErr, ErrMigrationDivergence) {
+		t.Errorf("upgrade error = %v, want ErrMigrationDivergence", upgradeErr)
+	}
+	if store.mapStore["alice"] != "{noop}password123" {
+		t.Errorf("legacy hash not restored, got = %v", store.mapStore["alice"])
+	}
+
+	// The upgrade is not retried for a user with a recorded divergence
+	upgradeErr = nil
+	if match, err := service.Authenticate(ctx, "alice", "password123"); err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+	if checks, _ := service.DoubleVerifier.Stats(); checks != 1 || upgradeErr != nil {
+		t.Errorf("upgrade retried after a divergence: %d checks, error = %v", checks, upgradeErr)
+	}
+}
+
+// truncatingMemoryStore is a MemoryCredentialStore whose column truncates upgraded hashes
+type truncatingMemoryStore struct {
+	*MemoryCredentialStore
+}
+
+func (s truncatingMemoryStore) ReplaceHash(ctx context.Context, userID, oldHash, newHash string) (bool, error) {
+	return s.MemoryCredentialStore.ReplaceHash(ctx, userID, oldHash, newHash[:min(len(newHash), 40)])
+}
+
+func TestAuthService_DoubleVerifyRestoresWithoutHistory(t *testing.T) {
+	ctx := context.Background()
+	store := truncatingMemoryStore{NewMemoryCredentialStore()}
+	_ = store.MemoryCredentialStore.UpdateHash(ctx, "alice", "{noop}password123")
+
+	service := newTestAuthService(t, store)
+	service.DoubleVerifier = NewDoubleVerifier(service.Encoder, nil)
+	if match, err := service.Authenticate(ctx, "alice", "password123"); err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+
+	encoded, _ := store.FindHash(ctx, "alice")
+	history, _ := store.HashHistory(ctx, "alice", 5)
+	if encoded != "{noop}password123" || len(history) != 0 {
+		t.Errorf("FindHash() = %v, HashHistory() = %v, want the legacy hash restored in place", encoded, history)
+	}
+}
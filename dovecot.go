@@ -0,0 +1,55 @@
+package passforge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DovecotOption is a functional option used to configure the encoder returned by NewDovecotDelegatingEncoder.
+type DovecotOption func(map[string]PasswordEncoder)
+
+// WithDovecotScheme adds an encoder for a scheme, or replaces the built-in one, e.g. to encode new
+// hashes with other parameters or to accept schemes passforge doesn't map such as PLAIN or MD5-CRYPT
+func WithDovecotScheme(scheme string, encoder PasswordEncoder) DovecotOption {
+	return func(schemes map[string]PasswordEncoder) {
+		schemes[strings.ToUpper(scheme)] = encoder
+	}
+}
+
+// dovecotSchemes returns the encoders of the Dovecot schemes passforge can verify, keyed by scheme
+func dovecotSchemes() map[string]PasswordEncoder {
+	sha := NewLDAPHashPasswordEncoder(LDAPSHA)
+	return map[string]PasswordEncoder{
+		"SSHA":         NewLDAPHashPasswordEncoder(LDAPSSHA),
+		"SSHA256":      NewLDAPHashPasswordEncoder(LDAPSSHA256),
+		"SSHA512":      NewLDAPHashPasswordEncoder(LDAPSSHA512),
+		"SHA":          sha,
+		"SHA1":         sha,
+		"SMD5":         NewLDAPHashPasswordEncoder(LDAPSMD5),
+		"LDAP-MD5":     NewLDAPHashPasswordEncoder(LDAPMD5),
+		"PLAIN-MD5":    NewMd5PasswordEncoder(),
+		"SHA256-CRYPT": NewSha256CryptPasswordEncoder(),
+		"SHA512-CRYPT": NewSha512CryptPasswordEncoder(),
+		"BLF-CRYPT":    NewBcryptPasswordEncoder(),
+		"ARGON2I":      NewArgon2PasswordEncoder(WithArgon2Variant(Argon2i), WithArgon2PHC()),
+		"ARGON2ID":     NewArgon2PasswordEncoder(WithArgon2PHC()),
+	}
+}
+
+// NewDovecotDelegatingEncoder creates a DelegatingPasswordEncoder for the {SCHEME}hash passwords of Dovecot's
+// passdb, e.g. {SHA512-CRYPT}$6$SALT$HASH, whose IDs are Dovecot scheme names: SSHA, SSHA256, SSHA512, SHA
+// (or SHA1), SMD5, LDAP-MD5, PLAIN-MD5, SHA256-CRYPT, SHA512-CRYPT, BLF-CRYPT, ARGON2I and ARGON2ID. New
+// passwords are encoded with defaultScheme, so the output can be written back to the passdb; behind an
+// AuthService, users of other schemes are rehashed with it on their next login. Scheme names are upper
+// case as written by doveadm pw; schemes with a .HEX or .B64 encoding suffix are not supported.
+func NewDovecotDelegatingEncoder(defaultScheme string, opts ...DovecotOption) (*DelegatingPasswordEncoder, error) {
+	schemes := dovecotSchemes()
+	for _, opt := range opts {
+		opt(schemes)
+	}
+	defaultScheme = strings.ToUpper(defaultScheme)
+	if _, ok := schemes[defaultScheme]; !ok {
+		return nil, fmt.Errorf("dovecot: unsupported default scheme %q", defaultScheme)
+	}
+	return NewDelegatingPasswordEncoderFromMap(defaultScheme, schemes)
+}
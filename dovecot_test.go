@@ -0,0 +1,116 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewDovecotDelegatingEncoder_Verify(t *testing.T) {
+	// Hashes computed with Python's hashlib and crypt, as written by doveadm pw
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  error
+	}{
+		{"SSHA512", "password", "{SSHA512}dVX3UK1WxAueucUnie+vBKWnUfSCLbiKiy7tj1e+7DvJtas1+7Nu5rO6Hy94i6yVOSdwSg03yOAL7rfuE6WZHAECAwQ=", true, nil},
+		{"SHA1", "password", "{SHA1}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", true, nil},
+		{"PLAIN-MD5", "password", "{PLAIN-MD5}5f4dcc3b5aa765d61d8327deb882cf99", true, nil},
+		{"SHA256-CRYPT", "password", "{SHA256-CRYPT}$5$saltsaltsaltsalt$WsFBeg1qQ90JL3VkUTuM7xVV/5njhLngIVm6ftSnBR2", true, nil},
+		{"SHA512-CRYPT", "password", "{SHA512-CRYPT}$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0", true, nil},
+		{"BLF-CRYPT", "password", "{BLF-CRYPT}$2y$05$abcdefghijklmnopqrstuuWG29KuyeAicPCJODk1zjyGvyQUU2awu", true, nil},
+		{"ARGON2I", "password", "{ARGON2I}$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, nil},
+		{"wrong password", "Password", "{SHA512-CRYPT}$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0", false, nil},
+		{"unknown scheme", "password", "{MD5-CRYPT}$1$saltsalt$qjXMvbEw8oaL.CzflDugX/", false, ErrUnknownEncoding},
+		{"encoding suffix", "password", "{SHA1.HEX}5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", false, ErrUnknownEncoding},
+	}
+	encoder, err := NewDovecotDelegatingEncoder("SHA512-CRYPT")
+	if err != nil {
+		t.Fatalf("NewDovecotDelegatingEncoder() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDovecotDelegatingEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		scheme     string
+		wantPrefix string
+	}{
+		{"ARGON2ID", "{ARGON2ID}$argon2id$v=19$"},
+		{"argon2i", "{ARGON2I}$argon2i$v=19$"},
+		{"SSHA512", "{SSHA512}"},
+		{"SHA256-CRYPT", "{SHA256-CRYPT}$5$rounds="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			encoder, err := NewDovecotDelegatingEncoder(tt.scheme)
+			if err != nil {
+				t.Fatalf("NewDovecotDelegatingEncoder() error = %v", err)
+			}
+			encoded, err := encoder.Encode("password")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.wantPrefix) {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.wantPrefix)
+			}
+			if match, err := encoder.Verify("password", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+		})
+	}
+}
+
+func TestNewDovecotDelegatingEncoder_Options(t *testing.T) {
+	if _, err := NewDovecotDelegatingEncoder("MD5-CRYPT"); err == nil {
+		t.Error("NewDovecotDelegatingEncoder() with an unknown scheme should fail")
+	}
+
+	encoder, err := NewDovecotDelegatingEncoder("BLF-CRYPT", WithDovecotScheme("blf-crypt", NewBcryptPasswordEncoder(WithCost(4))),
+		WithDovecotScheme("PLAIN", NewNoOpPasswordEncoder()))
+	if err != nil {
+		t.Fatalf("NewDovecotDelegatingEncoder() error = %v", err)
+	}
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "{BLF-CRYPT}$2a$04$") {
+		t.Errorf("Encode() = %v, want cost 4 bcrypt", encoded)
+	}
+	if match, err := encoder.Verify("password", "{PLAIN}password"); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true", match, err)
+	}
+}
+
+func TestNewDovecotDelegatingEncoder_UpgradeOnLogin(t *testing.T) {
+	ctx := context.Background()
+	encoder, err := NewDovecotDelegatingEncoder("BLF-CRYPT", WithDovecotScheme("BLF-CRYPT", NewBcryptPasswordEncoder(WithCost(4))))
+	if err != nil {
+		t.Fatalf("NewDovecotDelegatingEncoder() error = %v", err)
+	}
+	store := NewMemoryCredentialStore()
+	_ = store.UpdateHash(ctx, "alice@example.com", "{SSHA512}dVX3UK1WxAueucUnie+vBKWnUfSCLbiKiy7tj1e+7DvJtas1+7Nu5rO6Hy94i6yVOSdwSg03yOAL7rfuE6WZHAECAwQ=")
+	service := NewAuthService(store, encoder)
+
+	if ok, err := service.Authenticate(ctx, "alice@example.com", "password"); err != nil || !ok {
+		t.Fatalf("Authenticate() = %v, %v", ok, err)
+	}
+	stored, _ := store.FindHash(ctx, "alice@example.com")
+	if !strings.HasPrefix(stored, "{BLF-CRYPT}$2a$04$") {
+		t.Errorf("stored hash = %v, want a {BLF-CRYPT} hash", stored)
+	}
+}
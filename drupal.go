@@ -0,0 +1,84 @@
+package passforge
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// drupalMaxPasswordLen is DRUPAL_MAX_PASSWORD_LENGTH, longer passwords never match
+const drupalMaxPasswordLen = 512
+
+// DrupalPasswordEncoder is a verify-only password encoder for the pass column of Drupal 7's users table.
+// Drupal hashes passwords with a SHA-512 variant of phpass, $S$ + log2 of the iteration count + an
+// 8-character salt + 43 characters of iterated SHA-512. Like Drupal, it also accepts phpass's $P$ and $H$
+// hashes and the U$S$, U$P$ and U$H$ forms of accounts migrated from Drupal 6, which hash the MD5 hex digest
+// of the password. Every successful verification is reported to the weak algorithm hook, and AuthService
+// rehashes the password with the default encoder on login.
+type DrupalPasswordEncoder struct{}
+
+// NewDrupalPasswordEncoder creates a new DrupalPasswordEncoder
+func NewDrupalPasswordEncoder() *DrupalPasswordEncoder {
+	return &DrupalPasswordEncoder{}
+}
+
+// Encode is not supported: Drupal hashes must not be produced for new passwords
+func (d *DrupalPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (d *DrupalPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	hashed, md5Prehashed := strings.CutPrefix(encodedPassword, "U")
+	stored, err := parsePhpass("drupal", hashed)
+	if err != nil {
+		return false, err
+	}
+	if len(rawPassword) > drupalMaxPasswordLen {
+		return false, nil
+	}
+	if md5Prehashed {
+		sum := md5.Sum([]byte(rawPassword))
+		rawPassword = hex.EncodeToString(sum[:])
+	}
+	if !stored.matches(rawPassword) {
+		return false, nil
+	}
+	notifyWeak(d.Name(), "portable hash")
+	return true, nil
+}
+
+// ValidateEncoded rejects every Drupal hash once it is well-formed, as they use fast hash functions
+func (d *DrupalPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, err := parsePhpass("drupal", strings.TrimPrefix(encodedPassword, "U")); err != nil {
+		return err
+	}
+	return newFormatError("drupal", "portable hash", encodedPassword)
+}
+
+func init() {
+	RegisterHashFormat(&DrupalPasswordEncoder{})
+}
+
+// Identify reports whether the encoded password is a Drupal 7 hash or a Drupal 6 hash migrated to it
+func (d *DrupalPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$S$") ||
+		strings.HasPrefix(encodedPassword, "U$S$") ||
+		strings.HasPrefix(encodedPassword, "U$P$") ||
+		strings.HasPrefix(encodedPassword, "U$H$")
+}
+
+// EncodedParams returns the iterations of the hash
+func (d *DrupalPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parsePhpass(d.Name(), strings.TrimPrefix(encodedPassword, "U"))
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations)}
+}
+
+// Name returns the name of the encoder.
+func (d *DrupalPasswordEncoder) Name() string {
+	return "drupal"
+}
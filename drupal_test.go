@@ -0,0 +1,68 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDrupalPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewDrupalPasswordEncoder()
+
+	// Computed with a port of Drupal 7's password.inc
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"sha512", "password", "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH", true, false},
+		{"empty password", "", "$S$7abcdefghAZXsuRoaQHdeiM0i2cao62iRDbQiPHIRFViVEJ59h.R", true, false},
+		{"drupal 6 migrated", "password", "U$S$7saltsalteDyVmEVkoaLRGhEhGSqUAUt/olfTYTO6/aAQY6qzQpO", true, false},
+		{"drupal 6 migrated phpbb", "password", "U$H$7saltsaltGfCVi87pYwP7IWZpy1QZ7/", true, false},
+		{"phpass", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", true, false},
+		{"wrong password", "Password", "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH", false, false},
+		{"password too long", strings.Repeat("a", 513), "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH", false, false},
+		{"untruncated hash", "password", "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CHx", false, true},
+		{"iteration count too high", "password", "$S$UsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH", false, true},
+		{"md5 setting", "password", "$P$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrupalPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewDrupalPasswordEncoder()
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	encoded := "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH"
+	if match, _ := encoder.Verify("password", encoded); !match || len(flagged) != 1 || flagged[0].Algorithm != "drupal" {
+		t.Errorf("Verify() = %v, flagged %v, want one drupal event", match, flagged)
+	}
+	if err := encoder.ValidateEncoded(encoded); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestDrupalPasswordEncoder_Name(t *testing.T) {
+	if name := NewDrupalPasswordEncoder().Name(); name != "drupal" {
+		t.Errorf("Name() = %v, want drupal", name)
+	}
+}
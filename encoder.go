@@ -11,3 +11,47 @@ type PasswordEncoder interface {
 	// Name returns the name of the encoder.
 	Name() string
 }
+
+// KeyDeriver is implemented by KDF-backed encoders that can derive encryption keys from passwords
+// with their configured cost parameters, independently of the password storage format
+type KeyDeriver interface {
+	// DeriveKey derives a key of length bytes from the password and salt
+	DeriveKey(password string, salt []byte, length int) ([]byte, error)
+}
+
+// EncodedValidator is implemented by encoders that can check an encoded password without the raw password
+type EncodedValidator interface {
+	// ValidateEncoded returns an error if the encoded password is malformed or violates the encoder's policy
+	ValidateEncoded(encodedPassword string) error
+}
+
+// EncodingUpgrader is implemented by encoders that can tell when an encoded password should be re-encoded
+type EncodingUpgrader interface {
+	// UpgradeEncoding returns true if the encoded password is weaker than what Encode produces, e.g. a
+	// lower cost, and should be re-encoded once the raw password is known
+	UpgradeEncoding(encodedPassword string) bool
+}
+
+// minSaltLen is the shortest salt, in bytes, ValidateEncoded accepts
+const minSaltLen = 8
+
+// ValidateEncoded checks that an encoded password is well-formed and policy-compliant before it is stored:
+// parsable, produced by a known encoder, with parameters within bounds and consistent lengths.
+// Errors wrap ErrInvalidFormat, ErrUnknownEncoding or a more specific sentinel such as ErrIterationsTooLow.
+// Encoders that don't implement EncodedValidator accept any value.
+func ValidateEncoded(encoder PasswordEncoder, encodedPassword string) error {
+	if validator, ok := encoder.(EncodedValidator); ok {
+		return validator.ValidateEncoded(encodedPassword)
+	}
+	return nil
+}
+
+// UpgradeEncoding reports whether the encoded password should be re-encoded with the encoder, typically
+// checked after a successful Verify so the rehash can be stored. Malformed values are never reported:
+// they fail verification instead. Encoders that don't implement EncodingUpgrader never report one.
+func UpgradeEncoding(encoder PasswordEncoder, encodedPassword string) bool {
+	if upgrader, ok := encoder.(EncodingUpgrader); ok {
+		return upgrader.UpgradeEncoding(encodedPassword)
+	}
+	return false
+}
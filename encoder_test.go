@@ -0,0 +1,197 @@
+package passforge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateEncoded(t *testing.T) {
+	argon2 := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	scrypt := NewScryptPasswordEncoder(WithScryptN(1024), WithScryptMaxMem(1<<20))
+	pbkdf2 := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(1000), WithPBKDF2RejectBelowMin())
+	bcrypt := NewBcryptPasswordEncoder(WithCost(4))
+	delegating, _ := NewDelegatingPasswordEncoder("argon2", argon2, scrypt, pbkdf2, bcrypt, NewNoOpPasswordEncoder())
+
+	valid := func(encoder PasswordEncoder) string {
+		encoded, err := encoder.Encode("password123")
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return encoded
+	}
+
+	testCases := []struct {
+		name            string
+		encodedPassword string
+		wantErr         error
+	}{
+		{name: "argon2", encodedPassword: "{argon2}" + valid(argon2)},
+		{name: "scrypt", encodedPassword: "{scrypt}" + valid(scrypt)},
+		{name: "pbkdf2", encodedPassword: "{pbkdf2}" + valid(pbkdf2)},
+		{name: "bcrypt", encodedPassword: "{bcrypt}" + valid(bcrypt)},
+		{name: "noop accepts anything", encodedPassword: "{noop}anything"},
+		{name: "missing prefix", encodedPassword: valid(argon2), wantErr: ErrInvalidFormat},
+		{name: "unknown id", encodedPassword: "{md4}abc", wantErr: ErrUnknownEncoding},
+		{
+			name:            "argon2 truncated hash",
+			encodedPassword: "{argon2}time=1,memory=1024,threads=1,keyLen=32$c2FsdHNhbHRzYWx0$aGFzaA==",
+			wantErr:         ErrInvalidFormat,
+		},
+		{
+			name:            "argon2 memory below minimum",
+			encodedPassword: "{argon2}time=1,memory=4,threads=1,keyLen=4$c2FsdHNhbHRzYWx0$aGFzaA==",
+			wantErr:         ErrInvalidFormat,
+		},
+		{
+			name:            "scrypt short salt",
+			encodedPassword: "{scrypt}N=1024,r=8,p=1,keyLen=4$c2FsdA==$aGFzaA==",
+			wantErr:         ErrInvalidFormat,
+		},
+		{
+			name:            "scrypt over memory bound",
+			encodedPassword: "{scrypt}N=4096,r=8,p=1,keyLen=4$c2FsdHNhbHRzYWx0$aGFzaA==",
+			wantErr:         ErrScryptMemoryLimit,
+		},
+		{
+			name:            "scrypt invalid N",
+			encodedPassword: "{scrypt}N=1000,r=8,p=1,keyLen=4$c2FsdHNhbHRzYWx0$aGFzaA==",
+			wantErr:         ErrInvalidScryptParams,
+		},
+		{
+			name:            "pbkdf2 below iteration floor",
+			encodedPassword: "{pbkdf2}iterations=999,keyLen=4,hashFunc=sha256$c2FsdHNhbHRzYWx0$aGFzaA==",
+			wantErr:         ErrIterationsTooLow,
+		},
+		{name: "bcrypt truncated", encodedPassword: "{bcrypt}" + valid(bcrypt)[:59], wantErr: ErrInvalidFormat},
+		{name: "bcrypt trailing data", encodedPassword: "{bcrypt}" + valid(bcrypt) + "x", wantErr: ErrInvalidFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateEncoded(delegating, tc.encodedPassword)
+			if tc.wantErr == nil && err != nil {
+				t.Errorf("ValidateEncoded() error = %v, want nil", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("ValidateEncoded() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEncoded_Wrappers(t *testing.T) {
+	inner := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+
+	peppered, _ := NewPepperedPasswordEncoder(inner, "v1", testPepperV1)
+	encoded, _ := peppered.Encode("password123")
+	if err := ValidateEncoded(peppered, encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v, want nil", err)
+	}
+	if err := ValidateEncoded(peppered, "v1$AAAA"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+
+	tiered, _ := NewTieredEncoder(TierInteractive, WithTier(TierInteractive, inner))
+	encoded, _ = tiered.Encode("password123")
+	if err := ValidateEncoded(tiered, encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v, want nil", err)
+	}
+	if err := ValidateEncoded(tiered, "{admin}"+encoded); !errors.Is(err, ErrUnknownTier) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrUnknownTier", err)
+	}
+}
+
+func TestKeyDeriver(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, 16)
+
+	testCases := []struct {
+		name    string
+		encoder interface {
+			PasswordEncoder
+			KeyDeriver
+		}
+	}{
+		{name: "argon2", encoder: NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1), WithArgon2Rand(bytes.NewReader(salt)))},
+		{name: "scrypt", encoder: NewScryptPasswordEncoder(WithScryptN(1024), WithScryptRand(bytes.NewReader(salt)))},
+		{name: "pbkdf2", encoder: NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0), WithPBKDF2Rand(bytes.NewReader(salt)))},
+		{name: "bcrypt-pbkdf", encoder: NewBcryptPBKDFPasswordEncoder(WithBcryptPBKDFRounds(4), WithBcryptPBKDFRand(bytes.NewReader(salt)))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := tc.encoder.DeriveKey("password123", salt, 32)
+			if err != nil || len(key) != 32 {
+				t.Fatalf("DeriveKey() = %x, %v, want 32 bytes", key, err)
+			}
+
+			// The key is the hash Encode stores for the same salt and parameters
+			encoded, _ := tc.encoder.Encode("password123")
+			if !strings.HasSuffix(encoded, "$"+base64.StdEncoding.EncodeToString(key)) {
+				t.Errorf("DeriveKey() = %x, want the hash of %v", key, encoded)
+			}
+
+			if other, _ := tc.encoder.DeriveKey("password123", salt[:8], 32); bytes.Equal(key, other) {
+				t.Errorf("DeriveKey() returned the same key for a different salt")
+			}
+			if long, _ := tc.encoder.DeriveKey("password123", salt, 64); len(long) != 64 {
+				t.Errorf("DeriveKey() length = %v, want 64", len(long))
+			}
+			if _, err := tc.encoder.DeriveKey("password123", salt, 0); !errors.Is(err, ErrInvalidKeyLength) {
+				t.Errorf("DeriveKey() error = %v, want ErrInvalidKeyLength", err)
+			}
+		})
+	}
+}
+
+func TestUpgradeEncoding(t *testing.T) {
+	argon2 := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	scrypt := NewScryptPasswordEncoder(WithScryptN(1024))
+	pbkdf2 := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+	bcrypt := NewBcryptPasswordEncoder(WithCost(5))
+	delegating, _ := NewDelegatingPasswordEncoder("argon2", argon2, scrypt, pbkdf2, bcrypt, NewNoOpPasswordEncoder())
+
+	encode := func(encoder PasswordEncoder) string {
+		encoded, err := encoder.Encode("password123")
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return encoded
+	}
+
+	testCases := []struct {
+		name            string
+		encoder         PasswordEncoder
+		encodedPassword string
+		want            bool
+	}{
+		{"argon2 current", argon2, encode(argon2), false},
+		{"argon2 less memory", argon2, encode(NewArgon2PasswordEncoder(WithArgon2Memory(512), WithArgon2Threads(1))), true},
+		{"argon2 more memory", argon2, encode(NewArgon2PasswordEncoder(WithArgon2Memory(2048), WithArgon2Threads(1))), false},
+		{"argon2 fewer threads", argon2, encode(NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))), false},
+		{"argon2 other variant", argon2, encode(NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Variant(Argon2i))), true},
+		{"scrypt current", scrypt, encode(scrypt), false},
+		{"scrypt lower N", scrypt, encode(NewScryptPasswordEncoder(WithScryptN(512))), true},
+		{"pbkdf2 current", pbkdf2, encode(pbkdf2), false},
+		{"pbkdf2 fewer iterations", pbkdf2, encode(NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(999), WithPBKDF2MinIterations(0))), true},
+		{"pbkdf2 other hash function", pbkdf2, encode(NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2Hash("sha512"), WithPBKDF2MinIterations(0))), true},
+		{"bcrypt current", bcrypt, encode(bcrypt), false},
+		{"bcrypt lower cost", bcrypt, "$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm", true},
+		{"bcrypt malformed", bcrypt, "$2b$04$abc", false},
+		{"delegating current", delegating, "{argon2}" + encode(argon2), false},
+		{"delegating weaker default", delegating, "{argon2}" + encode(NewArgon2PasswordEncoder(WithArgon2Memory(512))), true},
+		{"delegating other id", delegating, "{bcrypt}" + encode(bcrypt), true},
+		{"delegating missing prefix", delegating, encode(argon2), true},
+		{"not an upgrader", NewNoOpPasswordEncoder(), "password123", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := UpgradeEncoding(tc.encoder, tc.encodedPassword); got != tc.want {
+				t.Errorf("UpgradeEncoding() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
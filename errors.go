@@ -1,9 +1,71 @@
 package passforge
 
-import "errors"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+)
 
 // ErrUnknownEncoding is returned when the encoding ID is not recognized
 var ErrUnknownEncoding = errors.New("unknown encoding")
 
 // ErrInvalidFormat is returned when the encoded password format is invalid
 var ErrInvalidFormat = errors.New("invalid format")
+
+// ErrEncodeNotSupported is returned by verify-only encoders when Encode is called
+var ErrEncodeNotSupported = errors.New("encode not supported")
+
+// ErrInvalidCredentials is returned by external verifiers (e.g. an LDAPBinder)
+// when the backend rejects the supplied credentials
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrInvalidKeyLength is returned by DeriveKey for key lengths the KDF can't produce
+var ErrInvalidKeyLength = errors.New("invalid key length")
+
+// errorFingerprints controls whether FormatError carries a fingerprint of the offending value
+var errorFingerprints atomic.Bool
+
+// SetErrorFingerprints enables or disables fingerprints in format errors.
+// A fingerprint is the first 8 hex characters of the SHA-256 of the encoded password: enough to
+// correlate a log line with a database row while debugging, without revealing salt or hash bytes.
+// Fingerprints are disabled by default.
+func SetErrorFingerprints(enabled bool) {
+	errorFingerprints.Store(enabled)
+}
+
+// FormatError reports why an encoded password could not be parsed.
+// Its message never contains any part of the password, salt or hash.
+type FormatError struct {
+	Encoder     string // Name of the encoder that rejected the value
+	Reason      string // Fixed description of the problem
+	Fingerprint string // Truncated SHA-256 of the value, empty unless enabled with SetErrorFingerprints
+	Err         error  // Optional typed cause, e.g. ErrInvalidScryptParams
+}
+
+// Error returns the redacted error message
+func (e *FormatError) Error() string {
+	msg := e.Encoder + ": " + ErrInvalidFormat.Error() + ": " + e.Reason
+	if e.Fingerprint != "" {
+		msg += " (fingerprint " + e.Fingerprint + ")"
+	}
+	return msg
+}
+
+// Unwrap makes FormatError match ErrInvalidFormat and its cause with errors.Is
+func (e *FormatError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrInvalidFormat}
+	}
+	return []error{ErrInvalidFormat, e.Err}
+}
+
+// newFormatError creates a FormatError for the encoded value
+func newFormatError(encoder, reason, encodedPassword string) *FormatError {
+	err := &FormatError{Encoder: encoder, Reason: reason}
+	if errorFingerprints.Load() {
+		sum := sha256.Sum256([]byte(encodedPassword))
+		err.Fingerprint = hex.EncodeToString(sum[:4])
+	}
+	return err
+}
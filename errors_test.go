@@ -0,0 +1,97 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatError_Redaction(t *testing.T) {
+	const secret = "S3CR3TSALTxyz"
+
+	testCases := []struct {
+		name            string
+		encoder         PasswordEncoder
+		encodedPassword string
+	}{
+		{
+			name:            "argon2 bad salt",
+			encoder:         NewArgon2PasswordEncoder(),
+			encodedPassword: "time=1,memory=64,threads=1,keyLen=32$" + secret + "!$hash",
+		},
+		{
+			name:            "argon2 bad parameters",
+			encoder:         NewArgon2PasswordEncoder(),
+			encodedPassword: "time=" + secret + "$salt$hash",
+		},
+		{
+			name:            "argon2 zero threads",
+			encoder:         NewArgon2PasswordEncoder(),
+			encodedPassword: "time=1,memory=64,threads=0,keyLen=32$c2FsdA==$aGFzaA==",
+		},
+		{
+			name:            "scrypt bad hash",
+			encoder:         NewScryptPasswordEncoder(),
+			encodedPassword: "N=1024,r=8,p=1,keyLen=32$c2FsdA==$" + secret + "!",
+		},
+		{
+			name:            "scrypt bad parameters",
+			encoder:         NewScryptPasswordEncoder(),
+			encodedPassword: "N=1000,r=8,p=1,keyLen=32$c2FsdA==$aGFzaA==",
+		},
+		{
+			name:            "pbkdf2 unsupported hash",
+			encoder:         NewPBKDF2PasswordEncoder(),
+			encodedPassword: "iterations=1,keyLen=32,hashFunc=" + secret + "$c2FsdA==$aGFzaA==",
+		},
+		{
+			name:            "bcrypt bad prefix",
+			encoder:         NewBcryptPasswordEncoder(),
+			encodedPassword: secret + "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.encoder.Verify("password", tc.encodedPassword)
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Fatalf("Verify() error = %v, want ErrInvalidFormat", err)
+			}
+
+			// Neither the secret nor any 4+ character fragment of it may appear in the message
+			for i := 0; i+4 <= len(secret); i++ {
+				if strings.Contains(err.Error(), secret[i:i+4]) {
+					t.Errorf("Verify() error leaks stored value: %v", err)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFormatError_Fingerprint(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder()
+
+	_, err := encoder.Verify("password", "invalid-format")
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) || formatErr.Fingerprint != "" {
+		t.Fatalf("Verify() error = %#v, want FormatError without fingerprint", err)
+	}
+
+	SetErrorFingerprints(true)
+	defer SetErrorFingerprints(false)
+
+	_, err = encoder.Verify("password", "invalid-format")
+	if !errors.As(err, &formatErr) || len(formatErr.Fingerprint) != 8 {
+		t.Fatalf("Verify() error = %#v, want FormatError with fingerprint", err)
+	}
+	if !strings.Contains(err.Error(), formatErr.Fingerprint) || formatErr.Encoder != "argon2" {
+		t.Errorf("Error() = %v", err)
+	}
+
+	// The fingerprint is stable for the same value
+	_, again := encoder.Verify("other", "invalid-format")
+	if again.Error() != err.Error() {
+		t.Errorf("fingerprints differ for the same value: %v != %v", again, err)
+	}
+}
@@ -0,0 +1,24 @@
+package passforge
+
+// CreateDelegatingPasswordEncoder creates a DelegatingPasswordEncoder with every general-purpose encoder
+// under its canonical ID, like Spring Security's PasswordEncoderFactories: "bcrypt" (the default), "argon2",
+// "scrypt", "pbkdf2" and "noop". New passwords are encoded with bcrypt at cost 10; the others follow the
+// OWASP Password Storage Cheat Sheet: Argon2id with the package defaults, scrypt with N=2^17, r=8 and
+// p=1, and PBKDF2-HMAC-SHA256 with 600000 iterations. "{noop}" is only there to verify plain-text seed
+// data; hashes of any ID other than "bcrypt" are upgraded on the next login behind an AuthService.
+//
+// Each call returns a new encoder. Replace the bcrypt encoder with Register("bcrypt", ...); to encode with
+// another algorithm, build an encoder with NewDelegatingBuilder().WithDefault(...) instead.
+func CreateDelegatingPasswordEncoder() *DelegatingPasswordEncoder {
+	encoder, err := NewDelegatingPasswordEncoder("bcrypt",
+		NewBcryptPasswordEncoder(),
+		NewArgon2PasswordEncoder(),
+		NewScryptPasswordEncoder(WithScryptN(1<<17), WithScryptR(8), WithScryptP(1)),
+		NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(OWASPMinPBKDF2Iterations)),
+		NewNoOpPasswordEncoder(),
+	)
+	if err != nil {
+		panic(err) // unreachable: the default ID is among the encoders
+	}
+	return encoder
+}
@@ -0,0 +1,38 @@
+package passforge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateDelegatingPasswordEncoder(t *testing.T) {
+	encoder := CreateDelegatingPasswordEncoder()
+
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "{bcrypt}$2a$10$") {
+		t.Errorf("Encode() = %q, want a {bcrypt} hash of cost 10", encoded)
+	}
+
+	for _, id := range []string{"bcrypt", "argon2", "scrypt", "pbkdf2", "noop"} {
+		t.Run(id, func(t *testing.T) {
+			delegate, ok := encoder.Encoders[id]
+			if !ok {
+				t.Fatalf("Encoders[%q] missing", id)
+			}
+			encoded, err := delegate.Encode("password")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if match, err := encoder.Verify("password", "{"+id+"}"+encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+		})
+	}
+
+	if CreateDelegatingPasswordEncoder().Encoders["bcrypt"] == encoder.Encoders["bcrypt"] {
+		t.Error("CreateDelegatingPasswordEncoder() should return new encoders on each call")
+	}
+}
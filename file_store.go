@@ -0,0 +1,160 @@
+package passforge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileCredentialStore is an append-only UserCredentialStore persisting one JSON record per line.
+// The whole file is replayed into memory on open; every update appends a record and syncs the file,
+// so the file doubles as the password history. It is intended for tests, demos and small tools.
+type FileCredentialStore struct {
+	Now func() time.Time // Time source for UpdatedAt
+
+	mu      sync.RWMutex
+	file    *os.File
+	records map[string][]CredentialRecord // oldest first
+}
+
+// fileRecord is a line of the file. Replaced is set by ReplaceHash: the record then takes the place of the
+// current one, holding that hash, instead of being appended to the history.
+type fileRecord struct {
+	CredentialRecord
+	Replaced string `json:"replaced,omitempty"`
+}
+
+// OpenFileCredentialStore opens or creates the JSONL file at path and loads its records
+func OpenFileCredentialStore(path string) (*FileCredentialStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileCredentialStore{
+		file:    file,
+		records: make(map[string][]CredentialRecord),
+		Now:     time.Now,
+	}
+	if err := store.load(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// load replays all records of the file
+func (f *FileCredentialStore) load() error {
+	scanner := bufio.NewScanner(f.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("credential store: line %d: %w", line, ErrInvalidFormat)
+		}
+		records := f.records[record.UserID]
+		if record.Replaced != "" && len(records) > 0 && records[len(records)-1].Hash == record.Replaced {
+			records[len(records)-1] = record.CredentialRecord
+			continue
+		}
+		f.records[record.UserID] = append(records, record.CredentialRecord)
+	}
+	return scanner.Err()
+}
+
+// FindHash returns the current encoded password of the user
+func (f *FileCredentialStore) FindHash(_ context.Context, userID string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	records := f.records[userID]
+	if len(records) == 0 {
+		return "", ErrCredentialNotFound
+	}
+	return records[len(records)-1].Hash, nil
+}
+
+// UpdateHash appends a new encoded password for the user to the file
+func (f *FileCredentialStore) UpdateHash(_ context.Context, userID, encodedPassword string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	record := CredentialRecord{
+		UserID:    userID,
+		Hash:      encodedPassword,
+		UpdatedAt: f.Now(),
+	}
+	if err := f.write(fileRecord{CredentialRecord: record}); err != nil {
+		return err
+	}
+
+	f.records[userID] = append(f.records[userID], record)
+	return nil
+}
+
+// ReplaceHash appends a record replacing the current encoded password of the user if it is still oldHash
+func (f *FileCredentialStore) ReplaceHash(_ context.Context, userID, oldHash, newHash string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records := f.records[userID]
+	if len(records) == 0 || records[len(records)-1].Hash != oldHash {
+		return false, nil
+	}
+	record := CredentialRecord{
+		UserID:    userID,
+		Hash:      newHash,
+		UpdatedAt: f.Now(),
+	}
+	if err := f.write(fileRecord{CredentialRecord: record, Replaced: oldHash}); err != nil {
+		return false, err
+	}
+
+	records[len(records)-1] = record
+	return true, nil
+}
+
+// write appends a record to the file and syncs it
+func (f *FileCredentialStore) write(record fileRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// HashHistory returns up to limit previous encoded passwords of the user, most recent first
+func (f *FileCredentialStore) HashHistory(_ context.Context, userID string, limit int) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return historyOf(f.records[userID], limit), nil
+}
+
+// ListCredentials returns the current record of every user, ordered by user ID
+func (f *FileCredentialStore) ListCredentials(_ context.Context) ([]CredentialRecord, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return currentRecords(f.records), nil
+}
+
+// Close closes the underlying file
+func (f *FileCredentialStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,130 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileCredentialStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "credentials.jsonl")
+
+	store, err := OpenFileCredentialStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileCredentialStore() error = %v", err)
+	}
+	_ = store.UpdateHash(ctx, "alice", "{noop}one")
+	_ = store.UpdateHash(ctx, "bob", "{noop}bob")
+	_ = store.UpdateHash(ctx, "alice", "{noop}two")
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Reopening replays the file
+	store, err = OpenFileCredentialStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileCredentialStore() error = %v", err)
+	}
+	defer store.Close()
+
+	encoded, err := store.FindHash(ctx, "alice")
+	if err != nil || encoded != "{noop}two" {
+		t.Errorf("FindHash() got = %v, %v, want {noop}two", encoded, err)
+	}
+	history, _ := store.HashHistory(ctx, "alice", 5)
+	if len(history) != 1 || history[0] != "{noop}one" {
+		t.Errorf("HashHistory() got = %v", history)
+	}
+	if _, err := store.FindHash(ctx, "carol"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("FindHash() error = %v, want ErrCredentialNotFound", err)
+	}
+	records, _ := store.ListCredentials(ctx)
+	if len(records) != 2 || records[0].Hash != "{noop}two" || records[1].UserID != "bob" {
+		t.Errorf("ListCredentials() got = %+v", records)
+	}
+
+	content, _ := os.ReadFile(path)
+	if lines := strings.Count(string(content), "\n"); lines != 3 {
+		t.Errorf("file has %d lines, want 3", lines)
+	}
+}
+
+func TestFileCredentialStore_ReplaceHash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "credentials.jsonl")
+
+	store, err := OpenFileCredentialStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileCredentialStore() error = %v", err)
+	}
+	_ = store.UpdateHash(ctx, "alice", "{noop}one")
+	_ = store.UpdateHash(ctx, "alice", "{noop}two")
+	if replaced, err := store.ReplaceHash(ctx, "alice", "{noop}one", "{noop}three"); err != nil || replaced {
+		t.Errorf("ReplaceHash() of a stale hash = %v, %v, want false, nil", replaced, err)
+	}
+	if replaced, err := store.ReplaceHash(ctx, "alice", "{noop}two", "{noop}three"); err != nil || !replaced {
+		t.Errorf("ReplaceHash() = %v, %v, want true, nil", replaced, err)
+	}
+	_ = store.Close()
+
+	// The replacement survives a reopen and stays out of the history
+	store, err = OpenFileCredentialStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileCredentialStore() error = %v", err)
+	}
+	defer store.Close()
+
+	encoded, _ := store.FindHash(ctx, "alice")
+	history, _ := store.HashHistory(ctx, "alice", 5)
+	if encoded != "{noop}three" || strings.Join(history, ",") != "{noop}one" {
+		t.Errorf("FindHash() = %v, HashHistory() = %v, want {noop}three without {noop}two in the history", encoded, history)
+	}
+}
+
+func TestFileCredentialStore_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.jsonl")
+	_ = os.WriteFile(path, []byte("{\"user\":\"alice\",\"hash\":\"{noop}x\"}\nnot-json\n"), 0o600)
+
+	_, err := OpenFileCredentialStore(path)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("OpenFileCredentialStore() error = %v, want ErrInvalidFormat", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "not-json") {
+		t.Errorf("OpenFileCredentialStore() error leaks file content: %v", err)
+	}
+}
+
+func TestFileCredentialStore_AuthServiceUpgrade(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "credentials.jsonl")
+	store, err := OpenFileCredentialStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileCredentialStore() error = %v", err)
+	}
+	defer store.Close()
+
+	_ = store.UpdateHash(ctx, "alice", "{noop}legacy-password")
+	service := newTestAuthService(t, store, WithAuthHistory(3))
+
+	match, err := service.Authenticate(ctx, "alice", "legacy-password")
+	if err != nil || !match {
+		t.Fatalf("Authenticate() got = %v, %v, want true, nil", match, err)
+	}
+
+	encoded, _ := store.FindHash(ctx, "alice")
+	if !strings.HasPrefix(encoded, "{bcrypt}") {
+		t.Errorf("Authenticate() did not upgrade the hash, got = %v", encoded)
+	}
+
+	// The upgrade replaced the legacy hash without recording it in the history
+	if history, _ := store.HashHistory(ctx, "alice", 3); len(history) != 0 {
+		t.Errorf("HashHistory() = %v, want no entry for the upgrade", history)
+	}
+	if err := service.ChangePassword(ctx, "alice", "legacy-password", "legacy-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Errorf("ChangePassword() error = %v, want ErrPasswordReused", err)
+	}
+}
@@ -0,0 +1,126 @@
+package passforge
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// FirebaseScryptPasswordEncoder is a verify-only password encoder for users exported from Firebase
+// Authentication, which hashes passwords with a modified scrypt: the key derived from the password and the
+// salt followed by the project's salt separator encrypts the project's signer key with AES-256-CTR.
+// Firebase exports the passwordHash and salt fields separately; join them as BASE64_SALT$BASE64_HASH.
+// The hash parameters are shown in the Firebase console under Authentication, Users, Password hash parameters.
+type FirebaseScryptPasswordEncoder struct {
+	SignerKey     []byte // Decoded base64_signer_key
+	SaltSeparator []byte // Decoded base64_salt_separator
+	Rounds        int    // scrypt block size r, rounds in Firebase
+	MemCost       int    // log2 of the scrypt cost N, mem_cost in Firebase
+}
+
+// FirebaseScryptOption is a functional option used to configure a FirebaseScryptPasswordEncoder instance.
+type FirebaseScryptOption func(*FirebaseScryptPasswordEncoder)
+
+// WithFirebaseRounds sets the rounds parameter of the project
+// Default: 8
+func WithFirebaseRounds(rounds int) FirebaseScryptOption {
+	return func(f *FirebaseScryptPasswordEncoder) {
+		f.Rounds = rounds
+	}
+}
+
+// WithFirebaseMemCost sets the mem_cost parameter of the project
+// Default: 14
+func WithFirebaseMemCost(memCost int) FirebaseScryptOption {
+	return func(f *FirebaseScryptPasswordEncoder) {
+		f.MemCost = memCost
+	}
+}
+
+// NewFirebaseScryptPasswordEncoder creates a new FirebaseScryptPasswordEncoder with the project's decoded
+// signer key and salt separator and Firebase's default rounds and mem_cost if not specified
+func NewFirebaseScryptPasswordEncoder(signerKey, saltSeparator []byte, opts ...FirebaseScryptOption) *FirebaseScryptPasswordEncoder {
+	encoder := &FirebaseScryptPasswordEncoder{
+		SignerKey:     signerKey,
+		SaltSeparator: saltSeparator,
+		Rounds:        8,
+		MemCost:       14,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode is not supported: Firebase hashes are only verified to migrate exported users
+func (f *FirebaseScryptPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (f *FirebaseScryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	salt, stored, err := parseFirebaseScrypt(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	// Firebase accepts rounds from 1 to 8 and mem_cost from 1 to 14
+	if f.Rounds < 1 || f.Rounds > 8 || f.MemCost < 1 || f.MemCost > 14 || len(f.SignerKey) == 0 {
+		return false, fmt.Errorf("firebase: invalid hash parameters")
+	}
+
+	key, err := scrypt.Key([]byte(rawPassword), append(salt, f.SaltSeparator...), 1<<f.MemCost, f.Rounds, 1, 32)
+	if err != nil {
+		return false, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return false, err
+	}
+	computedHash := make([]byte, len(f.SignerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(computedHash, f.SignerKey)
+	return subtle.ConstantTimeCompare(stored, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (f *FirebaseScryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	salt, _, err := parseFirebaseScrypt(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(salt) < minSaltLen {
+		return newFormatError("firebase-scrypt", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+// AdminStatus reports the Firebase scrypt cost; the signer key is never reported
+func (f *FirebaseScryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"rounds": f.Rounds, "memCost": f.MemCost}}
+}
+
+// Name returns the name of the encoder.
+func (f *FirebaseScryptPasswordEncoder) Name() string {
+	return "firebase-scrypt"
+}
+
+// parseFirebaseScrypt parses an encoded password of the form BASE64_SALT$BASE64_HASH
+func parseFirebaseScrypt(encodedPassword string) (salt, hash []byte, err error) {
+	encodedSalt, encodedHash, found := strings.Cut(encodedPassword, "$")
+	if !found {
+		return nil, nil, newFormatError("firebase-scrypt", "invalid encoded password format", encodedPassword)
+	}
+	salt, err = base64.StdEncoding.Strict().DecodeString(encodedSalt)
+	if err != nil || len(salt) == 0 {
+		return nil, nil, newFormatError("firebase-scrypt", "invalid salt encoding", encodedPassword)
+	}
+	hash, err = base64.StdEncoding.Strict().DecodeString(encodedHash)
+	if err != nil || len(hash) == 0 {
+		return nil, nil, newFormatError("firebase-scrypt", "invalid hash encoding", encodedPassword)
+	}
+	return salt, hash, nil
+}
@@ -0,0 +1,62 @@
+package passforge
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// Hash parameters and user from the README of Firebase's scrypt reference implementation
+var (
+	firebaseSignerKey, _     = base64.StdEncoding.DecodeString("jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==")
+	firebaseSaltSeparator, _ = base64.StdEncoding.DecodeString("Bw==")
+)
+
+const firebaseEncoded = "42xEC+ixf3L2lw==$lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="
+
+func TestFirebaseScryptPasswordEncoder_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoder  *FirebaseScryptPasswordEncoder
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"reference user", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator), "user1password", firebaseEncoded, true, false},
+		{"wrong password", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator), "user2password", firebaseEncoded, false, false},
+		{"wrong salt separator", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, nil), "user1password", firebaseEncoded, false, false},
+		{"wrong mem_cost", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator, WithFirebaseMemCost(13)), "user1password", firebaseEncoded, false, false},
+		{"rounds out of range", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator, WithFirebaseRounds(9)), "user1password", firebaseEncoded, false, true},
+		{"missing signer key", NewFirebaseScryptPasswordEncoder(nil, firebaseSaltSeparator), "user1password", firebaseEncoded, false, true},
+		{"missing salt", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator), "user1password", "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ==", false, true},
+		{"invalid hash encoding", NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator), "user1password", "42xEC+ixf3L2lw==$lSrfV15cpx9", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirebaseScryptPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewFirebaseScryptPasswordEncoder(firebaseSignerKey, firebaseSaltSeparator)
+	if _, err := encoder.Encode("user1password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	if err := encoder.ValidateEncoded(firebaseEncoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestFirebaseScryptPasswordEncoder_Name(t *testing.T) {
+	if name := NewFirebaseScryptPasswordEncoder(nil, nil).Name(); name != "firebase-scrypt" {
+		t.Errorf("Name() = %v, want firebase-scrypt", name)
+	}
+}
@@ -0,0 +1,177 @@
+package passforge
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// grubPrefix starts every password hash of grub-mkpasswd-pbkdf2
+const grubPrefix = "grub.pbkdf2.sha512."
+
+// GrubPBKDF2PasswordEncoder is a password encoder producing and verifying the PBKDF2 password hashes of the
+// GRUB 2 bootloader, grub.pbkdf2.sha512.ITERATIONS.HEX_SALT.HEX_HASH, as printed by grub-mkpasswd-pbkdf2 and
+// used in "password_pbkdf2 USER HASH" lines of grub.cfg.
+type GrubPBKDF2PasswordEncoder struct {
+	Iterations int       // Number of iterations
+	SaltLen    int       // Length of the salt
+	KeyLen     int       // Length of the hash
+	Rand       io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// GrubPBKDF2Option is a functional option used to configure a GrubPBKDF2PasswordEncoder instance.
+type GrubPBKDF2Option func(*GrubPBKDF2PasswordEncoder)
+
+// WithGrubPBKDF2Iterations sets the number of iterations
+// Default: 10000, as in grub-mkpasswd-pbkdf2
+func WithGrubPBKDF2Iterations(iterations int) GrubPBKDF2Option {
+	return func(g *GrubPBKDF2PasswordEncoder) {
+		g.Iterations = iterations
+	}
+}
+
+// WithGrubPBKDF2SaltLen sets the length of the salt
+// Default: 64, as in grub-mkpasswd-pbkdf2
+func WithGrubPBKDF2SaltLen(saltLen int) GrubPBKDF2Option {
+	return func(g *GrubPBKDF2PasswordEncoder) {
+		g.SaltLen = saltLen
+	}
+}
+
+// WithGrubPBKDF2KeyLen sets the length of the hash
+// Default: 64, as in grub-mkpasswd-pbkdf2
+func WithGrubPBKDF2KeyLen(keyLen int) GrubPBKDF2Option {
+	return func(g *GrubPBKDF2PasswordEncoder) {
+		g.KeyLen = keyLen
+	}
+}
+
+// WithGrubPBKDF2Rand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithGrubPBKDF2Rand(r io.Reader) GrubPBKDF2Option {
+	return func(g *GrubPBKDF2PasswordEncoder) {
+		g.Rand = r
+	}
+}
+
+// NewGrubPBKDF2PasswordEncoder creates a new GrubPBKDF2PasswordEncoder with default parameters if not specified
+func NewGrubPBKDF2PasswordEncoder(opts ...GrubPBKDF2Option) *GrubPBKDF2PasswordEncoder {
+	encoder := &GrubPBKDF2PasswordEncoder{
+		Iterations: 10000,
+		SaltLen:    64,
+		KeyLen:     64,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with PBKDF2-SHA512, writing the salt and hash in upper case hex as GRUB does
+func (g *GrubPBKDF2PasswordEncoder) Encode(rawPassword string) (string, error) {
+	if g.Iterations < 1 || g.SaltLen < 1 || g.KeyLen < 1 {
+		return "", fmt.Errorf("grub: iterations, saltLen and keyLen must be positive")
+	}
+	salt := make([]byte, g.SaltLen)
+	if _, err := io.ReadFull(randReader(g.Rand), salt); err != nil {
+		return "", err
+	}
+	hash, err := pbkdf2Key(rawPassword, salt, g.Iterations, g.KeyLen, sha512.New)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%d.%s.%s", grubPrefix, g.Iterations, strings.ToUpper(hex.EncodeToString(salt)),
+		strings.ToUpper(hex.EncodeToString(hash))), nil
+}
+
+// Verify checks if the raw password matches the encoded password
+func (g *GrubPBKDF2PasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseGrubPBKDF2(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	computedHash, err := pbkdf2Key(rawPassword, stored.salt, stored.iterations, len(stored.hash), sha512.New)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(stored.hash, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (g *GrubPBKDF2PasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseGrubPBKDF2(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("grub-pbkdf2", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&GrubPBKDF2PasswordEncoder{})
+	RegisterTestVectors("grub-pbkdf2", true, func(rand io.Reader) PasswordEncoder {
+		return NewGrubPBKDF2PasswordEncoder(WithGrubPBKDF2Rand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a GRUB 2 PBKDF2 hash
+func (g *GrubPBKDF2PasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, grubPrefix)
+}
+
+// EncodedParams returns the iterations of the hash
+func (g *GrubPBKDF2PasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseGrubPBKDF2(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations)}
+}
+
+// AdminStatus reports the PBKDF2 parameters of new GRUB hashes
+func (g *GrubPBKDF2PasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"iterations": g.Iterations, "saltLen": g.SaltLen, "keyLen": g.KeyLen}}
+}
+
+// Name returns the name of the encoder.
+func (g *GrubPBKDF2PasswordEncoder) Name() string {
+	return "grub-pbkdf2"
+}
+
+// grubPBKDF2Hash is a parsed GRUB password hash
+type grubPBKDF2Hash struct {
+	iterations int
+	salt, hash []byte
+}
+
+// parseGrubPBKDF2 parses a hash of the form grub.pbkdf2.sha512.ITERATIONS.HEX_SALT.HEX_HASH
+func parseGrubPBKDF2(encodedPassword string) (*grubPBKDF2Hash, error) {
+	rest, ok := strings.CutPrefix(encodedPassword, grubPrefix)
+	parts := strings.Split(rest, ".")
+	if !ok || len(parts) != 3 {
+		return nil, newFormatError("grub-pbkdf2", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &grubPBKDF2Hash{}
+	var err error
+	stored.iterations, err = strconv.Atoi(parts[0])
+	if err != nil || stored.iterations < 1 || parts[0][0] == '+' {
+		return nil, newFormatError("grub-pbkdf2", "invalid iterations", encodedPassword)
+	}
+	stored.salt, err = hex.DecodeString(parts[1])
+	if err != nil || len(stored.salt) == 0 {
+		return nil, newFormatError("grub-pbkdf2", "invalid salt encoding", encodedPassword)
+	}
+	stored.hash, err = hex.DecodeString(parts[2])
+	if err != nil || len(stored.hash) == 0 {
+		return nil, newFormatError("grub-pbkdf2", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
@@ -0,0 +1,69 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGrubPBKDF2PasswordEncoder_Verify(t *testing.T) {
+	// Hashes computed with Python's hashlib.pbkdf2_hmac
+	grubDefault := "grub.pbkdf2.sha512.10000.000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F202122232425262728292A2B2C2D2E2F303132333435363738393A3B3C3D3E3F." +
+		"DE25072AD1C2279350AA009DE388C0072AFD49313679A3CE2C980BE1F1AFB6084E2FF4E0BF920D3E24902616F118C50CBC79A21C877C08A5FDE691F177769D7A"
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"grub-mkpasswd-pbkdf2 defaults", "password", grubDefault, true, false},
+		{"missing prefix", "password", strings.TrimPrefix(grubDefault, grubPrefix), false, true},
+		{"lower case hex", "password", grubPrefix + strings.ToLower(strings.TrimPrefix(grubDefault, grubPrefix)), true, false},
+		{"short hash", "password", "grub.pbkdf2.sha512.1000.000102030405060708090A0B0C0D0E0F.C74E4080D0FBB41FEE5868C0FF60FD75ACAE2638215987E5FF54F8EAE211339B", true, false},
+		{"wrong password", "Password", grubDefault, false, false},
+		{"wrong iterations", "password", strings.Replace(grubDefault, ".10000.", ".10001.", 1), false, false},
+		{"sha256", "password", strings.Replace(grubDefault, "sha512", "sha256", 1), false, true},
+		{"invalid iterations", "password", strings.Replace(grubDefault, ".10000.", ".-1.", 1), false, true},
+		{"invalid hex", "password", "grub.pbkdf2.sha512.1000.0001020304050607.XYZ", false, true},
+		{"missing hash", "password", "grub.pbkdf2.sha512.1000.0001020304050607", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewGrubPBKDF2PasswordEncoder().Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrubPBKDF2PasswordEncoder_Encode(t *testing.T) {
+	encoder := NewGrubPBKDF2PasswordEncoder(WithGrubPBKDF2Iterations(1000), WithGrubPBKDF2SaltLen(16),
+		WithGrubPBKDF2KeyLen(32), WithGrubPBKDF2Rand(bytes.NewReader([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if want := "grub.pbkdf2.sha512.1000.000102030405060708090A0B0C0D0E0F.C74E4080D0FBB41FEE5868C0FF60FD75ACAE2638215987E5FF54F8EAE211339B"; encoded != want {
+		t.Errorf("Encode() = %v, want %v", encoded, want)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+	if err := encoder.ValidateEncoded("grub.pbkdf2.sha512.1000.00010203.C74E4080"); err == nil {
+		t.Error("ValidateEncoded() should reject a 4-byte salt")
+	}
+	if _, err := NewGrubPBKDF2PasswordEncoder(WithGrubPBKDF2KeyLen(0)).Encode("password"); err == nil {
+		t.Error("Encode() with keyLen 0 should fail")
+	}
+}
+
+func TestGrubPBKDF2PasswordEncoder_Name(t *testing.T) {
+	if name := NewGrubPBKDF2PasswordEncoder().Name(); name != "grub-pbkdf2" {
+		t.Errorf("Name() = %v, want grub-pbkdf2", name)
+	}
+}
@@ -0,0 +1,140 @@
+package passforge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrUnknownHmacKey is returned when an encoded secret references an HMAC key version that is not configured
+var ErrUnknownHmacKey = errors.New("unknown hmac key version")
+
+// ErrSecretTooShort is returned when a secret is shorter than HmacSha256Encoder's minimum length
+var ErrSecretTooShort = errors.New("secret too short")
+
+// hmacSha256MinKeyLen is the minimum length of an HMAC key, the size of the SHA-256 output
+const hmacSha256MinKeyLen = sha256.Size
+
+// HmacSha256Encoder hashes API tokens, session secrets and other random secrets of 128 bits or more with
+// HMAC-SHA256 under a server-side key, stored as "VERSION$BASE64(HMAC)". Such secrets can't be guessed, so
+// they need no salt nor memory-hard hashing: Verify costs a single HMAC, and equal secrets give equal
+// hashes, so the hash can index the token table. Never use it for passwords, which people choose: its
+// name, hmac-sha256, keeps it apart from the password encoders under a DelegatingPasswordEncoder.
+type HmacSha256Encoder struct {
+	Keyring          // Key versions => HMAC keys of at least 32 bytes; the current one is used by Encode
+	MinSecretLen int // Minimum length of the secrets Encode accepts, in bytes
+}
+
+// HmacSha256Option is a functional option used to configure an HmacSha256Encoder instance.
+type HmacSha256Option func(*HmacSha256Encoder)
+
+// WithHmacSha256Key adds an older key version, still accepted by Verify
+func WithHmacSha256Key(version string, key []byte) HmacSha256Option {
+	return func(h *HmacSha256Encoder) {
+		h.Keys[version] = key
+	}
+}
+
+// WithHmacSha256MinSecretLen sets the minimum length of the secrets Encode accepts, in bytes
+// Default: 16, a 128-bit secret when every byte is random
+func WithHmacSha256MinSecretLen(minSecretLen int) HmacSha256Option {
+	return func(h *HmacSha256Encoder) {
+		h.MinSecretLen = minSecretLen
+	}
+}
+
+// NewHmacSha256Encoder creates an HmacSha256Encoder hashing with the current key.
+// Versions must not contain '$' and every key must be at least 32 bytes long.
+func NewHmacSha256Encoder(currentVersion string, currentKey []byte, opts ...HmacSha256Option) (*HmacSha256Encoder, error) {
+	encoder := &HmacSha256Encoder{
+		Keyring:      newKeyring(currentVersion, currentKey),
+		MinSecretLen: 16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+
+	if err := encoder.validate("hmac key", minKeyLen(hmacSha256MinKeyLen)); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// Encode hashes the secret with the current key
+func (h *HmacSha256Encoder) Encode(rawPassword string) (string, error) {
+	if len(rawPassword) < h.MinSecretLen {
+		return "", ErrSecretTooShort
+	}
+	mac, err := h.mac(h.CurrentVersion, rawPassword)
+	if err != nil {
+		return "", err
+	}
+	return h.CurrentVersion + "$" + base64.StdEncoding.EncodeToString(mac), nil
+}
+
+// Verify checks if the secret matches the encoded secret, in constant time
+func (h *HmacSha256Encoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	version, stored, err := h.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	mac, err := h.mac(version, rawPassword)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(stored, mac), nil
+}
+
+// ValidateEncoded checks that the encoded secret references a known key and has the HMAC-SHA256 length
+func (h *HmacSha256Encoder) ValidateEncoded(encodedPassword string) error {
+	version, _, err := h.parse(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if _, ok := h.Key(version); !ok {
+		return ErrUnknownHmacKey
+	}
+	return nil
+}
+
+// AdminStatus reports the current secret version, never the secrets
+func (h *HmacSha256Encoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"currentVersion": h.CurrentVersion, "minSecretLen": h.MinSecretLen}}
+}
+
+// Name returns the name of the encoder.
+func (h *HmacSha256Encoder) Name() string {
+	return "hmac-sha256"
+}
+
+// NeedsRehash reports whether the encoded secret is hashed with a key other than the current one.
+// Secrets can't be rehashed offline: rehash them when they are next presented, or reissue them.
+func (h *HmacSha256Encoder) NeedsRehash(encodedPassword string) bool {
+	return !h.IsCurrent(encodedPassword)
+}
+
+// mac returns the HMAC-SHA256 of the secret under the key version
+func (h *HmacSha256Encoder) mac(version, rawPassword string) ([]byte, error) {
+	key, ok := h.Key(version)
+	if !ok {
+		return nil, ErrUnknownHmacKey
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(rawPassword))
+	return mac.Sum(nil), nil
+}
+
+// parse splits an encoded secret of the form VERSION$BASE64(HMAC)
+func (h *HmacSha256Encoder) parse(encodedPassword string) (string, []byte, error) {
+	version, encoded, found := strings.Cut(encodedPassword, "$")
+	if !found || version == "" {
+		return "", nil, newFormatError("hmac-sha256", "invalid encoded secret format", encodedPassword)
+	}
+	mac, err := base64.StdEncoding.Strict().DecodeString(encoded)
+	if err != nil || len(mac) != sha256.Size {
+		return "", nil, newFormatError("hmac-sha256", "invalid hmac encoding", encodedPassword)
+	}
+	return version, mac, nil
+}
@@ -0,0 +1,109 @@
+package passforge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewHmacSha256Encoder(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	tests := []struct {
+		name    string
+		version string
+		key     []byte
+		opts    []HmacSha256Option
+		wantErr bool
+	}{
+		{"valid", "v1", key, nil, false},
+		{"older key", "v2", key, []HmacSha256Option{WithHmacSha256Key("v1", key)}, false},
+		{"short key", "v1", key[:31], nil, true},
+		{"short older key", "v2", key, []HmacSha256Option{WithHmacSha256Key("v1", key[:16])}, true},
+		{"empty version", "", key, nil, true},
+		{"version with separator", "v$1", key, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHmacSha256Encoder(tt.version, tt.key, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewHmacSha256Encoder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHmacSha256Encoder_Verify(t *testing.T) {
+	// HMACs computed with Python's hmac module
+	encoder, _ := NewHmacSha256Encoder("v2", []byte("0123456789abcdef0123456789abcdef"),
+		WithHmacSha256Key("v1", bytes.Repeat([]byte("k"), 32)))
+	tests := []struct {
+		name    string
+		secret  string
+		encoded string
+		want    bool
+		wantErr error
+	}{
+		{"current key", "tok_0123456789abcdef", "v2$3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lfA38=", true, nil},
+		{"older key", "tok_0123456789abcdef", "v1$Nu9p2NWRIWhW1eRe6jlEb7dL9w9HHsUVAEvL5rOVT8Q=", true, nil},
+		{"wrong secret", "tok_0123456789abcdeF", "v2$3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lfA38=", false, nil},
+		{"relabelled key", "tok_0123456789abcdef", "v1$3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lfA38=", false, nil},
+		{"unknown key", "tok_0123456789abcdef", "v3$3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lfA38=", false, ErrUnknownHmacKey},
+		{"truncated hmac", "tok_0123456789abcdef", "v2$3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lf", false, ErrInvalidFormat},
+		{"missing version", "tok_0123456789abcdef", "3/wV2LA+RDPt2BMy/HE2jC9QfNS3AZiFyWRGn1lfA38=", false, ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.secret, tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestHmacSha256Encoder_Encode(t *testing.T) {
+	encoder, _ := NewHmacSha256Encoder("v1", bytes.Repeat([]byte("k"), 32))
+	encoded, err := encoder.Encode("tok_0123456789abcdef")
+	if err != nil || encoded != "v1$Nu9p2NWRIWhW1eRe6jlEb7dL9w9HHsUVAEvL5rOVT8Q=" {
+		t.Errorf("Encode() = %v, %v, want the v1 HMAC", encoded, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+	if _, err := encoder.Encode("password123"); !errors.Is(err, ErrSecretTooShort) {
+		t.Errorf("Encode() of a short secret error = %v, want ErrSecretTooShort", err)
+	}
+
+	rotated, _ := NewHmacSha256Encoder("v2", []byte("0123456789abcdef0123456789abcdef"), WithHmacSha256Key("v1", bytes.Repeat([]byte("k"), 32)))
+	if !rotated.NeedsRehash(encoded) {
+		t.Errorf("NeedsRehash() = false for a secret hashed with an older key")
+	}
+	if reencoded, _ := rotated.Encode("tok_0123456789abcdef"); rotated.NeedsRehash(reencoded) {
+		t.Errorf("NeedsRehash() = true for a secret hashed with the current key")
+	}
+}
+
+func TestHmacSha256Encoder_ResetTokens(t *testing.T) {
+	ctx := context.Background()
+	encoder, _ := NewHmacSha256Encoder("v1", bytes.Repeat([]byte("k"), 32))
+	manager := NewResetTokenManager(encoder, NewMemoryResetTokenStore())
+
+	token, err := manager.Issue(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if user, err := manager.Redeem(ctx, token); err != nil || user != "alice" {
+		t.Errorf("Redeem() = %v, %v, want alice", user, err)
+	}
+}
+
+func TestHmacSha256Encoder_Name(t *testing.T) {
+	encoder, _ := NewHmacSha256Encoder("v1", bytes.Repeat([]byte("k"), 32))
+	if name := encoder.Name(); name != "hmac-sha256" {
+		t.Errorf("Name() = %v, want hmac-sha256", name)
+	}
+}
@@ -0,0 +1,65 @@
+// Package htpasswd reads, verifies and writes the password files of Apache's htpasswd, one user:hash entry
+// per line, so reverse proxies and internal tools can share them with httpd and nginx.
+package htpasswd
+
+import (
+	"github.com/nduyhai/passforge"
+	"github.com/nduyhai/passforge/internal/unixcrypt"
+)
+
+// verifier verifies the formats of htpasswd
+var verifier = unixcrypt.Verifier{
+	Encoder: "htpasswd",
+	Formats: unixcrypt.Bcrypt | unixcrypt.Apr1 | unixcrypt.SHA1 | unixcrypt.DESCrypt | unixcrypt.Sha256Crypt | unixcrypt.Sha512Crypt,
+}
+
+// Encoder is a password encoder for the password field of htpasswd entries. Verify accepts the formats of
+// htpasswd: bcrypt ($2y$, htpasswd -B), MD5 ($apr1$, htpasswd -m), SHA-1 ({SHA}, htpasswd -s), DES crypt
+// (htpasswd -d) and, as written by newer versions, SHA-256 and SHA-512 crypt ($5$ and $6$). Encode always
+// produces bcrypt; NeedsRehash reports the entries in the other, weaker, formats, and successful verifications
+// against apr1, SHA-1 and DES crypt entries are reported to the weak algorithm hook.
+type Encoder struct {
+	Bcrypt *passforge.BcryptPasswordEncoder // Encodes new entries
+}
+
+// Option is a functional option used to configure an Encoder instance.
+type Option func(*Encoder)
+
+// WithBcryptCost sets the bcrypt cost of new entries
+// Default: 10; htpasswd -B uses 5 unless given -C
+func WithBcryptCost(cost int) Option {
+	return func(e *Encoder) {
+		e.Bcrypt.Cost = cost
+	}
+}
+
+// NewEncoder creates a new Encoder with default parameters if not specified
+func NewEncoder(opts ...Option) *Encoder {
+	encoder := &Encoder{
+		Bcrypt: passforge.NewBcryptPasswordEncoder(passforge.WithBcryptVariant(passforge.Bcrypt2y)),
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with bcrypt, $2y$ as written by htpasswd -B
+func (e *Encoder) Encode(rawPassword string) (string, error) {
+	return e.Bcrypt.Encode(rawPassword)
+}
+
+// Verify checks if the raw password matches the password field of an entry
+func (e *Encoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return verifier.Verify(rawPassword, encodedPassword)
+}
+
+// Name returns the name of the encoder.
+func (e *Encoder) Name() string {
+	return "htpasswd"
+}
+
+// NeedsRehash reports whether the password field is in a format other than bcrypt
+func (e *Encoder) NeedsRehash(encodedPassword string) bool {
+	return !unixcrypt.IsBcrypt(encodedPassword)
+}
@@ -0,0 +1,105 @@
+package htpasswd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEncoder_Verify(t *testing.T) {
+	// Entries for "password" in every format of htpasswd, computed with openssl passwd and crypt(3)
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"bcrypt", "password", "$2y$05$abcdefghijklmnopqrstuuWG29KuyeAicPCJODk1zjyGvyQUU2awu", true, false},
+		{"apr1", "password", "$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/", true, false},
+		{"sha1", "password", "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", true, false},
+		{"crypt", "password", "abJnggxhB/yWI", true, false},
+		{"sha256-crypt", "password", "$5$saltsaltsaltsalt$WsFBeg1qQ90JL3VkUTuM7xVV/5njhLngIVm6ftSnBR2", true, false},
+		{"sha512-crypt", "password", "$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0", true, false},
+		{"apr1 wrong password", "Password", "$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/", false, false},
+		{"sha1 wrong password", "Password", "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", false, false},
+		{"crypt wrong password", "Password", "abJnggxhB/yWI", false, false},
+		{"crypt only uses 8 characters", "password123", "abJnggxhB/yWI", true, false},
+		{"apr1 missing salt", "password", "$apr1$$yAAkm4libquA.ZWLHbSBq/", false, true},
+		{"apr1 missing hash", "password", "$apr1$saltsalt", false, true},
+		{"crypt invalid salt", "password", "a:JnggxhB/yWI", false, true},
+		{"plain text", "password", "password", false, true},
+	}
+	encoder := NewEncoder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, passforge.ErrInvalidFormat) {
+				t.Errorf("Verify() error = %v, want ErrInvalidFormat", err)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_VerifyLongBcrypt(t *testing.T) {
+	// Other bcrypt implementations truncate passwords to 72 bytes, so their hashes of long passwords verify
+	password := strings.Repeat("p", 100)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(password[:72]), 4)
+	encoded := "$2y$" + string(hashed[4:])
+	if match, err := NewEncoder().Verify(password, encoded); err != nil || !match {
+		t.Errorf("Verify() of a 100-byte password = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	encoder := NewEncoder(WithBcryptCost(4))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$2y$04$") {
+		t.Errorf("Encode() = %v, want a $2y$ bcrypt hash of cost 4", encoded)
+	}
+	if match, err := encoder.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true", match, err)
+	}
+	if encoder.NeedsRehash(encoded) {
+		t.Error("NeedsRehash() of a bcrypt hash = true, want false")
+	}
+	if !encoder.NeedsRehash("$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/") {
+		t.Error("NeedsRehash() of an apr1 hash = false, want true")
+	}
+}
+
+func TestEncoder_Weak(t *testing.T) {
+	var flagged []passforge.WeakAlgorithmEvent
+	passforge.SetWeakAlgorithmHook(func(event passforge.WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer passforge.SetWeakAlgorithmHook(nil)
+
+	encoder := NewEncoder()
+	for _, encoded := range []string{"$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/", "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", "abJnggxhB/yWI"} {
+		if match, err := encoder.Verify("password", encoded); err != nil || !match {
+			t.Fatalf("Verify(%q) = %v, %v, want true", encoded, match, err)
+		}
+	}
+	if len(flagged) != 3 || flagged[0].Algorithm != "htpasswd" {
+		t.Errorf("Verify() flagged %v, want three htpasswd events", flagged)
+	}
+}
+
+func TestEncoder_Name(t *testing.T) {
+	if name := NewEncoder().Name(); name != "htpasswd" {
+		t.Errorf("Name() = %v, want htpasswd", name)
+	}
+}
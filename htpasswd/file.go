@@ -0,0 +1,208 @@
+package htpasswd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidUser is returned for user names htpasswd can't store: empty, or containing ":" or a line break
+var ErrInvalidUser = errors.New("htpasswd: invalid user name")
+
+// line is a line of an htpasswd file, an entry or a comment or blank line kept as is
+type line struct {
+	user, hash string
+	raw        string // Text of comment and blank lines, whose user is empty
+}
+
+// File is an htpasswd file held in memory. Comments, blank lines and the order of entries are kept when
+// it is written back; as in httpd, the first entry of a user wins. It is safe for concurrent use.
+type File struct {
+	Encoder *Encoder // Verifies entries and encodes new ones
+
+	mu    sync.RWMutex
+	lines []line
+}
+
+// New creates an empty File
+func New(opts ...Option) *File {
+	return &File{Encoder: NewEncoder(opts...)}
+}
+
+// Parse reads an htpasswd file
+func Parse(r io.Reader, opts ...Option) (*File, error) {
+	file := New(opts...)
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if trimmed := strings.TrimSpace(text); trimmed == "" || trimmed[0] == '#' {
+			file.lines = append(file.lines, line{raw: text})
+			continue
+		}
+		user, hash, found := strings.Cut(text, ":")
+		if !found || user == "" {
+			return nil, fmt.Errorf("htpasswd: line %d: missing user name or password", number)
+		}
+		file.lines = append(file.lines, line{user: user, hash: hash})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Load reads the htpasswd file at path
+func Load(path string, opts ...Option) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, opts...)
+}
+
+// Verify checks the password of the user; unknown users don't match
+func (f *File) Verify(user, password string) (bool, error) {
+	f.mu.RLock()
+	hash, ok := f.lookup(user)
+	f.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return f.Encoder.Verify(password, hash)
+}
+
+// NeedsRehash reports whether the entry of the user is in a format other than bcrypt, so it can be replaced
+// with Set after a successful Verify
+func (f *File) NeedsRehash(user string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	hash, ok := f.lookup(user)
+	return ok && f.Encoder.NeedsRehash(hash)
+}
+
+// Set encodes the password and replaces the entry of the user, or appends one
+func (f *File) Set(user, password string) error {
+	if user == "" || strings.ContainsAny(user, ":\r\n") {
+		return ErrInvalidUser
+	}
+	hash, err := f.Encoder.Encode(password)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.lines {
+		if f.lines[i].user == user {
+			f.lines[i].hash = hash
+			return nil
+		}
+	}
+	f.lines = append(f.lines, line{user: user, hash: hash})
+	return nil
+}
+
+// Remove deletes every entry of the user and reports whether there was one
+func (f *File) Remove(user string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kept := f.lines[:0]
+	for _, l := range f.lines {
+		if l.user != user || user == "" {
+			kept = append(kept, l)
+		}
+	}
+	removed := len(kept) != len(f.lines)
+	clear(f.lines[len(kept):])
+	f.lines = kept
+	return removed
+}
+
+// Users returns the users with an entry, in file order
+func (f *File) Users() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var users []string
+	seen := make(map[string]bool)
+	for _, l := range f.lines {
+		if l.user != "" && !seen[l.user] {
+			seen[l.user] = true
+			users = append(users, l.user)
+		}
+	}
+	return users
+}
+
+// WriteTo writes the file in the htpasswd format
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	f.mu.RLock()
+	var buf bytes.Buffer
+	for _, l := range f.lines {
+		if l.user == "" {
+			buf.WriteString(l.raw)
+		} else {
+			buf.WriteString(l.user + ":" + l.hash)
+		}
+		buf.WriteByte('\n')
+	}
+	f.mu.RUnlock()
+	return buf.WriteTo(w)
+}
+
+// Save writes the file to path through a temporary file renamed over it, so readers never see a partial
+// file. An existing file keeps its permissions, new files are created with 0640.
+func (f *File) Save(path string) error {
+	mode := os.FileMode(0o640)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := f.WriteTo(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lookup returns the hash of the first entry of the user
+func (f *File) lookup(user string) (string, bool) {
+	if user == "" {
+		return "", false
+	}
+	for _, l := range f.lines {
+		if l.user == user {
+			return l.hash, true
+		}
+	}
+	return "", false
+}
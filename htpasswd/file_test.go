@@ -0,0 +1,138 @@
+package htpasswd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFile = `# Managed by hand
+alice:$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/
+bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=
+
+carol:abJnggxhB/yWI
+alice:{SHA}ignored
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if users := file.Users(); !reflect.DeepEqual(users, []string{"alice", "bob", "carol"}) {
+		t.Errorf("Users() = %v, want [alice bob carol]", users)
+	}
+
+	tests := []struct {
+		user, password string
+		want           bool
+	}{
+		{"alice", "password", true},
+		{"bob", "password", true},
+		{"carol", "password", true},
+		{"carol", "wrong", false},
+		{"dave", "password", false},
+		{"", "password", false},
+	}
+	for _, tt := range tests {
+		if match, err := file.Verify(tt.user, tt.password); err != nil || match != tt.want {
+			t.Errorf("Verify(%q, %q) = %v, %v, want %v", tt.user, tt.password, match, err, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"alice\n", ":$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/\n"} {
+		if _, err := Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Parse(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestFile_SetAndWrite(t *testing.T) {
+	file, err := Parse(strings.NewReader(testFile), WithBcryptCost(4))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !file.NeedsRehash("alice") || file.NeedsRehash("dave") {
+		t.Error("NeedsRehash() should only report existing non-bcrypt entries")
+	}
+	if err := file.Set("alice", "new password"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := file.Set("dave", "password"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if file.NeedsRehash("alice") {
+		t.Error("NeedsRehash() after Set() = true, want false")
+	}
+	for _, user := range []string{"", "eve:admin", "eve\n"} {
+		if err := file.Set(user, "password"); !errors.Is(err, ErrInvalidUser) {
+			t.Errorf("Set(%q) error = %v, want ErrInvalidUser", user, err)
+		}
+	}
+	if !file.Remove("bob") || file.Remove("bob") {
+		t.Error("Remove() should report whether an entry was removed")
+	}
+
+	var out strings.Builder
+	if _, err := file.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 7 || lines[0] != "# Managed by hand" || !strings.HasPrefix(lines[1], "alice:$2y$04$") ||
+		lines[2] != "" || lines[3] != "carol:abJnggxhB/yWI" || lines[4] != "alice:{SHA}ignored" ||
+		!strings.HasPrefix(lines[5], "dave:$2y$04$") || lines[6] != "" {
+		t.Errorf("WriteTo() = %q", out.String())
+	}
+
+	reread, err := Parse(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if match, err := reread.Verify("alice", "new password"); err != nil || !match {
+		t.Errorf("Verify() after rewrite = %v, %v, want true", match, err)
+	}
+}
+
+func TestFile_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".htpasswd")
+	file := New(WithBcryptCost(4))
+	if err := file.Set("alice", "password"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := file.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Set("bob", "password"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := file.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if users := loaded.Users(); !reflect.DeepEqual(users, []string{"alice", "bob"}) {
+		t.Errorf("Users() = %v, want [alice bob]", users)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want the existing 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want the temporary file removed", len(entries))
+	}
+}
@@ -0,0 +1,203 @@
+// Package descrypt implements the traditional DES-based crypt(3) of Version 7 Unix: 25 encryptions of a zero
+// block with the first 8 characters of the password as the key and a 12-bit salt perturbing the expansion
+// permutation. It is only fit for verifying entries of legacy htpasswd and passwd files.
+package descrypt
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSalt is returned for salts that aren't two characters of the crypt(3) alphabet
+var ErrInvalidSalt = errors.New("descrypt: invalid salt")
+
+// itoa64 is the alphabet of the salt and the encoded hash
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// The DES permutations of FIPS 46-3, with bit positions numbered from 1 at the most significant bit
+var (
+	initialPermutation = []byte{
+		58, 50, 42, 34, 26, 18, 10, 2, 60, 52, 44, 36, 28, 20, 12, 4,
+		62, 54, 46, 38, 30, 22, 14, 6, 64, 56, 48, 40, 32, 24, 16, 8,
+		57, 49, 41, 33, 25, 17, 9, 1, 59, 51, 43, 35, 27, 19, 11, 3,
+		61, 53, 45, 37, 29, 21, 13, 5, 63, 55, 47, 39, 31, 23, 15, 7,
+	}
+	finalPermutation = []byte{
+		40, 8, 48, 16, 56, 24, 64, 32, 39, 7, 47, 15, 55, 23, 63, 31,
+		38, 6, 46, 14, 54, 22, 62, 30, 37, 5, 45, 13, 53, 21, 61, 29,
+		36, 4, 44, 12, 52, 20, 60, 28, 35, 3, 43, 11, 51, 19, 59, 27,
+		34, 2, 42, 10, 50, 18, 58, 26, 33, 1, 41, 9, 49, 17, 57, 25,
+	}
+	expansion = []byte{
+		32, 1, 2, 3, 4, 5, 4, 5, 6, 7, 8, 9, 8, 9, 10, 11, 12, 13, 12, 13, 14, 15, 16, 17,
+		16, 17, 18, 19, 20, 21, 20, 21, 22, 23, 24, 25, 24, 25, 26, 27, 28, 29, 28, 29, 30, 31, 32, 1,
+	}
+	roundPermutation = []byte{
+		16, 7, 20, 21, 29, 12, 28, 17, 1, 15, 23, 26, 5, 18, 31, 10,
+		2, 8, 24, 14, 32, 27, 3, 9, 19, 13, 30, 6, 22, 11, 4, 25,
+	}
+	permutedChoice1 = []byte{
+		57, 49, 41, 33, 25, 17, 9, 1, 58, 50, 42, 34, 26, 18,
+		10, 2, 59, 51, 43, 35, 27, 19, 11, 3, 60, 52, 44, 36,
+		63, 55, 47, 39, 31, 23, 15, 7, 62, 54, 46, 38, 30, 22,
+		14, 6, 61, 53, 45, 37, 29, 21, 13, 5, 28, 20, 12, 4,
+	}
+	permutedChoice2 = []byte{
+		14, 17, 11, 24, 1, 5, 3, 28, 15, 6, 21, 10, 23, 19, 12, 4, 26, 8, 16, 7, 27, 20, 13, 2,
+		41, 52, 31, 37, 47, 55, 30, 40, 51, 45, 33, 48, 44, 49, 39, 56, 34, 53, 46, 42, 50, 36, 29, 32,
+	}
+	keyShifts = []uint{1, 1, 2, 2, 2, 2, 2, 2, 1, 2, 2, 2, 2, 2, 2, 1}
+)
+
+// sBoxes are the DES substitution boxes, indexed by row*16 + column
+var sBoxes = [8][64]byte{
+	{
+		14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7,
+		0, 15, 7, 4, 14, 2, 13, 1, 10, 6, 12, 11, 9, 5, 3, 8,
+		4, 1, 14, 8, 13, 6, 2, 11, 15, 12, 9, 7, 3, 10, 5, 0,
+		15, 12, 8, 2, 4, 9, 1, 7, 5, 11, 3, 14, 10, 0, 6, 13,
+	},
+	{
+		15, 1, 8, 14, 6, 11, 3, 4, 9, 7, 2, 13, 12, 0, 5, 10,
+		3, 13, 4, 7, 15, 2, 8, 14, 12, 0, 1, 10, 6, 9, 11, 5,
+		0, 14, 7, 11, 10, 4, 13, 1, 5, 8, 12, 6, 9, 3, 2, 15,
+		13, 8, 10, 1, 3, 15, 4, 2, 11, 6, 7, 12, 0, 5, 14, 9,
+	},
+	{
+		10, 0, 9, 14, 6, 3, 15, 5, 1, 13, 12, 7, 11, 4, 2, 8,
+		13, 7, 0, 9, 3, 4, 6, 10, 2, 8, 5, 14, 12, 11, 15, 1,
+		13, 6, 4, 9, 8, 15, 3, 0, 11, 1, 2, 12, 5, 10, 14, 7,
+		1, 10, 13, 0, 6, 9, 8, 7, 4, 15, 14, 3, 11, 5, 2, 12,
+	},
+	{
+		7, 13, 14, 3, 0, 6, 9, 10, 1, 2, 8, 5, 11, 12, 4, 15,
+		13, 8, 11, 5, 6, 15, 0, 3, 4, 7, 2, 12, 1, 10, 14, 9,
+		10, 6, 9, 0, 12, 11, 7, 13, 15, 1, 3, 14, 5, 2, 8, 4,
+		3, 15, 0, 6, 10, 1, 13, 8, 9, 4, 5, 11, 12, 7, 2, 14,
+	},
+	{
+		2, 12, 4, 1, 7, 10, 11, 6, 8, 5, 3, 15, 13, 0, 14, 9,
+		14, 11, 2, 12, 4, 7, 13, 1, 5, 0, 15, 10, 3, 9, 8, 6,
+		4, 2, 1, 11, 10, 13, 7, 8, 15, 9, 12, 5, 6, 3, 0, 14,
+		11, 8, 12, 7, 1, 14, 2, 13, 6, 15, 0, 9, 10, 4, 5, 3,
+	},
+	{
+		12, 1, 10, 15, 9, 2, 6, 8, 0, 13, 3, 4, 14, 7, 5, 11,
+		10, 15, 4, 2, 7, 12, 9, 5, 6, 1, 13, 14, 0, 11, 3, 8,
+		9, 14, 15, 5, 2, 8, 12, 3, 7, 0, 4, 10, 1, 13, 11, 6,
+		4, 3, 2, 12, 9, 5, 15, 10, 11, 14, 1, 7, 6, 0, 8, 13,
+	},
+	{
+		4, 11, 2, 14, 15, 0, 8, 13, 3, 12, 9, 7, 5, 10, 6, 1,
+		13, 0, 11, 7, 4, 9, 1, 10, 14, 3, 5, 12, 2, 15, 8, 6,
+		1, 4, 11, 13, 12, 3, 7, 14, 10, 15, 6, 8, 0, 5, 9, 2,
+		6, 11, 13, 8, 1, 4, 10, 7, 9, 5, 0, 15, 14, 2, 3, 12,
+	},
+	{
+		13, 2, 8, 4, 6, 15, 11, 1, 10, 9, 3, 14, 5, 0, 12, 7,
+		1, 15, 13, 8, 10, 3, 7, 4, 12, 5, 6, 11, 0, 14, 9, 2,
+		7, 11, 4, 1, 9, 12, 14, 2, 0, 6, 10, 13, 15, 3, 5, 8,
+		2, 1, 14, 7, 4, 10, 8, 13, 15, 12, 9, 0, 3, 5, 6, 11,
+	},
+}
+
+// Crypt returns the 13-character crypt(3) hash of the password with the two-character salt, the salt
+// followed by 11 characters of hash. Only the first 8 characters of the password are used.
+func Crypt(password []byte, salt string) (string, error) {
+	if len(salt) != 2 {
+		return "", ErrInvalidSalt
+	}
+	var saltBits uint64
+	for i := 0; i < 2; i++ {
+		v := strings.IndexByte(itoa64, salt[i])
+		if v < 0 {
+			return "", ErrInvalidSalt
+		}
+		saltBits |= uint64(v) << (6 * i)
+	}
+
+	var key uint64
+	for i := 0; i < 8; i++ {
+		key <<= 8
+		if i < len(password) {
+			key |= uint64(password[i] << 1)
+		}
+	}
+
+	subkeys := keySchedule(key)
+	var block uint64
+	for i := 0; i < 25; i++ {
+		block = encrypt(block, &subkeys, saltBits)
+	}
+
+	out := make([]byte, 0, 13)
+	out = append(out, salt...)
+	for i := 0; i < 11; i++ {
+		// 64 bits in 6-bit groups, most significant first, the last one padded with two zero bits
+		shift := 64 - 6*(i+1)
+		var v uint64
+		if shift >= 0 {
+			v = block >> uint(shift)
+		} else {
+			v = block << uint(-shift)
+		}
+		out = append(out, itoa64[v&0x3f])
+	}
+	return string(out), nil
+}
+
+// keySchedule derives the 16 48-bit round keys
+func keySchedule(key uint64) [16]uint64 {
+	var subkeys [16]uint64
+	cd := permute(key, permutedChoice1, 64)
+	c, d := cd>>28, cd&0xfffffff
+	for round, shift := range keyShifts {
+		c = (c<<shift | c>>(28-shift)) & 0xfffffff
+		d = (d<<shift | d>>(28-shift)) & 0xfffffff
+		subkeys[round] = permute(c<<28|d, permutedChoice2, 56)
+	}
+	return subkeys
+}
+
+// encrypt runs the 16 DES rounds over the block
+func encrypt(block uint64, subkeys *[16]uint64, saltBits uint64) uint64 {
+	block = permute(block, initialPermutation, 64)
+	left, right := block>>32, block&0xffffffff
+	for _, subkey := range subkeys {
+		left, right = right, left^feistel(right, subkey, saltBits)
+	}
+	return permute(right<<32|left, finalPermutation, 64)
+}
+
+// feistel is the DES round function, with salt bit i swapping bits i+1 and i+25 of the expansion
+func feistel(right, subkey, saltBits uint64) uint64 {
+	expanded := permute(right, expansion, 32)
+	for i := uint(0); i < 12; i++ {
+		if saltBits>>i&1 == 0 {
+			continue
+		}
+		hi, lo := expanded>>(47-i)&1, expanded>>(23-i)&1
+		if hi != lo {
+			expanded ^= 1<<(47-i) | 1<<(23-i)
+		}
+	}
+	expanded ^= subkey
+
+	var substituted uint64
+	for box := 0; box < 8; box++ {
+		six := expanded >> uint(42-6*box) & 0x3f
+		row := six>>4&2 | six&1
+		column := six >> 1 & 0xf
+		substituted = substituted<<4 | uint64(sBoxes[box][row*16+column])
+	}
+	return permute(substituted, roundPermutation, 32)
+}
+
+// permute returns the bits of in, a value of width bits, at the 1-based positions of table
+func permute(in uint64, table []byte, width uint) uint64 {
+	var out uint64
+	for _, position := range table {
+		out = out<<1 | in>>(width-uint(position))&1
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package descrypt
+
+import (
+	"crypto/des"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestCrypt(t *testing.T) {
+	// Computed with glibc's crypt(3)
+	tests := []struct {
+		name     string
+		password string
+		salt     string
+		want     string
+	}{
+		{"password", "password", "ab", "abJnggxhB/yWI"},
+		{"short", "test", "Xy", "Xy84zCXgG74kA"},
+		{"truncated to 8 characters", "longerthan8", "..", "..QW4imy5ekiU"},
+		{"empty", "", "zz", "zz6dpSdr.LHZw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Crypt([]byte(tt.password), tt.salt)
+			if err != nil {
+				t.Fatalf("Crypt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Crypt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, salt := range []string{"", "a", "abc", "a$"} {
+		if _, err := Crypt([]byte("password"), salt); !errors.Is(err, ErrInvalidSalt) {
+			t.Errorf("Crypt() with salt %q error = %v, want ErrInvalidSalt", salt, err)
+		}
+	}
+}
+
+func TestEncrypt_DES(t *testing.T) {
+	// Without salt, a round of crypt(3) is the DES block cipher
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var key, block [8]byte
+		rng.Read(key[:])
+		rng.Read(block[:])
+		cipher, err := des.NewCipher(key[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want [8]byte
+		cipher.Encrypt(want[:], block[:])
+
+		subkeys := keySchedule(binary.BigEndian.Uint64(key[:]))
+		if got := encrypt(binary.BigEndian.Uint64(block[:]), &subkeys, 0); got != binary.BigEndian.Uint64(want[:]) {
+			t.Fatalf("encrypt(%x, %x) = %x, want %x", block, key, got, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package md5crypt implements the MD5-based crypt(3) of Poul-Henning Kamp, $1$ in /etc/shadow and, with the
+// $apr1$ magic, the default of Apache's htpasswd.
+//
+// See https://httpd.apache.org/docs/2.4/misc/password_encryptions.html
+package md5crypt
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidHash is returned by Verify for hashes that aren't magic + SALT$HASH
+var ErrInvalidHash = errors.New("md5crypt: invalid hash")
+
+// MaxSaltLen is the maximum length of the salt in characters, longer salts are truncated
+const MaxSaltLen = 8
+
+// itoa64 is the alphabet of the encoded hash
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Crypt returns magic + salt + "$" + the 22-character hash of the password, e.g. $apr1$SALT$HASH for the
+// magic "$apr1$". The salt is truncated to MaxSaltLen characters and at the first "$".
+func Crypt(password []byte, salt, magic string) string {
+	if i := strings.IndexByte(salt, '$'); i >= 0 {
+		salt = salt[:i]
+	}
+	if len(salt) > MaxSaltLen {
+		salt = salt[:MaxSaltLen]
+	}
+
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write([]byte(salt))
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(password)
+	ctx.Write([]byte(magic))
+	ctx.Write([]byte(salt))
+	for n := len(password); n > 0; n -= md5.Size {
+		ctx.Write(altSum[:min(n, md5.Size)])
+	}
+	for i := len(password); i != 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(password[:1])
+		}
+	}
+	final := ctx.Sum(nil)
+
+	// 1000 rounds to slow down brute force attacks
+	for i := 0; i < 1000; i++ {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(password)
+		} else {
+			round.Write(final)
+		}
+		if i%3 != 0 {
+			round.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			round.Write(password)
+		}
+		if i&1 != 0 {
+			round.Write(final)
+		} else {
+			round.Write(password)
+		}
+		final = round.Sum(final[:0])
+	}
+
+	out := make([]byte, 0, len(magic)+len(salt)+1+22)
+	out = append(out, magic...)
+	out = append(out, salt...)
+	out = append(out, '$')
+	for _, group := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		out = to64(out, uint32(final[group[0]])<<16|uint32(final[group[1]])<<8|uint32(final[group[2]]), 4)
+	}
+	return string(to64(out, uint32(final[11]), 2))
+}
+
+// Verify reports whether the password matches the hash, magic + SALT$HASH
+func Verify(password []byte, hash, magic string) (bool, error) {
+	rest, ok := strings.CutPrefix(hash, magic)
+	salt, _, found := strings.Cut(rest, "$")
+	if !ok || !found || salt == "" || len(salt) > MaxSaltLen {
+		return false, ErrInvalidHash
+	}
+	return subtle.ConstantTimeCompare([]byte(Crypt(password, salt, magic)), []byte(hash)) == 1, nil
+}
+
+// to64 appends the n low 6-bit groups of v, least significant first
+func to64(out []byte, v uint32, n int) []byte {
+	for ; n > 0; n-- {
+		out = append(out, itoa64[v&0x3f])
+		v >>= 6
+	}
+	return out
+}
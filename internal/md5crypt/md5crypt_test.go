@@ -0,0 +1,62 @@
+package md5crypt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCrypt(t *testing.T) {
+	// Computed with openssl passwd -1 and -apr1
+	tests := []struct {
+		name     string
+		password string
+		salt     string
+		magic    string
+		want     string
+	}{
+		{"md5crypt", "password", "saltsalt", "$1$", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		{"apr1", "password", "saltsalt", "$apr1$", "$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/"},
+		{"apr1 dots", "myPassword", "r31.....", "$apr1$", "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/"},
+		{"long password", "a very long password over sixteen bytes", "ab", "$1$", "$1$ab$/KmR13UZ7vwn4UURI6qFb/"},
+		{"empty password", "", "xyz", "$apr1$", "$apr1$xyz$Pix4eE3fQHxJjb6LqtyMK1"},
+		{"long salt", "password", "saltsaltsalt", "$1$", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		{"salt with hash", "password", "saltsalt$qjXMvbEw8oaL.CzflDtaK/", "$1$", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Crypt([]byte(tt.password), tt.salt, tt.magic); got != tt.want {
+				t.Errorf("Crypt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		magic    string
+		want     bool
+		wantErr  error
+	}{
+		{"md5crypt", "password", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "$1$", true, nil},
+		{"apr1", "password", "$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/", "$apr1$", true, nil},
+		{"wrong password", "Password", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "$1$", false, nil},
+		{"wrong magic", "password", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "$apr1$", false, ErrInvalidHash},
+		{"missing salt", "password", "$1$$qjXMvbEw8oaL.CzflDtaK/", "$1$", false, ErrInvalidHash},
+		{"missing hash", "password", "$1$saltsalt", "$1$", false, ErrInvalidHash},
+		{"long salt", "password", "$1$saltsaltsalt$qjXMvbEw8oaL.CzflDtaK/", "$1$", false, ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := Verify([]byte(tt.password), tt.hash, tt.magic)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
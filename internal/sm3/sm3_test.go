@@ -0,0 +1,49 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	// The first two are the examples of GB/T 32905-2016, the others were computed with OpenSSL
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"abc", []byte("abc"), "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{"512 bits", []byte(strings.Repeat("abcd", 16)), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+		{"empty", nil, "1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b"},
+		{"several blocks", bytes.Repeat(byteRange(), 3), "9fc8d5a910965de08dbd81fa00771f102d400b071d873d089cbcc35e6f3f9db1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sum := Sum(tt.in); hex.EncodeToString(sum[:]) != tt.want {
+				t.Errorf("Sum() = %x, want %v", sum, tt.want)
+			}
+
+			// Writes of every size give the same checksum, and Sum doesn't end the computation
+			for _, chunk := range []int{1, 7, 63, 64, 65} {
+				h := New()
+				for in := tt.in; len(in) > 0; in = in[min(chunk, len(in)):] {
+					h.Write(in[:min(chunk, len(in))])
+					h.Sum(nil)
+				}
+				if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+					t.Errorf("chunks of %d: Sum() = %v, want %v", chunk, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func byteRange() []byte {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+// Package unixcrypt verifies the crypt(3) hashes shared by shadow and htpasswd files, dispatching on their
+// prefix and reporting successful verifications against weak formats with passforge.ReportWeakAlgorithm.
+package unixcrypt
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"github.com/nduyhai/passforge"
+	"github.com/nduyhai/passforge/internal/descrypt"
+	"github.com/nduyhai/passforge/internal/md5crypt"
+)
+
+// Format is a set of crypt(3) hash formats
+type Format int
+
+const (
+	Yescrypt    Format = 1 << iota // $y$
+	Sha512Crypt                    // $6$
+	Sha256Crypt                    // $5$
+	Bcrypt                         // $2b$, $2a$ and $2y$
+	MD5Crypt                       // $1$
+	Apr1                           // $apr1$, Apache's variant of MD5 crypt
+	SHA1                           // {SHA}, unsalted base64 SHA-1 of htpasswd -s
+	DESCrypt                       // Traditional 13 character DES crypt
+)
+
+// Verifier verifies the formats it accepts; Encoder names it in errors and weak algorithm events
+type Verifier struct {
+	Encoder string
+	Formats Format
+}
+
+// Verify checks if the raw password matches the crypt(3) hash
+func (v Verifier) Verify(rawPassword, encodedPassword string) (bool, error) {
+	switch {
+	case v.accepts(Yescrypt) && strings.HasPrefix(encodedPassword, "$y$"):
+		return passforge.NewYescryptPasswordEncoder().Verify(rawPassword, encodedPassword)
+	case v.accepts(Sha512Crypt) && strings.HasPrefix(encodedPassword, "$6$"):
+		return passforge.NewSha512CryptPasswordEncoder().Verify(rawPassword, encodedPassword)
+	case v.accepts(Sha256Crypt) && strings.HasPrefix(encodedPassword, "$5$"):
+		return passforge.NewSha256CryptPasswordEncoder().Verify(rawPassword, encodedPassword)
+	case v.accepts(Bcrypt) && IsBcrypt(encodedPassword):
+		return passforge.NewBcryptPasswordEncoder().Verify(rawPassword, encodedPassword)
+	case v.accepts(MD5Crypt) && strings.HasPrefix(encodedPassword, "$1$"):
+		return v.md5Crypt(rawPassword, encodedPassword, "$1$", "md5 crypt")
+	case v.accepts(Apr1) && strings.HasPrefix(encodedPassword, "$apr1$"):
+		return v.md5Crypt(rawPassword, encodedPassword, "$apr1$", "apr1")
+	case v.accepts(SHA1) && strings.HasPrefix(encodedPassword, "{SHA}"):
+		sum := sha1.Sum([]byte(rawPassword))
+		computed := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+		return v.weak(subtle.ConstantTimeCompare([]byte(computed), []byte(encodedPassword)) == 1, "unsalted sha1 hash"), nil
+	case v.accepts(DESCrypt) && len(encodedPassword) == 13:
+		computed, err := descrypt.Crypt([]byte(rawPassword), encodedPassword[:2])
+		if err != nil {
+			return false, v.formatError("invalid crypt salt")
+		}
+		return v.weak(subtle.ConstantTimeCompare([]byte(computed), []byte(encodedPassword)) == 1, "des crypt hash"), nil
+	}
+	return false, v.formatError("unsupported hash format")
+}
+
+// IsBcrypt reports whether the hash is a bcrypt hash
+func IsBcrypt(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$2b$") || strings.HasPrefix(encodedPassword, "$2a$") ||
+		strings.HasPrefix(encodedPassword, "$2y$")
+}
+
+// accepts reports whether the verifier accepts the format
+func (v Verifier) accepts(format Format) bool {
+	return v.Formats&format != 0
+}
+
+// md5Crypt verifies an MD5 crypt hash with the magic, named name in errors and weak algorithm events
+func (v Verifier) md5Crypt(rawPassword, encodedPassword, magic, name string) (bool, error) {
+	match, err := md5crypt.Verify([]byte(rawPassword), encodedPassword, magic)
+	if err != nil {
+		return false, v.formatError("invalid " + name + " hash")
+	}
+	return v.weak(match, name+" hash"), nil
+}
+
+// weak reports a successful verification to the weak algorithm hook and returns match
+func (v Verifier) weak(match bool, reason string) bool {
+	if match {
+		passforge.ReportWeakAlgorithm(v.Encoder, reason)
+	}
+	return match
+}
+
+// formatError returns a FormatError matching passforge.ErrInvalidFormat
+func (v Verifier) formatError(reason string) error {
+	return &passforge.FormatError{Encoder: v.Encoder, Reason: reason}
+}
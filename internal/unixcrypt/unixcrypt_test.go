@@ -0,0 +1,63 @@
+package unixcrypt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	// Hashes of "password", computed with openssl passwd and crypt(3)
+	tests := []struct {
+		name     string
+		format   Format
+		encoded  string
+		wantWeak passforge.WeakAlgorithmEvent
+	}{
+		{"yescrypt", Yescrypt, "$y$j9T$F5Jx5fExrKuPp53xLKQ..1$tnSYvahCwPBHKZUspmcxMfb0.WiB9W.zEaKlOBL35rC", passforge.WeakAlgorithmEvent{}},
+		{"sha512-crypt", Sha512Crypt, "$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0", passforge.WeakAlgorithmEvent{}},
+		{"sha256-crypt", Sha256Crypt, "$5$saltsaltsaltsalt$WsFBeg1qQ90JL3VkUTuM7xVV/5njhLngIVm6ftSnBR2", passforge.WeakAlgorithmEvent{}},
+		{"bcrypt", Bcrypt, "$2y$05$abcdefghijklmnopqrstuuWG29KuyeAicPCJODk1zjyGvyQUU2awu", weak("bcrypt", "cost below minimum")},
+		{"md5-crypt", MD5Crypt, "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", weak("test", "md5 crypt hash")},
+		{"apr1", Apr1, "$apr1$saltsalt$yAAkm4libquA.ZWLHbSBq/", weak("test", "apr1 hash")},
+		{"sha1", SHA1, "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", weak("test", "unsalted sha1 hash")},
+		{"des-crypt", DESCrypt, "abJnggxhB/yWI", weak("test", "des crypt hash")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var flagged []passforge.WeakAlgorithmEvent
+			passforge.SetWeakAlgorithmHook(func(event passforge.WeakAlgorithmEvent) {
+				flagged = append(flagged, event)
+			})
+			defer passforge.SetWeakAlgorithmHook(nil)
+
+			verifier := Verifier{Encoder: "test", Formats: tt.format}
+			if match, err := verifier.Verify("wrong", tt.encoded); err != nil || match {
+				t.Errorf("Verify() with wrong password = %v, %v, want false", match, err)
+			}
+			if len(flagged) != 0 {
+				t.Errorf("failed Verify() flagged %v, want no event", flagged)
+			}
+			if match, err := verifier.Verify("password", tt.encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+			if tt.wantWeak == (passforge.WeakAlgorithmEvent{}) && len(flagged) != 0 {
+				t.Errorf("Verify() flagged %v, want no event", flagged)
+			}
+			if tt.wantWeak != (passforge.WeakAlgorithmEvent{}) && (len(flagged) != 1 || flagged[0] != tt.wantWeak) {
+				t.Errorf("Verify() flagged %v, want %v", flagged, tt.wantWeak)
+			}
+
+			others := Verifier{Encoder: "test", Formats: ^tt.format}
+			if _, err := others.Verify("password", tt.encoded); !errors.Is(err, passforge.ErrInvalidFormat) {
+				t.Errorf("Verify() without the format error = %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
+
+// weak returns the event reported for a successful verification by the encoder
+func weak(algorithm, reason string) passforge.WeakAlgorithmEvent {
+	return passforge.WeakAlgorithmEvent{Algorithm: algorithm, Reason: reason}
+}
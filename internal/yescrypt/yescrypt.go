@@ -0,0 +1,463 @@
+// Copyright 2009 Colin Percival
+// Copyright 2012-2018 Alexander Peslyak
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package yescrypt is a Go translation of the yescrypt reference implementation (yescrypt-ref.c),
+// the password hashing scheme behind the $y$ hashes of libxcrypt and modern /etc/shadow files.
+// It implements the classic scrypt, WORM and RW flavors with the pwxform settings used by $y$
+// hashes; ROM, hash upgrades and the encryption of salts and hashes with a key are not supported.
+//
+// See https://www.openwall.com/yescrypt/
+package yescrypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Flavors of yescrypt, as encoded in the first character of a $y$ setting
+const (
+	// Classic is scrypt
+	Classic uint32 = 0
+	// WORM is scrypt with the t parameter and the yescrypt pre- and post-processing
+	WORM uint32 = 1
+	// RW is the default flavor: pwxform with 6 rounds, 4-way gather, 2-way simple and 12 KiB S-boxes
+	RW uint32 = 0xb6
+)
+
+// prehash marks the internal computation that replaces the password for large RW parameters
+const prehash uint32 = 0x10000000
+
+// flagRW is the mode bit of the RW flavor
+const flagRW uint32 = 0x002
+
+// pwxform settings of the RW flavor
+const (
+	pwxSimple = 2
+	pwxGather = 4
+	pwxRounds = 6
+	sWidth    = 8
+
+	pwxBytes = pwxGather * pwxSimple * 8
+	pwxWords = pwxBytes / 4
+	sBytes   = 3 * (1 << sWidth) * pwxSimple * 8
+	sWords   = sBytes / 4
+	sMask    = ((1 << sWidth) - 1) * pwxSimple * 8
+	sEntries = (1 << sWidth) * pwxSimple // 64-bit entries of one S-box
+)
+
+// ErrInvalidParams is returned for unsupported or out-of-range parameters
+var ErrInvalidParams = errors.New("yescrypt: invalid parameters")
+
+// Params are the yescrypt cost parameters
+type Params struct {
+	Flavor uint32 // Classic, WORM or RW
+	N      uint64 // Block count, a power of two greater than 1
+	R      uint32 // Block size in 128-byte units
+	P      uint32 // Parallelism
+	T      uint32 // Additional time factor
+}
+
+// Validate checks the parameters
+func (p Params) Validate() error {
+	switch {
+	case p.Flavor != Classic && p.Flavor != WORM && p.Flavor != RW:
+		return ErrInvalidParams
+	case p.Flavor == Classic && p.T != 0:
+		return ErrInvalidParams
+	case p.N < 2 || p.N&(p.N-1) != 0:
+		return ErrInvalidParams
+	case p.R < 1 || p.P < 1 || uint64(p.R)*uint64(p.P) >= 1<<30:
+		return ErrInvalidParams
+	case p.N > math.MaxInt/128/uint64(p.R) || p.N > math.MaxUint64/(uint64(p.T)+2):
+		return ErrInvalidParams
+	case p.Flavor == RW && p.N/uint64(p.P) <= 1:
+		return ErrInvalidParams
+	}
+	return nil
+}
+
+// Memory returns the memory in bytes a hash with the parameters needs (128*N*r, plus the S-boxes)
+func (p Params) Memory() int64 {
+	memory := 128 * int64(p.N) * int64(p.R)
+	if p.Flavor == RW {
+		memory += sBytes * int64(p.P)
+	}
+	return memory
+}
+
+// Key derives a key of keyLen bytes from the password and salt
+func Key(password, salt []byte, params Params, keyLen int) ([]byte, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if keyLen < 1 {
+		return nil, ErrInvalidParams
+	}
+
+	// Large RW parameters first hash the password with 1/64 of the memory, so that the main
+	// computation can't be started before the cheaper one has finished
+	if params.Flavor == RW && params.N/uint64(params.P) >= 0x100 && params.N/uint64(params.P)*uint64(params.R) >= 0x20000 {
+		pre := params
+		pre.N >>= 6
+		pre.T = 0
+		password = kdf(password, salt, params.Flavor|prehash, pre, 32)
+	}
+	return kdf(password, salt, params.Flavor, params, keyLen), nil
+}
+
+// kdf is yescrypt_kdf_body
+func kdf(password, salt []byte, flags uint32, params Params, keyLen int) []byte {
+	r := int(params.R)
+	p := int(params.P)
+	s := 32 * r
+
+	if flags != 0 {
+		key := []byte("yescrypt-prehash")
+		if flags&prehash == 0 {
+			key = key[:8]
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write(password)
+		password = mac.Sum(nil)
+	}
+
+	bBytes := pbkdf2.Key(password, salt, 1, 128*r*p, sha256.New)
+	B := make([]uint32, s*p)
+	for i := range B {
+		B[i] = binary.LittleEndian.Uint32(bBytes[4*i:])
+	}
+	if flags != 0 {
+		password = append([]byte(nil), bBytes[:32]...)
+	}
+
+	V := make([]uint32, uint64(s)*params.N)
+	XY := make([]uint32, 2*s)
+	if flags&flagRW != 0 {
+		password = smix(B, r, params.N, p, params.T, flags, V, XY, password)
+	} else {
+		for i := 0; i < p; i++ {
+			smix(B[i*s:(i+1)*s], r, params.N, 1, params.T, flags, V, XY, nil)
+		}
+	}
+
+	for i, word := range B {
+		binary.LittleEndian.PutUint32(bBytes[4*i:], word)
+	}
+	dk := pbkdf2.Key(password, bBytes, 1, keyLen, sha256.New)
+
+	// Except for classic scrypt, the last steps match SCRAM's ClientKey and StoredKey
+	if flags != 0 && flags&prehash == 0 {
+		dkp := dk
+		if keyLen < 32 {
+			dkp = pbkdf2.Key(password, bBytes, 1, 32, sha256.New)
+		}
+		mac := hmac.New(sha256.New, dkp[:32])
+		mac.Write([]byte("Client Key"))
+		storedKey := sha256.Sum256(mac.Sum(nil))
+		copy(dk, storedKey[:])
+	}
+	return dk
+}
+
+// pwxformCtx holds the S-boxes of one thread. Each S-box has sEntries 64-bit entries stored as pairs of words.
+type pwxformCtx struct {
+	s0, s1, s2 []uint32
+	w          int
+}
+
+// smix runs SMix1 and SMix2 over the p blocks of B and returns the password, which the RW flavor
+// replaces with an HMAC keyed by the first block
+func smix(B []uint32, r int, N uint64, p int, t uint32, flags uint32, V, XY []uint32, password []byte) []byte {
+	s := 32 * r
+
+	nChunk := N / uint64(p)
+	nLoopAll := nChunk
+	if flags&flagRW != 0 {
+		if t <= 1 {
+			if t != 0 {
+				nLoopAll *= 2 // 2/3
+			}
+			nLoopAll = (nLoopAll + 2) / 3 // 1/3, round up
+		} else {
+			nLoopAll *= uint64(t) - 1
+		}
+	} else if t != 0 {
+		if t == 1 {
+			nLoopAll += (nLoopAll + 1) / 2 // 1.5, round up
+		}
+		nLoopAll *= uint64(t)
+	}
+
+	nLoopRW := uint64(0)
+	if flags&flagRW != 0 {
+		nLoopRW = nLoopAll / uint64(p)
+	}
+
+	nChunk &^= 1                   // round down to even
+	nLoopAll = (nLoopAll + 1) &^ 1 // round up to even
+	nLoopRW = (nLoopRW + 1) &^ 1   // round up to even
+
+	var ctxs []pwxformCtx
+	if flags&flagRW != 0 {
+		ctxs = make([]pwxformCtx, p)
+	}
+	vChunk := uint64(0)
+	for i := 0; i < p; i++ {
+		np := nChunk
+		if i == p-1 {
+			np = N - vChunk
+		}
+		bp := B[i*s : (i+1)*s]
+		vp := V[vChunk*uint64(s):]
+		var ctx *pwxformCtx
+		if flags&flagRW != 0 {
+			ctx = &ctxs[i]
+			S := make([]uint32, sWords)
+			smix1(bp, 1, sBytes/128, 0, S, XY, nil)
+			ctx.s2 = S[:2*sEntries]
+			ctx.s1 = S[2*sEntries : 4*sEntries]
+			ctx.s0 = S[4*sEntries:]
+			ctx.w = 0
+			if i == 0 {
+				key := make([]byte, 64)
+				for k, word := range bp[s-16:] {
+					binary.LittleEndian.PutUint32(key[4*k:], word)
+				}
+				mac := hmac.New(sha256.New, key)
+				mac.Write(password)
+				password = mac.Sum(nil)
+			}
+		}
+		smix1(bp, r, np, flags, vp, XY, ctx)
+		smix2(bp, r, p2floor(np), nLoopRW, flags, vp, XY, ctx)
+		vChunk += nChunk
+	}
+
+	for i := 0; i < p; i++ {
+		var ctx *pwxformCtx
+		if flags&flagRW != 0 {
+			ctx = &ctxs[i]
+		}
+		smix2(B[i*s:(i+1)*s], r, N, nLoopAll-nLoopRW, flags&^flagRW, V, XY, ctx)
+	}
+	return password
+}
+
+// smix1 fills V with N blocks derived from B. Blocks are kept SIMD-shuffled in X and V,
+// which matters for pwxform.
+func smix1(B []uint32, r int, N uint64, flags uint32, V, XY []uint32, ctx *pwxformCtx) {
+	s := 32 * r
+	X, Y := XY[:s], XY[s:2*s]
+	shuffle(X, B, r)
+
+	for i := uint64(0); i < N; i++ {
+		copy(V[i*uint64(s):], X)
+		if flags&flagRW != 0 && i > 1 {
+			j := wrap(integerify(X, r), i)
+			blkxor(X, V[j*uint64(s):])
+		}
+		if ctx != nil {
+			blockmixPwxform(X, r, ctx)
+		} else {
+			blockmixSalsa8(X, Y, r)
+		}
+	}
+	unshuffle(B, X, r)
+}
+
+// smix2 mixes B with Nloop pseudo-random blocks of V, writing them back in the RW flavor
+func smix2(B []uint32, r int, N, nLoop uint64, flags uint32, V, XY []uint32, ctx *pwxformCtx) {
+	if nLoop == 0 {
+		return
+	}
+	s := 32 * r
+	X, Y := XY[:s], XY[s:2*s]
+	shuffle(X, B, r)
+
+	for i := uint64(0); i < nLoop; i++ {
+		j := integerify(X, r) & (N - 1)
+		vj := V[j*uint64(s) : (j+1)*uint64(s)]
+		blkxor(X, vj)
+		if flags&flagRW != 0 {
+			copy(vj, X)
+		}
+		if ctx != nil {
+			blockmixPwxform(X, r, ctx)
+		} else {
+			blockmixSalsa8(X, Y, r)
+		}
+	}
+	unshuffle(B, X, r)
+}
+
+// shuffle copies B to X in the SIMD-shuffled order of the reference implementation
+func shuffle(X, B []uint32, r int) {
+	for k := 0; k < 2*r; k++ {
+		for i := 0; i < 16; i++ {
+			X[k*16+i] = B[k*16+i*5%16]
+		}
+	}
+}
+
+// unshuffle reverses shuffle
+func unshuffle(B, X []uint32, r int) {
+	for k := 0; k < 2*r; k++ {
+		for i := 0; i < 16; i++ {
+			B[k*16+i*5%16] = X[k*16+i]
+		}
+	}
+}
+
+// blockmixSalsa8 is scrypt's BlockMix with Salsa20/8
+func blockmixSalsa8(B, Y []uint32, r int) {
+	var X [16]uint32
+	copy(X[:], B[(2*r-1)*16:])
+	for i := 0; i < 2*r; i++ {
+		blkxor(X[:], B[i*16:])
+		salsa20(X[:], 8)
+		copy(Y[i*16:], X[:])
+	}
+	for i := 0; i < r; i++ {
+		copy(B[i*16:], Y[(i*2)*16:(i*2+1)*16])
+	}
+	for i := 0; i < r; i++ {
+		copy(B[(i+r)*16:], Y[(i*2+1)*16:(i*2+2)*16])
+	}
+}
+
+// blockmixPwxform is yescrypt's BlockMix with pwxform and a final Salsa20/2
+func blockmixPwxform(B []uint32, r int, ctx *pwxformCtx) {
+	r1 := 128 * r / pwxBytes
+	var X [pwxWords]uint32
+	copy(X[:], B[(r1-1)*pwxWords:])
+	for i := 0; i < r1; i++ {
+		if r1 > 1 {
+			blkxor(X[:], B[i*pwxWords:])
+		}
+		pwxform(X[:], ctx)
+		copy(B[i*pwxWords:], X[:])
+	}
+
+	i := (r1 - 1) * pwxBytes / 64
+	salsa20(B[i*16:i*16+16], 2)
+	for i++; i < 2*r; i++ {
+		blkxor(B[i*16:i*16+16], B[(i-1)*16:])
+		salsa20(B[i*16:i*16+16], 2)
+	}
+}
+
+// pwxform transforms a 64-byte block with multiplications and S-box lookups, writing to S2 as it goes
+func pwxform(X []uint32, ctx *pwxformCtx) {
+	s0, s1, s2, w := ctx.s0, ctx.s1, ctx.s2, ctx.w
+	for i := 0; i < pwxRounds; i++ {
+		for j := 0; j < pwxGather; j++ {
+			p0 := (X[j*4] & sMask) / 4
+			p1 := (X[j*4+1] & sMask) / 4
+			for k := 0; k < pwxSimple; k++ {
+				lo, hi := j*4+k*2, j*4+k*2+1
+				v0 := uint64(s0[p0+uint32(2*k)+1])<<32 | uint64(s0[p0+uint32(2*k)])
+				v1 := uint64(s1[p1+uint32(2*k)+1])<<32 | uint64(s1[p1+uint32(2*k)])
+
+				x := uint64(X[hi]) * uint64(X[lo])
+				x += v0
+				x ^= v1
+				X[lo], X[hi] = uint32(x), uint32(x>>32)
+
+				if i != 0 && i != pwxRounds-1 {
+					s2[2*w], s2[2*w+1] = uint32(x), uint32(x>>32)
+					w++
+				}
+			}
+		}
+	}
+	ctx.s0, ctx.s1, ctx.s2 = s2, s0, s1
+	ctx.w = w & (sEntries - 1)
+}
+
+// salsa20 applies the Salsa20 core with the given number of rounds to a SIMD-shuffled block
+func salsa20(B []uint32, rounds int) {
+	var x [16]uint32
+	for i := 0; i < 16; i++ {
+		x[i*5%16] = B[i]
+	}
+
+	for i := 0; i < rounds; i += 2 {
+		// Operate on columns
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		// Operate on rows
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+
+	for i := 0; i < 16; i++ {
+		B[i] += x[i*5%16]
+	}
+}
+
+// blkxor xors src into dst
+func blkxor(dst, src []uint32) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// integerify returns the first 64 bits of the last 64-byte block of X
+func integerify(X []uint32, r int) uint64 {
+	last := X[(2*r-1)*16:]
+	return uint64(last[13])<<32 | uint64(last[0])
+}
+
+// p2floor returns the largest power of two not greater than x
+func p2floor(x uint64) uint64 {
+	return 1 << (63 - bits.LeadingZeros64(x))
+}
+
+// wrap maps x into the range [i - p2floor(i), i)
+func wrap(x, i uint64) uint64 {
+	n := p2floor(i)
+	return (x & (n - 1)) + (i - n)
+}
@@ -0,0 +1,61 @@
+package yescrypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestKeyClassicIsScrypt(t *testing.T) {
+	password, salt := []byte("password"), []byte("NaCl")
+	want, err := scrypt.Key(password, salt, 1024, 8, 16, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Key(password, salt, Params{Flavor: Classic, N: 1024, R: 8, P: 16}, 64)
+	if err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Key() = %x, want %x", got, want)
+	}
+}
+
+func TestKeyFlavorsDiffer(t *testing.T) {
+	password, salt := []byte("password"), []byte("salt")
+	seen := make(map[string]uint32)
+	for _, flavor := range []uint32{Classic, WORM, RW} {
+		key, err := Key(password, salt, Params{Flavor: flavor, N: 256, R: 8, P: 1}, 32)
+		if err != nil {
+			t.Fatalf("Key() flavor %#x error = %v", flavor, err)
+		}
+		if other, ok := seen[string(key)]; ok {
+			t.Errorf("Key() of flavors %#x and %#x are equal", flavor, other)
+		}
+		seen[string(key)] = flavor
+	}
+}
+
+func TestParamsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"rw", Params{Flavor: RW, N: 4096, R: 32, P: 1}, false},
+		{"classic with t", Params{Flavor: Classic, N: 1024, R: 8, P: 1, T: 1}, true},
+		{"unknown flavor", Params{Flavor: 0xb4, N: 1024, R: 8, P: 1}, true},
+		{"N not a power of two", Params{Flavor: RW, N: 1000, R: 8, P: 1}, true},
+		{"zero r", Params{Flavor: RW, N: 1024, R: 0, P: 1}, true},
+		{"N per thread too small", Params{Flavor: RW, N: 4, R: 8, P: 4}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidParams)) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package passforge
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedKeycloakCredential is returned for Keycloak credentials that aren't PBKDF2 passwords
+var ErrUnsupportedKeycloakCredential = errors.New("unsupported keycloak credential")
+
+// keycloakHashFuncs maps Keycloak's password hashing algorithms to PBKDF2PasswordEncoder hashFunc names
+var keycloakHashFuncs = map[string]string{
+	"pbkdf2":        "sha1",
+	"pbkdf2-sha256": "sha256",
+	"pbkdf2-sha512": "sha512",
+}
+
+// KeycloakCredential is a credential of a user in a Keycloak realm export, an entry of users[].credentials.
+// Keycloak 11 and later nest the hash in the secretData and credentialData JSON strings; older versions
+// export hashedSaltedValue, salt, hashIterations and algorithm directly.
+type KeycloakCredential struct {
+	Type           string `json:"type"`
+	SecretData     string `json:"secretData,omitempty"`
+	CredentialData string `json:"credentialData,omitempty"`
+
+	HashedSaltedValue string `json:"hashedSaltedValue,omitempty"`
+	Salt              string `json:"salt,omitempty"`
+	HashIterations    int    `json:"hashIterations,omitempty"`
+	Algorithm         string `json:"algorithm,omitempty"`
+}
+
+// keycloakSecretData is the content of KeycloakCredential.SecretData
+type keycloakSecretData struct {
+	Value string `json:"value"`
+	Salt  string `json:"salt"`
+}
+
+// keycloakCredentialData is the content of KeycloakCredential.CredentialData
+type keycloakCredentialData struct {
+	HashIterations int    `json:"hashIterations"`
+	Algorithm      string `json:"algorithm"`
+}
+
+// Encoded converts the credential to the format of PBKDF2PasswordEncoder, e.g.
+// iterations=27500,keyLen=64,hashFunc=sha256$BASE64_SALT$BASE64_HASH. Keycloak's pbkdf2 (PBKDF2-HMAC-SHA1),
+// pbkdf2-sha256 and pbkdf2-sha512 algorithms are supported; others return ErrUnsupportedKeycloakCredential.
+func (c KeycloakCredential) Encoded() (string, error) {
+	if c.Type != "password" {
+		return "", fmt.Errorf("%w: type %q", ErrUnsupportedKeycloakCredential, c.Type)
+	}
+	value, salt, iterations, algorithm := c.HashedSaltedValue, c.Salt, c.HashIterations, c.Algorithm
+	if c.SecretData != "" || c.CredentialData != "" {
+		var secret keycloakSecretData
+		var credential keycloakCredentialData
+		if err := json.Unmarshal([]byte(c.SecretData), &secret); err != nil {
+			return "", newFormatError("keycloak", "invalid secretData", c.SecretData)
+		}
+		if err := json.Unmarshal([]byte(c.CredentialData), &credential); err != nil {
+			return "", newFormatError("keycloak", "invalid credentialData", c.CredentialData)
+		}
+		value, salt, iterations, algorithm = secret.Value, secret.Salt, credential.HashIterations, credential.Algorithm
+	}
+
+	hashFunc, ok := keycloakHashFuncs[algorithm]
+	if !ok {
+		return "", fmt.Errorf("%w: algorithm %q", ErrUnsupportedKeycloakCredential, algorithm)
+	}
+	if iterations < 1 {
+		return "", newFormatError("keycloak", "invalid hashIterations", value)
+	}
+	decodedSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(decodedSalt) == 0 {
+		return "", newFormatError("keycloak", "invalid salt encoding", value)
+	}
+	hash, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(hash) == 0 {
+		return "", newFormatError("keycloak", "invalid hash encoding", value)
+	}
+	return fmt.Sprintf("iterations=%d,keyLen=%d,hashFunc=%s$%s$%s", iterations, len(hash), hashFunc,
+		base64.StdEncoding.EncodeToString(decodedSalt), base64.StdEncoding.EncodeToString(hash)), nil
+}
+
+// keycloakRealm is the part of a realm export, or of one of its realm-users-N.json files, read by ImportKeycloakRealm
+type keycloakRealm struct {
+	Users []struct {
+		Username    string               `json:"username"`
+		Credentials []KeycloakCredential `json:"credentials"`
+	} `json:"users"`
+}
+
+// ImportKeycloakRealm reads a Keycloak realm export and stores the password of every user as
+// "{pbkdf2}" followed by KeycloakCredential.Encoded, keyed by username, for a DelegatingPasswordEncoder
+// with a PBKDF2PasswordEncoder. Users without a password credential are skipped; the first credential
+// that can't be converted stops the import. It returns the number of imported users.
+func ImportKeycloakRealm(ctx context.Context, r io.Reader, store UserCredentialStore) (int, error) {
+	var realm keycloakRealm
+	if err := json.NewDecoder(r).Decode(&realm); err != nil {
+		return 0, fmt.Errorf("keycloak: %w", err)
+	}
+
+	imported := 0
+	for _, user := range realm.Users {
+		for _, credential := range user.Credentials {
+			if credential.Type != "password" {
+				continue
+			}
+			encoded, err := credential.Encoded()
+			if err != nil {
+				return imported, fmt.Errorf("keycloak: user %q: %w", user.Username, err)
+			}
+			if err := store.UpdateHash(ctx, user.Username, "{pbkdf2}"+encoded); err != nil {
+				return imported, err
+			}
+			imported++
+			break
+		}
+	}
+	return imported, nil
+}
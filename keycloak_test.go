@@ -0,0 +1,140 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeycloakCredential_Encoded(t *testing.T) {
+	// Hashes computed with Python's hashlib.pbkdf2_hmac, with Keycloak's 512-bit derived keys
+	tests := []struct {
+		name       string
+		credential KeycloakCredential
+		password   string
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "keycloak 11 pbkdf2-sha256",
+			credential: KeycloakCredential{
+				Type:           "password",
+				SecretData:     `{"value":"FLAKrylANDgDgtPBQiUvp4/v52EpM6tnb3b2ZEP26EQsBLnG6BJjrGNWdwchFI0CfpILE+L59Cc+XsuLt5amVQ==","salt":"c2FsdHNhbHRzYWx0c2FsdA==","additionalParameters":{}}`,
+				CredentialData: `{"hashIterations":27500,"algorithm":"pbkdf2-sha256","additionalParameters":{}}`,
+			},
+			password: "password",
+			want:     "iterations=27500,keyLen=64,hashFunc=sha256$c2FsdHNhbHRzYWx0c2FsdA==$FLAKrylANDgDgtPBQiUvp4/v52EpM6tnb3b2ZEP26EQsBLnG6BJjrGNWdwchFI0CfpILE+L59Cc+XsuLt5amVQ==",
+		},
+		{
+			name: "legacy pbkdf2-sha512",
+			credential: KeycloakCredential{
+				Type:              "password",
+				HashedSaltedValue: "oWHJTIlTanZAca4WPLR+C2OLaaYFihFtoGWMYX5tMMunfn3iVeE8ehUbaqnqgvP9eioV61AgUqYWH3g08DL6Rw==",
+				Salt:              "c2FsdHNhbHRzYWx0c2FsdA==",
+				HashIterations:    1000,
+				Algorithm:         "pbkdf2-sha512",
+			},
+			password: "pässword",
+			want:     "iterations=1000,keyLen=64,hashFunc=sha512$c2FsdHNhbHRzYWx0c2FsdA==$oWHJTIlTanZAca4WPLR+C2OLaaYFihFtoGWMYX5tMMunfn3iVeE8ehUbaqnqgvP9eioV61AgUqYWH3g08DL6Rw==",
+		},
+		{
+			name: "legacy pbkdf2",
+			credential: KeycloakCredential{
+				Type:              "password",
+				HashedSaltedValue: "2FWw/oC7TQkskizC+81lWlmFAMPzfuUU9jSdPALS95LIy5o7zcHjeb3ORZKEwmJExRHQSJFFjLR62Rvm9lmvBw==",
+				Salt:              "c2FsdHNhbHRzYWx0c2FsdA==",
+				HashIterations:    1000,
+				Algorithm:         "pbkdf2",
+			},
+			password: "password",
+			want:     "iterations=1000,keyLen=64,hashFunc=sha1$c2FsdHNhbHRzYWx0c2FsdA==$2FWw/oC7TQkskizC+81lWlmFAMPzfuUU9jSdPALS95LIy5o7zcHjeb3ORZKEwmJExRHQSJFFjLR62Rvm9lmvBw==",
+		},
+		{
+			name:       "otp",
+			credential: KeycloakCredential{Type: "otp", SecretData: `{"value":"secret"}`},
+			wantErr:    ErrUnsupportedKeycloakCredential,
+		},
+		{
+			name: "argon2",
+			credential: KeycloakCredential{
+				Type:           "password",
+				SecretData:     `{"value":"FLAKrylANDgDgtPBQiUvp4/v52EpM6tnb3b2ZEP26EQsBLnG6BJjrGNWdwchFI0CfpILE+L59Cc+XsuLt5amVQ==","salt":"c2FsdHNhbHRzYWx0c2FsdA=="}`,
+				CredentialData: `{"hashIterations":5,"algorithm":"argon2"}`,
+			},
+			wantErr: ErrUnsupportedKeycloakCredential,
+		},
+		{
+			name:       "invalid secretData",
+			credential: KeycloakCredential{Type: "password", SecretData: `{`, CredentialData: `{}`},
+			wantErr:    ErrInvalidFormat,
+		},
+		{
+			name:       "invalid salt",
+			credential: KeycloakCredential{Type: "password", HashedSaltedValue: "c2FsdA==", Salt: "!", HashIterations: 1000, Algorithm: "pbkdf2-sha256"},
+			wantErr:    ErrInvalidFormat,
+		},
+		{
+			name:       "missing iterations",
+			credential: KeycloakCredential{Type: "password", HashedSaltedValue: "c2FsdA==", Salt: "c2FsdA==", Algorithm: "pbkdf2-sha256"},
+			wantErr:    ErrInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.credential.Encoded()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encoded() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if encoded != tt.want {
+				t.Errorf("Encoded() = %v, want %v", encoded, tt.want)
+			}
+			if match, err := NewPBKDF2PasswordEncoder().Verify(tt.password, encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+		})
+	}
+}
+
+func TestImportKeycloakRealm(t *testing.T) {
+	ctx := context.Background()
+	export := `{
+	"realm": "demo",
+	"users": [
+		{
+			"username": "alice",
+			"credentials": [
+				{"type": "otp", "secretData": "{\"value\":\"secret\"}", "credentialData": "{\"subType\":\"totp\"}"},
+				{
+					"type": "password",
+					"secretData": "{\"value\":\"FLAKrylANDgDgtPBQiUvp4/v52EpM6tnb3b2ZEP26EQsBLnG6BJjrGNWdwchFI0CfpILE+L59Cc+XsuLt5amVQ==\",\"salt\":\"c2FsdHNhbHRzYWx0c2FsdA==\",\"additionalParameters\":{}}",
+					"credentialData": "{\"hashIterations\":27500,\"algorithm\":\"pbkdf2-sha256\",\"additionalParameters\":{}}"
+				}
+			]
+		},
+		{"username": "service-account-api", "credentials": []}
+	]
+}`
+	store := NewMemoryCredentialStore()
+	imported, err := ImportKeycloakRealm(ctx, strings.NewReader(export), store)
+	if err != nil || imported != 1 {
+		t.Fatalf("ImportKeycloakRealm() = %v, %v, want 1, nil", imported, err)
+	}
+
+	delegating, _ := NewDelegatingPasswordEncoder("pbkdf2", NewPBKDF2PasswordEncoder())
+	service := NewAuthService(store, delegating)
+	if match, err := service.Authenticate(ctx, "alice", "password"); err != nil || !match {
+		t.Errorf("Authenticate() = %v, %v, want true, nil", match, err)
+	}
+
+	unsupported := `{"users": [{"username": "bob", "credentials": [{"type": "password", "secretData": "{}", "credentialData": "{\"algorithm\":\"argon2\"}"}]}]}`
+	if _, err := ImportKeycloakRealm(ctx, strings.NewReader(unsupported), store); !errors.Is(err, ErrUnsupportedKeycloakCredential) {
+		t.Errorf("ImportKeycloakRealm() error = %v, want ErrUnsupportedKeycloakCredential", err)
+	}
+	if _, err := ImportKeycloakRealm(ctx, strings.NewReader("{"), store); err == nil {
+		t.Errorf("ImportKeycloakRealm() of invalid JSON error = nil, want an error")
+	}
+}
@@ -0,0 +1,68 @@
+package passforge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Keyring holds versioned secret keys, as used by peppers, HMAC keys and blind index keys. New values
+// are produced with the current version and carry it as a "VERSION$" prefix, so values produced with an
+// older version keep working while they are rotated.
+type Keyring struct {
+	CurrentVersion string            // Version of the key used for new values
+	Keys           map[string][]byte // Key version => key
+}
+
+// newKeyring creates a Keyring holding the current key only
+func newKeyring(currentVersion string, currentKey []byte) Keyring {
+	return Keyring{CurrentVersion: currentVersion, Keys: map[string][]byte{currentVersion: currentKey}}
+}
+
+// Key returns the key of the version
+func (k Keyring) Key(version string) ([]byte, bool) {
+	key, ok := k.Keys[version]
+	return key, ok
+}
+
+// Versions returns the configured versions, the current one first and the older ones sorted
+func (k Keyring) Versions() []string {
+	var older []string
+	for version := range k.Keys {
+		if version != k.CurrentVersion {
+			older = append(older, version)
+		}
+	}
+	sort.Strings(older)
+	return append([]string{k.CurrentVersion}, older...)
+}
+
+// IsCurrent reports whether the value carries the current version
+func (k Keyring) IsCurrent(value string) bool {
+	version, _, found := strings.Cut(value, "$")
+	return found && version == k.CurrentVersion
+}
+
+// validate rejects empty versions and versions containing '$', then checks every key with check.
+// kind names the keys in errors, e.g. "pepper".
+func (k Keyring) validate(kind string, check func(key []byte) error) error {
+	for _, version := range k.Versions() {
+		if version == "" || strings.Contains(version, "$") {
+			return fmt.Errorf("invalid %s version '%s'", kind, version)
+		}
+		if err := check(k.Keys[version]); err != nil {
+			return fmt.Errorf("%s '%s': %w", kind, version, err)
+		}
+	}
+	return nil
+}
+
+// minKeyLen returns a key check rejecting keys shorter than n bytes
+func minKeyLen(n int) func(key []byte) error {
+	return func(key []byte) error {
+		if len(key) < n {
+			return fmt.Errorf("must be at least %d bytes", n)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring := newKeyring("v3", bytes.Repeat([]byte{3}, 32))
+	keyring.Keys["v1"] = bytes.Repeat([]byte{1}, 32)
+	keyring.Keys["v2"] = bytes.Repeat([]byte{2}, 32)
+
+	if versions := strings.Join(keyring.Versions(), ","); versions != "v3,v1,v2" {
+		t.Errorf("Versions() = %v, want v3,v1,v2", versions)
+	}
+	if key, ok := keyring.Key("v1"); !ok || key[0] != 1 {
+		t.Errorf("Key(v1) = %v, %v", key, ok)
+	}
+	if _, ok := keyring.Key("v4"); ok {
+		t.Errorf("Key(v4) found an unknown version")
+	}
+	if !keyring.IsCurrent("v3$abc") || keyring.IsCurrent("v1$abc") || keyring.IsCurrent("v3") {
+		t.Errorf("IsCurrent() only accepts values prefixed with v3$")
+	}
+
+	testCases := []struct {
+		name    string
+		version string
+		key     []byte
+		wantErr string
+	}{
+		{name: "valid", version: "v4", key: bytes.Repeat([]byte{4}, 32)},
+		{name: "empty version", version: "", key: bytes.Repeat([]byte{4}, 32), wantErr: "invalid test key version ''"},
+		{name: "separator in version", version: "v$4", key: bytes.Repeat([]byte{4}, 32), wantErr: "invalid test key version 'v$4'"},
+		{name: "short key", version: "v4", key: []byte("short"), wantErr: "test key 'v4': must be at least 32 bytes"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyring := newKeyring("v3", bytes.Repeat([]byte{3}, 32))
+			keyring.Keys[tc.version] = tc.key
+			err := keyring.validate("test key", minKeyLen(32))
+			if (err == nil) != (tc.wantErr == "") || (err != nil && err.Error() != tc.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
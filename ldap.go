@@ -0,0 +1,108 @@
+package passforge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LDAPBinder performs an LDAP simple bind with the given DN and password.
+// Implementations usually wrap a connection (pool) from an LDAP client library such as go-ldap.
+// A bind rejected by the directory (result code 49) must be reported by returning an error
+// that wraps ErrInvalidCredentials; any other error is treated as an operational failure.
+type LDAPBinder interface {
+	Bind(dn, password string) error
+}
+
+// LDAPBindPasswordEncoder verifies credentials by performing an LDAP bind instead of comparing a local hash.
+// The "encoded password" is the DN of the directory entry, or the username when a DN template is configured.
+// Combined with DelegatingPasswordEncoder, users stored as "{ldap}uid=jdoe,ou=people,dc=example,dc=com"
+// are checked against the directory while all other users keep using local hashes.
+type LDAPBindPasswordEncoder struct {
+	Binder     LDAPBinder // Binder used to authenticate against the directory
+	DNTemplate string     // Optional DN template, e.g. "uid=%s,ou=people,dc=example,dc=com"
+}
+
+// LDAPOption is a functional option used to configure an LDAPBindPasswordEncoder instance.
+type LDAPOption func(*LDAPBindPasswordEncoder)
+
+// WithLDAPDNTemplate sets a DN template with a single %s verb.
+// When set, the stored value is treated as a username which is escaped per RFC 4514
+// and substituted into the template to build the bind DN.
+func WithLDAPDNTemplate(template string) LDAPOption {
+	return func(l *LDAPBindPasswordEncoder) {
+		l.DNTemplate = template
+	}
+}
+
+// NewLDAPBindPasswordEncoder creates a new LDAPBindPasswordEncoder using the given binder
+func NewLDAPBindPasswordEncoder(binder LDAPBinder, opts ...LDAPOption) *LDAPBindPasswordEncoder {
+	encoder := &LDAPBindPasswordEncoder{Binder: binder}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode is not supported: passwords verified by LDAP are owned by the directory
+func (l *LDAPBindPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify binds to the directory as the stored DN (or templated username) using the raw password
+func (l *LDAPBindPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	if encodedPassword == "" {
+		return false, ErrInvalidFormat
+	}
+	if l.Binder == nil {
+		return false, fmt.Errorf("ldap: no binder configured")
+	}
+
+	// An empty password would result in an unauthenticated bind, which most
+	// directories accept. Never treat that as a successful verification.
+	if rawPassword == "" {
+		return false, nil
+	}
+
+	dn := encodedPassword
+	if l.DNTemplate != "" {
+		dn = fmt.Sprintf(l.DNTemplate, escapeDN(encodedPassword))
+	}
+
+	err := l.Binder.Bind(dn, rawPassword)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Name returns the name of the encoder.
+func (l *LDAPBindPasswordEncoder) Name() string {
+	return "ldap"
+}
+
+// escapeDN escapes an attribute value for use in a distinguished name as described in RFC 4514
+func escapeDN(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';' || c == '=':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == 0:
+			sb.WriteString("\\00")
+		case i == 0 && (c == ' ' || c == '#'):
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case i == len(value)-1 && c == ' ':
+			sb.WriteString("\\ ")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
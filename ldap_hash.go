@@ -0,0 +1,208 @@
+package passforge
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// LDAPScheme is the storage scheme of an LDAP userPassword value, the SCHEME of {SCHEME}BASE64
+type LDAPScheme string
+
+const (
+	// LDAPSSHA is salted SHA-1, the default scheme of OpenLDAP's slappasswd
+	LDAPSSHA LDAPScheme = "SSHA"
+	// LDAPSSHA256 is salted SHA-256
+	LDAPSSHA256 LDAPScheme = "SSHA256"
+	// LDAPSSHA512 is salted SHA-512
+	LDAPSSHA512 LDAPScheme = "SSHA512"
+
+	// LDAPSHA is unsalted SHA-1, supported for verification only
+	//
+	// Deprecated: identical passwords have identical hashes; rehash users on their next login.
+	LDAPSHA LDAPScheme = "SHA"
+	// LDAPMD5 is unsalted MD5, supported for verification only
+	//
+	// Deprecated: identical passwords have identical hashes; rehash users on their next login.
+	LDAPMD5 LDAPScheme = "MD5"
+	// LDAPSMD5 is salted MD5, supported for verification only
+	//
+	// Deprecated: MD5 is broken; rehash users on their next login.
+	LDAPSMD5 LDAPScheme = "SMD5"
+)
+
+// ldapSchemeSpec describes how a scheme hashes passwords
+type ldapSchemeSpec struct {
+	newHash    func() hash.Hash
+	salted     bool
+	verifyOnly bool
+}
+
+// ldapSchemes maps the supported schemes to their hash function
+var ldapSchemes = map[LDAPScheme]ldapSchemeSpec{
+	LDAPSSHA:    {newHash: sha1.New, salted: true},
+	LDAPSSHA256: {newHash: sha256.New, salted: true},
+	LDAPSSHA512: {newHash: sha512.New, salted: true},
+	LDAPSHA:     {newHash: sha1.New, verifyOnly: true},
+	LDAPMD5:     {newHash: md5.New, verifyOnly: true},
+	LDAPSMD5:    {newHash: md5.New, salted: true, verifyOnly: true},
+}
+
+// LDAPHashPasswordEncoder is a password encoder for the salted SHA userPassword values of OpenLDAP and
+// other directories, {SSHA}BASE64 where BASE64 encodes the hash followed by the salt. Its name is the
+// scheme, so a DelegatingPasswordEncoder verifies userPassword values exported from a directory as is;
+// used directly, Verify accepts the value with or without the {SCHEME} prefix and Encode omits it.
+// The deprecated {SHA}, {MD5} and {SMD5} schemes are verify-only: Encode returns ErrEncodeNotSupported
+// and every successful verification is reported to the weak algorithm hook.
+type LDAPHashPasswordEncoder struct {
+	Scheme  LDAPScheme
+	SaltLen int       // Length of the salt, OpenLDAP uses 4
+	Rand    io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// LDAPHashOption is a functional option used to configure an LDAPHashPasswordEncoder instance.
+type LDAPHashOption func(*LDAPHashPasswordEncoder)
+
+// WithLDAPHashSaltLen sets the length of the salt
+// Default: 16
+func WithLDAPHashSaltLen(saltLen int) LDAPHashOption {
+	return func(l *LDAPHashPasswordEncoder) {
+		l.SaltLen = saltLen
+	}
+}
+
+// WithLDAPHashRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithLDAPHashRand(r io.Reader) LDAPHashOption {
+	return func(l *LDAPHashPasswordEncoder) {
+		l.Rand = r
+	}
+}
+
+// NewLDAPHashPasswordEncoder creates a new LDAPHashPasswordEncoder for the scheme with default parameters if not specified
+func NewLDAPHashPasswordEncoder(scheme LDAPScheme, opts ...LDAPHashOption) *LDAPHashPasswordEncoder {
+	encoder := &LDAPHashPasswordEncoder{
+		Scheme:  scheme,
+		SaltLen: 16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with a random salt and returns BASE64, without the {SCHEME} prefix
+func (l *LDAPHashPasswordEncoder) Encode(rawPassword string) (string, error) {
+	spec, ok := ldapSchemes[l.Scheme]
+	if !ok {
+		return "", fmt.Errorf("ldap: unsupported scheme %q", l.Scheme)
+	}
+	if spec.verifyOnly {
+		return "", ErrEncodeNotSupported
+	}
+	if l.SaltLen < 1 {
+		return "", fmt.Errorf("ldap: saltLen must be positive")
+	}
+	salt := make([]byte, l.SaltLen)
+	if _, err := io.ReadFull(randReader(l.Rand), salt); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(ldapHash(spec.newHash, rawPassword, salt), salt...)), nil
+}
+
+// Verify checks if the raw password matches the encoded password
+func (l *LDAPHashPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := l.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	spec := ldapSchemes[l.Scheme]
+	computedHash := ldapHash(spec.newHash, rawPassword, stored.salt)
+	if subtle.ConstantTimeCompare(stored.hash, computedHash) != 1 {
+		return false, nil
+	}
+	switch {
+	case spec.verifyOnly:
+		notifyWeak(l.Name(), "deprecated scheme")
+	case l.Scheme == LDAPSSHA:
+		notifyWeak(l.Name(), "sha1 hash function")
+	}
+	return true, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (l *LDAPHashPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := l.parse(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if !ldapSchemes[l.Scheme].salted {
+		return newFormatError(l.Name(), "unsalted scheme", encodedPassword)
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError(l.Name(), "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+// AdminStatus reports the scheme and salt length
+func (l *LDAPHashPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"scheme": string(l.Scheme), "saltLen": l.SaltLen}}
+}
+
+func init() {
+	for _, scheme := range []LDAPScheme{LDAPSSHA, LDAPSSHA256, LDAPSSHA512} {
+		RegisterTestVectors("ldap-"+strings.ToLower(string(scheme)), true, func(rand io.Reader) PasswordEncoder {
+			return NewLDAPHashPasswordEncoder(scheme, WithLDAPHashRand(rand))
+		})
+	}
+}
+
+// Name returns the scheme
+func (l *LDAPHashPasswordEncoder) Name() string {
+	return string(l.Scheme)
+}
+
+// ldapHashValue is a parsed userPassword value
+type ldapHashValue struct {
+	hash, salt []byte
+}
+
+// parse parses a userPassword value of the encoder's scheme, {SCHEME}BASE64 or BASE64. Scheme names
+// are case-insensitive as in OpenLDAP.
+func (l *LDAPHashPasswordEncoder) parse(encodedPassword string) (*ldapHashValue, error) {
+	spec, ok := ldapSchemes[l.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("ldap: unsupported scheme %q", l.Scheme)
+	}
+	value := encodedPassword
+	if id, rest, err := extractIDAndHash(encodedPassword); err == nil {
+		if !strings.EqualFold(id, string(l.Scheme)) {
+			return nil, newFormatError(l.Name(), "scheme mismatch", encodedPassword)
+		}
+		value = rest
+	}
+
+	decoded, err := base64.StdEncoding.Strict().DecodeString(value)
+	size := spec.newHash().Size()
+	if err != nil || len(decoded) < size || (len(decoded) > size) != spec.salted {
+		return nil, newFormatError(l.Name(), "invalid hash encoding", encodedPassword)
+	}
+	return &ldapHashValue{hash: decoded[:size], salt: decoded[size:]}, nil
+}
+
+// ldapHash returns the hash of the password followed by the salt
+func ldapHash(newHash func() hash.Hash, rawPassword string, salt []byte) []byte {
+	h := newHash()
+	h.Write([]byte(rawPassword))
+	h.Write(salt)
+	return h.Sum(nil)
+}
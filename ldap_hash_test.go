@@ -0,0 +1,132 @@
+package passforge
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLDAPHashPasswordEncoder_Encode(t *testing.T) {
+	for _, scheme := range []LDAPScheme{LDAPSSHA, LDAPSSHA256, LDAPSSHA512} {
+		t.Run(string(scheme), func(t *testing.T) {
+			encoder := NewLDAPHashPasswordEncoder(scheme)
+			encoded, err := encoder.Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			for _, value := range []string{encoded, "{" + string(scheme) + "}" + encoded} {
+				if match, err := encoder.Verify("password123", value); err != nil || !match {
+					t.Errorf("Verify(%v) = %v, %v, want true, nil", value, match, err)
+				}
+			}
+			if err := encoder.ValidateEncoded(encoded); err != nil {
+				t.Errorf("ValidateEncoded() error = %v", err)
+			}
+		})
+	}
+
+	encoder := NewLDAPHashPasswordEncoder(LDAPSSHA, WithLDAPHashSaltLen(4), WithLDAPHashRand(bytes.NewReader([]byte{1, 2, 3, 4})))
+	if encoded, err := encoder.Encode("secret"); err != nil || encoded != "uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME" {
+		t.Errorf("Encode() = %v, %v, want uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", encoded, err)
+	}
+	if _, err := NewLDAPHashPasswordEncoder("CRYPT").Encode("secret"); err == nil {
+		t.Errorf("Encode() with an unsupported scheme expected an error")
+	}
+}
+
+func TestLDAPHashPasswordEncoder_Verify(t *testing.T) {
+	// Hashes computed with Python's hashlib as base64(hash || salt)
+	tests := []struct {
+		name     string
+		scheme   LDAPScheme
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"ssha", LDAPSSHA, "secret", "{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA==", true, false},
+		{"ssha with OpenLDAP salt", LDAPSSHA, "secret", "{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", true, false},
+		{"lowercase scheme", LDAPSSHA, "secret", "{ssha}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", true, false},
+		{"unprefixed", LDAPSSHA, "secret", "uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", true, false},
+		{"ssha256", LDAPSSHA256, "secret", "{SSHA256}oBmrdHcA6OZEkkCLeXh71YAerbvhXz1qqwjrPsXmEtNzYWx0c2FsdA==", true, false},
+		{"ssha512", LDAPSSHA512, "secret", "{SSHA512}aCu7JRc+kLsuEmFs1zTY+AiP7DSGnjjG+dH28Dp+E5usqoAixeTPihKqZmkWal4mUfp63tqvCAkFV1LKTDFH6XNhbHRzYWx0", true, false},
+		{"wrong password", LDAPSSHA, "wrong", "{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA==", false, false},
+		{"scheme mismatch", LDAPSSHA256, "secret", "{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA==", false, true},
+		{"missing salt", LDAPSSHA, "secret", "{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/g=", false, true},
+		{"invalid base64", LDAPSSHA, "secret", "{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA", false, true},
+		{"sha", LDAPSHA, "secret", "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=", true, false},
+		{"md5", LDAPMD5, "secret", "{MD5}Xr4ilOzQ4PCOq3aQ0qbuaQ==", true, false},
+		{"smd5", LDAPSMD5, "secret", "{SMD5}VAfQ6nCkaw9o3u+x706wnXNhbHRzYWx0", true, false},
+		{"wrong md5 password", LDAPMD5, "wrong", "{MD5}Xr4ilOzQ4PCOq3aQ0qbuaQ==", false, false},
+		{"salted value for sha", LDAPSHA, "secret", "{SHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA==", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewLDAPHashPasswordEncoder(tt.scheme).Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDAPHashPasswordEncoder_Deprecated(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	for _, scheme := range []LDAPScheme{LDAPSHA, LDAPMD5, LDAPSMD5} {
+		if _, err := NewLDAPHashPasswordEncoder(scheme).Encode("secret"); !errors.Is(err, ErrEncodeNotSupported) {
+			t.Errorf("Encode() with %v error = %v, want ErrEncodeNotSupported", scheme, err)
+		}
+	}
+
+	encoder := NewLDAPHashPasswordEncoder(LDAPSHA)
+	if match, _ := encoder.Verify("wrong", "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ="); match || len(flagged) != 0 {
+		t.Errorf("Verify() with a wrong password = %v, flagged %v", match, flagged)
+	}
+	if match, _ := encoder.Verify("secret", "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ="); !match || len(flagged) != 1 || flagged[0].Algorithm != "SHA" {
+		t.Errorf("Verify() = %v, flagged %v, want one SHA event", match, flagged)
+	}
+	if err := encoder.ValidateEncoded("{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ="); err == nil {
+		t.Errorf("ValidateEncoded() expected an error for an unsalted scheme")
+	}
+}
+
+func TestLDAPHashPasswordEncoder_Delegating(t *testing.T) {
+	delegating, err := NewDelegatingPasswordEncoder("argon2", NewArgon2PasswordEncoder(),
+		NewLDAPHashPasswordEncoder(LDAPSSHA), NewLDAPHashPasswordEncoder(LDAPSSHA512))
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+	// userPassword values verify without an additional prefix
+	for _, encoded := range []string{
+		"{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME",
+		"{SSHA512}aCu7JRc+kLsuEmFs1zTY+AiP7DSGnjjG+dH28Dp+E5usqoAixeTPihKqZmkWal4mUfp63tqvCAkFV1LKTDFH6XNhbHRzYWx0",
+	} {
+		if match, err := delegating.Verify("secret", encoded); err != nil || !match {
+			t.Errorf("Verify(%v) = %v, %v, want true, nil", encoded, match, err)
+		}
+	}
+}
+
+func TestLDAPHashPasswordEncoder_ValidateEncoded(t *testing.T) {
+	encoder := NewLDAPHashPasswordEncoder(LDAPSSHA)
+	if err := encoder.ValidateEncoded("{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME"); err == nil {
+		t.Errorf("ValidateEncoded() expected an error for a 4-byte salt")
+	}
+	if err := encoder.ValidateEncoded("{SSHA}1G904nLkTkGWjKNnQuB/hpWXC/hzYWx0c2FsdA=="); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestLDAPHashPasswordEncoder_Name(t *testing.T) {
+	if name := NewLDAPHashPasswordEncoder(LDAPSSHA256).Name(); name != "SSHA256" {
+		t.Errorf("Name() = %v, want SSHA256", name)
+	}
+}
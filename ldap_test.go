@@ -0,0 +1,138 @@
+package passforge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeBinder is an in-memory LDAPBinder keyed by DN
+type fakeBinder struct {
+	entries map[string]string
+	lastDN  string
+	err     error
+}
+
+func (f *fakeBinder) Bind(dn, password string) error {
+	f.lastDN = dn
+	if f.err != nil {
+		return f.err
+	}
+	if pw, ok := f.entries[dn]; ok && pw == password {
+		return nil
+	}
+	return fmt.Errorf("ldap result code 49: %w", ErrInvalidCredentials)
+}
+
+func TestLDAPBindPasswordEncoder_Verify(t *testing.T) {
+	binder := &fakeBinder{entries: map[string]string{
+		"uid=jdoe,ou=people,dc=example,dc=com": "secret",
+	}}
+	encoder := NewLDAPBindPasswordEncoder(binder)
+
+	testCases := []struct {
+		name            string
+		rawPassword     string
+		encodedPassword string
+		wantMatch       bool
+		wantErr         bool
+	}{
+		{
+			name:            "matching password",
+			rawPassword:     "secret",
+			encodedPassword: "uid=jdoe,ou=people,dc=example,dc=com",
+			wantMatch:       true,
+		},
+		{
+			name:            "wrong password",
+			rawPassword:     "wrong",
+			encodedPassword: "uid=jdoe,ou=people,dc=example,dc=com",
+			wantMatch:       false,
+		},
+		{
+			name:            "empty password never binds",
+			rawPassword:     "",
+			encodedPassword: "uid=jdoe,ou=people,dc=example,dc=com",
+			wantMatch:       false,
+		},
+		{
+			name:            "empty DN",
+			rawPassword:     "secret",
+			encodedPassword: "",
+			wantErr:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := encoder.Verify(tc.rawPassword, tc.encodedPassword)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if match != tc.wantMatch {
+				t.Errorf("Verify() got = %v, want %v", match, tc.wantMatch)
+			}
+		})
+	}
+}
+
+func TestLDAPBindPasswordEncoder_DNTemplate(t *testing.T) {
+	binder := &fakeBinder{entries: map[string]string{
+		"uid=jdoe,ou=people,dc=example,dc=com": "secret",
+	}}
+	encoder := NewLDAPBindPasswordEncoder(binder, WithLDAPDNTemplate("uid=%s,ou=people,dc=example,dc=com"))
+
+	match, err := encoder.Verify("secret", "jdoe")
+	if err != nil || !match {
+		t.Errorf("Verify() got = %v, %v, want true, nil", match, err)
+	}
+
+	// Injection attempts must be escaped rather than altering the DN structure
+	_, _ = encoder.Verify("secret", "jdoe,ou=admins")
+	if binder.lastDN != `uid=jdoe\,ou\=admins,ou=people,dc=example,dc=com` {
+		t.Errorf("Verify() did not escape DN, got = %v", binder.lastDN)
+	}
+}
+
+func TestLDAPBindPasswordEncoder_BinderError(t *testing.T) {
+	binder := &fakeBinder{err: errors.New("connection refused")}
+	encoder := NewLDAPBindPasswordEncoder(binder)
+
+	match, err := encoder.Verify("secret", "uid=jdoe,dc=example,dc=com")
+	if err == nil || match {
+		t.Errorf("Verify() got = %v, %v, want false with error", match, err)
+	}
+}
+
+func TestLDAPBindPasswordEncoder_Delegating(t *testing.T) {
+	binder := &fakeBinder{entries: map[string]string{"cn=ad-user,dc=corp": "adpass"}}
+	delegating, err := NewDelegatingPasswordEncoder("noop", NewNoOpPasswordEncoder(), NewLDAPBindPasswordEncoder(binder))
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+
+	match, err := delegating.Verify("adpass", "{ldap}cn=ad-user,dc=corp")
+	if err != nil || !match {
+		t.Errorf("Verify() ldap user got = %v, %v, want true, nil", match, err)
+	}
+
+	match, err = delegating.Verify("local", "{noop}local")
+	if err != nil || !match {
+		t.Errorf("Verify() local user got = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestLDAPBindPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewLDAPBindPasswordEncoder(&fakeBinder{})
+
+	if _, err := encoder.Encode("secret"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+
+	if encoder.Name() != "ldap" {
+		t.Errorf("Name() = %v, want ldap", encoder.Name())
+	}
+}
@@ -0,0 +1,195 @@
+package passforge
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Placeholders of a legacy digest format
+const (
+	legacyDigestPassword = "{password}"
+	legacyDigestSalt     = "{salt}"
+)
+
+// LegacyDigestPasswordEncoder is a verify-only password encoder for the unsalted or ad hoc salted MD5 or
+// SHA-1 hex digests found in many inherited databases, created with NewMd5PasswordEncoder or
+// NewSha1PasswordEncoder. The format lays out the hashed input with the {password} and {salt} placeholders,
+// e.g. "{salt}{password}"; salted digests are stored as HEX:SALT. Encode returns ErrEncodeNotSupported
+// unless enabled with WithLegacyDigestEncode, and every successful verification is reported to the weak
+// algorithm hook, so users are rehashed on their next login behind a DelegatingPasswordEncoder.
+//
+// Deprecated: MD5 and SHA-1 are fast to compute and must not protect passwords; only use them to migrate existing hashes.
+type LegacyDigestPasswordEncoder struct {
+	Algorithm   string           // Name of the hash function, "md5" or "sha1"
+	NewHash     func() hash.Hash // Hash function of the digests
+	Size        int              // Length of the digests in bytes
+	Format      string           // Layout of the hashed input
+	AllowEncode bool             // Whether Encode produces digests
+	SaltLen     int              // Length of the salt in characters
+	Rand        io.Reader        // Source of salts, crypto/rand.Reader when nil
+}
+
+// LegacyDigestOption is a functional option used to configure a LegacyDigestPasswordEncoder instance.
+type LegacyDigestOption func(*LegacyDigestPasswordEncoder)
+
+// WithLegacyDigestFormat sets the layout of the hashed input, using the {password} and {salt} placeholders
+// Default: "{password}", unsalted
+func WithLegacyDigestFormat(format string) LegacyDigestOption {
+	return func(l *LegacyDigestPasswordEncoder) {
+		l.Format = format
+	}
+}
+
+// WithLegacyDigestEncode enables Encode, and makes ValidateEncoded accept well-formed digests
+// Only enable it while systems that can't verify stronger hashes still read the digests.
+func WithLegacyDigestEncode() LegacyDigestOption {
+	return func(l *LegacyDigestPasswordEncoder) {
+		l.AllowEncode = true
+	}
+}
+
+// WithLegacyDigestSaltLen sets the length of the salt in characters drawn by Encode
+// Default: 16
+func WithLegacyDigestSaltLen(saltLen int) LegacyDigestOption {
+	return func(l *LegacyDigestPasswordEncoder) {
+		l.SaltLen = saltLen
+	}
+}
+
+// WithLegacyDigestRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithLegacyDigestRand(r io.Reader) LegacyDigestOption {
+	return func(l *LegacyDigestPasswordEncoder) {
+		l.Rand = r
+	}
+}
+
+// newLegacyDigestPasswordEncoder creates a LegacyDigestPasswordEncoder for the hash function
+func newLegacyDigestPasswordEncoder(algorithm string, newHash func() hash.Hash, opts []LegacyDigestOption) *LegacyDigestPasswordEncoder {
+	encoder := &LegacyDigestPasswordEncoder{
+		Algorithm: algorithm,
+		NewHash:   newHash,
+		Size:      newHash().Size(),
+		Format:    legacyDigestPassword,
+		SaltLen:   16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode returns ErrEncodeNotSupported unless enabled, and the digest of the raw password otherwise
+func (l *LegacyDigestPasswordEncoder) Encode(rawPassword string) (string, error) {
+	if !l.AllowEncode {
+		return "", ErrEncodeNotSupported
+	}
+	encoded, err := encodeLegacyDigest(l.NewHash, l.Format, rawPassword, l.SaltLen, l.Rand)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", l.Algorithm, err)
+	}
+	return encoded, nil
+}
+
+// Verify checks if the raw password matches the encoded password
+func (l *LegacyDigestPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseLegacyDigest(l.Name(), l.Format, l.Size, encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if !verifyLegacyDigest(l.NewHash, l.Format, rawPassword, stored) {
+		return false, nil
+	}
+	notifyWeak(l.Name(), l.Algorithm+" digest")
+	return true, nil
+}
+
+// ValidateEncoded rejects every well-formed digest, unless Encode was enabled with WithLegacyDigestEncode
+func (l *LegacyDigestPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, err := parseLegacyDigest(l.Name(), l.Format, l.Size, encodedPassword); err != nil {
+		return err
+	}
+	if l.AllowEncode {
+		return nil
+	}
+	return newFormatError(l.Name(), l.Algorithm+" digest", encodedPassword)
+}
+
+// Identify reports whether the encoded password is a hex digest of the hash function, optionally followed by a salt
+func (l *LegacyDigestPasswordEncoder) Identify(encodedPassword string) bool {
+	return isLegacyDigest(encodedPassword, l.Size)
+}
+
+// AdminStatus reports the digest format and whether encoding is allowed
+func (l *LegacyDigestPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"format": l.Format, "allowEncode": l.AllowEncode}}
+}
+
+// Name returns the name of the hash function.
+func (l *LegacyDigestPasswordEncoder) Name() string {
+	return l.Algorithm
+}
+
+// legacyDigest is a parsed legacy digest, HEX or HEX:SALT
+type legacyDigest struct {
+	hash []byte
+	salt string
+}
+
+// encodeLegacyDigest hashes the raw password as laid out by format, drawing a salt first when format uses one
+func encodeLegacyDigest(newHash func() hash.Hash, format, rawPassword string, saltLen int, r io.Reader) (string, error) {
+	if !strings.Contains(format, legacyDigestPassword) {
+		return "", fmt.Errorf("format must contain %s", legacyDigestPassword)
+	}
+	if !strings.Contains(format, legacyDigestSalt) {
+		return hex.EncodeToString(legacyDigestHash(newHash, format, rawPassword, "")), nil
+	}
+	if saltLen < 1 {
+		return "", fmt.Errorf("saltLen must be positive")
+	}
+	salt, err := djangoSalt(randReader(r), saltLen)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(legacyDigestHash(newHash, format, rawPassword, salt)) + ":" + salt, nil
+}
+
+// verifyLegacyDigest checks if the raw password matches a parsed legacy digest
+func verifyLegacyDigest(newHash func() hash.Hash, format, rawPassword string, stored *legacyDigest) bool {
+	return subtle.ConstantTimeCompare(stored.hash, legacyDigestHash(newHash, format, rawPassword, stored.salt)) == 1
+}
+
+// parseLegacyDigest parses an encoded password of the form HEX, or HEX:SALT when format uses a salt
+func parseLegacyDigest(encoder, format string, size int, encodedPassword string) (*legacyDigest, error) {
+	if !strings.Contains(format, legacyDigestPassword) {
+		return nil, newFormatError(encoder, "invalid format", encodedPassword)
+	}
+	digest, salt, salted := strings.Cut(encodedPassword, ":")
+	if salted != strings.Contains(format, legacyDigestSalt) {
+		return nil, newFormatError(encoder, "invalid encoded password format", encodedPassword)
+	}
+	decoded, err := hex.DecodeString(digest)
+	if err != nil || len(decoded) != size {
+		return nil, newFormatError(encoder, "invalid hash encoding", encodedPassword)
+	}
+	return &legacyDigest{hash: decoded, salt: salt}, nil
+}
+
+// legacyDigestHash returns the hash of format with its placeholders replaced by the password and the salt
+func legacyDigestHash(newHash func() hash.Hash, format, rawPassword, salt string) []byte {
+	h := newHash()
+	h.Write([]byte(strings.NewReplacer(legacyDigestPassword, rawPassword, legacyDigestSalt, salt).Replace(format)))
+	return h.Sum(nil)
+}
+
+// isLegacyDigest reports whether the encoded password looks like a hex digest of size bytes, with or without a salt
+func isLegacyDigest(encodedPassword string, size int) bool {
+	digest, _, _ := strings.Cut(encodedPassword, ":")
+	decoded, err := hex.DecodeString(digest)
+	return err == nil && len(decoded) == size
+}
@@ -0,0 +1,141 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLegacyDigestPasswordEncoder_Verify(t *testing.T) {
+	// Digests computed with Python's hashlib
+	md5Format := func(format string) *LegacyDigestPasswordEncoder {
+		return NewMd5PasswordEncoder(WithLegacyDigestFormat(format))
+	}
+	sha1Format := func(format string) *LegacyDigestPasswordEncoder {
+		return NewSha1PasswordEncoder(WithLegacyDigestFormat(format))
+	}
+	tests := []struct {
+		name     string
+		encoder  *LegacyDigestPasswordEncoder
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"md5 unsalted", NewMd5PasswordEncoder(), "password", "5f4dcc3b5aa765d61d8327deb882cf99", true, false},
+		{"md5 uppercase hex", NewMd5PasswordEncoder(), "password", "5F4DCC3B5AA765D61D8327DEB882CF99", true, false},
+		{"md5 salt first", md5Format("{salt}{password}"), "password", "67a1e09bb1f83f5007dc119c14d663aa:salt", true, false},
+		{"md5 salt last", md5Format("{password}{salt}"), "password", "b305cadbb3bce54f3aa59c64fec00dea:salt", true, false},
+		{"md5 wrong password", NewMd5PasswordEncoder(), "Password", "5f4dcc3b5aa765d61d8327deb882cf99", false, false},
+		{"md5 wrong salt", md5Format("{salt}{password}"), "password", "67a1e09bb1f83f5007dc119c14d663aa:pepper", false, false},
+		{"md5 missing salt", md5Format("{salt}{password}"), "password", "67a1e09bb1f83f5007dc119c14d663aa", false, true},
+		{"md5 unexpected salt", NewMd5PasswordEncoder(), "password", "67a1e09bb1f83f5007dc119c14d663aa:salt", false, true},
+		{"md5 digest length", NewMd5PasswordEncoder(), "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", false, true},
+		{"md5 format without password", md5Format("{salt}"), "password", "67a1e09bb1f83f5007dc119c14d663aa:salt", false, true},
+		{"sha1 unsalted", NewSha1PasswordEncoder(), "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", true, false},
+		{"sha1 salt first", sha1Format("{salt}{password}"), "password", "59b3e8d637cf97edbe2384cf59cb7453dfe30789:salt", true, false},
+		{"sha1 salt with separator", sha1Format("{password}:{salt}"), "password", "707763a7f6c9b07e1b2791ecca9d6915d161be2e:sa:lt", true, false},
+		{"sha1 wrong password", NewSha1PasswordEncoder(), "Password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", false, false},
+		{"sha1 invalid hex", NewSha1PasswordEncoder(), "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fdz", false, true},
+		{"sha1 digest length", NewSha1PasswordEncoder(), "password", "5f4dcc3b5aa765d61d8327deb882cf99", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyDigestPasswordEncoder_Encode(t *testing.T) {
+	if _, err := NewSha1PasswordEncoder().Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+
+	unsalted := NewSha1PasswordEncoder(WithLegacyDigestEncode())
+	if encoded, err := unsalted.Encode("password"); err != nil || encoded != "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8" {
+		t.Errorf("Encode() = %v, %v, want the unsalted digest", encoded, err)
+	}
+
+	salted := NewMd5PasswordEncoder(WithLegacyDigestEncode(), WithLegacyDigestFormat("{salt}{password}"), WithLegacyDigestSaltLen(8))
+	encoded, err := salted.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if _, salt, _ := strings.Cut(encoded, ":"); len(salt) != 8 {
+		t.Errorf("Encode() = %v, want an 8 character salt", encoded)
+	}
+	if match, err := salted.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if _, err := NewSha1PasswordEncoder(WithLegacyDigestEncode(), WithLegacyDigestFormat("{salt}")).Encode("password"); err == nil {
+		t.Errorf("Encode() without {password} error = nil, want an error")
+	}
+}
+
+func TestLegacyDigestPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewMd5PasswordEncoder()
+	if match, _ := encoder.Verify("password", "5f4dcc3b5aa765d61d8327deb882cf99"); !match || len(flagged) != 1 || flagged[0].Algorithm != "md5" {
+		t.Errorf("Verify() = %v, flagged %v, want one md5 event", match, flagged)
+	}
+}
+
+func TestLegacyDigestPasswordEncoder_ValidateEncoded(t *testing.T) {
+	if err := NewMd5PasswordEncoder().ValidateEncoded("5f4dcc3b5aa765d61d8327deb882cf99"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+	enabled := NewMd5PasswordEncoder(WithLegacyDigestEncode())
+	if err := enabled.ValidateEncoded("5f4dcc3b5aa765d61d8327deb882cf99"); err != nil {
+		t.Errorf("ValidateEncoded() with encoding enabled error = %v, want nil", err)
+	}
+	if err := enabled.ValidateEncoded("5f4dcc3b5aa765d61d8327deb882cf9"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() of a truncated digest error = %v, want ErrInvalidFormat", err)
+	}
+
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+	delegating, _ := NewDelegatingPasswordEncoder("sha1", NewSha1PasswordEncoder(WithLegacyDigestEncode()))
+	service := NewAuthService(store, delegating)
+	if err := service.SetPassword(ctx, "alice", "password"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if stored, _ := store.FindHash(ctx, "alice"); stored != "{sha1}5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8" {
+		t.Errorf("stored hash = %v, want the sha1 digest", stored)
+	}
+}
+
+func TestLegacyDigestPasswordEncoder_UpgradeOnLogin(t *testing.T) {
+	ctx := context.Background()
+	delegating, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)), NewMd5PasswordEncoder())
+	store := NewMemoryCredentialStore()
+	_ = store.UpdateHash(ctx, "alice", "{md5}5f4dcc3b5aa765d61d8327deb882cf99")
+
+	service := NewAuthService(store, delegating)
+	if match, err := service.Authenticate(ctx, "alice", "password"); err != nil || !match {
+		t.Fatalf("Authenticate() = %v, %v, want true, nil", match, err)
+	}
+	if upgraded, _ := store.FindHash(ctx, "alice"); !strings.HasPrefix(upgraded, "{bcrypt}") {
+		t.Errorf("stored hash = %v, want a {bcrypt} hash", upgraded)
+	}
+}
+
+func TestLegacyDigestPasswordEncoder_Name(t *testing.T) {
+	if name := NewMd5PasswordEncoder().Name(); name != "md5" {
+		t.Errorf("Name() = %v, want md5", name)
+	}
+	if name := NewSha1PasswordEncoder().Name(); name != "sha1" {
+		t.Errorf("Name() = %v, want sha1", name)
+	}
+}
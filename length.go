@@ -0,0 +1,56 @@
+package passforge
+
+import (
+	"context"
+)
+
+// LengthLimitedPasswordEncoder rejects raw passwords longer than MaxBytes before any hashing work,
+// bounding the cost of requests with huge passwords and avoiding silent truncation, e.g. by bcrypt
+type LengthLimitedPasswordEncoder struct {
+	Encoder  PasswordEncoder
+	MaxBytes int
+}
+
+// NewLengthLimitedPasswordEncoder creates a LengthLimitedPasswordEncoder
+func NewLengthLimitedPasswordEncoder(encoder PasswordEncoder, maxBytes int) *LengthLimitedPasswordEncoder {
+	return &LengthLimitedPasswordEncoder{Encoder: encoder, MaxBytes: maxBytes}
+}
+
+// Encode encodes the password, or returns ErrPasswordTooLong
+func (l *LengthLimitedPasswordEncoder) Encode(rawPassword string) (string, error) {
+	if len(rawPassword) > l.MaxBytes {
+		return "", ErrPasswordTooLong
+	}
+	return l.Encoder.Encode(rawPassword)
+}
+
+// Verify verifies the password, or returns ErrPasswordTooLong without verifying
+func (l *LengthLimitedPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return l.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password, passing ctx to the wrapped encoder
+func (l *LengthLimitedPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	if len(rawPassword) > l.MaxBytes {
+		return false, ErrPasswordTooLong
+	}
+	return verifyContext(ctx, l.Encoder, rawPassword, encodedPassword)
+}
+
+// ValidateEncoded validates the encoded password with the wrapped encoder
+func (l *LengthLimitedPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	return ValidateEncoded(l.Encoder, encodedPassword)
+}
+
+// AdminStatus reports the length limit and the wrapped encoder
+func (l *LengthLimitedPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"maxBytes": l.MaxBytes},
+		Encoders: map[string]EncoderStatus{"inner": describe(l.Encoder)},
+	}
+}
+
+// Name returns the name of the wrapped encoder.
+func (l *LengthLimitedPasswordEncoder) Name() string {
+	return l.Encoder.Name()
+}
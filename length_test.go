@@ -0,0 +1,31 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLengthLimitedPasswordEncoder(t *testing.T) {
+	encoder := NewLengthLimitedPasswordEncoder(NewNoOpPasswordEncoder(), 8)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{"within limit", "password", nil},
+		{"over limit", "password1", ErrPasswordTooLong},
+		{"multibyte counted in bytes", strings.Repeat("é", 5), ErrPasswordTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encoder.Encode(tt.raw); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := encoder.Verify(tt.raw, tt.raw); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrTooManyAttempts is returned when an attempt limiter rejects a verification
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+// Attempt identifies the origin of a verification attempt
+type Attempt struct {
+	User string // Username or account ID
+	IP   string // Client IP address
+}
+
+// LimitKey selects which attributes of an Attempt are used to build limiter keys
+type LimitKey int
+
+const (
+	// LimitByUser counts attempts per user, regardless of origin
+	LimitByUser LimitKey = iota
+	// LimitByIP counts attempts per client IP, regardless of target user
+	LimitByIP
+	// LimitByUserAndIP counts attempts per user and client IP pair
+	LimitByUserAndIP
+)
+
+// AttemptLimiter tracks failed verification attempts per key
+type AttemptLimiter interface {
+	// Allow reports whether another attempt is permitted for the key
+	Allow(ctx context.Context, key string) (bool, error)
+
+	// Fail records a failed attempt for the key
+	Fail(ctx context.Context, key string) error
+
+	// Reset clears the recorded failures for the key
+	Reset(ctx context.Context, key string) error
+}
+
+// AttemptReserver is implemented by AttemptLimiters that can check and record an attempt in one atomic step.
+// LimitedPasswordEncoder then reserves every attempt before verifying it, so concurrent guesses, on one or
+// several replicas, can't all pass the check before their failures are recorded.
+type AttemptReserver interface {
+	// Reserve records an attempt for the key if another one is permitted, and reports whether it was
+	Reserve(ctx context.Context, key string) (bool, error)
+
+	// Release gives back an attempt reserved for the key, e.g. after a successful verification
+	Release(ctx context.Context, key string) error
+}
+
+type attemptContextKey struct{}
+
+// ContextWithAttempt returns a copy of ctx carrying the attempt
+func ContextWithAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptContextKey{}, attempt)
+}
+
+// AttemptFromContext returns the attempt carried by ctx, if any
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	attempt, ok := ctx.Value(attemptContextKey{}).(Attempt)
+	return attempt, ok
+}
+
+// LimitedPasswordEncoder decorates a PasswordEncoder with brute-force protection.
+// The attempt is taken from the context passed to VerifyContext; calls without an attempt are not limited.
+type LimitedPasswordEncoder struct {
+	Encoder PasswordEncoder
+	Limiter AttemptLimiter
+	Keys    []LimitKey
+
+	allowed atomic.Uint64
+	blocked atomic.Uint64
+}
+
+// LimiterStats counts the limited verification attempts since the encoder was created
+type LimiterStats struct {
+	Allowed uint64 `json:"allowed"` // Attempts that passed the limiter
+	Blocked uint64 `json:"blocked"` // Attempts rejected with ErrTooManyAttempts
+}
+
+// LimitedOption is a functional option used to configure a LimitedPasswordEncoder instance.
+type LimitedOption func(*LimitedPasswordEncoder)
+
+// WithLimitKeys sets which keys are checked for every attempt
+// Default: LimitByUser, LimitByIP
+func WithLimitKeys(keys ...LimitKey) LimitedOption {
+	return func(l *LimitedPasswordEncoder) {
+		l.Keys = keys
+	}
+}
+
+// NewLimitedPasswordEncoder creates a new LimitedPasswordEncoder wrapping the given encoder
+func NewLimitedPasswordEncoder(encoder PasswordEncoder, limiter AttemptLimiter, opts ...LimitedOption) *LimitedPasswordEncoder {
+	l := &LimitedPasswordEncoder{
+		Encoder: encoder,
+		Limiter: limiter,
+		Keys:    []LimitKey{LimitByUser, LimitByIP},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Encode encodes the raw password using the wrapped encoder
+func (l *LimitedPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return l.Encoder.Encode(rawPassword)
+}
+
+// Verify verifies the password without attempt information, so no limits are applied
+func (l *LimitedPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return l.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password, enforcing the limiter for the attempt carried by ctx.
+// Failed verifications are recorded for every key; a successful one resets the user-scoped keys only,
+// so a valid login on one account does not clear the counter of an IP spraying other accounts.
+// Limiters implementing AttemptReserver reserve the attempt before verifying; with the others, concurrent
+// attempts may all pass Allow before their failures are recorded.
+func (l *LimitedPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	attempt, ok := AttemptFromContext(ctx)
+	if !ok {
+		return verifyContext(ctx, l.Encoder, rawPassword, encodedPassword)
+	}
+	keys := l.limitKeys(attempt)
+	if reserver, ok := l.Limiter.(AttemptReserver); ok {
+		return l.verifyReserved(ctx, reserver, keys, rawPassword, encodedPassword)
+	}
+
+	for _, key := range keys {
+		allowed, err := l.Limiter.Allow(ctx, key.value)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			l.blocked.Add(1)
+			return false, ErrTooManyAttempts
+		}
+	}
+	l.allowed.Add(1)
+
+	match, err := verifyContext(ctx, l.Encoder, rawPassword, encodedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range keys {
+		if !match {
+			err = l.Limiter.Fail(ctx, key.value)
+		} else if key.kind != LimitByIP {
+			err = l.Limiter.Reset(ctx, key.value)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return match, nil
+}
+
+// verifyReserved verifies the password after reserving the attempt for every key, which records it as a
+// failure up front. Reservations are released when the attempt is blocked or fails with an error, and on
+// success, where the user-scoped keys are reset instead.
+func (l *LimitedPasswordEncoder) verifyReserved(ctx context.Context, reserver AttemptReserver, keys []limitKey, rawPassword, encodedPassword string) (bool, error) {
+	for i, key := range keys {
+		allowed, err := reserver.Reserve(ctx, key.value)
+		if err == nil && !allowed {
+			l.blocked.Add(1)
+			err = ErrTooManyAttempts
+		}
+		if err != nil {
+			if releaseErr := l.release(ctx, reserver, keys[:i]); releaseErr != nil {
+				err = errors.Join(err, releaseErr)
+			}
+			return false, err
+		}
+	}
+	l.allowed.Add(1)
+
+	match, err := verifyContext(ctx, l.Encoder, rawPassword, encodedPassword)
+	if err != nil {
+		if releaseErr := l.release(ctx, reserver, keys); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+		return false, err
+	}
+	if !match {
+		return false, nil
+	}
+	for _, key := range keys {
+		if key.kind != LimitByIP {
+			err = l.Limiter.Reset(ctx, key.value)
+		} else {
+			err = reserver.Release(ctx, key.value)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// release gives back the attempts reserved for the keys
+func (l *LimitedPasswordEncoder) release(ctx context.Context, reserver AttemptReserver, keys []limitKey) error {
+	var errs []error
+	for _, key := range keys {
+		if err := reserver.Release(ctx, key.value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AdminStatus reports the limiter counters and the wrapped encoder
+func (l *LimitedPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	stats := l.Stats()
+	return EncoderStatus{Limiter: &stats, Encoders: map[string]EncoderStatus{"inner": describe(l.Encoder)}}
+}
+
+// Name returns the name of the wrapped encoder.
+func (l *LimitedPasswordEncoder) Name() string {
+	return l.Encoder.Name()
+}
+
+// Stats returns how many limited attempts were allowed and blocked
+func (l *LimitedPasswordEncoder) Stats() LimiterStats {
+	return LimiterStats{Allowed: l.allowed.Load(), Blocked: l.blocked.Load()}
+}
+
+type limitKey struct {
+	kind  LimitKey
+	value string
+}
+
+// limitKeys builds the limiter keys for an attempt, skipping keys whose attributes are empty
+func (l *LimitedPasswordEncoder) limitKeys(attempt Attempt) []limitKey {
+	keys := make([]limitKey, 0, len(l.Keys))
+	for _, kind := range l.Keys {
+		switch {
+		case kind == LimitByUser && attempt.User != "":
+			keys = append(keys, limitKey{kind, "user:" + attempt.User})
+		case kind == LimitByIP && attempt.IP != "":
+			keys = append(keys, limitKey{kind, "ip:" + attempt.IP})
+		case kind == LimitByUserAndIP && attempt.User != "" && attempt.IP != "":
+			keys = append(keys, limitKey{kind, "user_ip:" + attempt.User + "|" + attempt.IP})
+		}
+	}
+	return keys
+}
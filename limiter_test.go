@@ -0,0 +1,106 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitedPasswordEncoder_VerifyContext(t *testing.T) {
+	limiter := NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(2))
+	encoder := NewLimitedPasswordEncoder(NewNoOpPasswordEncoder(), limiter, WithLimitKeys(LimitByUser, LimitByIP, LimitByUserAndIP))
+	ctx := ContextWithAttempt(context.Background(), Attempt{User: "alice", IP: "10.0.0.1"})
+
+	for i := 0; i < 2; i++ {
+		match, err := encoder.VerifyContext(ctx, "wrong", "secret")
+		if err != nil || match {
+			t.Fatalf("VerifyContext() attempt %d got = %v, %v, want false, nil", i, match, err)
+		}
+	}
+
+	// Even the right password is rejected once the limit is reached
+	_, err := encoder.VerifyContext(ctx, "secret", "secret")
+	if !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("VerifyContext() error = %v, want ErrTooManyAttempts", err)
+	}
+
+	// Calls without attempt information are not limited
+	match, err := encoder.Verify("secret", "secret")
+	if err != nil || !match {
+		t.Errorf("Verify() got = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestLimitedPasswordEncoder_SuccessResetsUserKeys(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(2))
+	encoder := NewLimitedPasswordEncoder(NewNoOpPasswordEncoder(), limiter)
+	attemptCtx := ContextWithAttempt(ctx, Attempt{User: "alice", IP: "10.0.0.1"})
+
+	_, _ = encoder.VerifyContext(attemptCtx, "wrong", "secret")
+	match, err := encoder.VerifyContext(attemptCtx, "secret", "secret")
+	if err != nil || !match {
+		t.Fatalf("VerifyContext() got = %v, %v, want true, nil", match, err)
+	}
+
+	if allowed, _ := limiter.Allow(ctx, "user:alice"); !allowed {
+		t.Errorf("user key should be reset after a successful verification")
+	}
+
+	// The IP key keeps counting so a valid account cannot be used to clear spraying attempts
+	_ = limiter.Fail(ctx, "ip:10.0.0.1")
+	if allowed, _ := limiter.Allow(ctx, "ip:10.0.0.1"); allowed {
+		t.Errorf("ip key should not be reset after a successful verification")
+	}
+}
+
+// slowCountingEncoder counts verifications and makes them slow enough for concurrent attempts to overlap
+type slowCountingEncoder struct {
+	PasswordEncoder
+	calls atomic.Int64
+}
+
+func (s *slowCountingEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	s.calls.Add(1)
+	time.Sleep(10 * time.Millisecond)
+	return s.PasswordEncoder.Verify(rawPassword, encodedPassword)
+}
+
+func TestLimitedPasswordEncoder_Concurrent(t *testing.T) {
+	inner := &slowCountingEncoder{PasswordEncoder: NewNoOpPasswordEncoder()}
+	encoder := NewLimitedPasswordEncoder(inner, NewRedisAttemptLimiter(newFakeRedis(), WithRedisLimit(3)))
+	ctx := ContextWithAttempt(context.Background(), Attempt{User: "alice", IP: "10.0.0.1"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = encoder.VerifyContext(ctx, "wrong", "secret")
+		}()
+	}
+	wg.Wait()
+
+	if calls := inner.calls.Load(); calls > 3 {
+		t.Errorf("inner encoder verified %d attempts, want at most 3", calls)
+	}
+	if stats := encoder.Stats(); stats.Allowed+stats.Blocked != 20 || stats.Allowed > 3 {
+		t.Errorf("Stats() = %+v, want at most 3 of 20 attempts allowed", stats)
+	}
+}
+
+func TestLimitedPasswordEncoder_Name(t *testing.T) {
+	encoder := NewLimitedPasswordEncoder(NewNoOpPasswordEncoder(), NewRedisAttemptLimiter(newFakeRedis()))
+
+	if encoder.Name() != "noop" {
+		t.Errorf("Name() = %v, want noop", encoder.Name())
+	}
+
+	encoded, err := encoder.Encode("secret")
+	if err != nil || encoded != "secret" {
+		t.Errorf("Encode() got = %v, %v", encoded, err)
+	}
+}
@@ -0,0 +1,14 @@
+package passforge
+
+import "crypto/md5"
+
+func init() {
+	RegisterHashFormat(NewMd5PasswordEncoder())
+}
+
+// NewMd5PasswordEncoder creates a LegacyDigestPasswordEncoder for MD5 hex digests, named "md5"
+//
+// Deprecated: MD5 is fast to compute and must not protect passwords; only use it to migrate existing hashes.
+func NewMd5PasswordEncoder(opts ...LegacyDigestOption) *LegacyDigestPasswordEncoder {
+	return newLegacyDigestPasswordEncoder("md5", md5.New, opts)
+}
@@ -0,0 +1,120 @@
+package passforge
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// MediaWikiPasswordEncoder is a verify-only password encoder for the user_password column of MediaWiki,
+// :pbkdf2:ALGORITHM:ITERATIONS:KEYLEN:BASE64_SALT:BASE64_HASH, e.g. :pbkdf2:sha512:30000:64:SALT:HASH with
+// MediaWiki's defaults. ALGORITHM is a PHP hash algorithm name, as accepted by WithPBKDF2Hash. Older :A:
+// and :B: MD5 hashes and layered hashes are not supported. Successful verifications of sha1 hashes are
+// reported to the weak algorithm hook.
+type MediaWikiPasswordEncoder struct{}
+
+// NewMediaWikiPasswordEncoder creates a new MediaWikiPasswordEncoder
+func NewMediaWikiPasswordEncoder() *MediaWikiPasswordEncoder {
+	return &MediaWikiPasswordEncoder{}
+}
+
+// Encode is not supported: MediaWiki hashes are only verified to consolidate wiki accounts
+func (m *MediaWikiPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (m *MediaWikiPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseMediaWiki(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	computedHash, err := pbkdf2Key(rawPassword, stored.salt, stored.iterations, len(stored.hash), stored.hashFunc)
+	if err != nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare(stored.hash, computedHash) != 1 {
+		return false, nil
+	}
+	if stored.algorithm == "sha1" {
+		notifyWeak(m.Name(), "sha1 hash function")
+	}
+	return true, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (m *MediaWikiPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseMediaWiki(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("mediawiki", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&MediaWikiPasswordEncoder{})
+}
+
+// Identify reports whether the encoded password is a MediaWiki PBKDF2 hash
+func (m *MediaWikiPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, ":pbkdf2:")
+}
+
+// EncodedParams returns the iterations and digest algorithm of the hash
+func (m *MediaWikiPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseMediaWiki(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations), "algorithm": stored.algorithm}
+}
+
+// Name returns the name of the encoder.
+func (m *MediaWikiPasswordEncoder) Name() string {
+	return "mediawiki"
+}
+
+// mediaWikiHash is a parsed MediaWiki encoded password
+type mediaWikiHash struct {
+	algorithm  string
+	hashFunc   func() hash.Hash
+	iterations int
+	salt, hash []byte
+}
+
+// parseMediaWiki parses an encoded password of the form :pbkdf2:ALGORITHM:ITERATIONS:KEYLEN:BASE64_SALT:BASE64_HASH
+func parseMediaWiki(encodedPassword string) (*mediaWikiHash, error) {
+	parts := strings.Split(encodedPassword, ":")
+	if len(parts) != 7 || parts[0] != "" || parts[1] != "pbkdf2" {
+		return nil, newFormatError("mediawiki", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &mediaWikiHash{algorithm: parts[2]}
+	var ok bool
+	if stored.hashFunc, ok = pbkdf2HashFuncs[stored.algorithm]; !ok {
+		return nil, newFormatError("mediawiki", "unsupported algorithm", encodedPassword)
+	}
+	var err error
+	stored.iterations, err = strconv.Atoi(parts[3])
+	if err != nil || stored.iterations < 1 || parts[3][0] == '+' {
+		return nil, newFormatError("mediawiki", "invalid iterations", encodedPassword)
+	}
+	keyLen, err := strconv.Atoi(parts[4])
+	if err != nil || keyLen < 1 || parts[4][0] == '+' {
+		return nil, newFormatError("mediawiki", "invalid key length", encodedPassword)
+	}
+	stored.salt, err = base64.StdEncoding.DecodeString(parts[5])
+	if err != nil || len(stored.salt) == 0 {
+		return nil, newFormatError("mediawiki", "invalid salt encoding", encodedPassword)
+	}
+	stored.hash, err = base64.StdEncoding.DecodeString(parts[6])
+	if err != nil || len(stored.hash) != keyLen {
+		return nil, newFormatError("mediawiki", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
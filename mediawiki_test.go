@@ -0,0 +1,66 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMediaWikiPasswordEncoder_Verify(t *testing.T) {
+	// Hashes computed with Python's hashlib.pbkdf2_hmac, as MediaWiki's hash_pbkdf2 call
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"sha512 defaults", "password", ":pbkdf2:sha512:30000:64:AAECAwQFBgcICQoLDA0ODw==:ll4mBZmR4q/jLpJcPLu3znpVM+eLVHQZLs34oWvAcUikJkWvrhDMpw9EMpRRP1sb5CTR/U7tyqzWfeFXiXi+4w==", true, false},
+		{"sha256", "password", ":pbkdf2:sha256:10000:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", true, false},
+		{"sha1", "password", ":pbkdf2:sha1:10000:20:AAECAwQFBgcICQoLDA0ODw==:jj4vc8PrY5CoGrvIEBwDQ7AXp68=", true, false},
+		{"wrong password", "Password", ":pbkdf2:sha256:10000:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, false},
+		{"wrong iterations", "password", ":pbkdf2:sha256:10001:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, false},
+		{"key length mismatch", "password", ":pbkdf2:sha256:10000:64:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, true},
+		{"unsupported algorithm", "password", ":pbkdf2:md5:10000:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, true},
+		{"salted md5", "password", ":B:838c83e1:e4ab7024509eef084cdabd03d8b2972c", false, true},
+		{"zero iterations", "password", ":pbkdf2:sha256:0:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, true},
+		{"missing salt", "password", ":pbkdf2:sha256:10000:32::62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewMediaWikiPasswordEncoder().Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaWikiPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewMediaWikiPasswordEncoder()
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	_, _ = encoder.Verify("password", ":pbkdf2:sha512:30000:64:AAECAwQFBgcICQoLDA0ODw==:ll4mBZmR4q/jLpJcPLu3znpVM+eLVHQZLs34oWvAcUikJkWvrhDMpw9EMpRRP1sb5CTR/U7tyqzWfeFXiXi+4w==")
+	_, _ = encoder.Verify("password", ":pbkdf2:sha1:10000:20:AAECAwQFBgcICQoLDA0ODw==:jj4vc8PrY5CoGrvIEBwDQ7AXp68=")
+	if len(flagged) != 1 || flagged[0].Algorithm != "mediawiki" {
+		t.Errorf("flagged %v, want one mediawiki event for the sha1 hash", flagged)
+	}
+	if err := encoder.ValidateEncoded(":pbkdf2:sha256:10000:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk="); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestMediaWikiPasswordEncoder_Name(t *testing.T) {
+	if name := NewMediaWikiPasswordEncoder().Name(); name != "mediawiki" {
+		t.Errorf("Name() = %v, want mediawiki", name)
+	}
+}
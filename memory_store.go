@@ -0,0 +1,100 @@
+package passforge
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryCredentialStore is a concurrency-safe in-memory UserCredentialStore keeping the full hash history.
+// It is intended for tests, demos and small tools.
+type MemoryCredentialStore struct {
+	Now func() time.Time // Time source for UpdatedAt
+
+	mu      sync.RWMutex
+	records map[string][]CredentialRecord // oldest first
+}
+
+// NewMemoryCredentialStore creates an empty MemoryCredentialStore
+func NewMemoryCredentialStore() *MemoryCredentialStore {
+	return &MemoryCredentialStore{
+		records: make(map[string][]CredentialRecord),
+		Now:     time.Now,
+	}
+}
+
+// FindHash returns the current encoded password of the user
+func (m *MemoryCredentialStore) FindHash(_ context.Context, userID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := m.records[userID]
+	if len(records) == 0 {
+		return "", ErrCredentialNotFound
+	}
+	return records[len(records)-1].Hash, nil
+}
+
+// UpdateHash stores a new encoded password for the user, keeping the previous one in the history
+func (m *MemoryCredentialStore) UpdateHash(_ context.Context, userID, encodedPassword string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[userID] = append(m.records[userID], CredentialRecord{
+		UserID:    userID,
+		Hash:      encodedPassword,
+		UpdatedAt: m.Now(),
+	})
+	return nil
+}
+
+// ReplaceHash swaps the current encoded password of the user for newHash if it is still oldHash
+func (m *MemoryCredentialStore) ReplaceHash(_ context.Context, userID, oldHash, newHash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := m.records[userID]
+	if len(records) == 0 || records[len(records)-1].Hash != oldHash {
+		return false, nil
+	}
+	records[len(records)-1] = CredentialRecord{UserID: userID, Hash: newHash, UpdatedAt: m.Now()}
+	return true, nil
+}
+
+// HashHistory returns up to limit previous encoded passwords of the user, most recent first
+func (m *MemoryCredentialStore) HashHistory(_ context.Context, userID string, limit int) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return historyOf(m.records[userID], limit), nil
+}
+
+// ListCredentials returns the current record of every user, ordered by user ID
+func (m *MemoryCredentialStore) ListCredentials(_ context.Context) ([]CredentialRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return currentRecords(m.records), nil
+}
+
+// currentRecords returns the last record of every user, ordered by user ID
+func currentRecords(records map[string][]CredentialRecord) []CredentialRecord {
+	current := make([]CredentialRecord, 0, len(records))
+	for _, history := range records {
+		if len(history) > 0 {
+			current = append(current, history[len(history)-1])
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].UserID < current[j].UserID })
+	return current
+}
+
+// historyOf returns up to limit hashes preceding the current record, most recent first
+func historyOf(records []CredentialRecord, limit int) []string {
+	var history []string
+	for i := len(records) - 2; i >= 0 && len(history) < limit; i-- {
+		history = append(history, records[i].Hash)
+	}
+	return history
+}
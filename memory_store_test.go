@@ -0,0 +1,109 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMemoryCredentialStore_FindAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+
+	if _, err := store.FindHash(ctx, "alice"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("FindHash() error = %v, want ErrCredentialNotFound", err)
+	}
+
+	_ = store.UpdateHash(ctx, "alice", "{noop}one")
+	_ = store.UpdateHash(ctx, "alice", "{noop}two")
+	_ = store.UpdateHash(ctx, "alice", "{noop}three")
+
+	encoded, err := store.FindHash(ctx, "alice")
+	if err != nil || encoded != "{noop}three" {
+		t.Errorf("FindHash() got = %v, %v, want {noop}three", encoded, err)
+	}
+
+	history, _ := store.HashHistory(ctx, "alice", 5)
+	if strings.Join(history, ",") != "{noop}two,{noop}one" {
+		t.Errorf("HashHistory() got = %v", history)
+	}
+
+	history, _ = store.HashHistory(ctx, "alice", 1)
+	if len(history) != 1 || history[0] != "{noop}two" {
+		t.Errorf("HashHistory() with limit got = %v", history)
+	}
+}
+
+func TestMemoryCredentialStore_ReplaceHash(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+	_ = store.UpdateHash(ctx, "alice", "{noop}one")
+	_ = store.UpdateHash(ctx, "alice", "{noop}two")
+
+	if replaced, err := store.ReplaceHash(ctx, "alice", "{noop}one", "{noop}three"); err != nil || replaced {
+		t.Errorf("ReplaceHash() of a stale hash = %v, %v, want false, nil", replaced, err)
+	}
+	if replaced, err := store.ReplaceHash(ctx, "alice", "{noop}two", "{noop}three"); err != nil || !replaced {
+		t.Errorf("ReplaceHash() = %v, %v, want true, nil", replaced, err)
+	}
+	if replaced, _ := store.ReplaceHash(ctx, "bob", "", "{noop}bob"); replaced {
+		t.Errorf("ReplaceHash() replaced the hash of an unknown user")
+	}
+
+	encoded, _ := store.FindHash(ctx, "alice")
+	history, _ := store.HashHistory(ctx, "alice", 5)
+	if encoded != "{noop}three" || strings.Join(history, ",") != "{noop}one" {
+		t.Errorf("FindHash() = %v, HashHistory() = %v, want {noop}three without {noop}two in the history", encoded, history)
+	}
+}
+
+func TestMemoryCredentialStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := fmt.Sprintf("user%d", i%4)
+			_ = store.UpdateHash(ctx, user, fmt.Sprintf("{noop}%d", i))
+			_, _ = store.FindHash(ctx, user)
+			_, _ = store.HashHistory(ctx, user, 3)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		if _, err := store.FindHash(ctx, fmt.Sprintf("user%d", i)); err != nil {
+			t.Errorf("FindHash() error = %v", err)
+		}
+	}
+}
+
+func TestMemoryCredentialStore_AuthServiceHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCredentialStore()
+	service := newTestAuthService(t, store, WithAuthHistory(2))
+
+	if err := service.SetPassword(ctx, "alice", "first-password"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if err := service.ChangePassword(ctx, "alice", "first-password", "second-password"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+
+	// Both the current and the previous password are rejected
+	if err := service.ChangePassword(ctx, "alice", "second-password", "second-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Errorf("ChangePassword() to current password error = %v, want ErrPasswordReused", err)
+	}
+	if err := service.ChangePassword(ctx, "alice", "second-password", "first-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Errorf("ChangePassword() to previous password error = %v, want ErrPasswordReused", err)
+	}
+	if err := service.ChangePassword(ctx, "alice", "second-password", "third-password"); err != nil {
+		t.Errorf("ChangePassword() to new password error = %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package passforge
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// messageDigestAlgorithms maps Java's MessageDigest algorithm names to their hash function
+var messageDigestAlgorithms = map[string]func() hash.Hash{
+	"MD5":     md5.New,
+	"SHA-1":   sha1.New,
+	"SHA-256": sha256.New,
+	"SHA-384": sha512.New384,
+	"SHA-512": sha512.New,
+}
+
+// MessageDigestPasswordEncoder is a verify-only password encoder for hashes of Spring Security's deprecated
+// MessageDigestPasswordEncoder, {SALT}HEX where HEX is the digest of "password{SALT}" (or of the password
+// alone without a salt). Pre-5.0 databases kept the salt elsewhere, e.g. the username; join it as {SALT}HEX.
+// Its name is the algorithm, so Spring's {SHA-256}{SALT}HEX values verify as is under a
+// DelegatingPasswordEncoder. Every successful verification is reported to the weak algorithm hook.
+type MessageDigestPasswordEncoder struct {
+	Algorithm  string // Java algorithm name: MD5, SHA-1, SHA-256, SHA-384 or SHA-512
+	Iterations int    // Number of digest iterations
+	Base64     bool   // Whether the digest is base64 instead of hex encoded, encodeHashAsBase64 in Spring
+}
+
+// MessageDigestOption is a functional option used to configure a MessageDigestPasswordEncoder instance.
+type MessageDigestOption func(*MessageDigestPasswordEncoder)
+
+// WithMessageDigestIterations sets the number of digest iterations
+// Default: 1
+func WithMessageDigestIterations(iterations int) MessageDigestOption {
+	return func(m *MessageDigestPasswordEncoder) {
+		m.Iterations = iterations
+	}
+}
+
+// WithMessageDigestBase64 reads base64 digests instead of hex ones
+func WithMessageDigestBase64() MessageDigestOption {
+	return func(m *MessageDigestPasswordEncoder) {
+		m.Base64 = true
+	}
+}
+
+// NewMessageDigestPasswordEncoder creates a new MessageDigestPasswordEncoder for the Java algorithm name, e.g. "SHA-256"
+func NewMessageDigestPasswordEncoder(algorithm string, opts ...MessageDigestOption) *MessageDigestPasswordEncoder {
+	encoder := &MessageDigestPasswordEncoder{Algorithm: algorithm, Iterations: 1}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode is not supported: message digests must not be produced for new passwords
+func (m *MessageDigestPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (m *MessageDigestPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	newHash, ok := messageDigestAlgorithms[m.Algorithm]
+	if !ok || m.Iterations < 1 {
+		return false, fmt.Errorf("message digest: unsupported algorithm %q or iterations %d", m.Algorithm, m.Iterations)
+	}
+	salt, stored, err := m.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	input := rawPassword
+	if salt != "" {
+		input += "{" + salt + "}"
+	}
+	h := newHash()
+	h.Write([]byte(input))
+	digest := h.Sum(nil)
+	for i := 1; i < m.Iterations; i++ {
+		h.Reset()
+		h.Write(digest)
+		digest = h.Sum(digest[:0])
+	}
+	if subtle.ConstantTimeCompare(stored, digest) != 1 {
+		return false, nil
+	}
+	notifyWeak(m.Name(), "message digest")
+	return true, nil
+}
+
+// ValidateEncoded rejects every message digest once it is well-formed, as they are fast to compute
+func (m *MessageDigestPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, _, err := m.parse(encodedPassword); err != nil {
+		return err
+	}
+	return newFormatError(m.Name(), "message digest", encodedPassword)
+}
+
+// AdminStatus reports the iterations and output encoding
+func (m *MessageDigestPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"iterations": m.Iterations, "base64": m.Base64}}
+}
+
+// Name returns the algorithm
+func (m *MessageDigestPasswordEncoder) Name() string {
+	return m.Algorithm
+}
+
+// parse splits an encoded password of the form [{SALT}]DIGEST and decodes the digest
+func (m *MessageDigestPasswordEncoder) parse(encodedPassword string) (salt string, digest []byte, err error) {
+	encoded := encodedPassword
+	if strings.HasPrefix(encoded, "{") {
+		end := strings.IndexByte(encoded, '}')
+		if end < 0 {
+			return "", nil, newFormatError(m.Name(), "invalid salt", encodedPassword)
+		}
+		salt, encoded = encoded[1:end], encoded[end+1:]
+	}
+
+	if m.Base64 {
+		digest, err = base64.StdEncoding.Strict().DecodeString(encoded)
+	} else {
+		digest, err = hex.DecodeString(encoded)
+	}
+	if newHash, ok := messageDigestAlgorithms[m.Algorithm]; err != nil || (ok && len(digest) != newHash().Size()) {
+		return "", nil, newFormatError(m.Name(), "invalid hash encoding", encodedPassword)
+	}
+	return salt, digest, nil
+}
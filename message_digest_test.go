@@ -0,0 +1,72 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMessageDigestPasswordEncoder_Verify(t *testing.T) {
+	// Digests computed with Python's hashlib over "password{salt}"
+	tests := []struct {
+		name     string
+		encoder  *MessageDigestPasswordEncoder
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"sha-256", NewMessageDigestPasswordEncoder("SHA-256"), "password", "{salt}ced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3", true, false},
+		{"md5", NewMessageDigestPasswordEncoder("MD5"), "password", "{salt}ce421738b1c5540836bdc8ff707f1572", true, false},
+		{"unsalted sha-1", NewMessageDigestPasswordEncoder("SHA-1"), "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", true, false},
+		{"iterations", NewMessageDigestPasswordEncoder("SHA-256", WithMessageDigestIterations(1024)), "password", "{salt}41165b15dc1b85de48d3d3968fb5c65498b0910b4d4b4451432cdc45f528bcd6", true, false},
+		{"base64", NewMessageDigestPasswordEncoder("SHA-512", WithMessageDigestBase64()), "password", "{5f3a2d}45OxX8vKHoPicIvUi5z5L9K8ALHrPLk+rHMLthK3rdVfwRjhZAXQFPFKUmTDqp6NZdsg8k8PwNS4NtlhuP1zPQ==", true, false},
+		{"wrong password", NewMessageDigestPasswordEncoder("SHA-256"), "Password", "{salt}ced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3", false, false},
+		{"wrong salt", NewMessageDigestPasswordEncoder("SHA-256"), "password", "{pepper}ced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3", false, false},
+		{"wrong iterations", NewMessageDigestPasswordEncoder("SHA-256"), "password", "{salt}41165b15dc1b85de48d3d3968fb5c65498b0910b4d4b4451432cdc45f528bcd6", false, false},
+		{"unterminated salt", NewMessageDigestPasswordEncoder("SHA-256"), "password", "{saltced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3", false, true},
+		{"digest length", NewMessageDigestPasswordEncoder("SHA-256"), "password", "{salt}ce421738b1c5540836bdc8ff707f1572", false, true},
+		{"unsupported algorithm", NewMessageDigestPasswordEncoder("SHA3-256"), "password", "{salt}ced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageDigestPasswordEncoder_Delegating(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	delegating, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)),
+		NewMessageDigestPasswordEncoder("SHA-256"), NewMessageDigestPasswordEncoder("MD5"))
+	// Values stored by Spring's DelegatingPasswordEncoder
+	encoded := "{SHA-256}{salt}ced22f25384e53c51e100acead5cfafd761f18150ef141be6896700d5a0c8ae3"
+	if match, err := delegating.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if len(flagged) != 1 || flagged[0].Algorithm != "SHA-256" {
+		t.Errorf("flagged %v, want one SHA-256 event", flagged)
+	}
+	if err := delegating.ValidateEncoded(encoded); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+	if _, err := NewMessageDigestPasswordEncoder("SHA-256").Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+}
+
+func TestMessageDigestPasswordEncoder_Name(t *testing.T) {
+	if name := NewMessageDigestPasswordEncoder("SHA-256").Name(); name != "SHA-256" {
+		t.Errorf("Name() = %v, want SHA-256", name)
+	}
+}
@@ -0,0 +1,115 @@
+package passforge
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// EncoderMetrics receives the outcome and duration of encoder calls, e.g. to export them to Prometheus
+type EncoderMetrics interface {
+	// ObserveEncode is called after every Encode
+	ObserveEncode(encoder string, duration time.Duration, err error)
+
+	// ObserveVerify is called after every Verify
+	ObserveVerify(encoder string, duration time.Duration, match bool, err error)
+}
+
+// MeteredPasswordEncoder reports every call of the wrapped encoder to an EncoderMetrics
+type MeteredPasswordEncoder struct {
+	Encoder PasswordEncoder
+	Metrics EncoderMetrics
+}
+
+// NewMeteredPasswordEncoder creates a MeteredPasswordEncoder
+func NewMeteredPasswordEncoder(encoder PasswordEncoder, metrics EncoderMetrics) *MeteredPasswordEncoder {
+	return &MeteredPasswordEncoder{Encoder: encoder, Metrics: metrics}
+}
+
+// Encode encodes the password using the wrapped encoder
+func (m *MeteredPasswordEncoder) Encode(rawPassword string) (string, error) {
+	start := time.Now()
+	encoded, err := m.Encoder.Encode(rawPassword)
+	m.Metrics.ObserveEncode(m.Encoder.Name(), time.Since(start), err)
+	return encoded, err
+}
+
+// Verify verifies the password using the wrapped encoder
+func (m *MeteredPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return m.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password, passing ctx to the wrapped encoder
+func (m *MeteredPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	start := time.Now()
+	match, err := verifyContext(ctx, m.Encoder, rawPassword, encodedPassword)
+	m.Metrics.ObserveVerify(m.Encoder.Name(), time.Since(start), match, err)
+	return match, err
+}
+
+// ValidateEncoded validates the encoded password with the wrapped encoder
+func (m *MeteredPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	return ValidateEncoded(m.Encoder, encodedPassword)
+}
+
+// AdminStatus reports the wrapped encoder
+func (m *MeteredPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Encoders: map[string]EncoderStatus{"inner": describe(m.Encoder)}}
+}
+
+// Name returns the name of the wrapped encoder.
+func (m *MeteredPasswordEncoder) Name() string {
+	return m.Encoder.Name()
+}
+
+// MetricsSnapshot is a point-in-time copy of MemoryMetrics
+type MetricsSnapshot struct {
+	Encodes      uint64        `json:"encodes"`
+	EncodeErrors uint64        `json:"encodeErrors"`
+	Verifies     uint64        `json:"verifies"`
+	Matches      uint64        `json:"matches"`
+	VerifyErrors uint64        `json:"verifyErrors"`
+	EncodeTime   time.Duration `json:"encodeTime"`
+	VerifyTime   time.Duration `json:"verifyTime"`
+}
+
+// MemoryMetrics is an EncoderMetrics keeping counters and total durations in memory
+type MemoryMetrics struct {
+	encodes, encodeErrors           atomic.Uint64
+	verifies, matches, verifyErrors atomic.Uint64
+	encodeTime, verifyTime          atomic.Int64
+}
+
+// ObserveEncode counts an Encode call
+func (m *MemoryMetrics) ObserveEncode(_ string, duration time.Duration, err error) {
+	m.encodes.Add(1)
+	m.encodeTime.Add(int64(duration))
+	if err != nil {
+		m.encodeErrors.Add(1)
+	}
+}
+
+// ObserveVerify counts a Verify call
+func (m *MemoryMetrics) ObserveVerify(_ string, duration time.Duration, match bool, err error) {
+	m.verifies.Add(1)
+	m.verifyTime.Add(int64(duration))
+	switch {
+	case err != nil:
+		m.verifyErrors.Add(1)
+	case match:
+		m.matches.Add(1)
+	}
+}
+
+// Snapshot returns the current counters
+func (m *MemoryMetrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Encodes:      m.encodes.Load(),
+		EncodeErrors: m.encodeErrors.Load(),
+		Verifies:     m.verifies.Load(),
+		Matches:      m.matches.Load(),
+		VerifyErrors: m.verifyErrors.Load(),
+		EncodeTime:   time.Duration(m.encodeTime.Load()),
+		VerifyTime:   time.Duration(m.verifyTime.Load()),
+	}
+}
@@ -0,0 +1,26 @@
+package passforge
+
+import (
+	"testing"
+)
+
+func TestMeteredPasswordEncoder(t *testing.T) {
+	metrics := &MemoryMetrics{}
+	encoder := NewMeteredPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), metrics)
+
+	encoded, _ := encoder.Encode("password")
+	_, _ = encoder.Verify("password", encoded)
+	_, _ = encoder.Verify("wrong", encoded)
+	_, _ = encoder.Verify("password", "not-a-hash")
+
+	snapshot := metrics.Snapshot()
+	if snapshot.Encodes != 1 || snapshot.EncodeErrors != 0 {
+		t.Errorf("Snapshot() encodes = %d/%d, want 1/0", snapshot.Encodes, snapshot.EncodeErrors)
+	}
+	if snapshot.Verifies != 3 || snapshot.Matches != 1 || snapshot.VerifyErrors != 1 {
+		t.Errorf("Snapshot() verifies = %+v", snapshot)
+	}
+	if snapshot.EncodeTime <= 0 || snapshot.VerifyTime <= 0 {
+		t.Errorf("Snapshot() durations not recorded: %+v", snapshot)
+	}
+}
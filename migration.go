@@ -0,0 +1,174 @@
+package passforge
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidSchema is returned when a migration schema is incomplete or contains unsafe identifiers
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// SQLDialect identifies the database flavor migrations are generated for
+type SQLDialect int
+
+const (
+	// DialectPostgres generates PostgreSQL migrations
+	DialectPostgres SQLDialect = iota
+	// DialectMySQL generates MySQL / MariaDB migrations
+	DialectMySQL
+	// DialectSQLite generates SQLite migrations (3.35 or newer for DROP COLUMN)
+	DialectSQLite
+)
+
+// MigrationSchema describes the target layout of the table holding encoded passwords
+type MigrationSchema struct {
+	Table              string // Table name, e.g. "users"
+	HashColumn         string // Column holding the encoded password
+	HashLength         int    // New length of the hash column, see RecommendedHashLength
+	PreviousHashLength int    // Optional previous length, used to revert the column in the down migration
+	HashNotNull        bool   // Whether the hash column is NOT NULL (MySQL requires the full column definition)
+	AlgorithmColumn    string // Optional column to add for the encoder ID
+	ParamsColumn       string // Optional column to add for encoder parameters / metadata
+}
+
+// Migration is a pair of SQL scripts applying and reverting a schema change
+type Migration struct {
+	Up   string
+	Down string
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// GenerateMigration emits the SQL scripts that widen the hash column and add the optional columns
+func GenerateMigration(dialect SQLDialect, schema MigrationSchema) (Migration, error) {
+	if err := schema.validate(); err != nil {
+		return Migration{}, err
+	}
+
+	var extra []string
+	for _, column := range []string{schema.AlgorithmColumn, schema.ParamsColumn} {
+		if column != "" {
+			extra = append(extra, column)
+		}
+	}
+
+	switch dialect {
+	case DialectPostgres:
+		return postgresMigration(schema, extra), nil
+	case DialectMySQL:
+		return mysqlMigration(schema, extra), nil
+	case DialectSQLite:
+		return sqliteMigration(schema, extra), nil
+	default:
+		return Migration{}, fmt.Errorf("unsupported SQL dialect: %d", dialect)
+	}
+}
+
+// RecommendedHashLength returns a column length able to hold the output of the given encoders
+// wrapped in the "{id}" prefix of DelegatingPasswordEncoder, rounded up to a multiple of 64 for headroom.
+func RecommendedHashLength(encoders ...PasswordEncoder) (int, error) {
+	longest := 0
+	for _, encoder := range encoders {
+		encoded, err := encoder.Encode("passforge-sample-password")
+		if err != nil {
+			return 0, err
+		}
+		if n := len(encoded) + len(encoder.Name()) + 2; n > longest {
+			longest = n
+		}
+	}
+	return (longest/64 + 1) * 64, nil
+}
+
+// validate checks required fields and rejects identifiers that would need quoting rules of their own
+func (s MigrationSchema) validate() error {
+	if s.Table == "" || s.HashColumn == "" || s.HashLength <= 0 {
+		return fmt.Errorf("%w: table, hash column and hash length are required", ErrInvalidSchema)
+	}
+	for _, identifier := range []string{s.Table, s.HashColumn, s.AlgorithmColumn, s.ParamsColumn} {
+		if identifier != "" && !sqlIdentifier.MatchString(identifier) {
+			return fmt.Errorf("%w: unsupported identifier %q", ErrInvalidSchema, identifier)
+		}
+	}
+	return nil
+}
+
+// extraColumnType returns the column type of an optional column
+func (s MigrationSchema) extraColumnType(column string) string {
+	if column == s.AlgorithmColumn {
+		return "VARCHAR(64)"
+	}
+	return "TEXT"
+}
+
+func postgresMigration(s MigrationSchema, extra []string) Migration {
+	q := func(id string) string { return `"` + id + `"` }
+	var up, down strings.Builder
+
+	up.WriteString("BEGIN;\n")
+	fmt.Fprintf(&up, "ALTER TABLE %s ALTER COLUMN %s TYPE VARCHAR(%d);\n", q(s.Table), q(s.HashColumn), s.HashLength)
+	for _, column := range extra {
+		fmt.Fprintf(&up, "ALTER TABLE %s ADD COLUMN %s %s;\n", q(s.Table), q(column), s.extraColumnType(column))
+	}
+	up.WriteString("COMMIT;\n")
+
+	down.WriteString("BEGIN;\n")
+	for i := len(extra) - 1; i >= 0; i-- {
+		fmt.Fprintf(&down, "ALTER TABLE %s DROP COLUMN %s;\n", q(s.Table), q(extra[i]))
+	}
+	if s.PreviousHashLength > 0 {
+		fmt.Fprintf(&down, "ALTER TABLE %s ALTER COLUMN %s TYPE VARCHAR(%d);\n", q(s.Table), q(s.HashColumn), s.PreviousHashLength)
+	}
+	down.WriteString("COMMIT;\n")
+
+	return Migration{Up: up.String(), Down: down.String()}
+}
+
+func mysqlMigration(s MigrationSchema, extra []string) Migration {
+	q := func(id string) string { return "`" + id + "`" }
+	hashColumn := func(length int) string {
+		definition := fmt.Sprintf("MODIFY COLUMN %s VARCHAR(%d)", q(s.HashColumn), length)
+		if s.HashNotNull {
+			definition += " NOT NULL"
+		}
+		return definition
+	}
+
+	clauses := []string{hashColumn(s.HashLength)}
+	for _, column := range extra {
+		clauses = append(clauses, fmt.Sprintf("ADD COLUMN %s %s", q(column), s.extraColumnType(column)))
+	}
+	up := fmt.Sprintf("ALTER TABLE %s\n  %s;\n", q(s.Table), strings.Join(clauses, ",\n  "))
+
+	clauses = nil
+	for i := len(extra) - 1; i >= 0; i-- {
+		clauses = append(clauses, "DROP COLUMN "+q(extra[i]))
+	}
+	if s.PreviousHashLength > 0 {
+		clauses = append(clauses, hashColumn(s.PreviousHashLength))
+	}
+	down := ""
+	if len(clauses) > 0 {
+		down = fmt.Sprintf("ALTER TABLE %s\n  %s;\n", q(s.Table), strings.Join(clauses, ",\n  "))
+	}
+
+	return Migration{Up: up, Down: down}
+}
+
+func sqliteMigration(s MigrationSchema, extra []string) Migration {
+	q := func(id string) string { return `"` + id + `"` }
+	var up, down strings.Builder
+
+	// SQLite does not enforce VARCHAR lengths, so the hash column needs no change
+	fmt.Fprintf(&up, "-- %s.%s: SQLite does not enforce VARCHAR(%d), no change required\n", s.Table, s.HashColumn, s.HashLength)
+	for _, column := range extra {
+		fmt.Fprintf(&up, "ALTER TABLE %s ADD COLUMN %s %s;\n", q(s.Table), q(column), s.extraColumnType(column))
+	}
+	for i := len(extra) - 1; i >= 0; i-- {
+		fmt.Fprintf(&down, "ALTER TABLE %s DROP COLUMN %s;\n", q(s.Table), q(extra[i]))
+	}
+
+	return Migration{Up: up.String(), Down: down.String()}
+}
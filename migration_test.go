@@ -0,0 +1,119 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateMigration(t *testing.T) {
+	schema := MigrationSchema{
+		Table:              "users",
+		HashColumn:         "password_hash",
+		HashLength:         255,
+		PreviousHashLength: 60,
+		HashNotNull:        true,
+		AlgorithmColumn:    "hash_algorithm",
+		ParamsColumn:       "hash_params",
+	}
+
+	testCases := []struct {
+		name     string
+		dialect  SQLDialect
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name:    "postgres",
+			dialect: DialectPostgres,
+			wantUp: "BEGIN;\n" +
+				"ALTER TABLE \"users\" ALTER COLUMN \"password_hash\" TYPE VARCHAR(255);\n" +
+				"ALTER TABLE \"users\" ADD COLUMN \"hash_algorithm\" VARCHAR(64);\n" +
+				"ALTER TABLE \"users\" ADD COLUMN \"hash_params\" TEXT;\n" +
+				"COMMIT;\n",
+			wantDown: "BEGIN;\n" +
+				"ALTER TABLE \"users\" DROP COLUMN \"hash_params\";\n" +
+				"ALTER TABLE \"users\" DROP COLUMN \"hash_algorithm\";\n" +
+				"ALTER TABLE \"users\" ALTER COLUMN \"password_hash\" TYPE VARCHAR(60);\n" +
+				"COMMIT;\n",
+		},
+		{
+			name:    "mysql",
+			dialect: DialectMySQL,
+			wantUp: "ALTER TABLE `users`\n" +
+				"  MODIFY COLUMN `password_hash` VARCHAR(255) NOT NULL,\n" +
+				"  ADD COLUMN `hash_algorithm` VARCHAR(64),\n" +
+				"  ADD COLUMN `hash_params` TEXT;\n",
+			wantDown: "ALTER TABLE `users`\n" +
+				"  DROP COLUMN `hash_params`,\n" +
+				"  DROP COLUMN `hash_algorithm`,\n" +
+				"  MODIFY COLUMN `password_hash` VARCHAR(60) NOT NULL;\n",
+		},
+		{
+			name:    "sqlite",
+			dialect: DialectSQLite,
+			wantUp: "-- users.password_hash: SQLite does not enforce VARCHAR(255), no change required\n" +
+				"ALTER TABLE \"users\" ADD COLUMN \"hash_algorithm\" VARCHAR(64);\n" +
+				"ALTER TABLE \"users\" ADD COLUMN \"hash_params\" TEXT;\n",
+			wantDown: "ALTER TABLE \"users\" DROP COLUMN \"hash_params\";\n" +
+				"ALTER TABLE \"users\" DROP COLUMN \"hash_algorithm\";\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migration, err := GenerateMigration(tc.dialect, schema)
+			if err != nil {
+				t.Fatalf("GenerateMigration() error = %v", err)
+			}
+
+			if migration.Up != tc.wantUp {
+				t.Errorf("GenerateMigration() up =\n%v\nwant\n%v", migration.Up, tc.wantUp)
+			}
+
+			if migration.Down != tc.wantDown {
+				t.Errorf("GenerateMigration() down =\n%v\nwant\n%v", migration.Down, tc.wantDown)
+			}
+		})
+	}
+}
+
+func TestGenerateMigration_InvalidSchema(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema MigrationSchema
+	}{
+		{
+			name:   "missing table",
+			schema: MigrationSchema{HashColumn: "password_hash", HashLength: 255},
+		},
+		{
+			name:   "missing length",
+			schema: MigrationSchema{Table: "users", HashColumn: "password_hash"},
+		},
+		{
+			name:   "unsafe identifier",
+			schema: MigrationSchema{Table: "users; DROP TABLE users", HashColumn: "password_hash", HashLength: 255},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := GenerateMigration(DialectPostgres, tc.schema)
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Errorf("GenerateMigration() error = %v, want ErrInvalidSchema", err)
+			}
+		})
+	}
+}
+
+func TestRecommendedHashLength(t *testing.T) {
+	length, err := RecommendedHashLength(NewBcryptPasswordEncoder(WithCost(4)), NewNoOpPasswordEncoder())
+	if err != nil {
+		t.Fatalf("RecommendedHashLength() error = %v", err)
+	}
+
+	// "{bcrypt}" + 60 character bcrypt hash = 68, rounded up to the next multiple of 64
+	if length != 128 {
+		t.Errorf("RecommendedHashLength() = %v, want 128", length)
+	}
+}
@@ -0,0 +1,155 @@
+package passforge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ModularCryptEncoder is a password encoder for the modular crypt format hashes passlib emits, $IDENT$...,
+// dispatching each hash to the passforge encoder of its identifier: pbkdf2, pbkdf2-sha256 and pbkdf2-sha512,
+// 5 and 6 (sha256_crypt and sha512_crypt), 2a, 2b and 2y (bcrypt), bcrypt-sha256, argon2i, argon2d and
+// argon2id, scrypt, and P and H (phpass). Hashes exported from a Python application verify without
+// configuration; new passwords are encoded with the default scheme, in a format passlib reads back.
+type ModularCryptEncoder struct {
+	DefaultIdent string                     // Identifier of the scheme encoding new passwords
+	Schemes      map[string]PasswordEncoder // Encoders keyed by identifier, e.g. "pbkdf2-sha256"
+}
+
+// ModularCryptOption is a functional option used to configure a ModularCryptEncoder instance.
+type ModularCryptOption func(*ModularCryptEncoder)
+
+// WithModularCryptDefault sets the identifier of the scheme encoding new passwords
+// Default: "argon2id"
+func WithModularCryptDefault(ident string) ModularCryptOption {
+	return func(m *ModularCryptEncoder) {
+		m.DefaultIdent = ident
+	}
+}
+
+// WithModularCryptScheme adds an encoder for an identifier, or replaces the built-in one, e.g. to encode
+// new hashes with other parameters
+func WithModularCryptScheme(ident string, encoder PasswordEncoder) ModularCryptOption {
+	return func(m *ModularCryptEncoder) {
+		m.Schemes[ident] = encoder
+	}
+}
+
+// NewModularCryptEncoder creates a new ModularCryptEncoder with default parameters if not specified
+func NewModularCryptEncoder(opts ...ModularCryptOption) *ModularCryptEncoder {
+	bcryptEncoder := NewBcryptPasswordEncoder(WithBcryptVariant(Bcrypt2b))
+	phpassEncoder := NewPhpassPasswordEncoder()
+	encoder := &ModularCryptEncoder{
+		DefaultIdent: "argon2id",
+		Schemes: map[string]PasswordEncoder{
+			"pbkdf2": NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Hash("sha1"),
+				WithPBKDF2Iterations(131000), WithPBKDF2MinIterations(131000)),
+			"pbkdf2-sha256": NewPBKDF2PasswordEncoder(WithPBKDF2Passlib()),
+			"pbkdf2-sha512": NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Hash("sha512"),
+				WithPBKDF2Iterations(25000), WithPBKDF2MinIterations(25000)),
+			"5":             NewSha256CryptPasswordEncoder(),
+			"6":             NewSha512CryptPasswordEncoder(),
+			"2a":            bcryptEncoder,
+			"2b":            bcryptEncoder,
+			"2y":            bcryptEncoder,
+			"bcrypt-sha256": NewBcryptSHA256PasswordEncoder(),
+			"argon2i":       NewArgon2PasswordEncoder(WithArgon2PHC(), WithArgon2Variant(Argon2i)),
+			"argon2d":       NewArgon2PasswordEncoder(WithArgon2PHC(), WithArgon2Variant(Argon2d)),
+			"argon2id":      NewArgon2PasswordEncoder(WithArgon2PHC()),
+			"scrypt":        NewScryptPasswordEncoder(WithScryptFormat(ScryptPHC)),
+			"P":             phpassEncoder,
+			"H":             phpassEncoder,
+		},
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with the encoder of the default identifier
+func (m *ModularCryptEncoder) Encode(rawPassword string) (string, error) {
+	encoder, ok := m.Schemes[m.DefaultIdent]
+	if !ok {
+		return "", fmt.Errorf("modular-crypt: unknown default scheme %q", m.DefaultIdent)
+	}
+	return encoder.Encode(rawPassword)
+}
+
+// Verify checks if the raw password matches the encoded password with the encoder of its identifier
+func (m *ModularCryptEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return m.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password with the encoder of its identifier, passing ctx to it
+func (m *ModularCryptEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	encoder, err := m.scheme(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	return verifyContext(ctx, encoder, rawPassword, encodedPassword)
+}
+
+// ValidateEncoded checks the encoded password with the encoder of its identifier without verifying it
+func (m *ModularCryptEncoder) ValidateEncoded(encodedPassword string) error {
+	encoder, err := m.scheme(encodedPassword)
+	if err != nil {
+		return err
+	}
+	return ValidateEncoded(encoder, encodedPassword)
+}
+
+// AdminStatus reports the default identifier and every scheme by identifier
+func (m *ModularCryptEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	status := EncoderStatus{Default: m.DefaultIdent, Encoders: make(map[string]EncoderStatus, len(m.Schemes))}
+	for ident, inner := range m.Schemes {
+		status.Encoders[ident] = describe(inner)
+	}
+	return status
+}
+
+// Name returns the name of the encoder.
+func (m *ModularCryptEncoder) Name() string {
+	return "modular-crypt"
+}
+
+// NeedsRehash reports whether the encoded password was produced by a scheme other than the default one
+func (m *ModularCryptEncoder) NeedsRehash(encodedPassword string) bool {
+	return modularCryptIdent(encodedPassword) != m.DefaultIdent
+}
+
+// UpgradeEncoding reports whether the encoded password was produced by a scheme other than the default one,
+// or the default scheme's encoder reports it as weaker than its configuration
+func (m *ModularCryptEncoder) UpgradeEncoding(encodedPassword string) bool {
+	if m.NeedsRehash(encodedPassword) {
+		return true
+	}
+	encoder, ok := m.Schemes[m.DefaultIdent]
+	return ok && UpgradeEncoding(encoder, encodedPassword)
+}
+
+// scheme returns the encoder of the encoded password's identifier
+func (m *ModularCryptEncoder) scheme(encodedPassword string) (PasswordEncoder, error) {
+	ident := modularCryptIdent(encodedPassword)
+	if ident == "" {
+		return nil, newFormatError("modular-crypt", "invalid encoded password format", encodedPassword)
+	}
+	encoder, ok := m.Schemes[ident]
+	if !ok {
+		return nil, newFormatError("modular-crypt", "unsupported scheme", encodedPassword)
+	}
+	return encoder, nil
+}
+
+// modularCryptIdent returns the IDENT of $IDENT$..., or "" when the encoded password has none
+func modularCryptIdent(encodedPassword string) string {
+	rest, ok := strings.CutPrefix(encodedPassword, "$")
+	if !ok {
+		return ""
+	}
+	ident, _, found := strings.Cut(rest, "$")
+	if !found {
+		return ""
+	}
+	return ident
+}
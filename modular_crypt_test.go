@@ -0,0 +1,97 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestModularCryptEncoder_Verify(t *testing.T) {
+	// Hashes in the formats of passlib's pbkdf2_sha256, pbkdf2_sha512, sha512_crypt, bcrypt, argon2, scrypt and phpass
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"pbkdf2_sha256", "password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", true, false},
+		{"pbkdf2_sha512", "password", "$pbkdf2-sha512$25000$AAECAwQFBgcICQoLDA0ODw$EIJTJci4GjJFueYP2IMIxGIhpWd96facmk2yGdjyFsEUE2PrPNQnrnUVT5Ch.GNpbgjHYeabQn2L9uP6DGJOVw", true, false},
+		{"sha512_crypt", "password", "$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0", true, false},
+		{"bcrypt", "password", "$2y$05$abcdefghijklmnopqrstuuWG29KuyeAicPCJODk1zjyGvyQUU2awu", true, false},
+		{"argon2", "password", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", true, false},
+		{"scrypt", "password", "$scrypt$ln=10,r=8,p=1$AAECAwQFBgcICQoLDA0ODw$OnwHgqTb31Q6zXxSL+hT2bNKu4ryelxll0iM3yKBQLU", true, false},
+		{"phpass", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", true, false},
+		{"wrong password", "Password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", false, false},
+		{"md5_crypt", "password", "$1$saltsalt$qjXMvbEw8oaL.CzflDugX/", false, true},
+		{"no identifier", "password", "iterations=10000,keyLen=32,hashFunc=sha256$c2FsdA==$aGFzaA==", false, true},
+		{"empty", "password", "", false, true},
+	}
+	encoder := NewModularCryptEncoder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("Verify() error = %v, want ErrInvalidFormat", err)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestModularCryptEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		ident      string
+		wantPrefix string
+	}{
+		{"argon2id", "$argon2id$v=19$"},
+		{"pbkdf2-sha256", "$pbkdf2-sha256$29000$"},
+		{"pbkdf2-sha512", "$pbkdf2-sha512$25000$"},
+		{"6", "$6$rounds=656000$"},
+		{"scrypt", "$scrypt$ln="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ident, func(t *testing.T) {
+			encoder := NewModularCryptEncoder(WithModularCryptDefault(tt.ident),
+				WithModularCryptScheme("argon2id", NewArgon2PasswordEncoder(WithArgon2PHC(), WithArgon2Memory(1024))))
+			encoded, err := encoder.Encode("password")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.wantPrefix) {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.wantPrefix)
+			}
+			if match, err := encoder.Verify("password", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+			if err := encoder.ValidateEncoded(encoded); err != nil {
+				t.Errorf("ValidateEncoded() error = %v", err)
+			}
+			if encoder.NeedsRehash(encoded) {
+				t.Errorf("NeedsRehash(%v) = true, want false", encoded)
+			}
+		})
+	}
+
+	encoder := NewModularCryptEncoder()
+	if !encoder.NeedsRehash("$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c") {
+		t.Error("NeedsRehash() of a pbkdf2_sha256 hash = false, want true")
+	}
+	if _, err := NewModularCryptEncoder(WithModularCryptDefault("1")).Encode("password"); err == nil {
+		t.Error("Encode() with an unknown default scheme should fail")
+	}
+	if _, err := NewModularCryptEncoder(WithModularCryptDefault("P")).Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() with phpass error = %v, want ErrEncodeNotSupported", err)
+	}
+}
+
+func TestModularCryptEncoder_Name(t *testing.T) {
+	if name := NewModularCryptEncoder().Name(); name != "modular-crypt" {
+		t.Errorf("Name() = %v, want modular-crypt", name)
+	}
+}
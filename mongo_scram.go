@@ -0,0 +1,102 @@
+package passforge
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// MongoSCRAMCredential is a MongoDB SCRAM credential, the value of credentials.SCRAM-SHA-1 or
+// credentials.SCRAM-SHA-256 in a user document of admin.system.users
+type MongoSCRAMCredential struct {
+	IterationCount int
+	Salt           []byte
+	StoredKey      []byte
+	ServerKey      []byte
+	// SaltedPassword is the key both others are derived from. MongoDB doesn't store it and it lets anyone
+	// authenticate as the user, so never persist or log it.
+	SaltedPassword []byte
+}
+
+// MongoSCRAMOption is a functional option used to configure the derivation of a MongoSCRAMCredential
+type MongoSCRAMOption func(*mongoSCRAMConfig)
+
+// mongoSCRAMConfig holds the settings of a derivation
+type mongoSCRAMConfig struct {
+	iterations int
+	rand       io.Reader
+}
+
+// WithMongoSCRAMIterations sets the iteration count
+// Default: 10000 for SCRAM-SHA-1 and 15000 for SCRAM-SHA-256, MongoDB's scramIterationCount and scramSHA256IterationCount
+func WithMongoSCRAMIterations(iterations int) MongoSCRAMOption {
+	return func(c *mongoSCRAMConfig) {
+		c.iterations = iterations
+	}
+}
+
+// WithMongoSCRAMRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithMongoSCRAMRand(r io.Reader) MongoSCRAMOption {
+	return func(c *mongoSCRAMConfig) {
+		c.rand = r
+	}
+}
+
+// NewMongoSCRAMSHA1Credential derives the SCRAM-SHA-1 credential of a MongoDB user. As in MongoDB, the
+// password is first digested as hex(MD5(username:mongo:password)), so the credential depends on the username.
+func NewMongoSCRAMSHA1Credential(username, password string, opts ...MongoSCRAMOption) (*MongoSCRAMCredential, error) {
+	digest := md5.Sum([]byte(username + ":mongo:" + password))
+	return newMongoSCRAMCredential(sha1.New, hex.EncodeToString(digest[:]), 10000, opts)
+}
+
+// NewMongoSCRAMSHA256Credential derives the SCRAM-SHA-256 credential of a MongoDB user. Passwords are used
+// as is: MongoDB normalizes them with SASLprep first, which only changes passwords containing non-ASCII
+// spaces, ignorable or compatibility characters.
+func NewMongoSCRAMSHA256Credential(password string, opts ...MongoSCRAMOption) (*MongoSCRAMCredential, error) {
+	return newMongoSCRAMCredential(sha256.New, password, 15000, opts)
+}
+
+// Document returns the credential in the shape MongoDB stores it, with base64 salt and keys
+func (c *MongoSCRAMCredential) Document() map[string]interface{} {
+	return map[string]interface{}{
+		"iterationCount": c.IterationCount,
+		"salt":           base64.StdEncoding.EncodeToString(c.Salt),
+		"storedKey":      base64.StdEncoding.EncodeToString(c.StoredKey),
+		"serverKey":      base64.StdEncoding.EncodeToString(c.ServerKey),
+	}
+}
+
+// newMongoSCRAMCredential derives a credential with a random salt four bytes shorter than the hash, as MongoDB does
+func newMongoSCRAMCredential(newHash func() hash.Hash, password string, iterations int, opts []MongoSCRAMOption) (*MongoSCRAMCredential, error) {
+	config := &mongoSCRAMConfig{iterations: iterations}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.iterations < 1 {
+		return nil, fmt.Errorf("scram: iterations must be positive")
+	}
+
+	salt := make([]byte, newHash().Size()-4)
+	if _, err := io.ReadFull(randReader(config.rand), salt); err != nil {
+		return nil, err
+	}
+	saltedPassword, err := pbkdf2Key(password, salt, config.iterations, newHash().Size(), newHash)
+	if err != nil {
+		return nil, err
+	}
+	storedKey, serverKey := scramKeysFromSaltedPassword(newHash, saltedPassword)
+	return &MongoSCRAMCredential{
+		IterationCount: config.iterations,
+		Salt:           salt,
+		StoredKey:      storedKey,
+		ServerKey:      serverKey,
+		SaltedPassword: saltedPassword,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package passforge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewMongoSCRAMCredential(t *testing.T) {
+	salt := make([]byte, 32)
+	for i := range salt {
+		salt[i] = byte(i)
+	}
+
+	// Computed with Python's hashlib and hmac following MongoDB's derivation
+	testCases := []struct {
+		name           string
+		derive         func() (*MongoSCRAMCredential, error)
+		wantIterations int
+		wantSalt       []byte
+		wantSalted     string
+		wantStoredKey  string
+		wantServerKey  string
+	}{
+		{
+			name: "SCRAM-SHA-1",
+			derive: func() (*MongoSCRAMCredential, error) {
+				return NewMongoSCRAMSHA1Credential("user", "pencil", WithMongoSCRAMRand(bytes.NewReader(salt)))
+			},
+			wantIterations: 10000,
+			wantSalt:       salt[:16],
+			wantSalted:     "gO9WCNp5KIkGg8S2hUt6aWm7j0Y=",
+			wantStoredKey:  "zA4YK81qMkHG7YzinWOF3n9T9ec=",
+			wantServerKey:  "DywqQj4B1Ch5MU2PjvT00Qu6EjU=",
+		},
+		{
+			name: "SCRAM-SHA-256",
+			derive: func() (*MongoSCRAMCredential, error) {
+				return NewMongoSCRAMSHA256Credential("pencil", WithMongoSCRAMRand(bytes.NewReader(salt)))
+			},
+			wantIterations: 15000,
+			wantSalt:       salt[:28],
+			wantSalted:     "ndP267dtll7AxEpXlguSapYca3xxiMUjKvaDcOS2xEU=",
+			wantStoredKey:  "nZbtY9VzVP9yYKIVwzBEMz4ZaM7isQFV5bLB3Gbk7qU=",
+			wantServerKey:  "TUlTs98yxOG0rskHccVTbZOKJm4PWRjsAz7F1H6Rgyg=",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credential, err := tc.derive()
+			if err != nil {
+				t.Fatalf("derive error = %v", err)
+			}
+			if credential.IterationCount != tc.wantIterations || !bytes.Equal(credential.Salt, tc.wantSalt) {
+				t.Errorf("credential = %d, %x, want %d, %x", credential.IterationCount, credential.Salt, tc.wantIterations, tc.wantSalt)
+			}
+			if got := base64.StdEncoding.EncodeToString(credential.SaltedPassword); got != tc.wantSalted {
+				t.Errorf("SaltedPassword = %v, want %v", got, tc.wantSalted)
+			}
+			document := credential.Document()
+			if document["storedKey"] != tc.wantStoredKey || document["serverKey"] != tc.wantServerKey {
+				t.Errorf("Document() = %v, want storedKey %v and serverKey %v", document, tc.wantStoredKey, tc.wantServerKey)
+			}
+		})
+	}
+}
+
+func TestNewMongoSCRAMCredential_Options(t *testing.T) {
+	credential, err := NewMongoSCRAMSHA256Credential("pencil", WithMongoSCRAMIterations(4096))
+	if err != nil {
+		t.Fatalf("NewMongoSCRAMSHA256Credential() error = %v", err)
+	}
+	if credential.IterationCount != 4096 {
+		t.Errorf("IterationCount = %v, want 4096", credential.IterationCount)
+	}
+	if _, err := NewMongoSCRAMSHA1Credential("user", "pencil", WithMongoSCRAMIterations(0)); err == nil {
+		t.Errorf("NewMongoSCRAMSHA1Credential() with 0 iterations expected an error")
+	}
+}
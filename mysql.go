@@ -0,0 +1,74 @@
+package passforge
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// MySQLPasswordEncoder is a verify-only password encoder for the hashes of MySQL 4.1's PASSWORD() function,
+// "*" followed by the uppercase hex SHA-1 digest of the SHA-1 digest of the password, as stored by older
+// PHP applications. The hashes are unsalted; the encoder only exists to rehash imported users on their
+// first login, and every successful verification is reported to the weak algorithm hook.
+type MySQLPasswordEncoder struct{}
+
+// NewMySQLPasswordEncoder creates a new MySQLPasswordEncoder
+func NewMySQLPasswordEncoder() *MySQLPasswordEncoder {
+	return &MySQLPasswordEncoder{}
+}
+
+// Encode is not supported: MySQL PASSWORD() hashes must not be produced for new passwords
+func (m *MySQLPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (m *MySQLPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseMySQL(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	first := sha1.Sum([]byte(rawPassword))
+	second := sha1.Sum(first[:])
+	if subtle.ConstantTimeCompare(stored, second[:]) != 1 {
+		return false, nil
+	}
+	notifyWeak(m.Name(), "unsalted double sha1 hash")
+	return true, nil
+}
+
+// ValidateEncoded rejects every MySQL hash once it is well-formed, as they are unsalted
+func (m *MySQLPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, err := parseMySQL(encodedPassword); err != nil {
+		return err
+	}
+	return newFormatError("mysql", "unsalted hash", encodedPassword)
+}
+
+func init() {
+	RegisterHashFormat(&MySQLPasswordEncoder{})
+}
+
+// Identify reports whether the encoded password is a mysql_native_password hash
+func (m *MySQLPasswordEncoder) Identify(encodedPassword string) bool {
+	return len(encodedPassword) == 41 && encodedPassword[0] == '*'
+}
+
+// Name returns the name of the encoder.
+func (m *MySQLPasswordEncoder) Name() string {
+	return "mysql"
+}
+
+// parseMySQL decodes a hash of the form *HEX. MySQL writes uppercase digits; lowercase ones are accepted.
+func parseMySQL(encodedPassword string) ([]byte, error) {
+	value, found := strings.CutPrefix(encodedPassword, "*")
+	if !found {
+		return nil, newFormatError("mysql", "invalid encoded password format", encodedPassword)
+	}
+	stored, err := hex.DecodeString(value)
+	if err != nil || len(stored) != sha1.Size {
+		return nil, newFormatError("mysql", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
@@ -0,0 +1,63 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMySQLPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewMySQLPasswordEncoder()
+
+	// Hashes as returned by MySQL's PASSWORD() function
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"password", "password", "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", true, false},
+		{"non-ascii password", "été", "*2D008F04D2AD0916292B17D78838895DCBE0FC32", true, false},
+		{"lowercase hex", "secret", "*14e65567abdb5135d0cfd9a70b3032c179a49ee7", true, false},
+		{"wrong password", "Password", "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", false, false},
+		{"missing star", "password", "2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", false, true},
+		{"pre-4.1 hash", "password", "5d2e19393cc5ef67", false, true},
+		{"empty", "", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewMySQLPasswordEncoder()
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	if match, _ := encoder.Verify("password", "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"); !match || len(flagged) != 1 || flagged[0].Algorithm != "mysql" {
+		t.Errorf("Verify() = %v, flagged %v, want one mysql event", match, flagged)
+	}
+	if err := encoder.ValidateEncoded("*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestMySQLPasswordEncoder_Name(t *testing.T) {
+	if name := NewMySQLPasswordEncoder().Name(); name != "mysql" {
+		t.Errorf("Name() = %v, want mysql", name)
+	}
+}
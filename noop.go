@@ -1,5 +1,7 @@
 package passforge
 
+import "io"
+
 // NoOpPasswordEncoder is a password encoder that does not perform any encoding
 // It's useful for testing and development purposes only and should not be used in production
 type NoOpPasswordEncoder struct{}
@@ -17,7 +19,17 @@ func (n *NoOpPasswordEncoder) Encode(rawPassword string) (string, error) {
 // Verify checks if the raw password matches the encoded password
 // Since NoOpPasswordEncoder doesn't perform any encoding, it just compares the strings directly
 func (n *NoOpPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
-	return rawPassword == encodedPassword, nil
+	if rawPassword != encodedPassword {
+		return false, nil
+	}
+	notifyWeak(n.Name(), "password stored in plain text")
+	return true, nil
+}
+
+func init() {
+	RegisterTestVectors("noop", true, func(io.Reader) PasswordEncoder {
+		return NewNoOpPasswordEncoder()
+	})
 }
 
 // Name returns the name of the encoder.
@@ -0,0 +1,49 @@
+package passforge
+
+import (
+	"context"
+)
+
+// NormalizingPasswordEncoder applies a normalization function to raw passwords before encoding and
+// verifying them, typically Unicode NFKC (norm.NFKC.String from golang.org/x/text) so that the same
+// password typed on different keyboards produces the same hash.
+// Changing the function invalidates existing hashes of passwords it alters.
+type NormalizingPasswordEncoder struct {
+	Encoder   PasswordEncoder
+	Normalize func(string) string
+}
+
+// NewNormalizingPasswordEncoder creates a NormalizingPasswordEncoder
+func NewNormalizingPasswordEncoder(encoder PasswordEncoder, normalize func(string) string) *NormalizingPasswordEncoder {
+	return &NormalizingPasswordEncoder{Encoder: encoder, Normalize: normalize}
+}
+
+// Encode encodes the normalized password
+func (n *NormalizingPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return n.Encoder.Encode(n.Normalize(rawPassword))
+}
+
+// Verify verifies the normalized password
+func (n *NormalizingPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return n.Encoder.Verify(n.Normalize(rawPassword), encodedPassword)
+}
+
+// VerifyContext verifies the normalized password, passing ctx to the wrapped encoder
+func (n *NormalizingPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	return verifyContext(ctx, n.Encoder, n.Normalize(rawPassword), encodedPassword)
+}
+
+// ValidateEncoded validates the encoded password with the wrapped encoder
+func (n *NormalizingPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	return ValidateEncoded(n.Encoder, encodedPassword)
+}
+
+// AdminStatus reports the wrapped encoder
+func (n *NormalizingPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Encoders: map[string]EncoderStatus{"inner": describe(n.Encoder)}}
+}
+
+// Name returns the name of the wrapped encoder.
+func (n *NormalizingPasswordEncoder) Name() string {
+	return n.Encoder.Name()
+}
@@ -0,0 +1,29 @@
+package passforge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizingPasswordEncoder(t *testing.T) {
+	encoder := NewNormalizingPasswordEncoder(NewNoOpPasswordEncoder(), strings.TrimSpace)
+
+	encoded, _ := encoder.Encode(" password ")
+	if encoded != "password" {
+		t.Errorf("Encode() got = %q, want normalized password", encoded)
+	}
+
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"password", true},
+		{"\tpassword\n", true},
+		{"pass word", false},
+	}
+	for _, tt := range tests {
+		if match, _ := encoder.Verify(tt.raw, encoded); match != tt.want {
+			t.Errorf("Verify(%q) = %v, want %v", tt.raw, match, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package passforge
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLMPasswordEncoder is a verify-only password encoder for NT hashes, the MD4 digest of the UTF-16LE
+// password as found in Active Directory and Samba dumps. The hash is 32 hex digits, optionally prefixed
+// with $NT$. NT hashes are unsalted and fast to compute; the encoder only exists to migrate imported
+// accounts, and every successful verification is reported to the weak algorithm hook.
+type NTLMPasswordEncoder struct{}
+
+// NewNTLMPasswordEncoder creates a new NTLMPasswordEncoder
+func NewNTLMPasswordEncoder() *NTLMPasswordEncoder {
+	return &NTLMPasswordEncoder{}
+}
+
+// Encode is not supported: NT hashes must not be produced for new passwords
+func (n *NTLMPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded NT hash
+func (n *NTLMPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseNTLM(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if subtle.ConstantTimeCompare(stored, ntHash(rawPassword)) != 1 {
+		return false, nil
+	}
+	notifyWeak(n.Name(), "unsalted md4 hash")
+	return true, nil
+}
+
+// ValidateEncoded rejects every NT hash once it is well-formed, as they are unsalted
+func (n *NTLMPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, err := parseNTLM(encodedPassword); err != nil {
+		return err
+	}
+	return newFormatError("ntlm", "unsalted hash", encodedPassword)
+}
+
+func init() {
+	RegisterHashFormat(&NTLMPasswordEncoder{})
+}
+
+// Identify reports whether the encoded password is an NT hash
+func (n *NTLMPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$NT$")
+}
+
+// Name returns the name of the encoder.
+func (n *NTLMPasswordEncoder) Name() string {
+	return "ntlm"
+}
+
+// parseNTLM decodes an NT hash of the form [$NT$]HEX, accepting either case
+func parseNTLM(encodedPassword string) ([]byte, error) {
+	value := strings.TrimPrefix(encodedPassword, "$NT$")
+	stored, err := hex.DecodeString(value)
+	if err != nil || len(stored) != md4.Size {
+		return nil, newFormatError("ntlm", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// ntHash returns the MD4 digest of the UTF-16LE encoding of the password
+func ntHash(rawPassword string) []byte {
+	units := utf16.Encode([]rune(rawPassword))
+	encoded := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		encoded = append(encoded, byte(u), byte(u>>8))
+	}
+	h := md4.New()
+	h.Write(encoded)
+	return h.Sum(nil)
+}
@@ -0,0 +1,62 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNTLMPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewNTLMPasswordEncoder()
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"password", "password", "8846f7eaee8fb117ad06bdd830b7586c", true, false},
+		{"uppercase hex", "password", "8846F7EAEE8FB117AD06BDD830B7586C", true, false},
+		{"john prefix", "password", "$NT$8846f7eaee8fb117ad06bdd830b7586c", true, false},
+		{"empty password", "", "31d6cfe0d16ae931b73c59d7e0c089c0", true, false},
+		{"wrong password", "Password", "8846f7eaee8fb117ad06bdd830b7586c", false, false},
+		{"truncated hash", "password", "8846f7eaee8fb117ad06bdd830b7586", false, true},
+		{"lm:nt pair", "password", "aad3b435b51404eeaad3b435b51404ee:8846f7eaee8fb117ad06bdd830b7586c", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestNTLMPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewNTLMPasswordEncoder()
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	if match, _ := encoder.Verify("password", "8846f7eaee8fb117ad06bdd830b7586c"); !match || len(flagged) != 1 || flagged[0].Algorithm != "ntlm" {
+		t.Errorf("Verify() = %v, flagged %v, want one ntlm event", match, flagged)
+	}
+	if err := encoder.ValidateEncoded("8846f7eaee8fb117ad06bdd830b7586c"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestNTLMPasswordEncoder_Name(t *testing.T) {
+	if name := NewNTLMPasswordEncoder().Name(); name != "ntlm" {
+		t.Errorf("Name() = %v, want ntlm", name)
+	}
+}
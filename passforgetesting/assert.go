@@ -0,0 +1,54 @@
+package passforgetesting
+
+import (
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+// AssertVerifies fails the test unless encoder.Verify accepts the raw password
+func AssertVerifies(t testing.TB, encoder passforge.PasswordEncoder, rawPassword, encodedPassword string) {
+	t.Helper()
+	match, err := encoder.Verify(rawPassword, encodedPassword)
+	if err != nil {
+		t.Errorf("%s: Verify() error = %v", encoder.Name(), err)
+		return
+	}
+	if !match {
+		t.Errorf("%s: Verify() rejected the password, want match", encoder.Name())
+	}
+}
+
+// AssertRejects fails the test unless encoder.Verify cleanly rejects the raw password
+func AssertRejects(t testing.TB, encoder passforge.PasswordEncoder, rawPassword, encodedPassword string) {
+	t.Helper()
+	match, err := encoder.Verify(rawPassword, encodedPassword)
+	if err != nil {
+		t.Errorf("%s: Verify() error = %v", encoder.Name(), err)
+		return
+	}
+	if match {
+		t.Errorf("%s: Verify() accepted the password, want mismatch", encoder.Name())
+	}
+}
+
+// AssertRoundTrip encodes the raw password and checks that it verifies while a different password does not.
+// It returns the encoded password for further assertions.
+func AssertRoundTrip(t testing.TB, encoder passforge.PasswordEncoder, rawPassword string) string {
+	t.Helper()
+	encoded, err := encoder.Encode(rawPassword)
+	if err != nil {
+		t.Fatalf("%s: Encode() error = %v", encoder.Name(), err)
+	}
+	AssertVerifies(t, encoder, rawPassword, encoded)
+	AssertRejects(t, encoder, rawPassword+"-wrong", encoded)
+	return encoded
+}
+
+// AssertCalled fails the test unless the mock recorded exactly n calls of the method
+func AssertCalled(t testing.TB, mock *MockEncoder, method string, n int) {
+	t.Helper()
+	if got := mock.CallCount(method); got != n {
+		t.Errorf("%s called %d times, want %d", method, got, n)
+	}
+}
@@ -0,0 +1,164 @@
+package passforgetesting
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+// ConformanceOption configures RunConformanceTests
+type ConformanceOption func(*conformanceConfig)
+
+type conformanceConfig struct {
+	deterministic bool
+	passwords     []string
+	goroutines    int
+}
+
+// AllowDeterministic skips the salt uniqueness check for encoders that intentionally produce the same
+// output for the same password, such as NoOpPasswordEncoder or FakeEncoder
+func AllowDeterministic() ConformanceOption {
+	return func(c *conformanceConfig) {
+		c.deterministic = true
+	}
+}
+
+// WithConformancePasswords replaces the passwords used for the round-trip checks
+func WithConformancePasswords(passwords ...string) ConformanceOption {
+	return func(c *conformanceConfig) {
+		c.passwords = passwords
+	}
+}
+
+// WithConformanceGoroutines sets how many goroutines the concurrency check runs
+// Default: 8
+func WithConformanceGoroutines(n int) ConformanceOption {
+	return func(c *conformanceConfig) {
+		c.goroutines = n
+	}
+}
+
+// RunConformanceTests checks that encoder honours the passforge.PasswordEncoder contract:
+//   - Encode/Verify round-trip and a different password is rejected
+//   - encoding the same password twice yields different outputs (salt uniqueness)
+//   - malformed input never panics, never matches and gives the same result every time
+//   - Name is non-empty, stable and usable as a DelegatingPasswordEncoder "{id}"
+//   - Encode and Verify are safe for concurrent use
+//
+// Configure the encoder with cheap parameters; every check runs as a subtest.
+func RunConformanceTests(t *testing.T, encoder passforge.PasswordEncoder, opts ...ConformanceOption) {
+	t.Helper()
+	config := &conformanceConfig{
+		passwords:  []string{"password123", "", "pässwörd-ünïcødé", "p@$$w0rd!{}", strings.Repeat("long", 16)},
+		goroutines: 8,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, password := range config.passwords {
+			AssertRoundTrip(t, encoder, password)
+		}
+	})
+
+	t.Run("SaltUniqueness", func(t *testing.T) {
+		if config.deterministic {
+			t.Skip("encoder is deterministic")
+		}
+		first, _ := encoder.Encode("password123")
+		second, _ := encoder.Encode("password123")
+		if first == second {
+			t.Errorf("%s: Encode() returned the same output twice, want a fresh salt per call", encoder.Name())
+		}
+	})
+
+	t.Run("MalformedInput", func(t *testing.T) {
+		valid, err := encoder.Encode("password123")
+		if err != nil {
+			t.Fatalf("%s: Encode() error = %v", encoder.Name(), err)
+		}
+		for _, malformed := range MalformedInputs(valid) {
+			first, firstErr, panicked := verifyNoPanic(encoder, "password123", malformed)
+			if panicked {
+				t.Errorf("%s: Verify() panicked on malformed input %q: %v", encoder.Name(), malformed, firstErr)
+				continue
+			}
+			second, secondErr, _ := verifyNoPanic(encoder, "password123", malformed)
+			if first {
+				t.Errorf("%s: Verify() matched malformed input %q", encoder.Name(), malformed)
+			}
+			if first != second || fmt.Sprint(firstErr) != fmt.Sprint(secondErr) {
+				t.Errorf("%s: Verify() of malformed input %q is not stable: (%v, %v) then (%v, %v)",
+					encoder.Name(), malformed, first, firstErr, second, secondErr)
+			}
+		}
+	})
+
+	t.Run("NameStable", func(t *testing.T) {
+		name := encoder.Name()
+		if name == "" || strings.ContainsAny(name, "{}") {
+			t.Errorf("Name() = %q, want a non-empty name without braces", name)
+		}
+		if again := encoder.Name(); again != name {
+			t.Errorf("Name() changed from %q to %q", name, again)
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < config.goroutines; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				password := fmt.Sprintf("password-%d", i)
+				encoded, err := encoder.Encode(password)
+				if err != nil {
+					t.Errorf("%s: Encode() error = %v", encoder.Name(), err)
+					return
+				}
+				if match, err := encoder.Verify(password, encoded); err != nil || !match {
+					t.Errorf("%s: concurrent Verify() = %v, %v, want true, nil", encoder.Name(), match, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+// MalformedInputs returns generic garbage plus damaged variants (truncated, altered, extra separators) of a
+// valid encoded password. It is the seed corpus used by RunConformanceTests and FuzzVerify.
+func MalformedInputs(valid string) []string {
+	inputs := []string{"", "$", "$$", "$$$", "{", "}", "{}", "\x00", "not-a-hash", strings.Repeat("$", 100)}
+	for _, n := range []int{1, len(valid) / 4, len(valid) / 2, len(valid) - 1} {
+		if n > 0 && n < len(valid) {
+			inputs = append(inputs, valid[:n])
+		}
+	}
+	if len(valid) > 0 {
+		// Trailing characters may carry unused bits, so damage the middle
+		flipped := []byte(valid)
+		middle := len(flipped) / 2
+		if flipped[middle] == 'A' {
+			flipped[middle] = 'B'
+		} else {
+			flipped[middle] = 'A'
+		}
+		inputs = append(inputs, string(flipped), valid+"$", "$"+valid)
+	}
+	return inputs
+}
+
+// verifyNoPanic calls Verify, reporting a panic as an error
+func verifyNoPanic(encoder passforge.PasswordEncoder, rawPassword, encodedPassword string) (match bool, err error, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			match, err, panicked = false, fmt.Errorf("%v", r), true
+		}
+	}()
+	match, err = encoder.Verify(rawPassword, encodedPassword)
+	return match, err, false
+}
@@ -0,0 +1,54 @@
+package passforgetesting
+
+import (
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+func TestRunConformanceTests_BuiltinEncoders(t *testing.T) {
+	pepper := make([]byte, 32)
+	peppered, _ := passforge.NewPepperedPasswordEncoder(passforge.NewBcryptPasswordEncoder(passforge.WithCost(4)), "v1", pepper)
+	delegating, _ := passforge.NewDelegatingPasswordEncoder("bcrypt", passforge.NewBcryptPasswordEncoder(passforge.WithCost(4)))
+
+	encoders := []passforge.PasswordEncoder{
+		passforge.NewArgon2PasswordEncoder(passforge.WithArgon2Memory(1024), passforge.WithArgon2Threads(1)),
+		passforge.NewBcryptPasswordEncoder(passforge.WithCost(4)),
+		passforge.NewScryptPasswordEncoder(passforge.WithScryptN(1024)),
+		passforge.NewPBKDF2PasswordEncoder(passforge.WithPBKDF2Iterations(1000), passforge.WithPBKDF2MinIterations(0)),
+		passforge.NewBcryptSHA256PasswordEncoder(passforge.WithBcryptSHA256Cost(4)),
+		passforge.NewDjangoPasswordEncoder(passforge.WithDjangoIterations(1000)),
+		passforge.NewYescryptPasswordEncoder(passforge.WithYescryptN(1024), passforge.WithYescryptR(8)),
+		passforge.NewSha512CryptPasswordEncoder(passforge.WithSha512CryptRounds(1000)),
+		passforge.NewSha256CryptPasswordEncoder(passforge.WithSha256CryptRounds(1000)),
+		passforge.NewLDAPHashPasswordEncoder(passforge.LDAPSSHA512),
+		passforge.NewScramSha256Encoder(passforge.WithScramSha256Iterations(1000)),
+		passforge.NewGrubPBKDF2PasswordEncoder(passforge.WithGrubPBKDF2Iterations(1000)),
+		passforge.NewPHPCompatEncoder(passforge.WithPHPBcryptCost(4)),
+		passforge.NewBlake2bPasswordEncoder(make([]byte, 32)),
+		passforge.NewDevisePasswordEncoder("pepper", passforge.WithDeviseStretches(4)),
+		passforge.NewModularCryptEncoder(passforge.WithModularCryptScheme("argon2id",
+			passforge.NewArgon2PasswordEncoder(passforge.WithArgon2PHC(), passforge.WithArgon2Memory(1024), passforge.WithArgon2Threads(1)))),
+		peppered,
+		delegating,
+	}
+	for _, encoder := range encoders {
+		t.Run(encoder.Name(), func(t *testing.T) {
+			RunConformanceTests(t, encoder)
+		})
+	}
+}
+
+func TestRunConformanceTests_BcryptPBKDF(t *testing.T) {
+	// bcrypt_pbkdf rejects empty passwords, as in OpenBSD
+	RunConformanceTests(t, passforge.NewBcryptPBKDFPasswordEncoder(passforge.WithBcryptPBKDFRounds(2)),
+		WithConformancePasswords("password123", "pässwörd-ünïcødé", "p@$$w0rd!{}"))
+}
+
+func TestRunConformanceTests_Deterministic(t *testing.T) {
+	RunConformanceTests(t, passforge.NewNoOpPasswordEncoder(), AllowDeterministic())
+	RunConformanceTests(t, NewFakeEncoder(), AllowDeterministic(), WithConformanceGoroutines(2))
+
+	hmacEncoder, _ := passforge.NewHmacSha256Encoder("v1", make([]byte, 32), passforge.WithHmacSha256MinSecretLen(0))
+	RunConformanceTests(t, hmacEncoder, AllowDeterministic())
+}
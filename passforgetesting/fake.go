@@ -0,0 +1,45 @@
+package passforgetesting
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/nduyhai/passforge"
+)
+
+// FakeEncoder is a fast, deterministic passforge.PasswordEncoder computing sha256(salt|password) with a fixed salt.
+// Its output has the shape "salt$hexhash". It must never be used outside of tests.
+type FakeEncoder struct {
+	Salt string
+}
+
+// NewFakeEncoder creates a new FakeEncoder using the fixed salt "passforge"
+func NewFakeEncoder() *FakeEncoder {
+	return &FakeEncoder{Salt: "passforge"}
+}
+
+// Encode returns salt$hex(sha256(salt|password))
+func (f *FakeEncoder) Encode(rawPassword string) (string, error) {
+	return f.Salt + "$" + fakeHash(f.Salt, rawPassword), nil
+}
+
+// Verify recomputes the hash using the salt stored in the encoded password
+func (f *FakeEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	salt, hash, ok := strings.Cut(encodedPassword, "$")
+	if !ok {
+		return false, passforge.ErrInvalidFormat
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(fakeHash(salt, rawPassword))) == 1, nil
+}
+
+// Name returns the name of the encoder.
+func (f *FakeEncoder) Name() string {
+	return "fake"
+}
+
+func fakeHash(salt, rawPassword string) string {
+	sum := sha256.Sum256([]byte(salt + "|" + rawPassword))
+	return hex.EncodeToString(sum[:])
+}
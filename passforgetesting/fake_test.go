@@ -0,0 +1,40 @@
+package passforgetesting
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+func TestFakeEncoder_Deterministic(t *testing.T) {
+	encoder := NewFakeEncoder()
+
+	first := AssertRoundTrip(t, encoder, "password123")
+	second, _ := encoder.Encode("password123")
+	if first != second {
+		t.Errorf("Encode() is not deterministic: %v != %v", first, second)
+	}
+
+	want := "passforge$4e024c3d814749de78fffafb6108ddbca2320b1b7aa2c734aeead876b9dad2fe"
+	if first != want {
+		t.Errorf("Encode() = %v, want %v", first, want)
+	}
+}
+
+func TestFakeEncoder_Verify(t *testing.T) {
+	encoder := &FakeEncoder{Salt: "other"}
+	encoded, _ := encoder.Encode("password123")
+
+	// The salt is read from the encoded value, so any FakeEncoder can verify it
+	AssertVerifies(t, NewFakeEncoder(), "password123", encoded)
+	AssertRejects(t, NewFakeEncoder(), "password124", encoded)
+
+	if _, err := encoder.Verify("password123", "no-separator"); !errors.Is(err, passforge.ErrInvalidFormat) {
+		t.Errorf("Verify() error = %v, want ErrInvalidFormat", err)
+	}
+
+	if encoder.Name() != "fake" {
+		t.Errorf("Name() = %v, want fake", encoder.Name())
+	}
+}
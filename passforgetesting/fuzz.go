@@ -0,0 +1,114 @@
+package passforgetesting
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+// VerifySeed is a (raw password, encoded password) pair for fuzzing Verify
+type VerifySeed struct {
+	RawPassword     string
+	EncodedPassword string
+}
+
+// VerifySeeds builds a seed corpus for encoder: valid encodings of a few passwords plus the
+// MalformedInputs derived from them. Verify-only encoders get the generic malformed inputs only.
+func VerifySeeds(encoder passforge.PasswordEncoder) []VerifySeed {
+	var seeds []VerifySeed
+	valid := ""
+	for _, password := range []string{"password123", ""} {
+		encoded, err := encoder.Encode(password)
+		if err != nil {
+			continue
+		}
+		seeds = append(seeds, VerifySeed{password, encoded}, VerifySeed{password + "-wrong", encoded})
+		valid = encoded
+	}
+	for _, malformed := range MalformedInputs(valid) {
+		seeds = append(seeds, VerifySeed{"password123", malformed})
+	}
+	return seeds
+}
+
+// FuzzVerify fuzzes encoder.Verify with the VerifySeeds corpus, checking that it never panics,
+// gives the same result for the same input and never reports a match together with an error.
+//
+//	func FuzzMyEncoderVerify(f *testing.F) {
+//		passforgetesting.FuzzVerify(f, NewMyEncoder())
+//	}
+//
+// Encoders that read cost parameters from the encoded value should be configured to bound them,
+// otherwise the fuzzer may generate inputs that are legitimately slow or memory hungry.
+func FuzzVerify(f *testing.F, encoder passforge.PasswordEncoder) {
+	f.Helper()
+	for _, seed := range VerifySeeds(encoder) {
+		f.Add(seed.RawPassword, seed.EncodedPassword)
+	}
+	f.Fuzz(func(t *testing.T, rawPassword, encodedPassword string) {
+		checkVerify(t, encoder, rawPassword, encodedPassword)
+	})
+}
+
+// FuzzRoundTrip fuzzes the property Verify(Encode(p), p) == true. Passwords the encoder refuses to
+// encode (e.g. longer than 72 bytes for bcrypt) are skipped.
+func FuzzRoundTrip(f *testing.F, encoder passforge.PasswordEncoder) {
+	f.Helper()
+	for _, password := range []string{"password123", "", "pässwörd", "\x00", "p@$$w0rd{}"} {
+		f.Add(password)
+	}
+	f.Fuzz(func(t *testing.T, rawPassword string) {
+		checkRoundTrip(t, encoder, rawPassword)
+	})
+}
+
+// CheckProperties runs the FuzzVerify and FuzzRoundTrip properties on n pseudo-random inputs derived
+// from seed, for use in plain tests and on toolchains without native fuzzing
+func CheckProperties(t *testing.T, encoder passforge.PasswordEncoder, seed string, n int) {
+	t.Helper()
+	reader := NewDeterministicReader(seed)
+	for _, s := range VerifySeeds(encoder) {
+		checkVerify(t, encoder, s.RawPassword, s.EncodedPassword)
+	}
+	for i := 0; i < n; i++ {
+		var size [2]byte
+		_, _ = reader.Read(size[:])
+		raw := make([]byte, int(size[0])%64)
+		encoded := make([]byte, int(size[1])%128)
+		_, _ = reader.Read(raw)
+		_, _ = reader.Read(encoded)
+
+		checkRoundTrip(t, encoder, string(raw))
+		checkVerify(t, encoder, string(raw), string(encoded))
+	}
+}
+
+// checkVerify asserts the Verify properties for a single input
+func checkVerify(t *testing.T, encoder passforge.PasswordEncoder, rawPassword, encodedPassword string) {
+	t.Helper()
+	first, firstErr, panicked := verifyNoPanic(encoder, rawPassword, encodedPassword)
+	if panicked {
+		t.Fatalf("%s: Verify(%q, %q) panicked: %v", encoder.Name(), rawPassword, encodedPassword, firstErr)
+	}
+	if first && firstErr != nil {
+		t.Errorf("%s: Verify(%q, %q) = true with error %v", encoder.Name(), rawPassword, encodedPassword, firstErr)
+	}
+	second, secondErr, _ := verifyNoPanic(encoder, rawPassword, encodedPassword)
+	if first != second || fmt.Sprint(firstErr) != fmt.Sprint(secondErr) {
+		t.Errorf("%s: Verify(%q, %q) is not stable: (%v, %v) then (%v, %v)",
+			encoder.Name(), rawPassword, encodedPassword, first, firstErr, second, secondErr)
+	}
+}
+
+// checkRoundTrip asserts that an encoded password verifies
+func checkRoundTrip(t *testing.T, encoder passforge.PasswordEncoder, rawPassword string) {
+	t.Helper()
+	encoded, err := encoder.Encode(rawPassword)
+	if err != nil {
+		return
+	}
+	if match, err := encoder.Verify(rawPassword, encoded); err != nil || !match {
+		t.Errorf("%s: Verify(%q, Encode(%q)) = %v, %v, want true, nil", encoder.Name(), rawPassword, rawPassword, match, err)
+	}
+}
@@ -0,0 +1,47 @@
+package passforgetesting
+
+import (
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+func FuzzVerify_Argon2(f *testing.F) {
+	FuzzVerify(f, passforge.NewArgon2PasswordEncoder(passforge.WithArgon2Memory(1024), passforge.WithArgon2Threads(1)))
+}
+
+func FuzzVerify_Bcrypt(f *testing.F) {
+	FuzzVerify(f, passforge.NewBcryptPasswordEncoder(passforge.WithCost(4)))
+}
+
+func FuzzVerify_Delegating(f *testing.F) {
+	delegating, _ := passforge.NewDelegatingPasswordEncoder("pbkdf2",
+		passforge.NewPBKDF2PasswordEncoder(passforge.WithPBKDF2Iterations(1000), passforge.WithPBKDF2MinIterations(0)),
+		passforge.NewNoOpPasswordEncoder(),
+	)
+	FuzzVerify(f, delegating)
+}
+
+func FuzzRoundTrip_Scrypt(f *testing.F) {
+	FuzzRoundTrip(f, passforge.NewScryptPasswordEncoder(passforge.WithScryptN(1024)))
+}
+
+func TestVerifySeeds(t *testing.T) {
+	seeds := VerifySeeds(NewFakeEncoder())
+	if len(seeds) < 4 || seeds[0].EncodedPassword == "" {
+		t.Fatalf("VerifySeeds() = %v, want valid encodings first", seeds)
+	}
+
+	// Verify-only encoders still get the generic malformed inputs
+	mock := NewMockEncoder("ldap")
+	mock.QueueEncode("", passforge.ErrEncodeNotSupported)
+	mock.QueueEncode("", passforge.ErrEncodeNotSupported)
+	if seeds := VerifySeeds(mock); len(seeds) == 0 {
+		t.Errorf("VerifySeeds() returned no seeds for a verify-only encoder")
+	}
+}
+
+func TestCheckProperties(t *testing.T) {
+	CheckProperties(t, passforge.NewPBKDF2PasswordEncoder(passforge.WithPBKDF2Iterations(100), passforge.WithPBKDF2MinIterations(0)), "seed", 20)
+	CheckProperties(t, NewFakeEncoder(), "seed", 100)
+}
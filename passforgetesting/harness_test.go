@@ -0,0 +1,95 @@
+package passforgetesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nduyhai/passforge"
+)
+
+func TestDeterministicReader(t *testing.T) {
+	a, b := make([]byte, 100), make([]byte, 100)
+	_, _ = NewDeterministicReader("seed").Read(a)
+
+	// Reading in chunks yields the same stream
+	reader := NewDeterministicReader("seed")
+	_, _ = reader.Read(b[:7])
+	_, _ = reader.Read(b[7:])
+	if string(a) != string(b) {
+		t.Errorf("Read() streams differ between chunked and single reads")
+	}
+
+	c := make([]byte, 100)
+	_, _ = NewDeterministicReader("other").Read(c)
+	if string(a) == string(c) {
+		t.Errorf("Read() streams for different seeds are equal")
+	}
+}
+
+func TestHarness_GoldenOutput(t *testing.T) {
+	encode := func() string {
+		encoder := passforge.NewArgon2PasswordEncoder(passforge.WithArgon2Memory(1024), passforge.WithArgon2Threads(1))
+		if err := NewHarness("golden").Wire(encoder); err != nil {
+			t.Fatalf("Wire() error = %v", err)
+		}
+		encoded, err := encoder.Encode("password123")
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return encoded
+	}
+
+	if first, second := encode(), encode(); first != second {
+		t.Errorf("Encode() output is not stable: %v != %v", first, second)
+	}
+}
+
+func TestHarness_WireDelegating(t *testing.T) {
+	pbkdf2 := passforge.NewPBKDF2PasswordEncoder(passforge.WithPBKDF2Iterations(1000), passforge.WithPBKDF2MinIterations(0))
+	scrypt := passforge.NewScryptPasswordEncoder(passforge.WithScryptN(1024))
+	delegating, _ := passforge.NewDelegatingPasswordEncoder("pbkdf2", pbkdf2, scrypt, passforge.NewNoOpPasswordEncoder())
+
+	harness := NewHarness("seed")
+	if err := harness.Wire(delegating); err != nil {
+		t.Fatalf("Wire() error = %v", err)
+	}
+	if pbkdf2.Rand != harness.Rand || scrypt.Rand != harness.Rand {
+		t.Errorf("Wire() did not inject the rand source into delegated encoders")
+	}
+}
+
+func TestHarness_WireConcurrentRegister(t *testing.T) {
+	delegating, _ := passforge.NewDelegatingPasswordEncoder("noop", passforge.NewNoOpPasswordEncoder())
+	harness := NewHarness("seed")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = delegating.Register("md5", passforge.NewMd5PasswordEncoder())
+			_ = delegating.Unregister("md5")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := harness.Wire(delegating); err != nil {
+			t.Fatalf("Wire() error = %v", err)
+		}
+	}
+	<-done
+}
+
+func TestHarness_WireClock(t *testing.T) {
+	harness := NewHarness("seed")
+	store := passforge.NewMemoryCredentialStore()
+	if err := harness.Wire(store); err != nil {
+		t.Fatalf("Wire() error = %v", err)
+	}
+
+	harness.Clock.Advance(time.Hour)
+	if got := store.Now(); !got.Equal(HarnessEpoch.Add(time.Hour)) {
+		t.Errorf("store.Now() = %v, want %v", got, HarnessEpoch.Add(time.Hour))
+	}
+
+	if err := harness.Wire(passforge.NewBcryptPasswordEncoder()); err == nil {
+		t.Errorf("Wire() of bcrypt encoder should report that it cannot be made deterministic")
+	}
+}
@@ -0,0 +1,130 @@
+// Package passforgetesting provides test doubles for passforge encoders so applications can
+// unit-test authentication flows without paying the cost of real password hashing.
+package passforgetesting
+
+import (
+	"strings"
+	"sync"
+)
+
+// Call records a single invocation of a MockEncoder method
+type Call struct {
+	Method          string // "Encode" or "Verify"
+	RawPassword     string
+	EncodedPassword string // Empty for Encode calls
+}
+
+type encodeResult struct {
+	encoded string
+	err     error
+}
+
+type verifyResult struct {
+	match bool
+	err   error
+}
+
+// MockEncoder is a passforge.PasswordEncoder that records calls and returns scripted results.
+// Queued results are consumed in order; once a queue is empty, EncodeFunc and VerifyFunc are used.
+// By default Encode returns "mock:" + raw password and Verify compares against that form.
+type MockEncoder struct {
+	EncoderName string
+	EncodeFunc  func(rawPassword string) (string, error)
+	VerifyFunc  func(rawPassword, encodedPassword string) (bool, error)
+
+	mu      sync.Mutex
+	calls   []Call
+	encodes []encodeResult
+	verifys []verifyResult
+}
+
+// NewMockEncoder creates a new MockEncoder reporting the given name
+func NewMockEncoder(name string) *MockEncoder {
+	return &MockEncoder{
+		EncoderName: name,
+		EncodeFunc: func(rawPassword string) (string, error) {
+			return "mock:" + rawPassword, nil
+		},
+		VerifyFunc: func(rawPassword, encodedPassword string) (bool, error) {
+			return strings.TrimPrefix(encodedPassword, "mock:") == rawPassword && strings.HasPrefix(encodedPassword, "mock:"), nil
+		},
+	}
+}
+
+// QueueEncode scripts the result of the next unscripted Encode call
+func (m *MockEncoder) QueueEncode(encoded string, err error) *MockEncoder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.encodes = append(m.encodes, encodeResult{encoded, err})
+	return m
+}
+
+// QueueVerify scripts the result of the next unscripted Verify call
+func (m *MockEncoder) QueueVerify(match bool, err error) *MockEncoder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.verifys = append(m.verifys, verifyResult{match, err})
+	return m
+}
+
+// Encode records the call and returns the next scripted result
+func (m *MockEncoder) Encode(rawPassword string) (string, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Encode", RawPassword: rawPassword})
+	if len(m.encodes) > 0 {
+		r := m.encodes[0]
+		m.encodes = m.encodes[1:]
+		m.mu.Unlock()
+		return r.encoded, r.err
+	}
+	m.mu.Unlock()
+	return m.EncodeFunc(rawPassword)
+}
+
+// Verify records the call and returns the next scripted result
+func (m *MockEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Verify", RawPassword: rawPassword, EncodedPassword: encodedPassword})
+	if len(m.verifys) > 0 {
+		r := m.verifys[0]
+		m.verifys = m.verifys[1:]
+		m.mu.Unlock()
+		return r.match, r.err
+	}
+	m.mu.Unlock()
+	return m.VerifyFunc(rawPassword, encodedPassword)
+}
+
+// Name returns the configured name of the encoder.
+func (m *MockEncoder) Name() string {
+	return m.EncoderName
+}
+
+// Calls returns a copy of the recorded calls
+func (m *MockEncoder) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns the number of recorded calls of the method
+func (m *MockEncoder) CallCount(method string) int {
+	n := 0
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset clears recorded calls and scripted results
+func (m *MockEncoder) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls, m.encodes, m.verifys = nil, nil, nil
+}
@@ -0,0 +1,60 @@
+package passforgetesting
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+)
+
+func TestMockEncoder_Defaults(t *testing.T) {
+	mock := NewMockEncoder("mock")
+
+	encoded := AssertRoundTrip(t, mock, "password123")
+	if encoded != "mock:password123" {
+		t.Errorf("Encode() = %v, want mock:password123", encoded)
+	}
+
+	AssertCalled(t, mock, "Encode", 1)
+	AssertCalled(t, mock, "Verify", 2)
+}
+
+func TestMockEncoder_Scripted(t *testing.T) {
+	mock := NewMockEncoder("mock")
+	boom := errors.New("boom")
+	mock.QueueEncode("", boom).QueueVerify(true, nil)
+
+	if _, err := mock.Encode("password"); !errors.Is(err, boom) {
+		t.Errorf("Encode() error = %v, want scripted error", err)
+	}
+	if match, _ := mock.Verify("anything", "whatever"); !match {
+		t.Errorf("Verify() = false, want scripted true")
+	}
+
+	// Queues are consumed, defaults apply afterwards
+	if match, _ := mock.Verify("anything", "whatever"); match {
+		t.Errorf("Verify() = true, want default mismatch")
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 3 || calls[1].EncodedPassword != "whatever" {
+		t.Errorf("Calls() = %+v", calls)
+	}
+
+	mock.Reset()
+	AssertCalled(t, mock, "Verify", 0)
+}
+
+func TestMockEncoder_Delegating(t *testing.T) {
+	mock := NewMockEncoder("mock")
+	delegating, err := passforge.NewDelegatingPasswordEncoder("mock", mock)
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+
+	encoded := AssertRoundTrip(t, delegating, "password123")
+	if encoded != "{mock}mock:password123" {
+		t.Errorf("Encode() = %v", encoded)
+	}
+	AssertCalled(t, mock, "Verify", 2)
+}
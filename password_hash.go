@@ -0,0 +1,172 @@
+package passforge
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PasswordHash is an opaque handle on an encoded password. It gives callers typed access to the
+// algorithm and parameters instead of passing bare strings around, and marshals as the encoded text.
+// (It is not named Hash because that name is taken by the package-level Hash function.)
+type PasswordHash struct {
+	encoded string
+}
+
+// ParsePasswordHash wraps an encoded password, either "{id}"-prefixed or produced directly by a built-in encoder.
+// It returns ErrInvalidFormat when the algorithm can't be determined.
+func ParsePasswordHash(encodedPassword string) (PasswordHash, error) {
+	h := PasswordHash{encoded: encodedPassword}
+	if h.Algorithm() == "" {
+		return PasswordHash{}, ErrInvalidFormat
+	}
+	return h, nil
+}
+
+// EncodeHash encodes the raw password with the encoder and wraps the result
+func EncodeHash(encoder PasswordEncoder, rawPassword string) (PasswordHash, error) {
+	encoded, err := encoder.Encode(rawPassword)
+	if err != nil {
+		return PasswordHash{}, err
+	}
+	return PasswordHash{encoded: encoded}, nil
+}
+
+// String returns the encoded password
+func (h PasswordHash) String() string {
+	return h.encoded
+}
+
+// Algorithm returns the "{id}" prefix, or the built-in encoder the unprefixed value was produced by,
+// or "" when unknown
+func (h PasswordHash) Algorithm() string {
+	if id, _, err := extractIDAndHash(h.encoded); err == nil {
+		return id
+	}
+	return identifyAlgorithm(h.encoded)
+}
+
+// Params returns the cost parameters recorded in the hash, e.g. {"time": "1", "memory": "65536", ...}
+// for Argon2 or {"cost": "10"} for bcrypt. It returns nil when there are none.
+func (h PasswordHash) Params() map[string]string {
+	encoded := h.encoded
+	if _, inner, err := extractIDAndHash(encoded); err == nil {
+		encoded = inner
+	}
+
+	if reader, ok := identifyFormat(encoded).(HashParamsReader); ok {
+		return reader.EncodedParams(encoded)
+	}
+	return parseParamsHead(encoded)
+}
+
+// Verify checks the raw password against the hash using the package default encoder (see SetDefault)
+func (h PasswordHash) Verify(rawPassword string) (bool, error) {
+	return Check(rawPassword, h.encoded)
+}
+
+// VerifyWith checks the raw password against the hash using the given encoder
+func (h PasswordHash) VerifyWith(encoder PasswordEncoder, rawPassword string) (bool, error) {
+	return encoder.Verify(rawPassword, h.encoded)
+}
+
+// NeedsRehash reports whether the hash does not satisfy the policy
+func (h PasswordHash) NeedsRehash(policy RehashPolicy) bool {
+	if policy.Algorithm != "" && h.Algorithm() != policy.Algorithm {
+		return true
+	}
+	params := h.Params()
+	for key, minimum := range policy.MinParams {
+		value, err := strconv.Atoi(params[key])
+		if err != nil || value < minimum {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalText returns the encoded password
+func (h PasswordHash) MarshalText() ([]byte, error) {
+	return []byte(h.encoded), nil
+}
+
+// UnmarshalText parses an encoded password, see ParsePasswordHash
+func (h *PasswordHash) UnmarshalText(text []byte) error {
+	parsed, err := ParsePasswordHash(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
+
+// RehashPolicy describes the algorithm and minimum cost parameters hashes are expected to use
+type RehashPolicy struct {
+	Algorithm string         // Expected algorithm, e.g. "argon2"; empty accepts any
+	MinParams map[string]int // Minimum value of numeric parameters, e.g. {"memory": 65536}; missing parameters fail
+}
+
+// HashIdentifier is implemented by encoders that recognise their own unprefixed encoded passwords
+type HashIdentifier interface {
+	PasswordEncoder
+	Identify(encodedPassword string) bool
+}
+
+// HashParamsReader is implemented by encoders that report the cost parameters recorded in their encoded passwords
+type HashParamsReader interface {
+	EncodedParams(encodedPassword string) map[string]string
+}
+
+var (
+	hashFormatsMu sync.RWMutex
+	hashFormats   []HashIdentifier
+)
+
+// RegisterHashFormat makes PasswordHash and crypt prefix routing recognise the unprefixed encoded
+// passwords of the encoder under its Name. Only Identify, EncodedParams and Name are called on it.
+// The built-in encoders register themselves.
+func RegisterHashFormat(encoder HashIdentifier) {
+	hashFormatsMu.Lock()
+	defer hashFormatsMu.Unlock()
+
+	hashFormats = append(hashFormats, encoder)
+}
+
+// identifyFormat returns the first registered encoder recognising the encoded password, nil when none does
+func identifyFormat(encodedPassword string) HashIdentifier {
+	hashFormatsMu.RLock()
+	defer hashFormatsMu.RUnlock()
+
+	for _, format := range hashFormats {
+		if format.Identify(encodedPassword) {
+			return format
+		}
+	}
+	return nil
+}
+
+// identifyAlgorithm returns the name of the registered encoder recognising the encoded password, "" when none does
+func identifyAlgorithm(encodedPassword string) string {
+	if format := identifyFormat(encodedPassword); format != nil {
+		return format.Name()
+	}
+	return ""
+}
+
+// parseParamsHead parses the comma-separated key=value parameters before the first "$",
+// as in "time=1,memory=65536,...$salt$hash". It returns nil when there are none.
+func parseParamsHead(encodedPassword string) map[string]string {
+	head, _, found := strings.Cut(encodedPassword, "$")
+	if !found {
+		return nil
+	}
+	params := make(map[string]string)
+	for _, pair := range strings.Split(head, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil
+		}
+		params[key] = value
+	}
+	return params
+}
@@ -0,0 +1,268 @@
+package passforge
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswordHash(t *testing.T) {
+	testCases := []struct {
+		name          string
+		encoded       string
+		wantAlgorithm string
+		wantParams    map[string]string
+		wantErr       bool
+	}{
+		{
+			name:          "prefixed argon2",
+			encoded:       "{argon2}time=1,memory=65536,threads=4,keyLen=32$c2FsdA==$aGFzaA==",
+			wantAlgorithm: "argon2",
+			wantParams:    map[string]string{"time": "1", "memory": "65536", "threads": "4", "keyLen": "32"},
+		},
+		{
+			name:          "argon2 PHC string",
+			encoded:       "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG",
+			wantAlgorithm: "argon2",
+			wantParams:    map[string]string{"time": "2", "memory": "65536", "threads": "4", "keyLen": "24", "v": "19", "variant": "argon2i"},
+		},
+		{
+			name:          "server relief",
+			encoded:       "relief=1,time=1,memory=65536,threads=4,keyLen=32$c2FsdA==$aGFzaA==",
+			wantAlgorithm: "relief",
+			wantParams:    map[string]string{"relief": "1", "time": "1", "memory": "65536", "threads": "4", "keyLen": "32"},
+		},
+		{
+			name:          "unprefixed pbkdf2",
+			encoded:       "iterations=10000,keyLen=32,hashFunc=sha256$c2FsdA==$aGFzaA==",
+			wantAlgorithm: "pbkdf2",
+			wantParams:    map[string]string{"iterations": "10000", "keyLen": "32", "hashFunc": "sha256"},
+		},
+		{
+			name:          "passlib pbkdf2",
+			encoded:       "$pbkdf2-sha512$25000$AAECAwQFBgcICQoLDA0ODw$EIJTJci4GjJFueYP2IMIxGIhpWd96facmk2yGdjyFsEUE2PrPNQnrnUVT5Ch.GNpbgjHYeabQn2L9uP6DGJOVw",
+			wantAlgorithm: "pbkdf2",
+			wantParams:    map[string]string{"iterations": "25000", "keyLen": "64", "hashFunc": "sha512"},
+		},
+		{
+			name:          "bcrypt",
+			encoded:       "$2a$04$SRQNEwWVO4sjnftG3H4Gse6SVKUkXTlOYQWNZ9BSXI6L5BebPfq4O",
+			wantAlgorithm: "bcrypt",
+			wantParams:    map[string]string{"cost": "4"},
+		},
+		{
+			name:          "bcrypt-sha256",
+			encoded:       "$bcrypt-sha256$v=2,t=2b,r=5$5Hg1DKFqPE8C2aflZ5vVoe$wOK1VFFtS8IGTrGa7.h5fs0u84qyPbS",
+			wantAlgorithm: "bcrypt-sha256",
+			wantParams:    map[string]string{"cost": "5"},
+		},
+		{
+			name:          "django",
+			encoded:       "pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc=",
+			wantAlgorithm: "django",
+			wantParams:    map[string]string{"iterations": "1000", "algorithm": "pbkdf2_sha256"},
+		},
+		{
+			name:          "yescrypt",
+			encoded:       "$y$j75..$saltsaltsaltsalt$m89vc6HyWxsfkkH188K53LeyR7/KCYBs.jfnq610747",
+			wantAlgorithm: "yescrypt",
+			wantParams:    map[string]string{"N": "1024", "r": "8", "p": "2", "t": "0"},
+		},
+		{
+			name:          "sha512-crypt",
+			encoded:       "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+			wantAlgorithm: "sha512-crypt",
+			wantParams:    map[string]string{"rounds": "5000"},
+		},
+		{
+			name:          "sha256-crypt",
+			encoded:       "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA",
+			wantAlgorithm: "sha256-crypt",
+			wantParams:    map[string]string{"rounds": "10000"},
+		},
+		{
+			name:          "scram-sha-256",
+			encoded:       "SCRAM-SHA-256$4096:c2FsdHNhbHRzYWx0c2FsdA==$CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477bQ=:VKers2x8MllK1Rh7LZLqtj6KOTzoFWJpIaokMX3blS0=",
+			wantAlgorithm: "scram-sha-256",
+			wantParams:    map[string]string{"iterations": "4096"},
+		},
+		{
+			name:          "grub-pbkdf2",
+			encoded:       "grub.pbkdf2.sha512.1000.000102030405060708090A0B0C0D0E0F.C74E4080D0FBB41FEE5868C0FF60FD75ACAE2638215987E5FF54F8EAE211339B",
+			wantAlgorithm: "grub-pbkdf2",
+			wantParams:    map[string]string{"iterations": "1000"},
+		},
+		{
+			name:          "mediawiki",
+			encoded:       ":pbkdf2:sha256:10000:32:AAECAwQFBgcICQoLDA0ODw==:62yBU1WSIDwJKxWPjTkJZyNipvXb0A2YKARMuqiyUuk=",
+			wantAlgorithm: "mediawiki",
+			wantParams:    map[string]string{"iterations": "10000", "algorithm": "sha256"},
+		},
+		{
+			name:          "phpass",
+			encoded:       "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0",
+			wantAlgorithm: "phpass",
+			wantParams:    map[string]string{"iterations": "2048"},
+		},
+		{
+			name:          "drupal",
+			encoded:       "$S$DsaltSALTd3g76wg2gTxalnh5UraGJM8nmVSZwDHb9zkLb/4b3CH",
+			wantAlgorithm: "drupal",
+			wantParams:    map[string]string{"iterations": "32768"},
+		},
+		{
+			name:          "cisco type 8",
+			encoded:       "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk",
+			wantAlgorithm: "cisco-type8",
+		},
+		{
+			name:          "cisco type 9",
+			encoded:       "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6",
+			wantAlgorithm: "cisco-type9",
+		},
+		{
+			name:          "ntlm",
+			encoded:       "$NT$8846f7eaee8fb117ad06bdd830b7586c",
+			wantAlgorithm: "ntlm",
+		},
+		{
+			name:          "mysql",
+			encoded:       "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19",
+			wantAlgorithm: "mysql",
+		},
+		{
+			name:          "bcrypt-pbkdf",
+			encoded:       "rounds=12,keyLen=32$c2FsdA==$GuQsBdSHvAL2SSGk6+Tqk7ys/hNf2pmXTAa3sB+uFJo=",
+			wantAlgorithm: "bcrypt-pbkdf",
+			wantParams:    map[string]string{"rounds": "12", "keyLen": "32"},
+		},
+		{
+			name:          "blake2b",
+			encoded:       "hashFunc=blake2b,keyLen=32$c2FsdHNhbHRzYWx0c2FsdA==$VnJwQxs+W2CsT+I0igdkXZVGjxPw8JXAURPPpwrChV0=",
+			wantAlgorithm: "blake2b",
+			wantParams:    map[string]string{"hashFunc": "blake2b", "keyLen": "32"},
+		},
+		{
+			name:          "md5",
+			encoded:       "5f4dcc3b5aa765d61d8327deb882cf99",
+			wantAlgorithm: "md5",
+		},
+		{
+			name:          "salted sha1",
+			encoded:       "59b3e8d637cf97edbe2384cf59cb7453dfe30789:salt",
+			wantAlgorithm: "sha1",
+		},
+		{
+			name:          "prefixed noop",
+			encoded:       "{noop}password123",
+			wantAlgorithm: "noop",
+		},
+		{name: "unknown", encoded: "password123", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := ParsePasswordHash(tc.encoded)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidFormat) {
+					t.Errorf("ParsePasswordHash() error = %v, want ErrInvalidFormat", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePasswordHash() error = %v", err)
+			}
+			if h.String() != tc.encoded || h.Algorithm() != tc.wantAlgorithm {
+				t.Errorf("ParsePasswordHash() = %v (%v), want %v (%v)", h, h.Algorithm(), tc.encoded, tc.wantAlgorithm)
+			}
+			params := h.Params()
+			if len(params) != len(tc.wantParams) {
+				t.Fatalf("Params() = %v, want %v", params, tc.wantParams)
+			}
+			for key, value := range tc.wantParams {
+				if params[key] != value {
+					t.Errorf("Params()[%v] = %v, want %v", key, params[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordHash_VerifyAndRehash(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))
+	h, err := EncodeHash(encoder, "password123")
+	if err != nil {
+		t.Fatalf("EncodeHash() error = %v", err)
+	}
+	if match, err := h.VerifyWith(encoder, "password123"); err != nil || !match {
+		t.Errorf("VerifyWith() = %v, %v, want true, nil", match, err)
+	}
+
+	if h.NeedsRehash(RehashPolicy{Algorithm: "argon2", MinParams: map[string]int{"memory": 1024}}) {
+		t.Errorf("NeedsRehash() = true for compliant hash")
+	}
+	if !h.NeedsRehash(RehashPolicy{Algorithm: "argon2", MinParams: map[string]int{"memory": 65536}}) {
+		t.Errorf("NeedsRehash() = false for hash below the memory minimum")
+	}
+	if !h.NeedsRehash(RehashPolicy{Algorithm: "bcrypt"}) {
+		t.Errorf("NeedsRehash() = false for a different algorithm")
+	}
+
+	prefixed, _ := EncodeHash(Default(), "password123")
+	if match, err := prefixed.Verify("password123"); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestPasswordHash_MarshalText(t *testing.T) {
+	type record struct {
+		Hash PasswordHash `json:"hash"`
+	}
+
+	in := record{Hash: PasswordHash{encoded: "{noop}password123"}}
+	data, err := json.Marshal(in)
+	if err != nil || string(data) != `{"hash":"{noop}password123"}` {
+		t.Fatalf("json.Marshal() = %s, %v", data, err)
+	}
+
+	var out record
+	if err := json.Unmarshal(data, &out); err != nil || out.Hash != in.Hash {
+		t.Errorf("json.Unmarshal() = %v, %v, want %v", out, err, in)
+	}
+	if err := json.Unmarshal([]byte(`{"hash":"garbage"}`), &out); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("json.Unmarshal() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+// customFormatEncoder is a third-party encoder recognising its own "$custom$" values
+type customFormatEncoder struct {
+	NoOpPasswordEncoder
+}
+
+func (c *customFormatEncoder) Name() string {
+	return "custom"
+}
+
+func (c *customFormatEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$custom$")
+}
+
+func (c *customFormatEncoder) EncodedParams(encodedPassword string) map[string]string {
+	return map[string]string{"rounds": strings.TrimPrefix(encodedPassword, "$custom$")}
+}
+
+func TestRegisterHashFormat(t *testing.T) {
+	if _, err := ParsePasswordHash("$custom$7"); err == nil {
+		t.Fatalf("ParsePasswordHash() recognised an unregistered format")
+	}
+
+	RegisterHashFormat(&customFormatEncoder{})
+	h, err := ParsePasswordHash("$custom$7")
+	if err != nil {
+		t.Fatalf("ParsePasswordHash() error = %v", err)
+	}
+	if h.Algorithm() != "custom" || h.Params()["rounds"] != "7" {
+		t.Errorf("Algorithm() = %v, Params() = %v, want custom with 7 rounds", h.Algorithm(), h.Params())
+	}
+}
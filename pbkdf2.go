@@ -1,15 +1,21 @@
 package passforge
 
 import (
-	"crypto/rand"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
+	"io"
+	"strconv"
 	"strings"
 
-	"golang.org/x/crypto/pbkdf2"
+	"github.com/nduyhai/passforge/internal/sm3"
+	"golang.org/x/crypto/sha3"
 )
 
 // PBKDF2PasswordEncoder is a password encoder that uses the PBKDF2 algorithm
@@ -19,14 +25,93 @@ type PBKDF2PasswordEncoder struct {
 	SaltLen      int              // Length of the salt
 	HashFunc     func() hash.Hash // Hash function to use (e.g., sha256.New)
 	HashFuncName string           // Name of the hash function (e.g., "sha256")
+	Rand         io.Reader        // Source of salts, crypto/rand.Reader when nil
+	Format       PBKDF2Format     // Output format
+	Secret       []byte           // Application secret appended to the salt, never stored
+
+	MinIterations       int            // Stored hashes below this iteration count are non-compliant, 0 disables the check
+	MinIterationsByHash map[string]int // Floors of individual hash functions, overriding MinIterations
+	RejectBelowMin      bool           // Fail verification of non-compliant hashes instead of only flagging them
+}
+
+// PBKDF2Format selects the text format of encoded PBKDF2 passwords
+type PBKDF2Format int
+
+const (
+	// PBKDF2Passforge is the passforge format iterations=ITERATIONS,keyLen=KEYLEN,hashFunc=HASHFUNC$BASE64_SALT$BASE64_HASH
+	PBKDF2Passforge PBKDF2Format = iota
+	// PBKDF2Spring is the format of Spring Security's Pbkdf2PasswordEncoder, hex(SALT || HASH) without parameters.
+	// Verify reads such values with the configured iterations, key and salt lengths, hash function and Secret,
+	// and still accepts the passforge format.
+	PBKDF2Spring
+	// PBKDF2Passlib is the modular crypt format of passlib's pbkdf2_sha256, pbkdf2_sha512 and pbkdf2_sha1 hashes,
+	// $pbkdf2-sha256$ITERATIONS$SALT$HASH in passlib's adapted base64. Verify always accepts it.
+	PBKDF2Passlib
+)
+
+// String returns the name of the format
+func (f PBKDF2Format) String() string {
+	switch f {
+	case PBKDF2Spring:
+		return "spring"
+	case PBKDF2Passlib:
+		return "passlib"
+	}
+	return "passforge"
 }
 
+// passlibPBKDF2Idents maps the hash functions of passlib's PBKDF2 hashes to their modular crypt identifier
+var passlibPBKDF2Idents = map[string]string{
+	"sha1":   "pbkdf2",
+	"sha256": "pbkdf2-sha256",
+	"sha512": "pbkdf2-sha512",
+}
+
+// passlibEncoding is passlib's adapted base64, the standard alphabet with "." instead of "+", without padding
+var passlibEncoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+// OWASPMinPBKDF2Iterations is the iteration count OWASP recommends for PBKDF2-HMAC-SHA256
+const OWASPMinPBKDF2Iterations = 600000
+
+// ErrIterationsTooLow is returned when a stored PBKDF2 hash uses fewer iterations than the configured minimum
+var ErrIterationsTooLow = errors.New("pbkdf2: iterations below minimum")
+
+// pbkdf2HashFuncs maps the hashFunc names Verify accepts to their hash functions
+var pbkdf2HashFuncs = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+
+	"sha3-256": sha3.New256,
+	"sha3-512": sha3.New512,
+
+	// For the Chinese national cryptography standards (GB/T 32905-2016)
+	"sm3": sm3.New,
+}
+
+// pbkdf2VerifyOnly lists the hash functions accepted for legacy hashes but never used by Encode
+var pbkdf2VerifyOnly = map[string]bool{
+	"sha1": true,
+}
+
+// ErrInvalidPBKDF2Params is matched by format errors for unknown, duplicate, missing or out-of-range PBKDF2 parameters
+var ErrInvalidPBKDF2Params = errors.New("invalid pbkdf2 parameters")
+
+// ErrPBKDF2SecretRequired is returned when a hash derived with an application secret is verified without one
+var ErrPBKDF2SecretRequired = errors.New("pbkdf2: hash requires the application secret")
+
+// ErrFIPSNotAllowed is returned when FIPS 140-only mode (GODEBUG=fips140=only) rejects the PBKDF2 parameters:
+// hash functions other than SHA-2 and SHA-3, salts shorter than 16 bytes or keys shorter than 14 bytes
+var ErrFIPSNotAllowed = errors.New("pbkdf2: not allowed in FIPS 140-only mode")
+
 // PBKDF2Option is a functional option used to configure a PBKDF2PasswordEncoder instance.
 type PBKDF2Option func(*PBKDF2PasswordEncoder)
 
 // WithPBKDF2Iterations sets the number of iterations
-// Recommended minimum: 10000
-// Default: 10000
+// Recommended minimum: 600000 for SHA-256, 210000 for SHA-512
+// Default: 600000
+// Encode refuses counts below the iteration floor of the hash function, see WithPBKDF2MinIterations.
 // See https://en.wikipedia.org/wiki/PBKDF2#Parameters
 func WithPBKDF2Iterations(iterations int) PBKDF2Option {
 	return func(p *PBKDF2PasswordEncoder) {
@@ -70,6 +155,7 @@ func WithPBKDF2SaltLen(saltLen int) PBKDF2Option {
 //	The hash function is used to derive the key from the password and the salt.
 //	The hash function must be deterministic, i.e., the same input should always produce the same output.
 //	The hash function must be cryptographically secure, i.e., it must be impossible to reverse the hash function.
+//	The name is stored in the encoded password and must be one Verify recognises, see WithPBKDF2Hash.
 func WithPBKDF2HashFunc(hashFunc func() hash.Hash, hashFuncName string) PBKDF2Option {
 	return func(p *PBKDF2PasswordEncoder) {
 		p.HashFunc = hashFunc
@@ -77,14 +163,120 @@ func WithPBKDF2HashFunc(hashFunc func() hash.Hash, hashFuncName string) PBKDF2Op
 	}
 }
 
+// WithPBKDF2Hash selects the hash function by the name stored in encoded passwords: "sha256", "sha384", "sha512",
+// "sha3-256", "sha3-512" or "sm3"
+// Default: "sha256"
+func WithPBKDF2Hash(name string) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.HashFunc = pbkdf2HashFuncs[name]
+		p.HashFuncName = name
+	}
+}
+
+// WithPBKDF2Secret mixes an application secret, kept out of the database, into new hashes: it is appended
+// to the salt as in Spring's Pbkdf2PasswordEncoder, so a leaked database alone can't be attacked offline.
+// Hashes record that a secret was used (secret=true) but not the secret itself; hashes without the marker
+// keep verifying without it. Changing the secret invalidates every hash derived with the old one.
+func WithPBKDF2Secret(secret []byte) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.Secret = secret
+	}
+}
+
+// WithPBKDF2Spring configures the format and defaults of Spring Security's Pbkdf2PasswordEncoder
+// (defaultsForSpringSecurity_v5_8): SHA-256, 310000 iterations, 16-byte salt and 32-byte hash, with
+// the application secret Spring was configured with. Options applied afterwards override the defaults,
+// e.g. WithPBKDF2Hash("sha1"), WithPBKDF2Iterations(185000) and WithPBKDF2SaltLen(8) for hashes of
+// Spring's former defaults. The iteration floor is lowered to the preset's 310000 iterations.
+func WithPBKDF2Spring(secret []byte) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.Format = PBKDF2Spring
+		p.Secret = secret
+		p.Iterations = 310000
+		p.MinIterations = 310000
+		p.MinIterationsByHash = nil
+		p.SaltLen = 16
+		p.KeyLen = 32
+		p.HashFunc = sha256.New
+		p.HashFuncName = "sha256"
+	}
+}
+
+// WithPBKDF2Passlib configures the format and defaults of passlib's pbkdf2_sha256: 29000 iterations and a
+// 16-byte salt. Options applied afterwards override the defaults, e.g. WithPBKDF2Hash("sha512") for
+// pbkdf2_sha512. The hash is as long as the hash function's output whatever the key length, as in passlib.
+// The iteration floor is lowered to the preset's 29000 iterations; set it again, e.g. with
+// WithPBKDF2MinIterations(25000) for pbkdf2_sha512, when overriding the iterations.
+func WithPBKDF2Passlib() PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.Format = PBKDF2Passlib
+		p.Iterations = 29000
+		p.MinIterations = 29000
+		p.MinIterationsByHash = nil
+		p.SaltLen = 16
+		p.KeyLen = 32
+		p.HashFunc = sha256.New
+		p.HashFuncName = "sha256"
+	}
+}
+
+// WithPBKDF2Rand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithPBKDF2Rand(r io.Reader) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.Rand = r
+	}
+}
+
+// WithPBKDF2MinIterations sets the iteration floor applied to stored hashes of every hash function on verification
+// Default: the OWASP recommendations, 600000 for PBKDF2-HMAC-SHA256, 1300000 for SHA-1 and 210000 for SHA-512;
+// the iterations of the preset with WithPBKDF2Spring and WithPBKDF2Passlib
+// A successful verification of a hash below the floor is reported to the weak algorithm hook
+// (see SetWeakAlgorithmHook), or rejected when WithPBKDF2RejectBelowMin is used.
+// 0 disables the check.
+func WithPBKDF2MinIterations(minIterations int) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.MinIterations = minIterations
+		p.MinIterationsByHash = nil
+	}
+}
+
+// WithPBKDF2MinIterationsFor sets the iteration floor of stored hashes using one hash function, e.g. "sha512",
+// leaving the floor of the others unchanged. The iteration count is part of the stored hash, so without
+// a floor an attacker able to modify rows could downgrade a hash to a single iteration.
+func WithPBKDF2MinIterationsFor(hashFuncName string, minIterations int) PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		byHash := make(map[string]int, len(p.MinIterationsByHash)+1)
+		for name, floor := range p.MinIterationsByHash {
+			byHash[name] = floor
+		}
+		byHash[hashFuncName] = minIterations
+		p.MinIterationsByHash = byHash
+	}
+}
+
+// WithPBKDF2RejectBelowMin makes Verify fail with ErrIterationsTooLow for hashes below the iteration floor
+// instead of only flagging them. Such users can no longer log in until their password is reset.
+func WithPBKDF2RejectBelowMin() PBKDF2Option {
+	return func(p *PBKDF2PasswordEncoder) {
+		p.RejectBelowMin = true
+	}
+}
+
 // NewPBKDF2PasswordEncoder creates a new PBKDF2PasswordEncoder with default parameters if not specified
 func NewPBKDF2PasswordEncoder(opts ...PBKDF2Option) *PBKDF2PasswordEncoder {
 	encoder := &PBKDF2PasswordEncoder{
-		Iterations:   10000,
-		KeyLen:       32,
-		SaltLen:      16,
-		HashFunc:     sha256.New,
-		HashFuncName: "sha256",
+		Iterations:    OWASPMinPBKDF2Iterations,
+		KeyLen:        32,
+		SaltLen:       16,
+		HashFunc:      sha256.New,
+		HashFuncName:  "sha256",
+		MinIterations: OWASPMinPBKDF2Iterations,
+		MinIterationsByHash: map[string]int{
+			"sha1":   1300000,
+			"sha512": 210000,
+		},
 	}
 	for _, opt := range opts {
 		opt(encoder)
@@ -92,69 +284,371 @@ func NewPBKDF2PasswordEncoder(opts ...PBKDF2Option) *PBKDF2PasswordEncoder {
 	return encoder
 }
 
+// NewPBKDF2PasswordEncoderE is like NewPBKDF2PasswordEncoder but rejects iterations below the floor of the
+// hash function with ErrIterationsTooLow
+func NewPBKDF2PasswordEncoderE(opts ...PBKDF2Option) (*PBKDF2PasswordEncoder, error) {
+	encoder := NewPBKDF2PasswordEncoder(opts...)
+	if err := encoder.Validate(); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// Validate checks the configured iterations against the floor of the hash function, the returned error
+// wraps ErrIterationsTooLow. Hashes below the floor would be reported as weak as soon as they are verified.
+func (p *PBKDF2PasswordEncoder) Validate() error {
+	if floor := p.minIterations(p.HashFuncName); p.Iterations < floor {
+		return fmt.Errorf("%w: %d is below %d for %s", ErrIterationsTooLow, p.Iterations, floor, p.HashFuncName)
+	}
+	return nil
+}
+
 // Encode hashes the raw password using PBKDF2
 func (p *PBKDF2PasswordEncoder) Encode(rawPassword string) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	if pbkdf2VerifyOnly[p.HashFuncName] {
+		return "", fmt.Errorf("pbkdf2: %s is only supported for verifying legacy hashes", p.HashFuncName)
+	}
+	if _, ok := pbkdf2HashFuncs[p.HashFuncName]; !ok || p.HashFunc == nil {
+		// Verify couldn't read the hash function back
+		return "", fmt.Errorf("pbkdf2: unsupported hash function %q", p.HashFuncName)
+	}
+
 	// Generate random salt
 	salt := make([]byte, p.SaltLen)
-	_, err := rand.Read(salt)
+	_, err := io.ReadFull(randReader(p.Rand), salt)
 	if err != nil {
 		return "", err
 	}
 
+	if p.Format == PBKDF2Spring {
+		hash, err := pbkdf2Key(rawPassword, p.secretSalt(salt), p.Iterations, p.KeyLen, p.HashFunc)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(append(salt, hash...)), nil
+	}
+	if p.Format == PBKDF2Passlib {
+		return p.encodePasslib(rawPassword, salt)
+	}
+
 	// Hash the password with PBKDF2
-	hash := pbkdf2.Key([]byte(rawPassword), salt, p.Iterations, p.KeyLen, p.HashFunc)
+	hash, err := pbkdf2Key(rawPassword, p.secretSalt(salt), p.Iterations, p.KeyLen, p.HashFunc)
+	if err != nil {
+		return "", err
+	}
 
-	// Format: iterations=ITERATIONS,keyLen=KEYLEN,hashFunc=HASHFUNC$BASE64_SALT$BASE64_HASH
+	// Format: iterations=ITERATIONS,keyLen=KEYLEN,hashFunc=HASHFUNC[,secret=true]$BASE64_SALT$BASE64_HASH
 	// This format allows us to retrieve the parameters when verifying
 	encodedSalt := base64.StdEncoding.EncodeToString(salt)
 	encodedHash := base64.StdEncoding.EncodeToString(hash)
+	params := fmt.Sprintf("iterations=%d,keyLen=%d,hashFunc=%s", p.Iterations, p.KeyLen, p.HashFuncName)
+	if len(p.Secret) > 0 {
+		params += ",secret=true"
+	}
+	return params + "$" + encodedSalt + "$" + encodedHash, nil
+}
+
+// EncodeWith hashes the raw password with the options applied for this call only, e.g. more iterations for admins
+func (p *PBKDF2PasswordEncoder) EncodeWith(rawPassword string, opts ...PBKDF2Option) (string, error) {
+	encoder := *p
+	for _, opt := range opts {
+		opt(&encoder)
+	}
+	return encoder.Encode(rawPassword)
+}
 
-	// Use the hash function name from the struct
-	return fmt.Sprintf("iterations=%d,keyLen=%d,hashFunc=%s$%s$%s",
-		p.Iterations, p.KeyLen, p.HashFuncName, encodedSalt, encodedHash), nil
+// DeriveKey derives a key from the password and salt with PBKDF2 and the configured iterations and hash function
+func (p *PBKDF2PasswordEncoder) DeriveKey(password string, salt []byte, length int) ([]byte, error) {
+	return pbkdf2Key(password, salt, p.Iterations, length, p.HashFunc)
 }
 
 // Verify checks if the raw password matches the encoded password
 func (p *PBKDF2PasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := p.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	belowMin := stored.iterations < p.minIterations(stored.hashFuncName)
+	if belowMin && p.RejectBelowMin {
+		return false, ErrIterationsTooLow
+	}
+
+	// Compute hash with the same parameters and salt
+	salt := stored.salt
+	if stored.secret {
+		if len(p.Secret) == 0 {
+			return false, ErrPBKDF2SecretRequired
+		}
+		salt = p.secretSalt(salt)
+	}
+	computedHash, err := pbkdf2Key(rawPassword, salt, stored.iterations, stored.keyLen, stored.hashFunc)
+	if err != nil {
+		return false, err
+	}
+
+	// Compare hashes using constant-time comparison to prevent timing attacks
+	if subtle.ConstantTimeCompare(stored.hash, computedHash) != 1 {
+		return false, nil
+	}
+	if belowMin {
+		notifyWeak(p.Name(), "iterations below minimum")
+	}
+	if pbkdf2VerifyOnly[stored.hashFuncName] {
+		notifyWeak(p.Name(), stored.hashFuncName+" hash function")
+	}
+	return true, nil
+}
+
+// ValidateEncoded checks the encoded password's parameters and lengths without verifying it.
+// Hashes below the iteration floor are rejected with ErrIterationsTooLow when RejectBelowMin is set.
+func (p *PBKDF2PasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := p.parse(encodedPassword)
+	if err != nil {
+		return err
+	}
+	switch {
+	case stored.iterations < p.minIterations(stored.hashFuncName) && p.RejectBelowMin:
+		return ErrIterationsTooLow
+	case len(stored.salt) < minSaltLen:
+		return newFormatError("pbkdf2", "salt too short", encodedPassword)
+	case stored.keyLen < 1 || len(stored.hash) != stored.keyLen:
+		return newFormatError("pbkdf2", "hash length does not match key length", encodedPassword)
+	}
+	return nil
+}
+
+// pbkdf2Hash is a parsed PBKDF2 encoded password
+type pbkdf2Hash struct {
+	iterations, keyLen int
+	hashFunc           func() hash.Hash
+	hashFuncName       string
+	salt, hash         []byte
+	secret             bool // The salt is followed by the application secret
+}
+
+// parse parses an encoded password in the passforge format, or in the Spring format with the configured
+// parameters. Spring values contain no "$", unlike the passforge format.
+func (p *PBKDF2PasswordEncoder) parse(encodedPassword string) (*pbkdf2Hash, error) {
+	if strings.HasPrefix(encodedPassword, "$pbkdf2") {
+		return parsePasslibPBKDF2(encodedPassword)
+	}
+	if p.Format != PBKDF2Spring || strings.Contains(encodedPassword, "$") {
+		return parsePBKDF2(encodedPassword)
+	}
+
+	decoded, err := hex.DecodeString(encodedPassword)
+	if err != nil || p.HashFunc == nil || p.SaltLen < 0 || p.KeyLen < 1 || len(decoded) != p.SaltLen+p.KeyLen {
+		return nil, newFormatError("pbkdf2", "invalid spring encoded password", encodedPassword)
+	}
+	return &pbkdf2Hash{
+		iterations:   p.Iterations,
+		keyLen:       p.KeyLen,
+		hashFunc:     p.HashFunc,
+		hashFuncName: p.HashFuncName,
+		salt:         decoded[:p.SaltLen],
+		hash:         decoded[p.SaltLen:],
+		secret:       len(p.Secret) > 0,
+	}, nil
+}
+
+// encodePasslib hashes the raw password into passlib's modular crypt format
+func (p *PBKDF2PasswordEncoder) encodePasslib(rawPassword string, salt []byte) (string, error) {
+	ident, ok := passlibPBKDF2Idents[p.HashFuncName]
+	if !ok || len(p.Secret) > 0 {
+		return "", fmt.Errorf("pbkdf2: the passlib format supports neither %s nor secrets", p.HashFuncName)
+	}
+	hash, err := pbkdf2Key(rawPassword, salt, p.Iterations, p.HashFunc().Size(), p.HashFunc)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$%s$%d$%s$%s", ident, p.Iterations, passlibEncoding.EncodeToString(salt),
+		passlibEncoding.EncodeToString(hash)), nil
+}
+
+// minIterations returns the iteration floor of hashes using the hash function
+func (p *PBKDF2PasswordEncoder) minIterations(hashFuncName string) int {
+	if floor, ok := p.MinIterationsByHash[hashFuncName]; ok {
+		return floor
+	}
+	return p.MinIterations
+}
+
+// secretSalt returns the salt followed by the secret, as in Spring's encoder
+func (p *PBKDF2PasswordEncoder) secretSalt(salt []byte) []byte {
+	return append(salt[:len(salt):len(salt)], p.Secret...)
+}
+
+// parsePBKDF2 parses an encoded password of the form iterations=ITERATIONS,keyLen=KEYLEN,hashFunc=HASHFUNC$BASE64_SALT$BASE64_HASH
+func parsePBKDF2(encodedPassword string) (*pbkdf2Hash, error) {
 	// Split the encoded password into parts
 	parts := strings.Split(encodedPassword, "$")
 	if len(parts) != 3 {
-		return false, fmt.Errorf("invalid encoded password format")
+		return nil, newFormatError("pbkdf2", "invalid encoded password format", encodedPassword)
 	}
 
-	// Parse parameters
-	var iterations, keyLen int
-	var hashFuncName string
-	_, err := fmt.Sscanf(parts[0], "iterations=%d,keyLen=%d,hashFunc=%s",
-		&iterations, &keyLen, &hashFuncName)
-	if err != nil {
-		return false, fmt.Errorf("invalid parameter format: %v", err)
+	var stored pbkdf2Hash
+	if err := stored.parseParams(parts[0], encodedPassword); err != nil {
+		return nil, err
 	}
 
-	// Determine hash function
-	var hashFunc func() hash.Hash
-	if hashFuncName == "sha256" {
-		hashFunc = sha256.New
-	} else {
-		return false, fmt.Errorf("unsupported hash function: %s", hashFuncName)
+	// Decode salt and hash
+	var err error
+	stored.salt, err = base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || strings.ContainsAny(parts[1], "\r\n") {
+		return nil, newFormatError("pbkdf2", "invalid salt encoding", encodedPassword)
 	}
 
-	// Decode salt and hash
-	salt, err := base64.StdEncoding.DecodeString(parts[1])
+	stored.hash, err = base64.StdEncoding.DecodeString(parts[2])
+	if err != nil || strings.ContainsAny(parts[2], "\r\n") {
+		return nil, newFormatError("pbkdf2", "invalid hash encoding", encodedPassword)
+	}
+	if len(stored.hash) != stored.keyLen {
+		return nil, pbkdf2ParamsError("hash length does not match key length", encodedPassword)
+	}
+	return &stored, nil
+}
+
+// parsePasslibPBKDF2 parses a passlib hash of the form $pbkdf2-sha256$ITERATIONS$SALT$HASH
+func parsePasslibPBKDF2(encodedPassword string) (*pbkdf2Hash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 5 || parts[0] != "" {
+		return nil, newFormatError("pbkdf2", "invalid encoded password format", encodedPassword)
+	}
+	stored := &pbkdf2Hash{}
+	for name, ident := range passlibPBKDF2Idents {
+		if parts[1] == ident {
+			stored.hashFunc, stored.hashFuncName = pbkdf2HashFuncs[name], name
+		}
+	}
+	if stored.hashFunc == nil {
+		return nil, pbkdf2ParamsError("unsupported hash function", encodedPassword)
+	}
+
+	var err error
+	stored.iterations, err = strconv.Atoi(parts[2])
+	if err != nil || stored.iterations < 1 || parts[2][0] < '1' || parts[2][0] > '9' {
+		return nil, pbkdf2ParamsError("invalid iterations", encodedPassword)
+	}
+	stored.salt, err = passlibEncoding.Strict().DecodeString(parts[3])
 	if err != nil {
-		return false, fmt.Errorf("invalid salt encoding: %v", err)
+		return nil, newFormatError("pbkdf2", "invalid salt encoding", encodedPassword)
 	}
+	stored.hash, err = passlibEncoding.Strict().DecodeString(parts[4])
+	if err != nil || len(stored.hash) != stored.hashFunc().Size() {
+		return nil, newFormatError("pbkdf2", "invalid hash encoding", encodedPassword)
+	}
+	stored.keyLen = len(stored.hash)
+	return stored, nil
+}
 
-	storedHash, err := base64.StdEncoding.DecodeString(parts[2])
+// parseParams parses the comma-separated iterations, keyLen and hashFunc parameters and the optional
+// secret marker. Each must appear at most once, in any order; unknown keys, signs, leading zeros and empty
+// values are rejected.
+func (stored *pbkdf2Hash) parseParams(params, encodedPassword string) error {
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(field, "=")
+		switch {
+		case !found || value == "":
+			return pbkdf2ParamsError("invalid parameter format", encodedPassword)
+		case seen[key]:
+			return pbkdf2ParamsError("duplicate parameter", encodedPassword)
+		}
+		seen[key] = true
+
+		switch key {
+		case "iterations", "keyLen":
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 || value[0] < '1' || value[0] > '9' {
+				return pbkdf2ParamsError("invalid "+key, encodedPassword)
+			}
+			if key == "iterations" {
+				stored.iterations = number
+			} else {
+				stored.keyLen = number
+			}
+		case "hashFunc":
+			hashFunc, ok := pbkdf2HashFuncs[value]
+			if !ok {
+				return pbkdf2ParamsError("unsupported hash function", encodedPassword)
+			}
+			stored.hashFunc, stored.hashFuncName = hashFunc, value
+		case "secret":
+			if value != "true" {
+				return pbkdf2ParamsError("invalid secret", encodedPassword)
+			}
+			stored.secret = true
+		default:
+			return pbkdf2ParamsError("unknown parameter", encodedPassword)
+		}
+	}
+	for _, key := range []string{"iterations", "keyLen", "hashFunc"} {
+		if !seen[key] {
+			return pbkdf2ParamsError("missing "+key, encodedPassword)
+		}
+	}
+	return nil
+}
+
+// pbkdf2ParamsError returns a FormatError matching ErrInvalidPBKDF2Params
+func pbkdf2ParamsError(reason, encodedPassword string) error {
+	err := newFormatError("pbkdf2", reason, encodedPassword)
+	err.Err = ErrInvalidPBKDF2Params
+	return err
+}
+
+// UpgradeEncoding reports whether the encoded password uses another hash function than the configured one,
+// or fewer iterations than configured or than the floor, or a shorter key
+func (p *PBKDF2PasswordEncoder) UpgradeEncoding(encodedPassword string) bool {
+	stored, err := p.parse(encodedPassword)
 	if err != nil {
-		return false, fmt.Errorf("invalid hash encoding: %v", err)
+		return false
 	}
+	return stored.hashFuncName != p.HashFuncName || stored.iterations < p.Iterations ||
+		stored.iterations < p.minIterations(stored.hashFuncName) || stored.keyLen < p.KeyLen
+}
 
-	// Compute hash with the same parameters and salt
-	computedHash := pbkdf2.Key([]byte(rawPassword), salt, iterations, keyLen, hashFunc)
+func init() {
+	RegisterHashFormat(&PBKDF2PasswordEncoder{})
+	RegisterTestVectors("pbkdf2", true, func(rand io.Reader) PasswordEncoder {
+		return NewPBKDF2PasswordEncoder(WithPBKDF2Rand(rand))
+	})
+	RegisterTestVectors("pbkdf2-spring", true, func(rand io.Reader) PasswordEncoder {
+		return NewPBKDF2PasswordEncoder(WithPBKDF2Spring(nil), WithPBKDF2Rand(rand))
+	})
+	RegisterTestVectors("pbkdf2-passlib", true, func(rand io.Reader) PasswordEncoder {
+		return NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Rand(rand))
+	})
+}
 
-	// Compare hashes using constant-time comparison to prevent timing attacks
-	return subtle.ConstantTimeCompare(storedHash, computedHash) == 1, nil
+// Identify reports whether the encoded password is a PBKDF2 hash in the passforge or passlib format
+func (p *PBKDF2PasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "iterations=") || strings.HasPrefix(encodedPassword, "$pbkdf2$") ||
+		strings.HasPrefix(encodedPassword, "$pbkdf2-sha256$") || strings.HasPrefix(encodedPassword, "$pbkdf2-sha512$")
+}
+
+// EncodedParams returns the parameters of the hash; passlib hashes are reported with the names of the passforge format
+func (p *PBKDF2PasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	if !strings.HasPrefix(encodedPassword, "$pbkdf2") {
+		return parseParamsHead(encodedPassword)
+	}
+	stored, err := parsePasslibPBKDF2(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations), "keyLen": strconv.Itoa(stored.keyLen),
+		"hashFunc": stored.hashFuncName}
+}
+
+// AdminStatus reports the PBKDF2 parameters and iteration floors; the secret is only reported as present
+func (p *PBKDF2PasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"iterations": p.Iterations, "keyLen": p.KeyLen, "saltLen": p.SaltLen,
+		"hashFunc": p.HashFuncName, "minIterations": p.MinIterations, "rejectBelowMin": p.RejectBelowMin, "format": p.Format.String(),
+		"minIterationsByHash": p.MinIterationsByHash, "secret": len(p.Secret) > 0}}
 }
 
 // Name returns the name of the encoder.
@@ -0,0 +1,21 @@
+package passforge
+
+import (
+	"crypto/fips140"
+	"crypto/pbkdf2"
+	"fmt"
+	"hash"
+)
+
+// pbkdf2Key derives a key with crypto/pbkdf2, which runs inside the Go Cryptographic Module when FIPS 140-3
+// mode is enabled. Errors raised by FIPS 140-only mode (GODEBUG=fips140=only) wrap ErrFIPSNotAllowed.
+func pbkdf2Key(password string, salt []byte, iterations, keyLen int, hashFunc func() hash.Hash) ([]byte, error) {
+	if keyLen < 1 {
+		return nil, ErrInvalidKeyLength
+	}
+	key, err := pbkdf2.Key(hashFunc, password, salt, iterations, keyLen)
+	if err != nil && fips140.Enabled() {
+		return nil, fmt.Errorf("%w: %w", ErrFIPSNotAllowed, err)
+	}
+	return key, err
+}
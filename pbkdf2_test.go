@@ -1,14 +1,21 @@
 package passforge
 
 import (
+	"bytes"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
 	"strings"
 	"testing"
 )
 
 func TestPBKDF2PasswordEncoder_Encode(t *testing.T) {
 	// Use smaller parameters for faster tests
-	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"))
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"), WithPBKDF2MinIterations(0))
 
 	testCases := []struct {
 		name        string
@@ -71,7 +78,7 @@ func TestPBKDF2PasswordEncoder_Encode(t *testing.T) {
 
 func TestPBKDF2PasswordEncoder_Verify(t *testing.T) {
 	// Use smaller parameters for faster tests
-	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"))
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"), WithPBKDF2MinIterations(0))
 
 	testCases := []struct {
 		name        string
@@ -131,7 +138,7 @@ func TestPBKDF2PasswordEncoder_Verify(t *testing.T) {
 }
 
 func TestPBKDF2PasswordEncoder_InvalidFormat(t *testing.T) {
-	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"))
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2KeyLen(32), WithPBKDF2SaltLen(16), WithPBKDF2HashFunc(sha256.New, "sha256"), WithPBKDF2MinIterations(0))
 
 	// Test with invalid format
 	_, err := encoder.Verify("password", "invalid-format")
@@ -190,3 +197,467 @@ func TestPBKDF2PasswordEncoder_Name(t *testing.T) {
 		t.Errorf("Name() = %v, want %v", actual, expected)
 	}
 }
+
+func TestPBKDF2PasswordEncoder_MinIterations(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoded, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0)).Encode("password123")
+
+	testCases := []struct {
+		name        string
+		encoder     *PBKDF2PasswordEncoder
+		rawPassword string
+		want        bool
+		wantErr     error
+		wantFlagged bool
+	}{
+		{
+			name:        "default floor flags",
+			encoder:     NewPBKDF2PasswordEncoder(),
+			rawPassword: "password123",
+			want:        true,
+			wantFlagged: true,
+		},
+		{
+			name:        "mismatch is not flagged",
+			encoder:     NewPBKDF2PasswordEncoder(),
+			rawPassword: "password124",
+		},
+		{
+			name:        "floor met",
+			encoder:     NewPBKDF2PasswordEncoder(WithPBKDF2MinIterations(1000)),
+			rawPassword: "password123",
+			want:        true,
+		},
+		{
+			name:        "floor disabled",
+			encoder:     NewPBKDF2PasswordEncoder(WithPBKDF2MinIterations(0)),
+			rawPassword: "password123",
+			want:        true,
+		},
+		{
+			name:        "reject below floor",
+			encoder:     NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin()),
+			rawPassword: "password123",
+			wantErr:     ErrIterationsTooLow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			flagged = nil
+			got, err := tc.encoder.Verify(tc.rawPassword, encoded)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Verify() = %v, want %v", got, tc.want)
+			}
+			if (len(flagged) == 1) != tc.wantFlagged {
+				t.Errorf("hook events = %v, want flagged %v", flagged, tc.wantFlagged)
+			}
+		})
+	}
+}
+
+func TestPBKDF2PasswordEncoder_EncodeWith(t *testing.T) {
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+
+	encoded, err := encoder.EncodeWith("password123", WithPBKDF2Iterations(2000))
+	if err != nil {
+		t.Fatalf("EncodeWith() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "iterations=2000,") || encoder.Iterations != 1000 {
+		t.Errorf("EncodeWith() = %v with Iterations = %v, want 2000 recorded and encoder unchanged", encoded, encoder.Iterations)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestPBKDF2PasswordEncoder_VerifySHA1(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	// RFC 6070 test vector
+	encoded := "iterations=4096,keyLen=20,hashFunc=sha1$c2FsdA==$SwB5AbdlSJq+rUnZJvch0GWkKcE="
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2MinIterations(0))
+
+	if match, err := encoder.Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if len(flagged) != 1 || !strings.Contains(flagged[0].Reason, "sha1") {
+		t.Errorf("hook events = %v, want one sha1 event", flagged)
+	}
+	if match, err := encoder.Verify("wrong", encoded); err != nil || match {
+		t.Errorf("Verify() = %v, %v, want false, nil", match, err)
+	}
+
+	sha1Encoder := NewPBKDF2PasswordEncoder(WithPBKDF2HashFunc(sha1.New, "sha1"))
+	if _, err := sha1Encoder.Encode("password"); err == nil {
+		t.Errorf("Encode() with sha1 expected an error")
+	}
+}
+
+func TestPBKDF2PasswordEncoder_HashFunctions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     PBKDF2Option
+		want    string
+		wantErr bool
+	}{
+		{"sha256", WithPBKDF2Hash("sha256"), "hashFunc=sha256$", false},
+		{"sha384", WithPBKDF2Hash("sha384"), "hashFunc=sha384$", false},
+		{"sha512", WithPBKDF2Hash("sha512"), "hashFunc=sha512$", false},
+		{"sha512 hash func", WithPBKDF2HashFunc(sha512.New, "sha512"), "hashFunc=sha512$", false},
+		{"sha3-256", WithPBKDF2Hash("sha3-256"), "hashFunc=sha3-256$", false},
+		{"sha3-512", WithPBKDF2Hash("sha3-512"), "hashFunc=sha3-512$", false},
+		{"sm3", WithPBKDF2Hash("sm3"), "hashFunc=sm3$", false},
+		{"unknown name", WithPBKDF2Hash("md5"), "", true},
+		{"name Verify can't read", WithPBKDF2HashFunc(sha512.New, "SHA-512"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), tt.opt, WithPBKDF2MinIterations(0))
+			encoded, err := encoder.Encode("password123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.Contains(encoded, tt.want) {
+				t.Errorf("Encode() = %v, want %v", encoded, tt.want)
+			}
+			// The hash function is read back from the encoded password
+			if match, err := NewPBKDF2PasswordEncoder().Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+		})
+	}
+
+	// Vectors computed with Python hashlib
+	encoded := "iterations=1,keyLen=64,hashFunc=sha512$c2FsdA==$hn9wzxreAs/zdSWZo6U9xK80x6ZpgVrl1RNVThyM8lLALUcKKFoFAbrZmb/pQ8CPBQI119aLHaVeY/c7YKV/zg=="
+	if match, err := NewPBKDF2PasswordEncoder().Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() of the sha512 vector = %v, %v, want true, nil", match, err)
+	}
+	encoded = "iterations=1000,keyLen=32,hashFunc=sha3-256$c2FsdHNhbHQ=$IXwcl5sa91ApazjQs5bXQyXzRp4C8Z0GWlketXo3wk0="
+	if match, err := NewPBKDF2PasswordEncoder().Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() of the sha3-256 vector = %v, %v, want true, nil", match, err)
+	}
+	encoded = "iterations=1000,keyLen=32,hashFunc=sm3$c2FsdHNhbHQ=$ObPh0OPubIP0Wdv6Mxr5XnC3oIwS6I1/IYPqX6UuDXo="
+	if match, err := NewPBKDF2PasswordEncoder().Verify("password", encoded); err != nil || !match {
+		t.Errorf("Verify() of the sm3 vector = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestPBKDF2PasswordEncoder_Spring(t *testing.T) {
+	// From Spring Security's Pbkdf2PasswordEncoderTests: new Pbkdf2PasswordEncoder("secret") with the
+	// former defaults of an 8-byte salt, 185000 iterations and PBKDF2WithHmacSHA1
+	legacy := NewPBKDF2PasswordEncoder(WithPBKDF2Spring([]byte("secret")), WithPBKDF2Hash("sha1"),
+		WithPBKDF2Iterations(185000), WithPBKDF2SaltLen(8), WithPBKDF2MinIterations(0))
+	vector := "ab1146a8458d4ce4e65789e5a3f60e423373cfa10b01abd23739e5ae2fdc37f8e9ede4ae6da65264"
+	if match, err := legacy.Verify("password", vector); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if match, err := legacy.Verify("wrong", vector); err != nil || match {
+		t.Errorf("Verify() = %v, %v, want false, nil", match, err)
+	}
+	noSecret := *legacy
+	noSecret.Secret = nil
+	if match, _ := noSecret.Verify("password", vector); match {
+		t.Errorf("Verify() without the secret = true, want false")
+	}
+
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Spring([]byte("secret")), WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if len(encoded) != 2*(16+32) || strings.Contains(encoded, "secret") {
+		t.Errorf("Encode() = %v, want 96 hex characters", encoded)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+
+	// Passforge-format hashes still verify, Spring values need the Spring format
+	passforgeEncoded, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0)).Encode("password123")
+	if match, err := encoder.Verify("password123", passforgeEncoded); err != nil || !match {
+		t.Errorf("Verify() of the passforge format = %v, %v, want true, nil", match, err)
+	}
+	if _, err := NewPBKDF2PasswordEncoder().Verify("password123", encoded); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Verify() without the Spring format error = %v, want ErrInvalidFormat", err)
+	}
+
+	for _, invalid := range []string{encoded[:len(encoded)-2], "zz" + encoded[2:], ""} {
+		if _, err := encoder.Verify("password123", invalid); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidFormat", invalid, err)
+		}
+	}
+}
+
+func TestPBKDF2PasswordEncoder_Passlib(t *testing.T) {
+	// Computed with Python's hashlib.pbkdf2_hmac and passlib's ab64 encoding
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"pbkdf2_sha256", "password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", true, false},
+		{"pbkdf2_sha512", "password", "$pbkdf2-sha512$25000$AAECAwQFBgcICQoLDA0ODw$EIJTJci4GjJFueYP2IMIxGIhpWd96facmk2yGdjyFsEUE2PrPNQnrnUVT5Ch.GNpbgjHYeabQn2L9uP6DGJOVw", true, false},
+		{"pbkdf2_sha1", "password", "$pbkdf2$131000$AAECAwQFBgcICQoLDA0ODw$qzAnUjKWb5dmfoCrQx/Gdbmy5Qc", true, false},
+		{"wrong password", "Password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", false, false},
+		{"standard base64", "password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo+Qwv7UYtHHmeg9SK49fGkEamC2c", false, true},
+		{"truncated hash", "password", "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2", false, true},
+		{"leading zero", "password", "$pbkdf2-sha256$01000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", false, true},
+		{"pbkdf2_sha384", "password", "$pbkdf2-sha384$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewPBKDF2PasswordEncoder(WithPBKDF2MinIterations(0)).Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+
+	salt := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0),
+		WithPBKDF2Rand(bytes.NewReader(salt)))
+	encoded, err := encoder.Encode("password")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if want := tests[0].encoded; encoded != want {
+		t.Errorf("Encode() = %v, want %v", encoded, want)
+	}
+	sha512Encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Hash("sha512"))
+	if encoded, err := sha512Encoder.Encode("password"); err != nil || !strings.HasPrefix(encoded, "$pbkdf2-sha512$29000$") {
+		t.Errorf("Encode() = %v, %v, want a pbkdf2_sha512 hash", encoded, err)
+	}
+	if _, err := NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2Hash("sha3-256")).Encode("password"); err == nil {
+		t.Error("Encode() with sha3-256 should fail in the passlib format")
+	}
+}
+
+func TestPBKDF2PasswordEncoder_PresetFloors(t *testing.T) {
+	var weak []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) { weak = append(weak, event) })
+	defer SetWeakAlgorithmHook(nil)
+
+	tests := []struct {
+		name    string
+		encoder *PBKDF2PasswordEncoder
+	}{
+		{"spring", NewPBKDF2PasswordEncoder(WithPBKDF2Spring([]byte("secret")), WithPBKDF2RejectBelowMin())},
+		{"passlib", NewPBKDF2PasswordEncoder(WithPBKDF2Passlib(), WithPBKDF2RejectBelowMin())},
+		{"passlib sha512", NewModularCryptEncoder().Schemes["pbkdf2-sha512"].(*PBKDF2PasswordEncoder)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weak = nil
+			encoded, err := tt.encoder.Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if match, err := tt.encoder.Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+			if len(weak) != 0 {
+				t.Errorf("Verify() of the preset's own hash reported %v", weak)
+			}
+		})
+	}
+}
+
+func TestPBKDF2PasswordEncoder_StrictParams(t *testing.T) {
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2MinIterations(0))
+	const tail = "$c2FsdHNhbHQ=$IXwcl5sa91ApazjQs5bXQyXzRp4C8Z0GWlketXo3wk0="
+
+	tests := []struct {
+		name    string
+		params  string
+		wantErr bool
+	}{
+		{"canonical", "iterations=1000,keyLen=32,hashFunc=sha3-256", false},
+		{"any order", "hashFunc=sha3-256,iterations=1000,keyLen=32", false},
+		{"trailing garbage", "iterations=1000,keyLen=32,hashFunc=sha3-256 junk", true},
+		{"trailing separator", "iterations=1000,keyLen=32,hashFunc=sha3-256,", true},
+		{"unknown key", "iterations=1000,keyLen=32,hashFunc=sha3-256,salt=x", true},
+		{"duplicate key", "iterations=1000,iterations=1000,keyLen=32,hashFunc=sha3-256", true},
+		{"missing key", "iterations=1000,hashFunc=sha3-256", true},
+		{"empty value", "iterations=,keyLen=32,hashFunc=sha3-256", true},
+		{"signed number", "iterations=+1000,keyLen=32,hashFunc=sha3-256", true},
+		{"leading zero", "iterations=01000,keyLen=32,hashFunc=sha3-256", true},
+		{"zero iterations", "iterations=0,keyLen=32,hashFunc=sha3-256", true},
+		{"overflow", "iterations=99999999999999999999,keyLen=32,hashFunc=sha3-256", true},
+		{"key length mismatch", "iterations=1000,keyLen=16,hashFunc=sha3-256", true},
+		{"unsupported hash", "iterations=1000,keyLen=32,hashFunc=md5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify("password", tt.params+tail)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidPBKDF2Params) {
+				t.Errorf("Verify() error = %v, want ErrInvalidPBKDF2Params", err)
+			}
+			if !tt.wantErr && !match {
+				t.Errorf("Verify() = false, want true")
+			}
+		})
+	}
+}
+
+func TestPBKDF2PasswordEncoder_FIPSOnly(t *testing.T) {
+	// FIPS 140-only mode can't be toggled at runtime, so the checks run in a child process
+	if os.Getenv("PASSFORGE_FIPS_ONLY") != "1" {
+		if runtime.GOOS == "js" || runtime.GOOS == "wasip1" {
+			t.Skip("subprocesses are not supported")
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPBKDF2PasswordEncoder_FIPSOnly$")
+		cmd.Env = append(os.Environ(), "PASSFORGE_FIPS_ONLY=1", "GODEBUG=fips140=only")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("FIPS 140-only run failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+
+	sha1Hash := "iterations=4096,keyLen=20,hashFunc=sha1$c2FsdHNhbHRzYWx0c2FsdA==$SwB5AbdlSJq+rUnZJvch0GWkKcE="
+	if _, err := encoder.Verify("password", sha1Hash); !errors.Is(err, ErrFIPSNotAllowed) {
+		t.Errorf("Verify() of a sha1 hash error = %v, want ErrFIPSNotAllowed", err)
+	}
+	if _, err := NewPBKDF2PasswordEncoder(WithPBKDF2SaltLen(8)).Encode("password123"); !errors.Is(err, ErrFIPSNotAllowed) {
+		t.Errorf("Encode() with an 8-byte salt error = %v, want ErrFIPSNotAllowed", err)
+	}
+}
+
+func TestPBKDF2PasswordEncoder_Secret(t *testing.T) {
+	secret := []byte("application-secret")
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2Secret(secret), WithPBKDF2MinIterations(0))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.Contains(encoded, ",secret=true$") || strings.Contains(encoded, string(secret)) {
+		t.Errorf("Encode() = %v, want the secret marker but not the secret", encoded)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+
+	if _, err := NewPBKDF2PasswordEncoder().Verify("password123", encoded); !errors.Is(err, ErrPBKDF2SecretRequired) {
+		t.Errorf("Verify() without secret error = %v, want ErrPBKDF2SecretRequired", err)
+	}
+	other := NewPBKDF2PasswordEncoder(WithPBKDF2Secret([]byte("other-secret")))
+	if match, err := other.Verify("password123", encoded); err != nil || match {
+		t.Errorf("Verify() with another secret = %v, %v, want false, nil", match, err)
+	}
+
+	// Hashes from before the secret was introduced keep verifying
+	plain, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0)).Encode("password123")
+	if match, err := encoder.Verify("password123", plain); err != nil || !match {
+		t.Errorf("Verify() of a hash without secret = %v, %v, want true, nil", match, err)
+	}
+
+	if _, err := encoder.Verify("password123", strings.Replace(encoded, "secret=true", "secret=yes", 1)); !errors.Is(err, ErrInvalidPBKDF2Params) {
+		t.Errorf("Verify() with an invalid marker error = %v, want ErrInvalidPBKDF2Params", err)
+	}
+}
+
+func TestPBKDF2PasswordEncoder_MinIterationsByHash(t *testing.T) {
+	sha512Hash, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Hash("sha512"), WithPBKDF2Iterations(300000), WithPBKDF2MinIterations(0)).Encode("password123")
+	sha256Hash, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(300000), WithPBKDF2MinIterations(0)).Encode("password123")
+	// A row modified to use a single iteration
+	downgraded, _ := NewPBKDF2PasswordEncoder(WithPBKDF2Hash("sha512"), WithPBKDF2Iterations(1), WithPBKDF2MinIterations(0)).Encode("password123")
+
+	tests := []struct {
+		name    string
+		encoder *PBKDF2PasswordEncoder
+		encoded string
+		wantErr error
+	}{
+		{"sha512 above its OWASP floor", NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin()), sha512Hash, nil},
+		{"sha256 below its OWASP floor", NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin()), sha256Hash, ErrIterationsTooLow},
+		{"downgraded row", NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin()), downgraded, ErrIterationsTooLow},
+		{"custom sha512 floor", NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin(), WithPBKDF2MinIterationsFor("sha512", 400000)), sha512Hash, ErrIterationsTooLow},
+		{"uniform floor", NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin(), WithPBKDF2MinIterations(300000)), sha256Hash, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.encoder.Verify("password123", tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := tt.encoder.ValidateEncoded(tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateEncoded() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPBKDF2PasswordEncoder_DefaultsMeetFloor(t *testing.T) {
+	var weak []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) { weak = append(weak, event) })
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2RejectBelowMin())
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+	if encoder.UpgradeEncoding(encoded) || len(weak) != 0 {
+		t.Errorf("the default encoder's own hash is reported: upgrade %v, weak %v", encoder.UpgradeEncoding(encoded), weak)
+	}
+}
+
+func TestPBKDF2PasswordEncoder_IterationsBelowFloor(t *testing.T) {
+	if _, err := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(10000)).Encode("password123"); !errors.Is(err, ErrIterationsTooLow) {
+		t.Errorf("Encode() error = %v, want ErrIterationsTooLow", err)
+	}
+	if _, err := NewPBKDF2PasswordEncoderE(WithPBKDF2Iterations(10000)); !errors.Is(err, ErrIterationsTooLow) {
+		t.Errorf("NewPBKDF2PasswordEncoderE() error = %v, want ErrIterationsTooLow", err)
+	}
+	if _, err := NewPBKDF2PasswordEncoderE(WithPBKDF2Hash("sha512"), WithPBKDF2Iterations(210000)); err != nil {
+		t.Errorf("NewPBKDF2PasswordEncoderE() error = %v, want nil at the sha512 floor", err)
+	}
+
+	// Hashes below the floor need an upgrade even when the configured iterations are lower still
+	legacy, err := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0)).Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(500), WithPBKDF2MinIterations(2000))
+	if !encoder.UpgradeEncoding(legacy) {
+		t.Error("UpgradeEncoding() = false, want true below the floor")
+	}
+}
@@ -0,0 +1,180 @@
+package passforge
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrUnknownPepper is returned when an encoded password references a pepper version that is not configured
+var ErrUnknownPepper = errors.New("unknown pepper version")
+
+// PepperedPasswordEncoder adds a secret key (pepper), kept outside the database, to another encoder.
+// The inner encoder's output is encrypted with AES-GCM under the current pepper and stored as
+// "VERSION$BASE64(nonce|ciphertext)", so a leaked database alone cannot be attacked offline.
+//
+// Unlike pre-hashing the password with HMAC(pepper, password), which can only be migrated to a new
+// pepper at the user's next login, encrypting the hash lets Rewrap move every stored value to a new
+// pepper offline, using both keys but no plaintext. A compromised pepper can be rotated fleet-wide at once.
+type PepperedPasswordEncoder struct {
+	Keyring                 // Pepper versions => AES keys of 16, 24 or 32 bytes; the current one is used by Encode and Rewrap
+	Encoder PasswordEncoder // Encoder producing the hash that is encrypted
+	Rand    io.Reader       // Source of nonces, crypto/rand.Reader when nil
+}
+
+// PepperOption is a functional option used to configure a PepperedPasswordEncoder instance.
+type PepperOption func(*PepperedPasswordEncoder)
+
+// WithPepper adds an older pepper version, still accepted by Verify and Rewrap
+func WithPepper(version string, key []byte) PepperOption {
+	return func(p *PepperedPasswordEncoder) {
+		p.Keys[version] = key
+	}
+}
+
+// WithPepperRand sets the source of nonces
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithPepperRand(r io.Reader) PepperOption {
+	return func(p *PepperedPasswordEncoder) {
+		p.Rand = r
+	}
+}
+
+// NewPepperedPasswordEncoder creates a PepperedPasswordEncoder encrypting the output of encoder with the
+// current pepper. Versions must not contain '$' and every key must be a valid AES key.
+func NewPepperedPasswordEncoder(encoder PasswordEncoder, currentVersion string, currentKey []byte, opts ...PepperOption) (*PepperedPasswordEncoder, error) {
+	peppered := &PepperedPasswordEncoder{
+		Keyring: newKeyring(currentVersion, currentKey),
+		Encoder: encoder,
+	}
+	for _, opt := range opts {
+		opt(peppered)
+	}
+
+	err := peppered.validate("pepper", func(key []byte) error {
+		_, err := aes.NewCipher(key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return peppered, nil
+}
+
+// Encode hashes the raw password with the inner encoder and encrypts the result with the current pepper
+func (p *PepperedPasswordEncoder) Encode(rawPassword string) (string, error) {
+	encoded, err := p.Encoder.Encode(rawPassword)
+	if err != nil {
+		return "", err
+	}
+	return p.seal(encoded)
+}
+
+// Verify decrypts the stored hash with the pepper it references and verifies it with the inner encoder
+func (p *PepperedPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return p.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext decrypts the encoded password and verifies it, passing ctx to the wrapped encoder
+func (p *PepperedPasswordEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	_, inner, err := p.open(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	return verifyContext(ctx, p.Encoder, rawPassword, inner)
+}
+
+// ValidateEncoded checks that the encoded password decrypts with a known pepper and is valid for the inner encoder
+func (p *PepperedPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	_, inner, err := p.open(encodedPassword)
+	if err != nil {
+		return err
+	}
+	return ValidateEncoded(p.Encoder, inner)
+}
+
+// AdminStatus reports the pepper versions, current first, but never the peppers
+func (p *PepperedPasswordEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{PepperVersions: p.Versions(), Encoders: map[string]EncoderStatus{"inner": describe(p.Encoder)}}
+}
+
+// Name returns the name of the encoder.
+func (p *PepperedPasswordEncoder) Name() string {
+	return "peppered"
+}
+
+// NeedsRewrap reports whether the encoded password is encrypted with a pepper other than the current one
+func (p *PepperedPasswordEncoder) NeedsRewrap(encodedPassword string) bool {
+	return !p.IsCurrent(encodedPassword)
+}
+
+// Rewrap re-encrypts an encoded password under the current pepper without knowing the password.
+// Values already using the current pepper are returned unchanged.
+func (p *PepperedPasswordEncoder) Rewrap(encodedPassword string) (string, error) {
+	version, inner, err := p.open(encodedPassword)
+	if err != nil {
+		return "", err
+	}
+	if version == p.CurrentVersion {
+		return encodedPassword, nil
+	}
+	return p.seal(inner)
+}
+
+// aead returns the AES-GCM cipher of the pepper version
+func (p *PepperedPasswordEncoder) aead(version string) (cipher.AEAD, error) {
+	key, ok := p.Key(version)
+	if !ok {
+		return nil, ErrUnknownPepper
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the inner encoded password with the current pepper
+func (p *PepperedPasswordEncoder) seal(inner string) (string, error) {
+	aead, err := p.aead(p.CurrentVersion)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(randReader(p.Rand), nonce); err != nil {
+		return "", err
+	}
+
+	// The version is authenticated so a value can't be relabelled to another pepper
+	sealed := aead.Seal(nonce, nonce, []byte(inner), []byte(p.CurrentVersion))
+
+	// Format: VERSION$BASE64(NONCE|CIPHERTEXT)
+	return p.CurrentVersion + "$" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts an encoded password, returning its pepper version and the inner encoded password
+func (p *PepperedPasswordEncoder) open(encodedPassword string) (string, string, error) {
+	version, payload, found := strings.Cut(encodedPassword, "$")
+	if !found {
+		return "", "", newFormatError("peppered", "invalid encoded password format", encodedPassword)
+	}
+	aead, err := p.aead(version)
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", "", newFormatError("peppered", "invalid ciphertext encoding", encodedPassword)
+	}
+	inner, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(version))
+	if err != nil {
+		return "", "", newFormatError("peppered", "decryption failed", encodedPassword)
+	}
+	return version, string(inner), nil
+}
@@ -0,0 +1,109 @@
+package passforge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	testPepperV1 = bytes.Repeat([]byte{1}, 32)
+	testPepperV2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestNewPepperedPasswordEncoder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version string
+		key     []byte
+		opts    []PepperOption
+		wantErr bool
+	}{
+		{name: "valid", version: "v1", key: testPepperV1},
+		{name: "short key", version: "v1", key: []byte("short"), wantErr: true},
+		{name: "empty version", version: "", key: testPepperV1, wantErr: true},
+		{name: "version with separator", version: "v$1", key: testPepperV1, wantErr: true},
+		{name: "invalid older key", version: "v2", key: testPepperV2, opts: []PepperOption{WithPepper("v1", nil)}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPepperedPasswordEncoder(NewNoOpPasswordEncoder(), tc.version, tc.key, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NewPepperedPasswordEncoder() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPepperedPasswordEncoder_EncodeVerify(t *testing.T) {
+	encoder, _ := NewPepperedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), "v1", testPepperV1)
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "v1$") || strings.Contains(encoded, "$2a$") {
+		t.Errorf("Encode() = %v, want encrypted value with version prefix", encoded)
+	}
+
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if match, _ := encoder.Verify("password124", encoded); match {
+		t.Errorf("Verify() = true for wrong password")
+	}
+
+	// Another pepper can't decrypt the value
+	other, _ := NewPepperedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), "v1", testPepperV2)
+	if _, err := other.Verify("password123", encoded); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Verify() with wrong pepper error = %v, want ErrInvalidFormat", err)
+	}
+
+	// Relabelling the version is detected
+	relabelled, _ := NewPepperedPasswordEncoder(NewBcryptPasswordEncoder(WithCost(4)), "v2", testPepperV1)
+	if _, err := relabelled.Verify("password123", "v2"+strings.TrimPrefix(encoded, "v1")); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Verify() of relabelled value error = %v, want ErrInvalidFormat", err)
+	}
+
+	if _, err := encoder.Verify("password123", "v9$AAAA"); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("Verify() error = %v, want ErrUnknownPepper", err)
+	}
+	if _, err := encoder.Verify("password123", "no-separator"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Verify() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestPepperedPasswordEncoder_Rewrap(t *testing.T) {
+	inner := NewBcryptPasswordEncoder(WithCost(4))
+	old, _ := NewPepperedPasswordEncoder(inner, "v1", testPepperV1)
+	encoded, _ := old.Encode("password123")
+
+	rotated, _ := NewPepperedPasswordEncoder(inner, "v2", testPepperV2, WithPepper("v1", testPepperV1))
+	if !rotated.NeedsRewrap(encoded) {
+		t.Errorf("NeedsRewrap() = false for value under old pepper")
+	}
+
+	rewrapped, err := rotated.Rewrap(encoded)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+	if !strings.HasPrefix(rewrapped, "v2$") || rotated.NeedsRewrap(rewrapped) {
+		t.Errorf("Rewrap() = %v, want value under v2", rewrapped)
+	}
+
+	// Once v1 is retired, only the rewrapped value still verifies
+	retired, _ := NewPepperedPasswordEncoder(inner, "v2", testPepperV2)
+	if match, err := retired.Verify("password123", rewrapped); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if _, err := retired.Verify("password123", encoded); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("Verify() error = %v, want ErrUnknownPepper", err)
+	}
+
+	// Values already under the current pepper are left untouched
+	if again, _ := rotated.Rewrap(rewrapped); again != rewrapped {
+		t.Errorf("Rewrap() changed a value already under the current pepper")
+	}
+}
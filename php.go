@@ -0,0 +1,132 @@
+package passforge
+
+import (
+	"strings"
+)
+
+// PHPAlgorithm selects the algorithm PHPCompatEncoder encodes with, named like PHP's PASSWORD_* constants
+type PHPAlgorithm int
+
+const (
+	// PHPBcrypt produces $2y$ hashes, PASSWORD_BCRYPT and PASSWORD_DEFAULT
+	PHPBcrypt PHPAlgorithm = iota
+	// PHPArgon2i produces $argon2i$ PHC strings, PASSWORD_ARGON2I
+	PHPArgon2i
+	// PHPArgon2id produces $argon2id$ PHC strings, PASSWORD_ARGON2ID
+	PHPArgon2id
+)
+
+// String returns the value of the PHP constant, e.g. "2y"
+func (a PHPAlgorithm) String() string {
+	switch a {
+	case PHPArgon2i:
+		return "argon2i"
+	case PHPArgon2id:
+		return "argon2id"
+	}
+	return "2y"
+}
+
+// PHPCompatEncoder is a password encoder reading and writing the output of PHP's password_hash(), as stored
+// by Laravel and Symfony applications: $2y$ bcrypt hashes and $argon2i$ or $argon2id$ PHC strings. It picks
+// the algorithm from the hash itself, so existing values need no "{id}" prefix when verified directly.
+// Like PHP, bcrypt only uses the first 72 bytes of the password.
+type PHPCompatEncoder struct {
+	Algorithm PHPAlgorithm           // Algorithm of new hashes
+	Bcrypt    *BcryptPasswordEncoder // Encoder of bcrypt hashes
+	Argon2    *Argon2PasswordEncoder // Encoder of Argon2 hashes, its variant follows Algorithm
+}
+
+// PHPCompatOption is a functional option used to configure a PHPCompatEncoder instance.
+type PHPCompatOption func(*PHPCompatEncoder)
+
+// WithPHPAlgorithm sets the algorithm of new hashes
+// Default: PHPBcrypt, PHP's PASSWORD_DEFAULT
+func WithPHPAlgorithm(algorithm PHPAlgorithm) PHPCompatOption {
+	return func(p *PHPCompatEncoder) {
+		p.Algorithm = algorithm
+	}
+}
+
+// WithPHPBcryptCost sets the bcrypt cost
+// Default: 12, as in PHP 8.4
+func WithPHPBcryptCost(cost int) PHPCompatOption {
+	return func(p *PHPCompatEncoder) {
+		p.Bcrypt.Cost = cost
+	}
+}
+
+// WithPHPArgon2Options sets the Argon2 memory in KiB, iterations and threads, PHP's memory_cost, time_cost and threads
+// Default: 65536, 4 and 1, PHP's PASSWORD_ARGON2_DEFAULT_* values
+func WithPHPArgon2Options(memoryCost, timeCost uint32, threads uint8) PHPCompatOption {
+	return func(p *PHPCompatEncoder) {
+		p.Argon2.Memory, p.Argon2.Time, p.Argon2.Threads = memoryCost, timeCost, threads
+	}
+}
+
+// NewPHPCompatEncoder creates a new PHPCompatEncoder with PHP's default parameters if not specified
+func NewPHPCompatEncoder(opts ...PHPCompatOption) *PHPCompatEncoder {
+	encoder := &PHPCompatEncoder{
+		Bcrypt: NewBcryptPasswordEncoder(WithCost(12), WithBcryptVariant(Bcrypt2y), WithBcryptLongPassword(BcryptTruncateLong)),
+		Argon2: NewArgon2PasswordEncoder(WithArgon2PHC(), WithArgon2Memory(65536), WithArgon2Time(4),
+			WithArgon2Threads(1), WithArgon2SaltLen(16), WithArgon2KeyLen(32)),
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password like password_hash() with the configured algorithm
+func (p *PHPCompatEncoder) Encode(rawPassword string) (string, error) {
+	switch p.Algorithm {
+	case PHPArgon2i:
+		return p.Argon2.EncodeWith(rawPassword, WithArgon2Variant(Argon2i))
+	case PHPArgon2id:
+		return p.Argon2.EncodeWith(rawPassword, WithArgon2Variant(Argon2id))
+	}
+	return p.Bcrypt.Encode(rawPassword)
+}
+
+// Verify checks the raw password like password_verify(), with the algorithm recorded in the hash
+func (p *PHPCompatEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	encoder, err := p.encoderFor(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	return encoder.Verify(rawPassword, encodedPassword)
+}
+
+// ValidateEncoded checks the encoded password with the encoder of its algorithm
+func (p *PHPCompatEncoder) ValidateEncoded(encodedPassword string) error {
+	encoder, err := p.encoderFor(encodedPassword)
+	if err != nil {
+		return err
+	}
+	return ValidateEncoded(encoder, encodedPassword)
+}
+
+// AdminStatus reports the algorithm of new hashes and both underlying encoders
+func (p *PHPCompatEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{
+		Params:   map[string]interface{}{"algorithm": p.Algorithm.String()},
+		Encoders: map[string]EncoderStatus{"bcrypt": describe(p.Bcrypt), "argon2": describe(p.Argon2)},
+	}
+}
+
+// Name returns the name of the encoder.
+func (p *PHPCompatEncoder) Name() string {
+	return "php"
+}
+
+// encoderFor returns the encoder of the algorithm password_hash() recorded in the hash
+func (p *PHPCompatEncoder) encoderFor(encodedPassword string) (PasswordEncoder, error) {
+	switch {
+	case strings.HasPrefix(encodedPassword, "$2y$"), strings.HasPrefix(encodedPassword, "$2a$"),
+		strings.HasPrefix(encodedPassword, "$2b$"):
+		return p.Bcrypt, nil
+	case strings.HasPrefix(encodedPassword, "$argon2i$"), strings.HasPrefix(encodedPassword, "$argon2id$"):
+		return p.Argon2, nil
+	}
+	return nil, newFormatError("php", "unsupported algorithm", encodedPassword)
+}
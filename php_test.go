@@ -0,0 +1,85 @@
+package passforge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPHPCompatEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name       string
+		encoder    *PHPCompatEncoder
+		wantPrefix string
+	}{
+		{"bcrypt", NewPHPCompatEncoder(WithPHPBcryptCost(4)), "$2y$04$"},
+		{"argon2i", NewPHPCompatEncoder(WithPHPAlgorithm(PHPArgon2i), WithPHPArgon2Options(1024, 2, 1)), "$argon2i$v=19$m=1024,t=2,p=1$"},
+		{"argon2id", NewPHPCompatEncoder(WithPHPAlgorithm(PHPArgon2id), WithPHPArgon2Options(1024, 2, 1)), "$argon2id$v=19$m=1024,t=2,p=1$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encoder.Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.wantPrefix) {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.wantPrefix)
+			}
+			// Verify doesn't depend on the configured algorithm
+			if match, err := NewPHPCompatEncoder().Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+			if err := tt.encoder.ValidateEncoded(encoded); err != nil {
+				t.Errorf("ValidateEncoded() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPHPCompatEncoder_Verify(t *testing.T) {
+	encoder := NewPHPCompatEncoder()
+
+	// Hashes from the password_hash() examples of the PHP manual
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"bcrypt", "rasmuslerdorf", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a", true, false},
+		{"argon2i", "rasmuslerdorf", "$argon2i$v=19$m=1024,t=2,p=2$YzJBSzV4TUhkMzc3d3laeg$zqU/1IN0/AogfP4cmSJI1vc8lpXRW9/S0sYY2i2jHT0", true, false},
+		{"wrong password", "rasmus", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a", false, false},
+		{"md5-crypt", "rasmuslerdorf", "$1$rasmusle$rISCgZzpwk3UhDidwXvin0", false, true},
+		{"passforge argon2 format", "password", "time=1,memory=65536,threads=4,keyLen=32,v=19$c2FsdA==$aGFzaA==", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestPHPCompatEncoder_LongPassword(t *testing.T) {
+	// PHP's bcrypt ignores everything after 72 bytes
+	encoder := NewPHPCompatEncoder(WithPHPBcryptCost(4))
+	prefix := strings.Repeat("a", 72)
+	encoded, err := encoder.Encode(prefix + "b")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if match, err := encoder.Verify(prefix+"c", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestPHPCompatEncoder_Name(t *testing.T) {
+	if name := NewPHPCompatEncoder().Name(); name != "php" {
+		t.Errorf("Name() = %v, want php", name)
+	}
+}
@@ -0,0 +1,128 @@
+package passforge
+
+import (
+	"crypto/md5"
+	"crypto/sha512"
+	"crypto/subtle"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// PhpassPasswordEncoder is a verify-only password encoder for the portable hashes of phpass, used by
+// WordPress ($P$) and phpBB ($H$): $P$ + log2 of the iteration count + an 8-character salt + 22 characters
+// of iterated MD5. The hashes are MD5-based; every successful verification is reported to the weak
+// algorithm hook, and AuthService rehashes the password with the default encoder on login.
+type PhpassPasswordEncoder struct{}
+
+// NewPhpassPasswordEncoder creates a new PhpassPasswordEncoder
+func NewPhpassPasswordEncoder() *PhpassPasswordEncoder {
+	return &PhpassPasswordEncoder{}
+}
+
+// Encode is not supported: portable hashes must not be produced for new passwords
+func (p *PhpassPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify checks if the raw password matches the encoded password
+func (p *PhpassPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := p.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if !stored.matches(rawPassword) {
+		return false, nil
+	}
+	notifyWeak(p.Name(), "md5 portable hash")
+	return true, nil
+}
+
+// ValidateEncoded rejects every portable hash once it is well-formed, as they are MD5-based
+func (p *PhpassPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	if _, err := p.parse(encodedPassword); err != nil {
+		return err
+	}
+	return newFormatError("phpass", "md5 portable hash", encodedPassword)
+}
+
+func init() {
+	RegisterHashFormat(&PhpassPasswordEncoder{})
+}
+
+// Identify reports whether the encoded password is a phpass portable hash
+func (p *PhpassPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$P$") || strings.HasPrefix(encodedPassword, "$H$")
+}
+
+// EncodedParams returns the iterations of the hash
+func (p *PhpassPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parsePhpass(p.Name(), encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations)}
+}
+
+// Name returns the name of the encoder.
+func (p *PhpassPasswordEncoder) Name() string {
+	return "phpass"
+}
+
+// parse parses an MD5 portable hash, $P$ or $H$
+func (p *PhpassPasswordEncoder) parse(encodedPassword string) (*phpassHash, error) {
+	if !strings.HasPrefix(encodedPassword, "$P$") && !strings.HasPrefix(encodedPassword, "$H$") {
+		return nil, newFormatError("phpass", "invalid encoded password format", encodedPassword)
+	}
+	return parsePhpass("phpass", encodedPassword)
+}
+
+// phpassHash is a parsed portable hash
+type phpassHash struct {
+	newHash    func() hash.Hash
+	iterations int
+	salt       string
+	checksum   string // Encoded hash, truncated for $S$
+}
+
+// parsePhpass parses a portable hash: $P$ or $H$ for MD5 and Drupal's $S$ for SHA-512, followed by the
+// log2 of the iteration count, an 8-character salt and the hash. MD5 hashes are 34 characters long;
+// Drupal truncates SHA-512 hashes to 55.
+func parsePhpass(encoder, encodedPassword string) (*phpassHash, error) {
+	stored := &phpassHash{}
+	switch {
+	case len(encodedPassword) == 34 && (strings.HasPrefix(encodedPassword, "$P$") || strings.HasPrefix(encodedPassword, "$H$")):
+		stored.newHash = md5.New
+	case len(encodedPassword) == 55 && strings.HasPrefix(encodedPassword, "$S$"):
+		stored.newHash = sha512.New
+	default:
+		return nil, newFormatError(encoder, "invalid encoded password format", encodedPassword)
+	}
+	// phpass accepts 2^7 to 2^30 iterations
+	logCount := strings.IndexByte(crypt64, encodedPassword[3])
+	if logCount < 7 || logCount > 30 {
+		return nil, newFormatError(encoder, "invalid iteration count", encodedPassword)
+	}
+	stored.iterations, stored.salt, stored.checksum = 1<<logCount, encodedPassword[4:12], encodedPassword[12:]
+	if strings.Trim(stored.checksum, crypt64) != "" {
+		return nil, newFormatError(encoder, "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// matches reports whether the raw password hashes to the stored checksum
+func (h *phpassHash) matches(rawPassword string) bool {
+	password := []byte(rawPassword)
+	digest := h.newHash()
+	digest.Write([]byte(h.salt))
+	digest.Write(password)
+	sum := digest.Sum(nil)
+	for range h.iterations {
+		digest.Reset()
+		digest.Write(sum)
+		digest.Write(password)
+		sum = digest.Sum(sum[:0])
+	}
+	computed := encodeCrypt64(sum)[:len(h.checksum)]
+	return subtle.ConstantTimeCompare([]byte(h.checksum), []byte(computed)) == 1
+}
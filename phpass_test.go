@@ -0,0 +1,83 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPhpassPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewPhpassPasswordEncoder()
+
+	// The first hash is from phpass's test suite, the others were computed with a port of its PHP implementation
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"phpass", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", true, false},
+		{"wordpress", "password", "$P$BsaltSALTO/FPL1VMMPAb3U9I4RIv/1", true, false},
+		{"phpbb", "password", "$H$9saltsaltTPYWOFleH9nxJ26A2VSHl1", true, false},
+		{"empty password", "", "$P$7abcdefghYnUeeP9OIovbnQLOKRJX7/", true, false},
+		{"wrong password", "test1234", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", false, false},
+		{"iteration count too low", "test12345", "$P$4IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", false, true},
+		{"iteration count too high", "test12345", "$P$UIQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", false, true},
+		{"truncated hash", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L", false, true},
+		{"invalid hash encoding", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L!", false, true},
+		{"md5-crypt", "password", "$1$saltsalt$qjXMvbEw8oaL.CzflDugX.", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhpassPasswordEncoder_Weak(t *testing.T) {
+	var flagged []WeakAlgorithmEvent
+	SetWeakAlgorithmHook(func(event WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer SetWeakAlgorithmHook(nil)
+
+	encoder := NewPhpassPasswordEncoder()
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+	if match, _ := encoder.Verify("test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0"); !match || len(flagged) != 1 || flagged[0].Algorithm != "phpass" {
+		t.Errorf("Verify() = %v, flagged %v, want one phpass event", match, flagged)
+	}
+	if err := encoder.ValidateEncoded("$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidateEncoded() error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestPhpassPasswordEncoder_UpgradeOnLogin(t *testing.T) {
+	ctx := context.Background()
+	delegating, _ := NewDelegatingPasswordEncoder("bcrypt", NewBcryptPasswordEncoder(WithCost(4)), NewPhpassPasswordEncoder())
+	store := NewMemoryCredentialStore()
+	_ = store.UpdateHash(ctx, "alice", "{phpass}$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0")
+
+	service := NewAuthService(store, delegating)
+	if match, err := service.Authenticate(ctx, "alice", "test12345"); err != nil || !match {
+		t.Fatalf("Authenticate() = %v, %v, want true, nil", match, err)
+	}
+	if upgraded, _ := store.FindHash(ctx, "alice"); !strings.HasPrefix(upgraded, "{bcrypt}") {
+		t.Errorf("stored hash = %v, want a {bcrypt} hash", upgraded)
+	}
+}
+
+func TestPhpassPasswordEncoder_Name(t *testing.T) {
+	if name := NewPhpassPasswordEncoder().Name(); name != "phpass" {
+		t.Errorf("Name() = %v, want phpass", name)
+	}
+}
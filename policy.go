@@ -0,0 +1,36 @@
+package passforge
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// ErrPasswordTooShort is returned when a password is shorter than the policy allows
+var ErrPasswordTooShort = errors.New("password too short")
+
+// ErrPasswordTooLong is returned when a password is longer than the policy or algorithm allows
+var ErrPasswordTooLong = errors.New("password too long")
+
+// PasswordPolicy validates raw passwords before they are encoded and stored
+type PasswordPolicy interface {
+	// Validate returns an error if the raw password is not acceptable
+	Validate(rawPassword string) error
+}
+
+// LengthPolicy is a PasswordPolicy enforcing minimum and maximum lengths counted in characters
+type LengthPolicy struct {
+	Min int // Minimum number of characters
+	Max int // Maximum number of characters, 0 means unlimited
+}
+
+// Validate checks the length of the raw password
+func (l LengthPolicy) Validate(rawPassword string) error {
+	n := utf8.RuneCountInString(rawPassword)
+	if n < l.Min {
+		return ErrPasswordTooShort
+	}
+	if l.Max > 0 && n > l.Max {
+		return ErrPasswordTooLong
+	}
+	return nil
+}
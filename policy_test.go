@@ -0,0 +1,51 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLengthPolicy_Validate(t *testing.T) {
+	policy := LengthPolicy{Min: 8, Max: 12}
+
+	testCases := []struct {
+		name        string
+		rawPassword string
+		wantErr     error
+	}{
+		{
+			name:        "valid password",
+			rawPassword: "password123",
+			wantErr:     nil,
+		},
+		{
+			name:        "too short",
+			rawPassword: "short",
+			wantErr:     ErrPasswordTooShort,
+		},
+		{
+			name:        "too long",
+			rawPassword: "thisisaverylongpassword",
+			wantErr:     ErrPasswordTooLong,
+		},
+		{
+			name:        "multi-byte characters counted once",
+			rawPassword: "пароль-пароль",
+			wantErr:     ErrPasswordTooLong,
+		},
+		{
+			name:        "multi-byte characters within bounds",
+			rawPassword: "пароль12",
+			wantErr:     nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate(tc.rawPassword)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package passforge
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// randReader returns r, or crypto/rand.Reader when r is nil
+func randReader(r io.Reader) io.Reader {
+	if r == nil {
+		return rand.Reader
+	}
+	return r
+}
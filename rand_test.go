@@ -0,0 +1,53 @@
+package passforge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRandSource_Deterministic(t *testing.T) {
+	fixed := func() *bytes.Reader { return bytes.NewReader(bytes.Repeat([]byte{0x42}, 64)) }
+
+	testCases := []struct {
+		name    string
+		encoder func() PasswordEncoder
+	}{
+		{
+			name: "argon2",
+			encoder: func() PasswordEncoder {
+				return NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Rand(fixed()))
+			},
+		},
+		{
+			name:    "scrypt",
+			encoder: func() PasswordEncoder { return NewScryptPasswordEncoder(WithScryptN(1024), WithScryptRand(fixed())) },
+		},
+		{
+			name: "pbkdf2",
+			encoder: func() PasswordEncoder {
+				return NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0), WithPBKDF2Rand(fixed()))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := tc.encoder().Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			second, _ := tc.encoder().Encode("password123")
+			if first != second {
+				t.Errorf("Encode() with fixed rand is not deterministic: %v != %v", first, second)
+			}
+		})
+	}
+}
+
+func TestRandSource_ShortRead(t *testing.T) {
+	encoder := NewArgon2PasswordEncoder(WithArgon2Rand(bytes.NewReader([]byte{1, 2, 3})))
+
+	if _, err := encoder.Encode("password123"); err == nil {
+		t.Errorf("Encode() with exhausted rand source should return error")
+	}
+}
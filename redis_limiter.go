@@ -0,0 +1,246 @@
+package passforge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RedisClient is the subset of a Redis client used by RedisAttemptLimiter.
+// It is satisfied by a thin adapter around go-redis, e.g.
+//
+//	func (a adapter) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		return a.client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisStrategy selects the rate limiting algorithm used by RedisAttemptLimiter
+type RedisStrategy int
+
+const (
+	// RedisSlidingWindow allows at most Limit failures within any Window
+	RedisSlidingWindow RedisStrategy = iota
+	// RedisTokenBucket allows bursts of Limit failures, refilling Limit tokens per Window
+	RedisTokenBucket
+)
+
+const (
+	redisSlidingAllowScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', tonumber(ARGV[1]) - tonumber(ARGV[2]))
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then return 1 end
+return 0`
+
+	redisSlidingFailScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', tonumber(ARGV[1]) - tonumber(ARGV[2]))
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1`
+
+	redisSlidingReserveScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', tonumber(ARGV[1]) - tonumber(ARGV[2]))
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then return 0 end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1`
+
+	redisSlidingReleaseScript = `
+redis.call('ZPOPMAX', KEYS[1])
+return 1`
+
+	redisBucketAllowScript = `
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local now, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local tokens = tonumber(b[1]) or limit
+local ts = tonumber(b[2]) or now
+tokens = math.min(limit, tokens + (now - ts) * limit / window)
+if tokens >= 1 then return 1 end
+return 0`
+
+	redisBucketFailScript = `
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local now, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local tokens = tonumber(b[1]) or limit
+local ts = tonumber(b[2]) or now
+tokens = math.max(0, math.min(limit, tokens + (now - ts) * limit / window) - 1)
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return 1`
+
+	redisBucketReserveScript = `
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local now, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local tokens = tonumber(b[1]) or limit
+local ts = tonumber(b[2]) or now
+tokens = math.min(limit, tokens + (now - ts) * limit / window)
+if tokens < 1 then return 0 end
+redis.call('HSET', KEYS[1], 'tokens', tokens - 1, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return 1`
+
+	redisBucketReleaseScript = `
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local now, window, limit = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local tokens = tonumber(b[1]) or limit
+local ts = tonumber(b[2]) or now
+tokens = math.min(limit, tokens + (now - ts) * limit / window + 1)
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return 1`
+
+	redisResetScript = `return redis.call('DEL', KEYS[1])`
+)
+
+// RedisAttemptLimiter is an AttemptLimiter backed by Redis so that horizontally scaled
+// services share brute-force counters. Every operation is a single, atomic Lua script. It implements
+// AttemptReserver, so LimitedPasswordEncoder checks and records each attempt in one script before
+// verifying, and the limit holds for concurrent attempts across replicas.
+type RedisAttemptLimiter struct {
+	Client   RedisClient      // Redis client
+	Prefix   string           // Key prefix
+	Limit    int              // Maximum number of failures (or bucket capacity)
+	Window   time.Duration    // Sliding window length (or bucket refill period)
+	Strategy RedisStrategy    // Rate limiting algorithm
+	Now      func() time.Time // Time source
+}
+
+// RedisLimiterOption is a functional option used to configure a RedisAttemptLimiter instance.
+type RedisLimiterOption func(*RedisAttemptLimiter)
+
+// WithRedisPrefix sets the prefix of all keys written to Redis
+// Default: "passforge:attempts:"
+func WithRedisPrefix(prefix string) RedisLimiterOption {
+	return func(r *RedisAttemptLimiter) {
+		r.Prefix = prefix
+	}
+}
+
+// WithRedisLimit sets the number of failures allowed per window
+// Default: 5
+func WithRedisLimit(limit int) RedisLimiterOption {
+	return func(r *RedisAttemptLimiter) {
+		r.Limit = limit
+	}
+}
+
+// WithRedisWindow sets the window length
+// Default: 15 minutes
+func WithRedisWindow(window time.Duration) RedisLimiterOption {
+	return func(r *RedisAttemptLimiter) {
+		r.Window = window
+	}
+}
+
+// WithRedisStrategy sets the rate limiting algorithm
+// Default: RedisSlidingWindow
+func WithRedisStrategy(strategy RedisStrategy) RedisLimiterOption {
+	return func(r *RedisAttemptLimiter) {
+		r.Strategy = strategy
+	}
+}
+
+// NewRedisAttemptLimiter creates a new RedisAttemptLimiter with default parameters if not specified
+func NewRedisAttemptLimiter(client RedisClient, opts ...RedisLimiterOption) *RedisAttemptLimiter {
+	limiter := &RedisAttemptLimiter{
+		Client:   client,
+		Prefix:   "passforge:attempts:",
+		Limit:    5,
+		Window:   15 * time.Minute,
+		Strategy: RedisSlidingWindow,
+		Now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// Allow reports whether another attempt is permitted for the key
+func (r *RedisAttemptLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	script := redisSlidingAllowScript
+	if r.Strategy == RedisTokenBucket {
+		script = redisBucketAllowScript
+	}
+	res, err := r.Client.Eval(ctx, script, []string{r.Prefix + key}, r.args()...)
+	if err != nil {
+		return false, err
+	}
+	allowed, ok := res.(int64)
+	if !ok {
+		return false, fmt.Errorf("redis limiter: unexpected reply type %T", res)
+	}
+	return allowed == 1, nil
+}
+
+// Fail records a failed attempt for the key
+func (r *RedisAttemptLimiter) Fail(ctx context.Context, key string) error {
+	script := redisSlidingFailScript
+	args := r.args()
+	if r.Strategy == RedisTokenBucket {
+		script = redisBucketFailScript
+	} else {
+		member, err := r.member()
+		if err != nil {
+			return err
+		}
+		args = append(args, member)
+	}
+	_, err := r.Client.Eval(ctx, script, []string{r.Prefix + key}, args...)
+	return err
+}
+
+// Reserve records an attempt for the key if another one is permitted, in a single script
+func (r *RedisAttemptLimiter) Reserve(ctx context.Context, key string) (bool, error) {
+	script := redisBucketReserveScript
+	args := r.args()
+	if r.Strategy != RedisTokenBucket {
+		script = redisSlidingReserveScript
+		member, err := r.member()
+		if err != nil {
+			return false, err
+		}
+		args = append(args, member)
+	}
+	res, err := r.Client.Eval(ctx, script, []string{r.Prefix + key}, args...)
+	if err != nil {
+		return false, err
+	}
+	reserved, ok := res.(int64)
+	if !ok {
+		return false, fmt.Errorf("redis limiter: unexpected reply type %T", res)
+	}
+	return reserved == 1, nil
+}
+
+// Release gives back an attempt reserved for the key: the latest one in the sliding window, or a token
+func (r *RedisAttemptLimiter) Release(ctx context.Context, key string) error {
+	script := redisSlidingReleaseScript
+	if r.Strategy == RedisTokenBucket {
+		script = redisBucketReleaseScript
+	}
+	_, err := r.Client.Eval(ctx, script, []string{r.Prefix + key}, r.args()...)
+	return err
+}
+
+// Reset clears the recorded failures for the key
+func (r *RedisAttemptLimiter) Reset(ctx context.Context, key string) error {
+	_, err := r.Client.Eval(ctx, redisResetScript, []string{r.Prefix + key})
+	return err
+}
+
+// args returns the common script arguments: now and window in milliseconds, and the limit
+func (r *RedisAttemptLimiter) args() []interface{} {
+	return []interface{}{r.Now().UnixMilli(), r.Window.Milliseconds(), r.Limit}
+}
+
+// member returns a random sorted set member; members must be unique, even for attempts within the same millisecond
+func (r *RedisAttemptLimiter) member() (string, error) {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(member), nil
+}
@@ -0,0 +1,87 @@
+//go:build integration
+
+package passforge
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// respClient is a minimal RedisClient speaking RESP over a single connection, enough to run the limiter
+// scripts without a Redis client dependency
+type respClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *respClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+	}
+	command := append([]string{"EVAL", script, strconv.Itoa(len(keys))}, keys...)
+	for _, arg := range args {
+		command = append(command, fmt.Sprint(arg))
+	}
+	var request strings.Builder
+	fmt.Fprintf(&request, "*%d\r\n", len(command))
+	for _, part := range command {
+		fmt.Fprintf(&request, "$%d\r\n%s\r\n", len(part), part)
+	}
+	if _, err := c.conn.Write([]byte(request.String())); err != nil {
+		return nil, err
+	}
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+	switch line[0] {
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '-':
+		return nil, errors.New(line[1:])
+	}
+	return nil, fmt.Errorf("unsupported reply %q", line)
+}
+
+// TestRedisAttemptLimiter_Integration runs the limiter scripts against the Redis server at
+// PASSFORGE_REDIS_ADDR (default localhost:6379): go test -tags integration -run Integration .
+func TestRedisAttemptLimiter_Integration(t *testing.T) {
+	addr := os.Getenv("PASSFORGE_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("cannot connect to Redis at %s: %v", addr, err)
+	}
+	defer conn.Close()
+	client := &respClient{conn: conn, reader: bufio.NewReader(conn)}
+
+	// Keys are unique per run so that repeated runs don't see each other's failures
+	prefix := fmt.Sprintf("passforge:test:%d:", time.Now().UnixNano())
+	t.Run("sliding window", func(t *testing.T) {
+		testRedisSlidingWindow(t, client, prefix+"sliding:")
+	})
+	t.Run("token bucket", func(t *testing.T) {
+		testRedisTokenBucket(t, client, prefix+"bucket:")
+	})
+	t.Run("reset", func(t *testing.T) {
+		testRedisReset(t, client, prefix+"reset:")
+	})
+	t.Run("reserve", func(t *testing.T) {
+		testRedisReserve(t, client, prefix+"reserve:")
+	})
+}
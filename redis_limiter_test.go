@@ -0,0 +1,237 @@
+package passforge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisCommands lists the Redis commands of each limiter script that fakeRedis emulates. The real
+// scripts run against Redis in redis_limiter_integration_test.go.
+var fakeRedisCommands = map[string][]string{
+	redisSlidingAllowScript:   {"ZREMRANGEBYSCORE", "ZCARD"},
+	redisSlidingFailScript:    {"ZREMRANGEBYSCORE", "ZADD", "PEXPIRE"},
+	redisSlidingReserveScript: {"ZREMRANGEBYSCORE", "ZCARD", "ZADD", "PEXPIRE"},
+	redisSlidingReleaseScript: {"ZPOPMAX"},
+	redisBucketAllowScript:    {"HMGET"},
+	redisBucketFailScript:     {"HMGET", "HSET", "PEXPIRE"},
+	redisBucketReserveScript:  {"HMGET", "HSET", "PEXPIRE"},
+	redisBucketReleaseScript:  {"HMGET", "HSET", "PEXPIRE"},
+	redisResetScript:          {"DEL"},
+}
+
+// fakeRedis emulates the limiter Lua scripts in memory. Like Redis, it runs one script at a time.
+type fakeRedis struct {
+	mu      sync.Mutex
+	zsets   map[string][]int64
+	buckets map[string][2]float64
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{zsets: map[string][]int64{}, buckets: map[string][2]float64{}}
+}
+
+func (f *fakeRedis) Eval(_ context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	if _, ok := fakeRedisCommands[script]; !ok {
+		return nil, fmt.Errorf("unknown script")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := keys[0]
+	switch script {
+	case redisResetScript:
+		delete(f.zsets, key)
+		delete(f.buckets, key)
+		return int64(1), nil
+	case redisSlidingReleaseScript:
+		if zset := f.zsets[key]; len(zset) > 0 {
+			latest := 0
+			for i, ts := range zset {
+				if ts >= zset[latest] {
+					latest = i
+				}
+			}
+			f.zsets[key] = slices.Delete(zset, latest, latest+1)
+		}
+		return int64(1), nil
+	}
+	now, window, limit := args[0].(int64), args[1].(int64), args[2].(int)
+
+	switch script {
+	case redisSlidingAllowScript, redisSlidingFailScript, redisSlidingReserveScript:
+		kept := f.zsets[key][:0]
+		for _, ts := range f.zsets[key] {
+			if ts > now-window {
+				kept = append(kept, ts)
+			}
+		}
+		f.zsets[key] = kept
+		allowed := len(kept) < limit
+		if script == redisSlidingFailScript || (script == redisSlidingReserveScript && allowed) {
+			f.zsets[key] = append(f.zsets[key], now)
+		}
+		if allowed || script == redisSlidingFailScript {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case redisBucketAllowScript, redisBucketFailScript, redisBucketReserveScript, redisBucketReleaseScript:
+		b, ok := f.buckets[key]
+		if !ok {
+			b = [2]float64{float64(limit), float64(now)}
+		}
+		tokens := math.Min(float64(limit), b[0]+(float64(now)-b[1])*float64(limit)/float64(window))
+		switch {
+		case script == redisBucketFailScript:
+			f.buckets[key] = [2]float64{math.Max(0, tokens-1), float64(now)}
+			return int64(1), nil
+		case script == redisBucketReleaseScript:
+			f.buckets[key] = [2]float64{math.Min(float64(limit), tokens+1), float64(now)}
+			return int64(1), nil
+		case tokens < 1:
+			return int64(0), nil
+		case script == redisBucketReserveScript:
+			f.buckets[key] = [2]float64{tokens - 1, float64(now)}
+		}
+		return int64(1), nil
+	}
+	return nil, fmt.Errorf("unknown script")
+}
+
+func TestFakeRedis_Commands(t *testing.T) {
+	// Changing a script without updating fakeRedis would leave the unit tests checking stale behavior
+	call := regexp.MustCompile(`redis\.call\('(\w+)'`)
+	for script, want := range fakeRedisCommands {
+		var got []string
+		for _, match := range call.FindAllStringSubmatch(script, -1) {
+			got = append(got, match[1])
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("script %q calls %v, fakeRedis emulates %v", script, got, want)
+		}
+	}
+}
+
+func TestRedisAttemptLimiter_SlidingWindow(t *testing.T) {
+	testRedisSlidingWindow(t, newFakeRedis(), "test:")
+}
+
+func TestRedisAttemptLimiter_TokenBucket(t *testing.T) {
+	testRedisTokenBucket(t, newFakeRedis(), "test:")
+}
+
+func TestRedisAttemptLimiter_Reset(t *testing.T) {
+	testRedisReset(t, newFakeRedis(), "test:")
+}
+
+func TestRedisAttemptLimiter_Reserve(t *testing.T) {
+	testRedisReserve(t, newFakeRedis(), "test:")
+}
+
+// testRedisSlidingWindow checks the sliding window strategy against the client, with keys under prefix
+func testRedisSlidingWindow(t *testing.T, client RedisClient, prefix string) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewRedisAttemptLimiter(client, WithRedisPrefix(prefix), WithRedisLimit(3), WithRedisWindow(time.Minute))
+	limiter.Now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		allowed, err := limiter.Allow(ctx, "user:alice")
+		if err != nil || !allowed {
+			t.Fatalf("Allow() attempt %d got = %v, %v, want true, nil", i, allowed, err)
+		}
+		if err := limiter.Fail(ctx, "user:alice"); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+	}
+
+	if allowed, _ := limiter.Allow(ctx, "user:alice"); allowed {
+		t.Errorf("Allow() after limit got = true, want false")
+	}
+
+	// Other keys are independent
+	if allowed, _ := limiter.Allow(ctx, "user:bob"); !allowed {
+		t.Errorf("Allow() for other key got = false, want true")
+	}
+
+	// Failures expire after the window
+	now = now.Add(time.Minute + time.Second)
+	if allowed, _ := limiter.Allow(ctx, "user:alice"); !allowed {
+		t.Errorf("Allow() after window got = false, want true")
+	}
+}
+
+// testRedisTokenBucket checks the token bucket strategy against the client, with keys under prefix
+func testRedisTokenBucket(t *testing.T, client RedisClient, prefix string) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewRedisAttemptLimiter(client, WithRedisPrefix(prefix), WithRedisLimit(2), WithRedisWindow(time.Minute), WithRedisStrategy(RedisTokenBucket))
+	limiter.Now = func() time.Time { return now }
+
+	_ = limiter.Fail(ctx, "ip:10.0.0.1")
+	_ = limiter.Fail(ctx, "ip:10.0.0.1")
+	if allowed, _ := limiter.Allow(ctx, "ip:10.0.0.1"); allowed {
+		t.Errorf("Allow() with empty bucket got = true, want false")
+	}
+
+	// Half a window refills one token
+	now = now.Add(30 * time.Second)
+	if allowed, _ := limiter.Allow(ctx, "ip:10.0.0.1"); !allowed {
+		t.Errorf("Allow() after refill got = false, want true")
+	}
+}
+
+// testRedisReset checks Reset against the client, with keys under prefix
+func testRedisReset(t *testing.T, client RedisClient, prefix string) {
+	ctx := context.Background()
+	limiter := NewRedisAttemptLimiter(client, WithRedisLimit(1), WithRedisPrefix(prefix))
+
+	_ = limiter.Fail(ctx, "user:alice")
+	if allowed, _ := limiter.Allow(ctx, "user:alice"); allowed {
+		t.Fatalf("Allow() after failure got = true, want false")
+	}
+
+	if err := limiter.Reset(ctx, "user:alice"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if allowed, _ := limiter.Allow(ctx, "user:alice"); !allowed {
+		t.Errorf("Allow() after reset got = false, want true")
+	}
+}
+
+// testRedisReserve checks Reserve and Release with both strategies against the client, with keys under prefix
+func testRedisReserve(t *testing.T, client RedisClient, prefix string) {
+	for _, strategy := range []RedisStrategy{RedisSlidingWindow, RedisTokenBucket} {
+		ctx := context.Background()
+		now := time.Unix(1700000000, 0)
+		limiter := NewRedisAttemptLimiter(client, WithRedisPrefix(prefix+strconv.Itoa(int(strategy))+":"),
+			WithRedisLimit(2), WithRedisWindow(time.Minute), WithRedisStrategy(strategy))
+		limiter.Now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			if reserved, err := limiter.Reserve(ctx, "user:alice"); err != nil || !reserved {
+				t.Fatalf("strategy %d: Reserve() attempt %d got = %v, %v, want true, nil", strategy, i, reserved, err)
+			}
+		}
+		if reserved, _ := limiter.Reserve(ctx, "user:alice"); reserved {
+			t.Errorf("strategy %d: Reserve() after limit got = true, want false", strategy)
+		}
+		if allowed, _ := limiter.Allow(ctx, "user:alice"); allowed {
+			t.Errorf("strategy %d: Allow() after reservations got = true, want false", strategy)
+		}
+
+		// A released attempt can be reserved again
+		if err := limiter.Release(ctx, "user:alice"); err != nil {
+			t.Fatalf("strategy %d: Release() error = %v", strategy, err)
+		}
+		if reserved, _ := limiter.Reserve(ctx, "user:alice"); !reserved {
+			t.Errorf("strategy %d: Reserve() after release got = false, want true", strategy)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package passforge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// reliefPrefix marks stored server relief values, so they can't be mistaken for Argon2 hashes
+const reliefPrefix = "relief=1,"
+
+// ErrParamsDowngrade is returned when server relief parameters are weaker than the accepted minimum
+var ErrParamsDowngrade = errors.New("server relief: parameters below minimum")
+
+// ServerReliefParams are the Argon2id parameters and salt the client uses to derive its key.
+// The server sends them at registration and, for the stored hash, at every login.
+type ServerReliefParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	KeyLen  uint32 `json:"keyLen"`
+	Salt    []byte `json:"salt"`
+}
+
+// AtLeast reports whether the parameters are at least as strong as the minimum
+func (p ServerReliefParams) AtLeast(minimum ServerReliefParams) bool {
+	return p.Time >= minimum.Time && p.Memory >= minimum.Memory && p.KeyLen >= minimum.KeyLen && len(p.Salt) >= len(minimum.Salt)
+}
+
+// DeriveClientKey performs the memory-hard step on the client. It refuses parameters weaker than
+// minimum with ErrParamsDowngrade, so a tampered challenge can't make the client send a cheap key.
+func DeriveClientKey(rawPassword string, params, minimum ServerReliefParams) ([]byte, error) {
+	if !params.AtLeast(minimum) || params.Time < 1 || params.Threads < 1 {
+		return nil, ErrParamsDowngrade
+	}
+	return argon2.IDKey([]byte(rawPassword), params.Salt, params.Time, params.Memory, params.Threads, params.KeyLen), nil
+}
+
+// ServerReliefEncoder implements server relief: the client runs Argon2id with server-provided salt and
+// parameters, and the server only stores and checks HMAC-SHA256(Key, clientKey). This moves the KDF
+// cost off busy authentication servers, while a leaked database still requires the Argon2id work
+// per guess plus the server key.
+//
+// Downgrade protection works on both sides: the server recomputes from the stored parameters and
+// refuses to register parameters below its minimum, and clients refuse weak challenges in DeriveClientKey.
+// Encode and Verify run both steps on the server, for migrations and tests.
+type ServerReliefEncoder struct {
+	Key     []byte             // Server key of the finalization HMAC, at least 16 bytes
+	Params  ServerReliefParams // Parameters issued for new registrations; Salt is ignored
+	SaltLen int                // Length of the salt
+	Minimum ServerReliefParams // Weakest parameters accepted at registration
+	Rand    io.Reader          // Source of salts, crypto/rand.Reader when nil
+}
+
+// ServerReliefOption is a functional option used to configure a ServerReliefEncoder instance.
+type ServerReliefOption func(*ServerReliefEncoder)
+
+// WithServerReliefParams sets the Argon2id parameters issued to clients
+// Default: time=1, memory=65536, threads=4, keyLen=32
+func WithServerReliefParams(time, memory uint32, threads uint8, keyLen uint32) ServerReliefOption {
+	return func(s *ServerReliefEncoder) {
+		s.Params = ServerReliefParams{Time: time, Memory: memory, Threads: threads, KeyLen: keyLen}
+	}
+}
+
+// WithServerReliefMinimum sets the weakest parameters accepted at registration
+// Default: the issued parameters
+func WithServerReliefMinimum(minimum ServerReliefParams) ServerReliefOption {
+	return func(s *ServerReliefEncoder) {
+		s.Minimum = minimum
+	}
+}
+
+// WithServerReliefRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithServerReliefRand(r io.Reader) ServerReliefOption {
+	return func(s *ServerReliefEncoder) {
+		s.Rand = r
+	}
+}
+
+// NewServerReliefEncoder creates a ServerReliefEncoder finalizing client keys with the server key
+func NewServerReliefEncoder(key []byte, opts ...ServerReliefOption) (*ServerReliefEncoder, error) {
+	if len(key) < 16 {
+		return nil, fmt.Errorf("server relief key must be at least 16 bytes")
+	}
+	encoder := &ServerReliefEncoder{
+		Key:     key,
+		Params:  ServerReliefParams{Time: 1, Memory: 64 * 1024, Threads: 4, KeyLen: 32},
+		SaltLen: 16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	if encoder.Minimum.Time == 0 && encoder.Minimum.Memory == 0 {
+		encoder.Minimum = encoder.Params
+		encoder.Minimum.Salt = make([]byte, encoder.SaltLen)
+	}
+	return encoder, nil
+}
+
+// NewParams returns parameters with a fresh salt for a registration
+func (s *ServerReliefEncoder) NewParams() (ServerReliefParams, error) {
+	params := s.Params
+	params.Salt = make([]byte, s.SaltLen)
+	if _, err := io.ReadFull(randReader(s.Rand), params.Salt); err != nil {
+		return ServerReliefParams{}, err
+	}
+	return params, nil
+}
+
+// Register finalizes the client key derived with params and returns the value to store.
+// Parameters below the minimum or a key of the wrong length are rejected with ErrParamsDowngrade.
+func (s *ServerReliefEncoder) Register(params ServerReliefParams, clientKey []byte) (string, error) {
+	if !params.AtLeast(s.Minimum) || len(clientKey) != int(params.KeyLen) {
+		return "", ErrParamsDowngrade
+	}
+
+	// Format: relief=1,time=TIME,memory=MEMORY,threads=THREADS,keyLen=KEYLEN$BASE64_SALT$BASE64_HMAC
+	return fmt.Sprintf(reliefPrefix+"time=%d,memory=%d,threads=%d,keyLen=%d$%s$%s",
+		params.Time, params.Memory, params.Threads, params.KeyLen,
+		base64.StdEncoding.EncodeToString(params.Salt),
+		base64.StdEncoding.EncodeToString(s.finalize(clientKey))), nil
+}
+
+// ParamsFor returns the login challenge for a stored value
+func (s *ServerReliefEncoder) ParamsFor(encodedPassword string) (ServerReliefParams, error) {
+	params, _, err := s.parse(encodedPassword)
+	return params, err
+}
+
+// DummyParams returns a stable challenge for an unknown user, indistinguishable from a real one,
+// so the challenge endpoint can't be used to enumerate accounts
+func (s *ServerReliefEncoder) DummyParams(userID string) ServerReliefParams {
+	params := s.Params
+	params.Salt = nil
+	for counter := byte(0); len(params.Salt) < s.SaltLen; counter++ {
+		mac := hmac.New(sha256.New, s.Key)
+		mac.Write([]byte{counter})
+		mac.Write([]byte("dummy-salt:" + userID))
+		params.Salt = mac.Sum(params.Salt)
+	}
+	params.Salt = params.Salt[:s.SaltLen]
+	return params
+}
+
+// VerifyClientKey checks a client key against a stored value in constant time
+func (s *ServerReliefEncoder) VerifyClientKey(clientKey []byte, encodedPassword string) (bool, error) {
+	params, stored, err := s.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if len(clientKey) != int(params.KeyLen) {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare(stored, s.finalize(clientKey)) == 1, nil
+}
+
+// Encode performs both the client and the server step
+func (s *ServerReliefEncoder) Encode(rawPassword string) (string, error) {
+	params, err := s.NewParams()
+	if err != nil {
+		return "", err
+	}
+	clientKey, err := DeriveClientKey(rawPassword, params, s.Minimum)
+	if err != nil {
+		return "", err
+	}
+	return s.Register(params, clientKey)
+}
+
+// Verify performs both the client and the server step
+func (s *ServerReliefEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	params, _, err := s.parse(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	clientKey, err := DeriveClientKey(rawPassword, params, ServerReliefParams{})
+	if err != nil {
+		return false, newFormatError("relief", "invalid parameters", encodedPassword)
+	}
+	return s.VerifyClientKey(clientKey, encodedPassword)
+}
+
+func init() {
+	RegisterHashFormat(&ServerReliefEncoder{})
+}
+
+// Identify reports whether the encoded password is a server relief value registered with the relief=1 marker
+func (s *ServerReliefEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, reliefPrefix)
+}
+
+// AdminStatus reports the Argon2id parameters issued to clients; the server key is never reported
+func (s *ServerReliefEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{
+		"time": s.Params.Time, "memory": s.Params.Memory, "threads": s.Params.Threads, "keyLen": s.Params.KeyLen,
+	}}
+}
+
+// Name returns the name of the encoder.
+func (s *ServerReliefEncoder) Name() string {
+	return "relief"
+}
+
+// finalize computes the keyed finalization of a client key
+func (s *ServerReliefEncoder) finalize(clientKey []byte) []byte {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(clientKey)
+	return mac.Sum(nil)
+}
+
+// parse splits a stored value into its parameters and finalization.
+// Values registered before the relief=1 marker was introduced are accepted without it.
+func (s *ServerReliefEncoder) parse(encodedPassword string) (ServerReliefParams, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(encodedPassword, reliefPrefix), "$")
+	if len(parts) != 3 {
+		return ServerReliefParams{}, nil, newFormatError("relief", "invalid encoded password format", encodedPassword)
+	}
+
+	var params ServerReliefParams
+	_, err := fmt.Sscanf(parts[0], "time=%d,memory=%d,threads=%d,keyLen=%d",
+		&params.Time, &params.Memory, &params.Threads, &params.KeyLen)
+	if err != nil {
+		return ServerReliefParams{}, nil, newFormatError("relief", "invalid parameter format", encodedPassword)
+	}
+
+	params.Salt, err = base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ServerReliefParams{}, nil, newFormatError("relief", "invalid salt encoding", encodedPassword)
+	}
+	stored, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ServerReliefParams{}, nil, newFormatError("relief", "invalid hash encoding", encodedPassword)
+	}
+	return params, stored, nil
+}
@@ -0,0 +1,144 @@
+package passforge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestServerReliefEncoder(t *testing.T) *ServerReliefEncoder {
+	t.Helper()
+	encoder, err := NewServerReliefEncoder(bytes.Repeat([]byte{7}, 32), WithServerReliefParams(1, 1024, 1, 32))
+	if err != nil {
+		t.Fatalf("NewServerReliefEncoder() error = %v", err)
+	}
+	return encoder
+}
+
+func TestNewServerReliefEncoder(t *testing.T) {
+	if _, err := NewServerReliefEncoder([]byte("short")); err == nil {
+		t.Errorf("NewServerReliefEncoder() with short key should return error")
+	}
+}
+
+func TestServerReliefEncoder_Protocol(t *testing.T) {
+	server := newTestServerReliefEncoder(t)
+
+	// Registration: the server issues parameters, the client derives its key
+	params, err := server.NewParams()
+	if err != nil {
+		t.Fatalf("NewParams() error = %v", err)
+	}
+	clientKey, err := DeriveClientKey("password123", params, server.Minimum)
+	if err != nil {
+		t.Fatalf("DeriveClientKey() error = %v", err)
+	}
+	stored, err := server.Register(params, clientKey)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	// Login: the server returns the stored parameters, the client derives its key again
+	challenge, err := server.ParamsFor(stored)
+	if err != nil {
+		t.Fatalf("ParamsFor() error = %v", err)
+	}
+	loginKey, _ := DeriveClientKey("password123", challenge, server.Minimum)
+	if match, err := server.VerifyClientKey(loginKey, stored); err != nil || !match {
+		t.Errorf("VerifyClientKey() = %v, %v, want true, nil", match, err)
+	}
+
+	wrongKey, _ := DeriveClientKey("password124", challenge, server.Minimum)
+	if match, _ := server.VerifyClientKey(wrongKey, stored); match {
+		t.Errorf("VerifyClientKey() = true for wrong password")
+	}
+	if match, _ := server.VerifyClientKey(loginKey[:16], stored); match {
+		t.Errorf("VerifyClientKey() = true for truncated key")
+	}
+
+	// The stored value alone does not verify: the server key is required
+	other, _ := NewServerReliefEncoder(bytes.Repeat([]byte{8}, 32))
+	if match, _ := other.VerifyClientKey(loginKey, stored); match {
+		t.Errorf("VerifyClientKey() = true with a different server key")
+	}
+}
+
+func TestServerReliefEncoder_Format(t *testing.T) {
+	server := newTestServerReliefEncoder(t)
+	stored, err := server.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(stored, "relief=1,time=1,") {
+		t.Errorf("Encode() = %v, want the relief=1 marker", stored)
+	}
+	if algorithm := (PasswordHash{encoded: stored}).Algorithm(); algorithm != "relief" {
+		t.Errorf("Algorithm() = %v, want relief", algorithm)
+	}
+
+	// Values stored before the marker was introduced still verify
+	legacy := strings.TrimPrefix(stored, reliefPrefix)
+	if match, err := server.Verify("password123", legacy); err != nil || !match {
+		t.Errorf("Verify() legacy value = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestServerReliefEncoder_Downgrade(t *testing.T) {
+	server := newTestServerReliefEncoder(t)
+	params, _ := server.NewParams()
+
+	weak := params
+	weak.Memory = 8
+	if _, err := DeriveClientKey("password123", weak, server.Minimum); !errors.Is(err, ErrParamsDowngrade) {
+		t.Errorf("DeriveClientKey() error = %v, want ErrParamsDowngrade", err)
+	}
+
+	weakKey, _ := DeriveClientKey("password123", weak, ServerReliefParams{})
+	if _, err := server.Register(weak, weakKey); !errors.Is(err, ErrParamsDowngrade) {
+		t.Errorf("Register() error = %v, want ErrParamsDowngrade", err)
+	}
+
+	key, _ := DeriveClientKey("password123", params, server.Minimum)
+	if _, err := server.Register(params, key[:16]); !errors.Is(err, ErrParamsDowngrade) {
+		t.Errorf("Register() with short key error = %v, want ErrParamsDowngrade", err)
+	}
+}
+
+func TestServerReliefEncoder_DummyParams(t *testing.T) {
+	server := newTestServerReliefEncoder(t)
+
+	first, second := server.DummyParams("ghost"), server.DummyParams("ghost")
+	if !bytes.Equal(first.Salt, second.Salt) || len(first.Salt) != server.SaltLen {
+		t.Errorf("DummyParams() is not stable: %x != %x", first.Salt, second.Salt)
+	}
+	if bytes.Equal(first.Salt, server.DummyParams("other").Salt) {
+		t.Errorf("DummyParams() returned the same salt for different users")
+	}
+
+	server.SaltLen = 48
+	if salt := server.DummyParams("ghost").Salt; len(salt) != 48 {
+		t.Errorf("DummyParams() salt length = %v, want 48", len(salt))
+	}
+}
+
+func TestServerReliefEncoder_EncodeVerify(t *testing.T) {
+	encoder := newTestServerReliefEncoder(t)
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if match, _ := encoder.Verify("password124", encoded); match {
+		t.Errorf("Verify() = true for wrong password")
+	}
+	if _, err := encoder.Verify("password123", "invalid"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Verify() error = %v, want ErrInvalidFormat", err)
+	}
+	if encoder.Name() != "relief" {
+		t.Errorf("Name() = %v, want relief", encoder.Name())
+	}
+}
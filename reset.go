@@ -0,0 +1,251 @@
+package passforge
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrResetTokenInvalid is returned for malformed, unknown or mismatching reset tokens
+var ErrResetTokenInvalid = errors.New("reset token invalid")
+
+// ErrResetTokenExpired is returned for reset tokens past their expiry
+var ErrResetTokenExpired = errors.New("reset token expired")
+
+// ErrResetTokenUsed is returned for reset tokens that were already redeemed
+var ErrResetTokenUsed = errors.New("reset token already used")
+
+// resetIDLen and resetSecretLen are the random bytes of the lookup ID and of the secret of a token
+const (
+	resetIDLen     = 16
+	resetSecretLen = 32
+)
+
+// ResetToken is the stored form of a password-reset token. Only the hash of its secret is kept.
+type ResetToken struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user"`
+	Hash      string    `json:"hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedAt    time.Time `json:"used_at,omitzero"`
+}
+
+// ResetTokenStore persists password-reset tokens
+type ResetTokenStore interface {
+	// SaveResetToken stores a newly issued token
+	SaveResetToken(ctx context.Context, token ResetToken) error
+
+	// FindResetToken returns the token with the ID, or ErrResetTokenInvalid
+	FindResetToken(ctx context.Context, id string) (ResetToken, error)
+
+	// ConsumeResetToken marks the token used at the given time. It must be atomic and return
+	// ErrResetTokenUsed when the token was already consumed, so a token can't be redeemed twice.
+	ConsumeResetToken(ctx context.Context, id string, at time.Time) error
+}
+
+// ResetTokenManager issues and redeems single-use, expiring password-reset tokens.
+//
+// A token is "ID.SECRET": the ID is a random lookup key and the secret is hashed with the configured
+// encoder, so a leaked token table doesn't allow resetting passwords. Unknown IDs are verified against
+// a dummy hash, so the response time doesn't reveal whether a token exists. Expiry and consumption are
+// only reported once the secret matched.
+type ResetTokenManager struct {
+	Encoder PasswordEncoder  // Encoder of token secrets; tokens are high-entropy, so a cheap one suffices
+	Store   ResetTokenStore  // Token persistence
+	TTL     time.Duration    // Lifetime of issued tokens
+	Now     func() time.Time // Time source
+	Rand    io.Reader        // Source of tokens, crypto/rand.Reader when nil
+
+	dummyOnce sync.Once
+	dummy     string
+}
+
+// ResetOption is a function that configures a ResetTokenManager
+type ResetOption func(*ResetTokenManager)
+
+// WithResetTTL sets the lifetime of issued tokens
+// Default: 1 hour
+func WithResetTTL(ttl time.Duration) ResetOption {
+	return func(r *ResetTokenManager) {
+		r.TTL = ttl
+	}
+}
+
+// WithResetRand sets the source of random tokens
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithResetRand(rand io.Reader) ResetOption {
+	return func(r *ResetTokenManager) {
+		r.Rand = rand
+	}
+}
+
+// NewResetTokenManager creates a ResetTokenManager hashing token secrets with the encoder
+func NewResetTokenManager(encoder PasswordEncoder, store ResetTokenStore, opts ...ResetOption) *ResetTokenManager {
+	manager := &ResetTokenManager{
+		Encoder: encoder,
+		Store:   store,
+		TTL:     time.Hour,
+		Now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(manager)
+	}
+	return manager
+}
+
+// Issue creates a token for the user and returns it. The token is shown to the user once, e.g. in a
+// reset link, and can't be recovered from the store.
+func (r *ResetTokenManager) Issue(ctx context.Context, userID string) (string, error) {
+	raw := make([]byte, resetIDLen+resetSecretLen)
+	if _, err := io.ReadFull(randReader(r.Rand), raw); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(raw[:resetIDLen])
+	secret := base64.RawURLEncoding.EncodeToString(raw[resetIDLen:])
+
+	hash, err := r.Encoder.Encode(secret)
+	if err != nil {
+		return "", err
+	}
+	err = r.Store.SaveResetToken(ctx, ResetToken{
+		ID:        id,
+		UserID:    userID,
+		Hash:      hash,
+		ExpiresAt: r.Now().Add(r.TTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return id + "." + secret, nil
+}
+
+// Verify checks the token without consuming it and returns the user it was issued for
+func (r *ResetTokenManager) Verify(ctx context.Context, token string) (string, error) {
+	stored, err := r.lookup(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	return stored.UserID, nil
+}
+
+// Redeem checks the token, marks it used and returns the user it was issued for.
+// The caller then sets the new password for that user.
+func (r *ResetTokenManager) Redeem(ctx context.Context, token string) (string, error) {
+	stored, err := r.lookup(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if err := r.Store.ConsumeResetToken(ctx, stored.ID, r.Now()); err != nil {
+		return "", err
+	}
+	return stored.UserID, nil
+}
+
+// lookup finds and verifies a token, spending the same verification work for unknown IDs
+func (r *ResetTokenManager) lookup(ctx context.Context, token string) (ResetToken, error) {
+	id, secret, found := strings.Cut(token, ".")
+	if !found || id == "" || secret == "" {
+		return ResetToken{}, ErrResetTokenInvalid
+	}
+
+	stored, err := r.Store.FindResetToken(ctx, id)
+	if errors.Is(err, ErrResetTokenInvalid) {
+		_, _ = r.Encoder.Verify(secret, r.dummyHash())
+		return ResetToken{}, ErrResetTokenInvalid
+	}
+	if err != nil {
+		return ResetToken{}, err
+	}
+
+	match, err := r.Encoder.Verify(secret, stored.Hash)
+	if err != nil {
+		return ResetToken{}, err
+	}
+	switch {
+	case !match:
+		return ResetToken{}, ErrResetTokenInvalid
+	case !stored.UsedAt.IsZero():
+		return ResetToken{}, ErrResetTokenUsed
+	case !r.Now().Before(stored.ExpiresAt):
+		return ResetToken{}, ErrResetTokenExpired
+	}
+	return stored, nil
+}
+
+// dummyHash returns a hash of a random secret, encoded once, used to verify unknown IDs
+func (r *ResetTokenManager) dummyHash() string {
+	r.dummyOnce.Do(func() {
+		r.dummy, _ = r.Encoder.Encode(strings.Repeat("x", base64.RawURLEncoding.EncodedLen(resetSecretLen)))
+	})
+	return r.dummy
+}
+
+// MemoryResetTokenStore is a concurrency-safe in-memory ResetTokenStore.
+// It is intended for tests, demos and small tools.
+type MemoryResetTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]ResetToken
+}
+
+// NewMemoryResetTokenStore creates an empty MemoryResetTokenStore
+func NewMemoryResetTokenStore() *MemoryResetTokenStore {
+	return &MemoryResetTokenStore{tokens: make(map[string]ResetToken)}
+}
+
+// SaveResetToken stores a newly issued token
+func (m *MemoryResetTokenStore) SaveResetToken(_ context.Context, token ResetToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens[token.ID] = token
+	return nil
+}
+
+// FindResetToken returns the token with the ID, or ErrResetTokenInvalid
+func (m *MemoryResetTokenStore) FindResetToken(_ context.Context, id string) (ResetToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[id]
+	if !ok {
+		return ResetToken{}, ErrResetTokenInvalid
+	}
+	return token, nil
+}
+
+// ConsumeResetToken marks the token used, or returns ErrResetTokenUsed if it already was
+func (m *MemoryResetTokenStore) ConsumeResetToken(_ context.Context, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[id]
+	switch {
+	case !ok:
+		return ErrResetTokenInvalid
+	case !token.UsedAt.IsZero():
+		return ErrResetTokenUsed
+	}
+	token.UsedAt = at
+	m.tokens[id] = token
+	return nil
+}
+
+// DeleteExpired removes tokens that expired before now and returns how many were removed
+func (m *MemoryResetTokenStore) DeleteExpired(now time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for id, token := range m.tokens {
+		if token.ExpiresAt.Before(now) {
+			delete(m.tokens, id)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,107 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestResetManager(now *time.Time) *ResetTokenManager {
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(1000))
+	manager := NewResetTokenManager(encoder, NewMemoryResetTokenStore(), WithResetTTL(time.Hour))
+	manager.Now = func() time.Time { return *now }
+	return manager
+}
+
+func TestResetTokenManager_Redeem(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := newTestResetManager(&now)
+
+	token, err := manager.Issue(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	if user, err := manager.Verify(ctx, token); err != nil || user != "alice" {
+		t.Errorf("Verify() got = %v, %v, want alice", user, err)
+	}
+	if user, err := manager.Redeem(ctx, token); err != nil || user != "alice" {
+		t.Errorf("Redeem() got = %v, %v, want alice", user, err)
+	}
+	if _, err := manager.Redeem(ctx, token); !errors.Is(err, ErrResetTokenUsed) {
+		t.Errorf("second Redeem() error = %v, want ErrResetTokenUsed", err)
+	}
+}
+
+func TestResetTokenManager_Invalid(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := newTestResetManager(&now)
+
+	token, _ := manager.Issue(ctx, "alice")
+	id, _, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no separator", "abcdef"},
+		{"unknown id", "unknown." + strings.Repeat("a", 43)},
+		{"wrong secret", id + "." + strings.Repeat("a", 43)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manager.Redeem(ctx, tt.token); !errors.Is(err, ErrResetTokenInvalid) {
+				t.Errorf("Redeem() error = %v, want ErrResetTokenInvalid", err)
+			}
+		})
+	}
+
+	// Failed attempts don't consume the token
+	if _, err := manager.Redeem(ctx, token); err != nil {
+		t.Errorf("Redeem() error = %v", err)
+	}
+}
+
+func TestResetTokenManager_Expired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := newTestResetManager(&now)
+	store := manager.Store.(*MemoryResetTokenStore)
+
+	token, _ := manager.Issue(ctx, "alice")
+	now = now.Add(time.Hour)
+	if _, err := manager.Redeem(ctx, token); !errors.Is(err, ErrResetTokenExpired) {
+		t.Errorf("Redeem() error = %v, want ErrResetTokenExpired", err)
+	}
+
+	if removed := store.DeleteExpired(now.Add(time.Second)); removed != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", removed)
+	}
+	if _, err := manager.Redeem(ctx, token); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Errorf("Redeem() after cleanup error = %v, want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestResetTokenManager_StoresOnlyHash(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	manager := newTestResetManager(&now)
+
+	token, _ := manager.Issue(ctx, "alice")
+	id, secret, _ := strings.Cut(token, ".")
+	stored, err := manager.Store.FindResetToken(ctx, id)
+	if err != nil {
+		t.Fatalf("FindResetToken() error = %v", err)
+	}
+	if strings.Contains(stored.Hash, secret) {
+		t.Errorf("stored hash contains the token secret")
+	}
+	if !stored.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want %v", stored.ExpiresAt, now.Add(time.Hour))
+	}
+}
@@ -0,0 +1,176 @@
+package passforge
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// RotationAction is what a RotationAdvisor recommends for a stored credential
+type RotationAction int
+
+const (
+	// RotationNone means the credential meets the current policy
+	RotationNone RotationAction = iota
+	// RotationRehash means the hash should be re-encoded the next time the user logs in
+	RotationRehash
+	// RotationPrompt means the user should be asked to choose a new password
+	RotationPrompt
+)
+
+// String returns the name of the action
+func (a RotationAction) String() string {
+	switch a {
+	case RotationRehash:
+		return "rehash"
+	case RotationPrompt:
+		return "rotate"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText returns the name of the action
+func (a RotationAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// CostStep is a cost policy that applies from a point in time on
+type CostStep struct {
+	Since  time.Time    // When the policy took effect
+	Policy RehashPolicy // Algorithm and minimum parameters required from then on
+}
+
+// CostSchedule is the history of cost policies, e.g. the Argon2 memory raised every year.
+// Steps may be listed in any order.
+type CostSchedule []CostStep
+
+// At returns the step in effect at t, i.e. the latest step that is not after t
+func (s CostSchedule) At(t time.Time) (CostStep, bool) {
+	var current CostStep
+	found := false
+	for _, step := range s {
+		if !step.Since.After(t) && (!found || step.Since.After(current.Since)) {
+			current, found = step, true
+		}
+	}
+	return current, found
+}
+
+// RotationAdvice is the recommendation for one credential
+type RotationAdvice struct {
+	UserID    string         `json:"user"`
+	Algorithm string         `json:"algorithm"`
+	Action    RotationAction `json:"action"`
+	Reason    string         `json:"reason,omitempty"`
+	Age       time.Duration  `json:"age"`
+}
+
+// RotationAdvisor decides from a credential's UpdatedAt and the cost schedule whether the hash
+// should be re-encoded at the next login or the user prompted to rotate the password.
+//
+// A hash below the current policy is normally upgraded transparently at login. Once it has stayed
+// below the policy for longer than the grace period, the user evidently isn't logging in and the
+// only remaining remedy is a rotation. UpdatedAt is the time the hash was last written, so a
+// rehash also resets the age used by MaxAge.
+type RotationAdvisor struct {
+	Schedule    CostSchedule
+	MaxAge      time.Duration    // Prompt for credentials older than this; 0 disables
+	GracePeriod time.Duration    // How long a hash may stay below the policy before prompting; 0 never prompts
+	Now         func() time.Time // Time source
+}
+
+// RotationOption is a function that configures a RotationAdvisor
+type RotationOption func(*RotationAdvisor)
+
+// WithRotationMaxAge prompts users whose credential has not been written for longer than maxAge
+func WithRotationMaxAge(maxAge time.Duration) RotationOption {
+	return func(r *RotationAdvisor) {
+		r.MaxAge = maxAge
+	}
+}
+
+// WithRotationGracePeriod prompts users whose hash stayed below the policy for longer than grace
+func WithRotationGracePeriod(grace time.Duration) RotationOption {
+	return func(r *RotationAdvisor) {
+		r.GracePeriod = grace
+	}
+}
+
+// NewRotationAdvisor creates a RotationAdvisor for the cost schedule
+func NewRotationAdvisor(schedule CostSchedule, opts ...RotationOption) *RotationAdvisor {
+	advisor := &RotationAdvisor{
+		Schedule: schedule,
+		Now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(advisor)
+	}
+	return advisor
+}
+
+// Advise returns the recommendation for a stored credential
+func (r *RotationAdvisor) Advise(record CredentialRecord) RotationAdvice {
+	now := r.Now()
+	hash := PasswordHash{encoded: record.Hash}
+	advice := RotationAdvice{
+		UserID:    record.UserID,
+		Algorithm: hash.Algorithm(),
+		Age:       now.Sub(record.UpdatedAt),
+	}
+
+	if step, ok := r.Schedule.At(now); ok && hash.NeedsRehash(step.Policy) {
+		// The hash has been below the policy since the later of its creation and the policy change
+		since := step.Since
+		if record.UpdatedAt.After(since) {
+			since = record.UpdatedAt
+		}
+		if r.GracePeriod > 0 && now.Sub(since) > r.GracePeriod {
+			advice.Action, advice.Reason = RotationPrompt, "below cost policy past grace period"
+		} else {
+			advice.Action, advice.Reason = RotationRehash, "below cost policy"
+		}
+		return advice
+	}
+
+	if r.MaxAge > 0 && advice.Age > r.MaxAge {
+		advice.Action, advice.Reason = RotationPrompt, "older than maximum age"
+	}
+	return advice
+}
+
+// RotationReport aggregates the advice for every credential of a store
+type RotationReport struct {
+	Total      int              `json:"total"`
+	Current    int              `json:"current"`
+	Rehash     int              `json:"rehash"`
+	Rotate     int              `json:"rotate"`
+	Algorithms map[string]int   `json:"algorithms"`
+	Advice     []RotationAdvice `json:"advice"` // Credentials needing action, oldest first
+}
+
+// Report advises on every credential of the store
+func (r *RotationAdvisor) Report(ctx context.Context, store CredentialLister) (*RotationReport, error) {
+	records, err := store.ListCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RotationReport{Total: len(records), Algorithms: make(map[string]int)}
+	for _, record := range records {
+		advice := r.Advise(record)
+		report.Algorithms[advice.Algorithm]++
+		switch advice.Action {
+		case RotationNone:
+			report.Current++
+			continue
+		case RotationRehash:
+			report.Rehash++
+		case RotationPrompt:
+			report.Rotate++
+		}
+		report.Advice = append(report.Advice, advice)
+	}
+	sort.SliceStable(report.Advice, func(i, j int) bool { return report.Advice[i].Age > report.Advice[j].Age })
+	return report, nil
+}
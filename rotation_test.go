@@ -0,0 +1,89 @@
+package passforge
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var rotationEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testRotationSchedule() CostSchedule {
+	return CostSchedule{
+		{Since: rotationEpoch.AddDate(0, 6, 0), Policy: RehashPolicy{Algorithm: "argon2", MinParams: map[string]int{"memory": 65536}}},
+		{Since: rotationEpoch, Policy: RehashPolicy{Algorithm: "argon2", MinParams: map[string]int{"memory": 19456}}},
+	}
+}
+
+func TestCostSchedule_At(t *testing.T) {
+	schedule := testRotationSchedule()
+
+	if _, ok := schedule.At(rotationEpoch.Add(-time.Second)); ok {
+		t.Errorf("At() before the first step should find nothing")
+	}
+	if step, _ := schedule.At(rotationEpoch.AddDate(0, 1, 0)); step.Policy.MinParams["memory"] != 19456 {
+		t.Errorf("At() got = %v, want the first step", step)
+	}
+	if step, _ := schedule.At(rotationEpoch.AddDate(1, 0, 0)); step.Policy.MinParams["memory"] != 65536 {
+		t.Errorf("At() got = %v, want the second step", step)
+	}
+}
+
+func TestRotationAdvisor_Advise(t *testing.T) {
+	now := rotationEpoch.AddDate(0, 8, 0)
+	weak := "{argon2}time=1,memory=19456,threads=1,keyLen=32$c2FsdHNhbHQ=$aGFzaA=="
+	strong := "{argon2}time=1,memory=65536,threads=1,keyLen=32$c2FsdHNhbHQ=$aGFzaA=="
+
+	advisor := NewRotationAdvisor(testRotationSchedule(),
+		WithRotationMaxAge(365*24*time.Hour), WithRotationGracePeriod(30*24*time.Hour))
+	advisor.Now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		record     CredentialRecord
+		wantAction RotationAction
+	}{
+		{"current", CredentialRecord{Hash: strong, UpdatedAt: now.AddDate(0, -1, 0)}, RotationNone},
+		{"below policy within grace", CredentialRecord{Hash: weak, UpdatedAt: now.AddDate(0, 0, -10)}, RotationRehash},
+		{"below policy past grace", CredentialRecord{Hash: weak, UpdatedAt: rotationEpoch}, RotationPrompt},
+		{"wrong algorithm", CredentialRecord{Hash: "{bcrypt}$2a$10$x", UpdatedAt: now}, RotationRehash},
+		{"older than max age", CredentialRecord{Hash: strong, UpdatedAt: now.AddDate(-2, 0, 0)}, RotationPrompt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := advisor.Advise(tt.record); got.Action != tt.wantAction {
+				t.Errorf("Advise() action = %v (%s), want %v", got.Action, got.Reason, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestRotationAdvisor_Report(t *testing.T) {
+	ctx := context.Background()
+	clock := rotationEpoch
+	store := NewMemoryCredentialStore()
+	store.Now = func() time.Time { return clock }
+
+	_ = store.UpdateHash(ctx, "alice", "{argon2}time=1,memory=19456,threads=1,keyLen=32$c2FsdHNhbHQ=$aGFzaA==")
+	_ = store.UpdateHash(ctx, "bob", "{bcrypt}$2a$10$x")
+	clock = rotationEpoch.AddDate(0, 7, 0)
+	_ = store.UpdateHash(ctx, "carol", "{argon2}time=1,memory=65536,threads=1,keyLen=32$c2FsdHNhbHQ=$aGFzaA==")
+	_ = store.UpdateHash(ctx, "alice", "{argon2}time=1,memory=32768,threads=1,keyLen=32$c2FsdHNhbHQ=$aGFzaA==")
+
+	advisor := NewRotationAdvisor(testRotationSchedule(), WithRotationGracePeriod(60*24*time.Hour))
+	advisor.Now = func() time.Time { return rotationEpoch.AddDate(0, 9, 0) }
+
+	report, err := advisor.Report(ctx, store)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.Total != 3 || report.Current != 1 || report.Rehash != 0 || report.Rotate != 2 {
+		t.Errorf("Report() counts = %+v", report)
+	}
+	if report.Algorithms["argon2"] != 2 || report.Algorithms["bcrypt"] != 1 {
+		t.Errorf("Report() algorithms = %v", report.Algorithms)
+	}
+	if len(report.Advice) != 2 || report.Advice[0].UserID != "bob" || report.Advice[1].UserID != "alice" {
+		t.Errorf("Report() advice = %+v, want bob then alice", report.Advice)
+	}
+}
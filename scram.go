@@ -0,0 +1,204 @@
+package passforge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ScramSha256Encoder is a password encoder producing and verifying PostgreSQL's SCRAM-SHA-256 role
+// credentials, SCRAM-SHA-256$ITERATIONS:SALT$STORED_KEY:SERVER_KEY as stored in pg_authid.rolpassword,
+// so the output can be used in CREATE ROLE ... PASSWORD or ALTER ROLE ... PASSWORD.
+// Passwords are used as is: PostgreSQL normalizes them with SASLprep first, which only changes passwords
+// containing non-ASCII spaces, ignorable or compatibility characters.
+type ScramSha256Encoder struct {
+	Iterations int       // Number of iterations
+	SaltLen    int       // Length of the salt
+	Rand       io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// ScramSha256Option is a functional option used to configure a ScramSha256Encoder instance.
+type ScramSha256Option func(*ScramSha256Encoder)
+
+// WithScramSha256Iterations sets the number of iterations
+// Default: 4096, PostgreSQL's scram_iterations default
+func WithScramSha256Iterations(iterations int) ScramSha256Option {
+	return func(s *ScramSha256Encoder) {
+		s.Iterations = iterations
+	}
+}
+
+// WithScramSha256SaltLen sets the length of the salt
+// Default: 16, as in PostgreSQL
+func WithScramSha256SaltLen(saltLen int) ScramSha256Option {
+	return func(s *ScramSha256Encoder) {
+		s.SaltLen = saltLen
+	}
+}
+
+// WithScramSha256Rand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithScramSha256Rand(r io.Reader) ScramSha256Option {
+	return func(s *ScramSha256Encoder) {
+		s.Rand = r
+	}
+}
+
+// NewScramSha256Encoder creates a new ScramSha256Encoder with default parameters if not specified
+func NewScramSha256Encoder(opts ...ScramSha256Option) *ScramSha256Encoder {
+	encoder := &ScramSha256Encoder{
+		Iterations: 4096,
+		SaltLen:    16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode derives the SCRAM-SHA-256 credential of the raw password
+func (s *ScramSha256Encoder) Encode(rawPassword string) (string, error) {
+	if s.Iterations < 1 || s.SaltLen < 1 {
+		return "", fmt.Errorf("scram: iterations and saltLen must be positive")
+	}
+	salt := make([]byte, s.SaltLen)
+	if _, err := io.ReadFull(randReader(s.Rand), salt); err != nil {
+		return "", err
+	}
+	storedKey, serverKey, err := scramKeys(sha256.New, rawPassword, salt, s.Iterations)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SCRAM-SHA-256$%d:%s$%s:%s", s.Iterations, base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey), base64.StdEncoding.EncodeToString(serverKey)), nil
+}
+
+// Verify checks if the raw password matches the encoded credential, comparing both keys as PostgreSQL
+// does for plain-text password authentication
+func (s *ScramSha256Encoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseScramSha256(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	storedKey, serverKey, err := scramKeys(sha256.New, rawPassword, stored.salt, stored.iterations)
+	if err != nil {
+		return false, err
+	}
+	match := subtle.ConstantTimeCompare(stored.storedKey, storedKey) & subtle.ConstantTimeCompare(stored.serverKey, serverKey)
+	return match == 1, nil
+}
+
+// ValidateEncoded checks the encoded credential's format and salt length without verifying it
+func (s *ScramSha256Encoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseScramSha256(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("scram-sha-256", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&ScramSha256Encoder{})
+	RegisterTestVectors("scram-sha-256", true, func(rand io.Reader) PasswordEncoder {
+		return NewScramSha256Encoder(WithScramSha256Rand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a PostgreSQL SCRAM-SHA-256 verifier
+func (s *ScramSha256Encoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "SCRAM-SHA-256$")
+}
+
+// EncodedParams returns the iterations of the verifier
+func (s *ScramSha256Encoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseScramSha256(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"iterations": strconv.Itoa(stored.iterations)}
+}
+
+// AdminStatus reports the iterations and salt length of new verifiers
+func (s *ScramSha256Encoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"iterations": s.Iterations, "saltLen": s.SaltLen}}
+}
+
+// Name returns the name of the encoder.
+func (s *ScramSha256Encoder) Name() string {
+	return "scram-sha-256"
+}
+
+// scramCredential is a parsed SCRAM credential
+type scramCredential struct {
+	iterations           int
+	salt                 []byte
+	storedKey, serverKey []byte
+}
+
+// parseScramSha256 parses a credential of the form SCRAM-SHA-256$ITERATIONS:SALT$STORED_KEY:SERVER_KEY
+func parseScramSha256(encodedPassword string) (*scramCredential, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 3 || parts[0] != "SCRAM-SHA-256" {
+		return nil, newFormatError("scram-sha-256", "invalid encoded password format", encodedPassword)
+	}
+	iterations, salt, found := strings.Cut(parts[1], ":")
+	storedKey, serverKey, keysFound := strings.Cut(parts[2], ":")
+	if !found || !keysFound {
+		return nil, newFormatError("scram-sha-256", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &scramCredential{}
+	var err error
+	stored.iterations, err = strconv.Atoi(iterations)
+	if err != nil || stored.iterations < 1 || iterations[0] == '+' {
+		return nil, newFormatError("scram-sha-256", "invalid iterations", encodedPassword)
+	}
+	stored.salt, err = base64.StdEncoding.Strict().DecodeString(salt)
+	if err != nil || len(stored.salt) == 0 {
+		return nil, newFormatError("scram-sha-256", "invalid salt encoding", encodedPassword)
+	}
+	stored.storedKey, err = base64.StdEncoding.Strict().DecodeString(storedKey)
+	if err != nil || len(stored.storedKey) != sha256.Size {
+		return nil, newFormatError("scram-sha-256", "invalid stored key encoding", encodedPassword)
+	}
+	stored.serverKey, err = base64.StdEncoding.Strict().DecodeString(serverKey)
+	if err != nil || len(stored.serverKey) != sha256.Size {
+		return nil, newFormatError("scram-sha-256", "invalid server key encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// scramKeys derives the StoredKey and ServerKey of RFC 5802 from the password
+func scramKeys(newHash func() hash.Hash, password string, salt []byte, iterations int) (storedKey, serverKey []byte, err error) {
+	saltedPassword, err := pbkdf2Key(password, salt, iterations, newHash().Size(), newHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	storedKey, serverKey = scramKeysFromSaltedPassword(newHash, saltedPassword)
+	return storedKey, serverKey, nil
+}
+
+// scramKeysFromSaltedPassword derives the StoredKey and ServerKey from the SaltedPassword
+func scramKeysFromSaltedPassword(newHash func() hash.Hash, saltedPassword []byte) (storedKey, serverKey []byte) {
+	clientKey := scramHMAC(newHash, saltedPassword, "Client Key")
+	h := newHash()
+	h.Write(clientKey)
+	return h.Sum(nil), scramHMAC(newHash, saltedPassword, "Server Key")
+}
+
+// scramHMAC returns the HMAC of the message keyed by key
+func scramHMAC(newHash func() hash.Hash, key []byte, message string) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
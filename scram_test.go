@@ -0,0 +1,67 @@
+package passforge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScramSha256Encoder_Encode(t *testing.T) {
+	encoder := NewScramSha256Encoder(WithScramSha256Iterations(1000), WithScramSha256Rand(bytes.NewReader([]byte{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	})))
+
+	encoded, err := encoder.Encode("secret")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	// Computed with Python's hashlib and hmac following RFC 5802
+	want := "SCRAM-SHA-256$1000:AAECAwQFBgcICQoLDA0ODw==$ePJnVG7v6bd9giBvAjyO9G+qa/j/51rz+Ahi4lYCyx0=:F+9nVKYNM1hNfRpt8zTSUdZwGHHNaPa71WIOtpOCp1U="
+	if encoded != want {
+		t.Errorf("Encode() = %v, want %v", encoded, want)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	if _, err := NewScramSha256Encoder(WithScramSha256Iterations(0)).Encode("secret"); err == nil {
+		t.Errorf("Encode() with 0 iterations expected an error")
+	}
+}
+
+func TestScramSha256Encoder_Verify(t *testing.T) {
+	encoder := NewScramSha256Encoder()
+
+	const valid = "SCRAM-SHA-256$4096:c2FsdHNhbHRzYWx0c2FsdA==$CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477bQ=:VKers2x8MllK1Rh7LZLqtj6KOTzoFWJpIaokMX3blS0="
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"valid", "password", valid, true, false},
+		{"wrong password", "Password", valid, false, false},
+		{"swapped keys", "password", "SCRAM-SHA-256$4096:c2FsdHNhbHRzYWx0c2FsdA==$VKers2x8MllK1Rh7LZLqtj6KOTzoFWJpIaokMX3blS0=:CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477bQ=", false, false},
+		{"md5 password", "password", "md5a3556571e93b0d20722ba62be61e8c2d", false, true},
+		{"invalid iterations", "password", "SCRAM-SHA-256$0:c2FsdHNhbHRzYWx0c2FsdA==$CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477bQ=:VKers2x8MllK1Rh7LZLqtj6KOTzoFWJpIaokMX3blS0=", false, true},
+		{"missing server key", "password", "SCRAM-SHA-256$4096:c2FsdHNhbHRzYWx0c2FsdA==$CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477bQ=", false, true},
+		{"truncated stored key", "password", "SCRAM-SHA-256$4096:c2FsdHNhbHRzYWx0c2FsdA==$CozjiHjNmiMjBgH9gZ7qn0QWud6nrVP6E72IBh477b=:VKers2x8MllK1Rh7LZLqtj6KOTzoFWJpIaokMX3blS0=", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestScramSha256Encoder_Name(t *testing.T) {
+	if name := NewScramSha256Encoder().Name(); name != "scram-sha-256" {
+		t.Errorf("Name() = %v, want scram-sha-256", name)
+	}
+}
@@ -1,10 +1,14 @@
 package passforge
 
 import (
-	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/scrypt"
@@ -12,13 +16,52 @@ import (
 
 // ScryptPasswordEncoder is a password encoder that uses the scrypt algorithm
 type ScryptPasswordEncoder struct {
-	N       int // CPU/memory cost parameter (logN)
-	R       int // Block size parameter
-	P       int // Parallelization parameter
-	KeyLen  int // Length of the derived key
-	SaltLen int // Length of the salt
+	N       int          // CPU/memory cost parameter (logN)
+	R       int          // Block size parameter
+	P       int          // Parallelization parameter
+	KeyLen  int          // Length of the derived key
+	SaltLen int          // Length of the salt
+	Rand    io.Reader    // Source of salts, crypto/rand.Reader when nil
+	MaxMem  int64        // Upper bound for the memory used by a single hash in bytes, 0 means unbounded
+	Format  ScryptFormat // Output format; Verify accepts every format
 }
 
+// ScryptFormat selects the text format of encoded scrypt passwords
+type ScryptFormat int
+
+const (
+	// ScryptPassforge is the passforge format N=N,r=R,p=P,keyLen=KEYLEN$BASE64_SALT$BASE64_HASH
+	ScryptPassforge ScryptFormat = iota
+	// ScryptPHC is the PHC string format $scrypt$ln=LOG2_N,r=R,p=P$SALT$HASH with unpadded base64,
+	// as used by @phc/scrypt and other PHC implementations
+	ScryptPHC
+	// ScryptMCF is the $7$ modular crypt format of the scrypt reference implementation and libsodium's
+	// crypto_pwhash_scryptsalsa208sha256_str
+	ScryptMCF
+	// ScryptSpring is the format of Spring Security's SCryptPasswordEncoder, $PARAMS$BASE64_SALT$BASE64_HASH,
+	// with log2(N), r and p packed into one hex value as log2(N)<<16 | r<<8 | p
+	ScryptSpring
+)
+
+// String returns the name of the format
+func (f ScryptFormat) String() string {
+	switch f {
+	case ScryptPHC:
+		return "phc"
+	case ScryptMCF:
+		return "mcf"
+	case ScryptSpring:
+		return "spring"
+	}
+	return "passforge"
+}
+
+// ErrInvalidScryptParams is returned when the scrypt cost parameters violate the algorithm's constraints
+var ErrInvalidScryptParams = errors.New("invalid scrypt parameters")
+
+// ErrScryptMemoryLimit is returned when the scrypt parameters would need more memory than MaxMem allows
+var ErrScryptMemoryLimit = errors.New("scrypt: parameters exceed memory limit")
+
 // ScryptOption is a functional option used to configure a ScryptPasswordEncoder instance.
 type ScryptOption func(*ScryptPasswordEncoder)
 
@@ -92,6 +135,49 @@ func WithScryptSaltLen(saltLen int) ScryptOption {
 	}
 }
 
+// WithScryptRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithScryptRand(r io.Reader) ScryptOption {
+	return func(s *ScryptPasswordEncoder) {
+		s.Rand = r
+	}
+}
+
+// WithScryptMaxMem bounds the memory a single Encode or Verify may use, in bytes
+// Default: 0 (unbounded)
+// Parameters whose estimated memory exceeds the bound are rejected with ErrScryptMemoryLimit,
+// both when encoding and when verifying stored hashes.
+func WithScryptMaxMem(maxMem int64) ScryptOption {
+	return func(s *ScryptPasswordEncoder) {
+		s.MaxMem = maxMem
+	}
+}
+
+// WithScryptFormat sets the output format
+// Default: ScryptPassforge
+// The PHC and $7$ formats require N to be a power of two, which Validate enforces for every format.
+func WithScryptFormat(format ScryptFormat) ScryptOption {
+	return func(s *ScryptPasswordEncoder) {
+		s.Format = format
+	}
+}
+
+// WithScryptSpring encodes exactly like Spring Security's SCryptPasswordEncoder.defaultsForSpringSecurity_v5_8():
+// N=65536, r=8, p=1, a 16-byte salt and a 32-byte hash in the Spring format. Under a DelegatingPasswordEncoder
+// with the "scrypt" ID, hashes stored as {scrypt}$e0801$... by Spring verify unchanged, including those of the
+// older defaults (N=16384, 64-byte salt), since parameters are read from the hash. The format limits r and p to 255.
+func WithScryptSpring() ScryptOption {
+	return func(s *ScryptPasswordEncoder) {
+		s.Format = ScryptSpring
+		s.N = 1 << 16
+		s.R = 8
+		s.P = 1
+		s.KeyLen = 32
+		s.SaltLen = 16
+	}
+}
+
 // NewScryptPasswordEncoder creates a new ScryptPasswordEncoder with default parameters if not specified
 func NewScryptPasswordEncoder(opts ...ScryptOption) *ScryptPasswordEncoder {
 	encoder := &ScryptPasswordEncoder{
@@ -107,15 +193,113 @@ func NewScryptPasswordEncoder(opts ...ScryptOption) *ScryptPasswordEncoder {
 	return encoder
 }
 
+// NewScryptPasswordEncoderE is like NewScryptPasswordEncoder but rejects invalid parameters with ErrInvalidScryptParams
+// and parameters needing more memory than MaxMem with ErrScryptMemoryLimit
+func NewScryptPasswordEncoderE(opts ...ScryptOption) (*ScryptPasswordEncoder, error) {
+	encoder := NewScryptPasswordEncoder(opts...)
+	if err := encoder.Validate(); err != nil {
+		return nil, err
+	}
+	if err := encoder.checkMemory(encoder.N, encoder.R); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// Validate checks the configured parameters, the returned error wraps ErrInvalidScryptParams
+func (s *ScryptPasswordEncoder) Validate() error {
+	if err := validateScryptParams(s.N, s.R, s.P); err != nil {
+		return err
+	}
+	switch {
+	case s.KeyLen < minScryptKeyLen:
+		return fmt.Errorf("%w: keyLen must be at least %d bytes", ErrInvalidScryptParams, minScryptKeyLen)
+	case s.SaltLen < minSaltLen:
+		return fmt.Errorf("%w: saltLen must be at least %d bytes", ErrInvalidScryptParams, minSaltLen)
+	case s.Format == ScryptSpring && (s.R > 0xff || s.P > 0xff):
+		return fmt.Errorf("%w: the Spring format limits r and p to 255", ErrInvalidScryptParams)
+	}
+	return nil
+}
+
+// minScryptKeyLen is the shortest derived key Validate accepts for new hashes
+const minScryptKeyLen = 16
+
+// validateScryptParams checks the constraints scrypt places on N, r and p
+func validateScryptParams(n, r, p int) error {
+	switch {
+	case n <= 1 || n&(n-1) != 0:
+		return fmt.Errorf("%w: N must be a power of two greater than 1", ErrInvalidScryptParams)
+	case r < 1 || p < 1:
+		return fmt.Errorf("%w: r and p must be positive", ErrInvalidScryptParams)
+	case uint64(r)*uint64(p) >= 1<<30:
+		return fmt.Errorf("%w: r*p must be less than 2^30", ErrInvalidScryptParams)
+	case uint64(n) > math.MaxInt/128/uint64(r):
+		return fmt.Errorf("%w: 128*N*r exceeds the addressable memory", ErrInvalidScryptParams)
+	}
+	return nil
+}
+
+// EstimatedMemory returns the memory in bytes a single hash with the configured parameters needs (128*N*r)
+func (s *ScryptPasswordEncoder) EstimatedMemory() int64 {
+	return scryptMemory(s.N, s.R)
+}
+
+// scryptMemory estimates the memory used by scrypt for the cost parameters
+func scryptMemory(n, r int) int64 {
+	return 128 * int64(n) * int64(r)
+}
+
+// checkMemory reports whether the cost parameters fit within MaxMem. The error wraps ErrScryptMemoryLimit
+// and states the memory needed, so operators can tell how far a stored hash exceeds the bound.
+func (s *ScryptPasswordEncoder) checkMemory(n, r int) error {
+	if needed := scryptMemory(n, r); s.MaxMem > 0 && needed > s.MaxMem {
+		return fmt.Errorf("%w: N=%d, r=%d need %d bytes, limit is %d", ErrScryptMemoryLimit, n, r, needed, s.MaxMem)
+	}
+	return nil
+}
+
 // Encode hashes the raw password using scrypt
 func (s *ScryptPasswordEncoder) Encode(rawPassword string) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	if err := s.checkMemory(s.N, s.R); err != nil {
+		return "", err
+	}
+
 	// Generate random salt
 	salt := make([]byte, s.SaltLen)
-	_, err := rand.Read(salt)
+	_, err := io.ReadFull(randReader(s.Rand), salt)
 	if err != nil {
 		return "", err
 	}
 
+	switch s.Format {
+	case ScryptPHC:
+		hash, err := scrypt.Key([]byte(rawPassword), salt, s.N, s.R, s.P, s.KeyLen)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", bits.TrailingZeros(uint(s.N)), s.R, s.P,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+	case ScryptMCF:
+		// The $7$ format hashes the encoded salt text, not the random bytes
+		setting := string(crypt64[bits.TrailingZeros(uint(s.N))]) + encodeCrypt64Uint30(s.R) + encodeCrypt64Uint30(s.P) + encodeCrypt64(salt)
+		hash, err := scrypt.Key([]byte(rawPassword), []byte(setting[11:]), s.N, s.R, s.P, s.KeyLen)
+		if err != nil {
+			return "", err
+		}
+		return "$7$" + setting + "$" + encodeCrypt64(hash), nil
+	case ScryptSpring:
+		hash, err := scrypt.Key([]byte(rawPassword), salt, s.N, s.R, s.P, s.KeyLen)
+		if err != nil {
+			return "", err
+		}
+		params := bits.TrailingZeros(uint(s.N))<<16 | s.R<<8 | s.P
+		return fmt.Sprintf("$%x$%s$%s", params, base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(hash)), nil
+	}
+
 	// Hash the password with scrypt
 	hash, err := scrypt.Key([]byte(rawPassword), salt, s.N, s.R, s.P, s.KeyLen)
 	if err != nil {
@@ -131,40 +315,367 @@ func (s *ScryptPasswordEncoder) Encode(rawPassword string) (string, error) {
 		s.N, s.R, s.P, s.KeyLen, encodedSalt, encodedHash), nil
 }
 
+// EncodeWith hashes the raw password with the options applied for this call only, e.g. a higher N for admins
+func (s *ScryptPasswordEncoder) EncodeWith(rawPassword string, opts ...ScryptOption) (string, error) {
+	encoder := *s
+	for _, opt := range opts {
+		opt(&encoder)
+	}
+	return encoder.Encode(rawPassword)
+}
+
+// DeriveKey derives a key from the password and salt with scrypt and the configured N, r and p.
+// The parameters are validated and MaxMem is honoured as for Encode.
+func (s *ScryptPasswordEncoder) DeriveKey(password string, salt []byte, length int) ([]byte, error) {
+	if length < 1 {
+		return nil, ErrInvalidKeyLength
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkMemory(s.N, s.R); err != nil {
+		return nil, err
+	}
+	return scrypt.Key([]byte(password), salt, s.N, s.R, s.P, length)
+}
+
 // Verify checks if the raw password matches the encoded password
 func (s *ScryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseScrypt(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	// Refuse stored parameters that would exceed the memory bound
+	if err := s.checkMemory(stored.n, stored.r); err != nil {
+		return false, err
+	}
+
+	// Compute hash with the same parameters and salt
+	computedHash, err := scrypt.Key([]byte(rawPassword), stored.salt, stored.n, stored.r, stored.p, stored.keyLen)
+	if err != nil {
+		return false, newFormatError("scrypt", "invalid parameters", encodedPassword)
+	}
+
+	// Compare hashes using constant-time comparison to prevent timing attacks
+	return subtle.ConstantTimeCompare(stored.hash, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password against the scrypt parameter constraints and MaxMem without verifying it
+func (s *ScryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseScrypt(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.checkMemory(stored.n, stored.r); err != nil {
+		return err
+	}
+	switch {
+	case len(stored.salt) < minSaltLen:
+		return newFormatError("scrypt", "salt too short", encodedPassword)
+	case stored.keyLen < 1 || len(stored.hash) != stored.keyLen:
+		return newFormatError("scrypt", "hash length does not match key length", encodedPassword)
+	}
+	return nil
+}
+
+// scryptHash is a parsed scrypt encoded password
+type scryptHash struct {
+	n, r, p, keyLen int
+	salt, hash      []byte
+}
+
+// parseScrypt parses an encoded password of the form N=N,r=R,p=P,keyLen=KEYLEN$BASE64_SALT$BASE64_HASH,
+// a PHC string, a $7$ string or a Spring Security hash
+func parseScrypt(encodedPassword string) (*scryptHash, error) {
+	var stored *scryptHash
+	var err error
+	switch {
+	case strings.HasPrefix(encodedPassword, "$scrypt$"):
+		stored, err = parseScryptPHC(encodedPassword)
+	case strings.HasPrefix(encodedPassword, "$7$"):
+		stored, err = parseScryptMCF(encodedPassword)
+	case strings.HasPrefix(encodedPassword, "$"):
+		stored, err = parseScryptSpring(encodedPassword)
+	default:
+		stored, err = parseScryptPassforge(encodedPassword)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateScryptParams(stored.n, stored.r, stored.p); err != nil {
+		formatErr := newFormatError("scrypt", err.Error(), encodedPassword)
+		formatErr.Err = ErrInvalidScryptParams
+		return nil, formatErr
+	}
+	return stored, nil
+}
+
+// parseScryptPassforge parses an encoded password in the passforge format
+func parseScryptPassforge(encodedPassword string) (*scryptHash, error) {
 	// Split the encoded password into parts
 	parts := strings.Split(encodedPassword, "$")
 	if len(parts) != 3 {
-		return false, fmt.Errorf("invalid encoded password format")
+		return nil, newFormatError("scrypt", "invalid encoded password format", encodedPassword)
 	}
 
 	// Parse parameters
-	var n, r, p, keyLen int
-	_, err := fmt.Sscanf(parts[0], "N=%d,r=%d,p=%d,keyLen=%d", &n, &r, &p, &keyLen)
+	var stored scryptHash
+	_, err := fmt.Sscanf(parts[0], "N=%d,r=%d,p=%d,keyLen=%d", &stored.n, &stored.r, &stored.p, &stored.keyLen)
 	if err != nil {
-		return false, fmt.Errorf("invalid parameter format: %v", err)
+		return nil, newFormatError("scrypt", "invalid parameter format", encodedPassword)
 	}
 
 	// Decode salt and hash
-	salt, err := base64.StdEncoding.DecodeString(parts[1])
+	stored.salt, err = base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return false, fmt.Errorf("invalid salt encoding: %v", err)
+		return nil, newFormatError("scrypt", "invalid salt encoding", encodedPassword)
 	}
 
-	storedHash, err := base64.StdEncoding.DecodeString(parts[2])
+	stored.hash, err = base64.StdEncoding.DecodeString(parts[2])
 	if err != nil {
-		return false, fmt.Errorf("invalid hash encoding: %v", err)
+		return nil, newFormatError("scrypt", "invalid hash encoding", encodedPassword)
 	}
+	return &stored, nil
+}
 
-	// Compute hash with the same parameters and salt
-	computedHash, err := scrypt.Key([]byte(rawPassword), salt, n, r, p, keyLen)
+// parseScryptPHC parses a PHC string $scrypt$ln=LOG2_N,r=R,p=P$SALT$HASH; parameters may come in any order
+func parseScryptPHC(encodedPassword string) (*scryptHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 5 {
+		return nil, newFormatError("scrypt", "invalid PHC string format", encodedPassword)
+	}
+
+	var stored scryptHash
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(parts[2], ",") {
+		key, value, found := strings.Cut(field, "=")
+		number, err := strconv.Atoi(value)
+		if !found || seen[key] || err != nil || number < 0 {
+			return nil, newFormatError("scrypt", "invalid parameter format", encodedPassword)
+		}
+		seen[key] = true
+
+		switch key {
+		case "ln":
+			if number < 1 || number >= strconv.IntSize-1 {
+				return nil, newFormatError("scrypt", "invalid parameters", encodedPassword)
+			}
+			stored.n = 1 << number
+		case "r":
+			stored.r = number
+		case "p":
+			stored.p = number
+		default:
+			return nil, newFormatError("scrypt", "unknown parameter", encodedPassword)
+		}
+	}
+	if !seen["ln"] || !seen["r"] || !seen["p"] {
+		return nil, newFormatError("scrypt", "missing parameter", encodedPassword)
+	}
+
+	var err error
+	if stored.salt, _, err = decodeBase64(parts[3]); err != nil {
+		return nil, newFormatError("scrypt", "invalid salt encoding", encodedPassword)
+	}
+	if stored.hash, _, err = decodeBase64(parts[4]); err != nil || len(stored.hash) == 0 {
+		return nil, newFormatError("scrypt", "invalid hash encoding", encodedPassword)
+	}
+	stored.keyLen = len(stored.hash)
+	return &stored, nil
+}
+
+// parseScryptMCF parses a $7$ string: the log2 of N in one character, r and p in five characters each
+// and the salt text, followed by the hash, all in the little-endian crypt base64 alphabet
+func parseScryptMCF(encodedPassword string) (*scryptHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 4 || len(parts[2]) < 11 {
+		return nil, newFormatError("scrypt", "invalid $7$ format", encodedPassword)
+	}
+	setting := parts[2]
+
+	var stored scryptHash
+	logN := strings.IndexByte(crypt64, setting[0])
+	r, rOK := decodeCrypt64Uint30(setting[1:6])
+	p, pOK := decodeCrypt64Uint30(setting[6:11])
+	if logN < 1 || logN >= strconv.IntSize-1 || !rOK || !pOK {
+		return nil, newFormatError("scrypt", "invalid parameters", encodedPassword)
+	}
+	stored.n, stored.r, stored.p = 1<<logN, r, p
+
+	var ok bool
+	stored.salt = []byte(setting[11:])
+	if stored.hash, ok = decodeCrypt64(parts[3]); !ok || len(stored.hash) == 0 {
+		return nil, newFormatError("scrypt", "invalid hash encoding", encodedPassword)
+	}
+	stored.keyLen = len(stored.hash)
+	return &stored, nil
+}
+
+// parseScryptSpring parses a Spring Security hash $PARAMS$BASE64_SALT$BASE64_HASH
+func parseScryptSpring(encodedPassword string) (*scryptHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 4 {
+		return nil, newFormatError("scrypt", "invalid Spring format", encodedPassword)
+	}
+	params, err := strconv.ParseUint(parts[1], 16, 64)
 	if err != nil {
-		return false, err
+		return nil, newFormatError("scrypt", "invalid parameter format", encodedPassword)
 	}
 
-	// Compare hashes using constant-time comparison to prevent timing attacks
-	return subtle.ConstantTimeCompare(storedHash, computedHash) == 1, nil
+	var stored scryptHash
+	logN := int(params >> 16 & 0xffff)
+	if logN < 1 || logN >= strconv.IntSize-1 {
+		return nil, newFormatError("scrypt", "invalid parameters", encodedPassword)
+	}
+	stored.n, stored.r, stored.p = 1<<logN, int(params>>8&0xff), int(params&0xff)
+
+	if stored.salt, _, err = decodeBase64(parts[2]); err != nil {
+		return nil, newFormatError("scrypt", "invalid salt encoding", encodedPassword)
+	}
+	if stored.hash, _, err = decodeBase64(parts[3]); err != nil || len(stored.hash) == 0 {
+		return nil, newFormatError("scrypt", "invalid hash encoding", encodedPassword)
+	}
+	stored.keyLen = len(stored.hash)
+	return &stored, nil
+}
+
+// isSpringScrypt reports whether the encoded password looks like a Spring Security scrypt hash.
+// log2(N) is at least 1, so the hex parameters have at least five digits, unlike bcrypt's $2a$.
+func isSpringScrypt(encodedPassword string) bool {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 4 || parts[0] != "" || len(parts[1]) < 5 {
+		return false
+	}
+	_, err := strconv.ParseUint(parts[1], 16, 64)
+	return err == nil
+}
+
+// crypt64 is the alphabet of the crypt(3) base64 encoding
+const crypt64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// encodeCrypt64Uint30 encodes a 30-bit value as five characters, least significant first
+func encodeCrypt64Uint30(value int) string {
+	var dst [5]byte
+	for i := range dst {
+		dst[i] = crypt64[value&0x3f]
+		value >>= 6
+	}
+	return string(dst[:])
+}
+
+// decodeCrypt64Uint30 decodes five characters written by encodeCrypt64Uint30
+func decodeCrypt64Uint30(src string) (int, bool) {
+	value := 0
+	for i := len(src) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(crypt64, src[i])
+		if digit < 0 {
+			return 0, false
+		}
+		value = value<<6 | digit
+	}
+	return value, true
+}
+
+// encodeCrypt64 encodes bytes in groups of three, each as a little-endian 24-bit value
+func encodeCrypt64(src []byte) string {
+	var dst strings.Builder
+	for i := 0; i < len(src); i += 3 {
+		value, width := 0, 0
+		for j := i; j < len(src) && j < i+3; j++ {
+			value |= int(src[j]) << width
+			width += 8
+		}
+		for ; width > 0; width -= 6 {
+			dst.WriteByte(crypt64[value&0x3f])
+			value >>= 6
+		}
+	}
+	return dst.String()
+}
+
+// decodeCrypt64 decodes the output of encodeCrypt64, rejecting impossible lengths and stray bits
+func decodeCrypt64(src string) ([]byte, bool) {
+	var dst []byte
+	for i := 0; i < len(src); i += 4 {
+		chunk := src[i:min(i+4, len(src))]
+		if len(chunk) == 1 {
+			return nil, false
+		}
+		value := 0
+		for j := len(chunk) - 1; j >= 0; j-- {
+			digit := strings.IndexByte(crypt64, chunk[j])
+			if digit < 0 {
+				return nil, false
+			}
+			value = value<<6 | digit
+		}
+		n := len(chunk) * 6 / 8
+		if value>>(8*n) != 0 {
+			return nil, false
+		}
+		for j := 0; j < n; j++ {
+			dst = append(dst, byte(value>>(8*j)))
+		}
+	}
+	return dst, true
+}
+
+// UpgradeEncoding reports whether the encoded password has a lower N, r or p, or a shorter key, than the
+// configured ones
+func (s *ScryptPasswordEncoder) UpgradeEncoding(encodedPassword string) bool {
+	stored, err := parseScrypt(encodedPassword)
+	if err != nil {
+		return false
+	}
+	return stored.n < s.N || stored.r < s.R || stored.p < s.P || stored.keyLen < s.KeyLen
+}
+
+func init() {
+	RegisterHashFormat(&ScryptPasswordEncoder{})
+	RegisterTestVectors("scrypt", true, func(rand io.Reader) PasswordEncoder {
+		return NewScryptPasswordEncoder(WithScryptRand(rand))
+	})
+	RegisterTestVectors("scrypt-phc", true, func(rand io.Reader) PasswordEncoder {
+		return NewScryptPasswordEncoder(WithScryptFormat(ScryptPHC), WithScryptRand(rand))
+	})
+	RegisterTestVectors("scrypt-mcf", true, func(rand io.Reader) PasswordEncoder {
+		return NewScryptPasswordEncoder(WithScryptFormat(ScryptMCF), WithScryptRand(rand))
+	})
+	RegisterTestVectors("scrypt-spring", true, func(rand io.Reader) PasswordEncoder {
+		return NewScryptPasswordEncoder(WithScryptSpring(), WithScryptRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a scrypt hash in the passforge, PHC, $7$ or Spring format
+func (s *ScryptPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "N=") || strings.HasPrefix(encodedPassword, "$scrypt$") ||
+		strings.HasPrefix(encodedPassword, "$7$") || isSpringScrypt(encodedPassword)
+}
+
+// EncodedParams returns N, r, p and the key length of the hash, whatever its format
+func (s *ScryptPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	if strings.HasPrefix(encodedPassword, "N=") {
+		return parseParamsHead(encodedPassword)
+	}
+	stored, err := parseScrypt(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{
+		"N":      strconv.Itoa(stored.n),
+		"r":      strconv.Itoa(stored.r),
+		"p":      strconv.Itoa(stored.p),
+		"keyLen": strconv.Itoa(stored.keyLen),
+	}
+}
+
+// AdminStatus reports the scrypt cost parameters and output format
+func (s *ScryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{
+		"N": s.N, "r": s.R, "p": s.P, "keyLen": s.KeyLen, "saltLen": s.SaltLen, "maxMem": s.MaxMem,
+		"format": s.Format.String()}}
 }
 
 // Name returns the name of the encoder.
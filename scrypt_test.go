@@ -1,6 +1,7 @@
 package passforge
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -189,3 +190,203 @@ func TestScryptPasswordEncoder_Name(t *testing.T) {
 		t.Errorf("Name() = %v, want %v", actual, expected)
 	}
 }
+
+func TestScryptPasswordEncoder_MaxMem(t *testing.T) {
+	encoder := NewScryptPasswordEncoder(WithScryptN(1024), WithScryptR(8))
+	if got, want := encoder.EstimatedMemory(), int64(128*1024*8); got != want {
+		t.Errorf("EstimatedMemory() = %v, want %v", got, want)
+	}
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// A bound below the estimate rejects encoding
+	bounded := NewScryptPasswordEncoder(WithScryptN(1024), WithScryptR(8), WithScryptMaxMem(encoder.EstimatedMemory()-1))
+	if _, err := bounded.Encode("password123"); !errors.Is(err, ErrScryptMemoryLimit) {
+		t.Errorf("Encode() error = %v, want ErrScryptMemoryLimit", err)
+	}
+
+	// Stored parameters are checked against the bound of the verifying encoder
+	if _, err := bounded.Verify("password123", encoded); !errors.Is(err, ErrScryptMemoryLimit) {
+		t.Errorf("Verify() error = %v, want ErrScryptMemoryLimit", err)
+	} else if !strings.Contains(err.Error(), "need 1048576 bytes, limit is 1048575") {
+		t.Errorf("Verify() error = %v, want the needed memory and the limit", err)
+	}
+
+	// The constructor variant rejects the bound up front
+	if _, err := NewScryptPasswordEncoderE(WithScryptN(1024), WithScryptR(8), WithScryptMaxMem(1<<20-1)); !errors.Is(err, ErrScryptMemoryLimit) {
+		t.Errorf("NewScryptPasswordEncoderE() error = %v, want ErrScryptMemoryLimit", err)
+	}
+
+	// A bound equal to the estimate is enough
+	exact := NewScryptPasswordEncoder(WithScryptMaxMem(encoder.EstimatedMemory()))
+	if match, err := exact.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestScryptPasswordEncoder_ValidateParams(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    []ScryptOption
+		wantErr bool
+	}{
+		{name: "defaults", opts: nil},
+		{name: "N not a power of two", opts: []ScryptOption{WithScryptN(1000)}, wantErr: true},
+		{name: "N of one", opts: []ScryptOption{WithScryptN(1)}, wantErr: true},
+		{name: "zero r", opts: []ScryptOption{WithScryptR(0)}, wantErr: true},
+		{name: "negative p", opts: []ScryptOption{WithScryptP(-1)}, wantErr: true},
+		{name: "r*p too large", opts: []ScryptOption{WithScryptR(1 << 15), WithScryptP(1 << 15)}, wantErr: true},
+		{name: "N*r too large", opts: []ScryptOption{WithScryptN(1 << 30), WithScryptR(1 << 29)}, wantErr: true},
+		{name: "short key", opts: []ScryptOption{WithScryptKeyLen(8)}, wantErr: true},
+		{name: "short salt", opts: []ScryptOption{WithScryptSaltLen(4)}, wantErr: true},
+		{name: "Spring r too large", opts: []ScryptOption{WithScryptSpring(), WithScryptR(256)}, wantErr: true},
+		{name: "Spring defaults", opts: []ScryptOption{WithScryptSpring()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder, err := NewScryptPasswordEncoderE(tc.opts...)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidScryptParams) || encoder != nil {
+					t.Errorf("NewScryptPasswordEncoderE() = %v, %v, want ErrInvalidScryptParams", encoder, err)
+				}
+				// Encode refuses the same parameters when the plain constructor was used
+				if _, err := NewScryptPasswordEncoder(tc.opts...).Encode("password123"); !errors.Is(err, ErrInvalidScryptParams) {
+					t.Errorf("Encode() error = %v, want ErrInvalidScryptParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("NewScryptPasswordEncoderE() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestScryptPasswordEncoder_VerifyInvalidParams(t *testing.T) {
+	encoder := NewScryptPasswordEncoder()
+
+	_, err := encoder.Verify("password123", "N=1000,r=8,p=1,keyLen=32$c2FsdA==$aGFzaA==")
+	if !errors.Is(err, ErrInvalidScryptParams) || !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Verify() error = %v, want ErrInvalidScryptParams and ErrInvalidFormat", err)
+	}
+	if !strings.Contains(err.Error(), "power of two") {
+		t.Errorf("Verify() error = %v, want the failed constraint", err)
+	}
+}
+
+func TestScryptPasswordEncoder_EncodeWith(t *testing.T) {
+	encoder := NewScryptPasswordEncoder(WithScryptN(1024))
+
+	encoded, err := encoder.EncodeWith("password123", WithScryptN(2048))
+	if err != nil {
+		t.Fatalf("EncodeWith() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "N=2048,") || encoder.N != 1024 {
+		t.Errorf("EncodeWith() = %v with N = %v, want N=2048 recorded and encoder unchanged", encoded, encoder.N)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestScryptPasswordEncoder_Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ScryptFormat
+		prefix string
+	}{
+		{"passforge", ScryptPassforge, "N=1024,r=8,p=1,keyLen=32$"},
+		{"phc", ScryptPHC, "$scrypt$ln=10,r=8,p=1$"},
+		{"mcf", ScryptMCF, "$7$86..../...."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewScryptPasswordEncoder(WithScryptN(1024), WithScryptFormat(tt.format))
+			encoded, err := encoder.Encode("password123")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.prefix) {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.prefix)
+			}
+			// Verify accepts every format regardless of the configured one
+			if match, err := NewScryptPasswordEncoder().Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+			if err := encoder.ValidateEncoded(encoded); err != nil {
+				t.Errorf("ValidateEncoded() error = %v", err)
+			}
+			if got := (PasswordHash{encoded: encoded}).Params(); got["N"] != "1024" || got["keyLen"] != "32" {
+				t.Errorf("Params() = %v, want N=1024 and keyLen=32", got)
+			}
+		})
+	}
+}
+
+func TestScryptPasswordEncoder_VerifyStandardFormats(t *testing.T) {
+	encoder := NewScryptPasswordEncoder()
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		// RFC 7914 test vector: password "password", salt "NaCl", N=1024, r=8, p=16, 64-byte key
+		{"phc rfc 7914", "password", "$scrypt$ln=10,r=8,p=16$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA", true, false},
+		{"phc reordered", "password", "$scrypt$p=16,ln=10,r=8$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA", true, false},
+		{"phc wrong password", "wrong", "$scrypt$ln=10,r=8,p=16$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA", false, false},
+		{"phc missing parameter", "password", "$scrypt$ln=10,r=8$TmFDbA$/bq+HJ00cgB4VucZDQHp", false, true},
+		{"phc unknown parameter", "password", "$scrypt$ln=10,r=8,p=16,x=1$TmFDbA$/bq+HJ00cgB4VucZDQHp", false, true},
+		// scrypt reference implementation and libsodium test vector
+		{"mcf reference", "pleaseletmein", "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D", true, false},
+		{"mcf wrong password", "pleaseletme", "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D", false, false},
+		{"mcf short setting", "pleaseletmein", "$7$C6..../$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D", false, true},
+		{"mcf invalid hash", "pleaseletmein", "$7$C6..../....SodiumChloride$kBGj9fHznVYFQ!En", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithScryptSpring(t *testing.T) {
+	encoder := NewScryptPasswordEncoder(WithScryptSpring(), WithScryptN(1024))
+	delegating, err := NewDelegatingPasswordEncoder("scrypt", encoder)
+	if err != nil {
+		t.Fatalf("NewDelegatingPasswordEncoder() error = %v", err)
+	}
+
+	encoded, err := delegating.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "{scrypt}$a0801$") {
+		t.Errorf("Encode() = %v, want the Spring Security format", encoded)
+	}
+	if match, err := delegating.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+
+	// Example of the Spring Security reference documentation, password "password": N=2^14, r=8, p=1, 64-byte salt
+	spring := "{scrypt}$e0801$8bWJaSu2IKSn9Z9kM+TPXfOc/9bdYSrN1oD9qfVThWEwdRTnO7re7Ei+fUZRJ68k9lTyuTeUp4of4g24hHnazw==$OAOec05+bXxvuu/1qZ6NUR+xQYvYv7BeL1QxwRpY5Pc="
+	if match, err := delegating.Verify("password", spring); err != nil || !match {
+		t.Errorf("Verify() Spring example = %v, %v, want true, nil", match, err)
+	}
+	if got := (PasswordHash{encoded: spring}).Params(); got["N"] != "16384" || got["r"] != "8" || got["p"] != "1" {
+		t.Errorf("Params() = %v, want N=16384, r=8, p=1", got)
+	}
+
+	if _, err := NewScryptPasswordEncoder(WithScryptSpring(), WithScryptN(1024), WithScryptR(256)).Encode("x"); !errors.Is(err, ErrInvalidScryptParams) {
+		t.Errorf("Encode() with r=256 error = %v, want ErrInvalidScryptParams", err)
+	}
+}
@@ -0,0 +1,14 @@
+package passforge
+
+import "crypto/sha1"
+
+func init() {
+	RegisterHashFormat(NewSha1PasswordEncoder())
+}
+
+// NewSha1PasswordEncoder creates a LegacyDigestPasswordEncoder for SHA-1 hex digests, named "sha1"
+//
+// Deprecated: SHA-1 is fast to compute and must not protect passwords; only use it to migrate existing hashes.
+func NewSha1PasswordEncoder(opts ...LegacyDigestOption) *LegacyDigestPasswordEncoder {
+	return newLegacyDigestPasswordEncoder("sha1", sha1.New, opts)
+}
@@ -0,0 +1,122 @@
+package passforge
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sha256CryptOrder is the order SHA256-crypt encodes the bytes of its digest in, grouped in threes for encodeCrypt64
+var sha256CryptOrder = []int{
+	20, 10, 0, 11, 1, 21, 2, 22, 12, 23, 13, 3, 14, 4, 24, 5, 25, 15, 26, 16, 6, 17, 7, 27,
+	8, 28, 18, 29, 19, 9, 30, 31,
+}
+
+// Sha256CryptPasswordEncoder is a password encoder producing and verifying the $5$ SHA256-crypt hashes of
+// crypt(3), e.g. $5$rounds=535000$SALT$HASH as found in exports of older RHEL and AIX systems. Hashes
+// without rounds= use 5000 rounds. Prefer Sha512CryptPasswordEncoder when producing new crypt(3) hashes.
+type Sha256CryptPasswordEncoder struct {
+	Rounds  int       // Number of rounds
+	SaltLen int       // Length of the salt in characters, at most 16
+	Rand    io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// Sha256CryptOption is a functional option used to configure a Sha256CryptPasswordEncoder instance.
+type Sha256CryptOption func(*Sha256CryptPasswordEncoder)
+
+// WithSha256CryptRounds sets the number of rounds, between 1000 and 999999999
+// Default: 535000, as in passlib
+func WithSha256CryptRounds(rounds int) Sha256CryptOption {
+	return func(s *Sha256CryptPasswordEncoder) {
+		s.Rounds = rounds
+	}
+}
+
+// WithSha256CryptSaltLen sets the length of the salt in characters
+// Default: 16, the maximum
+func WithSha256CryptSaltLen(saltLen int) Sha256CryptOption {
+	return func(s *Sha256CryptPasswordEncoder) {
+		s.SaltLen = saltLen
+	}
+}
+
+// WithSha256CryptRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithSha256CryptRand(r io.Reader) Sha256CryptOption {
+	return func(s *Sha256CryptPasswordEncoder) {
+		s.Rand = r
+	}
+}
+
+// NewSha256CryptPasswordEncoder creates a new Sha256CryptPasswordEncoder with default parameters if not specified
+func NewSha256CryptPasswordEncoder(opts ...Sha256CryptOption) *Sha256CryptPasswordEncoder {
+	encoder := &Sha256CryptPasswordEncoder{
+		Rounds:  535000,
+		SaltLen: shaCryptMaxSaltLen,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password, writing rounds= unless the default 5000 rounds are used
+func (s *Sha256CryptPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return encodeShaCrypt("5", sha256.New, sha256CryptOrder, rawPassword, s.Rounds, s.SaltLen, s.Rand)
+}
+
+// Verify checks if the raw password matches the encoded password
+func (s *Sha256CryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseShaCrypt(s.Name(), "5", sha256.Size, encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	computedHash := shaCrypt(sha256.New, []byte(rawPassword), []byte(stored.salt), stored.rounds)
+	return subtle.ConstantTimeCompare(stored.hash, permute(computedHash, sha256CryptOrder)) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (s *Sha256CryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseShaCrypt(s.Name(), "5", sha256.Size, encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError(s.Name(), "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&Sha256CryptPasswordEncoder{})
+	RegisterTestVectors("sha256-crypt", true, func(rand io.Reader) PasswordEncoder {
+		return NewSha256CryptPasswordEncoder(WithSha256CryptRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a SHA-256 crypt hash
+func (s *Sha256CryptPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$5$")
+}
+
+// EncodedParams returns the rounds of the hash, 5000 when it does not record them
+func (s *Sha256CryptPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseShaCrypt(s.Name(), "5", sha256.Size, encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"rounds": strconv.Itoa(stored.rounds)}
+}
+
+// AdminStatus reports the rounds and salt length of new SHA-256 crypt hashes
+func (s *Sha256CryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"rounds": s.Rounds, "saltLen": s.SaltLen}}
+}
+
+// Name returns the name of the encoder.
+func (s *Sha256CryptPasswordEncoder) Name() string {
+	return "sha256-crypt"
+}
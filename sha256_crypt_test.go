@@ -0,0 +1,89 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSha256CryptPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewSha256CryptPasswordEncoder(WithSha256CryptRounds(1000), WithSha256CryptRand(bytes.NewReader(make([]byte, 16))))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if want := "$5$rounds=1000$................$"; !strings.HasPrefix(encoded, want) || len(encoded) != len(want)+43 {
+		t.Errorf("Encode() = %v, want a %s hash", encoded, want)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	encoded, _ = NewSha256CryptPasswordEncoder(WithSha256CryptRounds(5000)).Encode("password123")
+	if strings.Contains(encoded, "rounds=") {
+		t.Errorf("Encode() = %v, want the default rounds to be omitted", encoded)
+	}
+
+	for _, encoder := range []*Sha256CryptPasswordEncoder{
+		NewSha256CryptPasswordEncoder(WithSha256CryptRounds(999)),
+		NewSha256CryptPasswordEncoder(WithSha256CryptSaltLen(17)),
+	} {
+		if _, err := encoder.Encode("password123"); err == nil {
+			t.Errorf("Encode() with %+v expected an error", encoder)
+		}
+	}
+}
+
+func TestSha256CryptPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewSha256CryptPasswordEncoder()
+
+	// The first two hashes are from the SHA-crypt specification, the others were computed with libxcrypt
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"default rounds", "Hello world!", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", true, false},
+		{"rounds", "Hello world!", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", true, false},
+		{"empty password", "", "$5$rounds=1000$abcdefgh$GkBTClnHKE8dKvOznb7VazxXnS.36rgDH/r3khMCLaB", true, false},
+		{"short salt", "password", "$5$rounds=1000$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4M3", true, false},
+		{"long password", strings.Repeat("a", 200), "$5$rounds=1400$anotherlongsalts$N6ISQ0mIxTv8tFTzjl6o5zu7b7eBQckW81ftzQga6H3", true, false},
+		{"wrong password", "wrong", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", false, false},
+		{"rounds too low", "password", "$5$rounds=999$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4M3", false, true},
+		{"signed rounds", "password", "$5$rounds=+1000$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4M3", false, true},
+		{"salt too long", "Hello world!", "$5$rounds=10000$saltstringsaltstring$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", false, true},
+		{"truncated hash", "password", "$5$rounds=1000$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4M", false, true},
+		{"stray bits", "password", "$5$rounds=1000$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4Mz", false, true},
+		{"sha512-crypt", "Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestSha256CryptPasswordEncoder_ValidateEncoded(t *testing.T) {
+	encoder := NewSha256CryptPasswordEncoder()
+	if err := encoder.ValidateEncoded("$5$rounds=1000$abc$chB2229SaEAMndXolPyqp1RFge2UaeCAJVGEAvqr4M3"); err == nil {
+		t.Errorf("ValidateEncoded() expected an error for a 3-character salt")
+	}
+}
+
+func TestSha256CryptPasswordEncoder_Name(t *testing.T) {
+	if name := NewSha256CryptPasswordEncoder().Name(); name != "sha256-crypt" {
+		t.Errorf("Name() = %v, want sha256-crypt", name)
+	}
+}
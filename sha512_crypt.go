@@ -0,0 +1,122 @@
+package passforge
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sha512CryptOrder is the order SHA512-crypt encodes the bytes of its digest in, grouped in threes for encodeCrypt64
+var sha512CryptOrder = []int{
+	42, 21, 0, 1, 43, 22, 23, 2, 44, 45, 24, 3, 4, 46, 25, 26, 5, 47, 48, 27, 6, 7, 49, 28,
+	29, 8, 50, 51, 30, 9, 10, 52, 31, 32, 11, 53, 54, 33, 12, 13, 55, 34, 35, 14, 56, 57, 36, 15,
+	16, 58, 37, 38, 17, 59, 60, 39, 18, 19, 61, 40, 41, 20, 62, 63,
+}
+
+// Sha512CryptPasswordEncoder is a password encoder producing and verifying the $6$ SHA512-crypt hashes of
+// crypt(3), e.g. $6$rounds=656000$SALT$HASH as found in /etc/shadow. Hashes without rounds= use 5000 rounds.
+type Sha512CryptPasswordEncoder struct {
+	Rounds  int       // Number of rounds
+	SaltLen int       // Length of the salt in characters, at most 16
+	Rand    io.Reader // Source of salts, crypto/rand.Reader when nil
+}
+
+// Sha512CryptOption is a functional option used to configure a Sha512CryptPasswordEncoder instance.
+type Sha512CryptOption func(*Sha512CryptPasswordEncoder)
+
+// WithSha512CryptRounds sets the number of rounds, between 1000 and 999999999
+// Default: 656000, as in passlib
+func WithSha512CryptRounds(rounds int) Sha512CryptOption {
+	return func(s *Sha512CryptPasswordEncoder) {
+		s.Rounds = rounds
+	}
+}
+
+// WithSha512CryptSaltLen sets the length of the salt in characters
+// Default: 16, the maximum
+func WithSha512CryptSaltLen(saltLen int) Sha512CryptOption {
+	return func(s *Sha512CryptPasswordEncoder) {
+		s.SaltLen = saltLen
+	}
+}
+
+// WithSha512CryptRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithSha512CryptRand(r io.Reader) Sha512CryptOption {
+	return func(s *Sha512CryptPasswordEncoder) {
+		s.Rand = r
+	}
+}
+
+// NewSha512CryptPasswordEncoder creates a new Sha512CryptPasswordEncoder with default parameters if not specified
+func NewSha512CryptPasswordEncoder(opts ...Sha512CryptOption) *Sha512CryptPasswordEncoder {
+	encoder := &Sha512CryptPasswordEncoder{
+		Rounds:  656000,
+		SaltLen: shaCryptMaxSaltLen,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password, writing rounds= unless the default 5000 rounds are used
+func (s *Sha512CryptPasswordEncoder) Encode(rawPassword string) (string, error) {
+	return encodeShaCrypt("6", sha512.New, sha512CryptOrder, rawPassword, s.Rounds, s.SaltLen, s.Rand)
+}
+
+// Verify checks if the raw password matches the encoded password
+func (s *Sha512CryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseShaCrypt(s.Name(), "6", sha512.Size, encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	computedHash := shaCrypt(sha512.New, []byte(rawPassword), []byte(stored.salt), stored.rounds)
+	return subtle.ConstantTimeCompare(stored.hash, permute(computedHash, sha512CryptOrder)) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password's format and salt length without verifying it
+func (s *Sha512CryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseShaCrypt(s.Name(), "6", sha512.Size, encodedPassword)
+	if err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError(s.Name(), "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&Sha512CryptPasswordEncoder{})
+	RegisterTestVectors("sha512-crypt", true, func(rand io.Reader) PasswordEncoder {
+		return NewSha512CryptPasswordEncoder(WithSha512CryptRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a SHA-512 crypt hash
+func (s *Sha512CryptPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$6$")
+}
+
+// EncodedParams returns the rounds of the hash, 5000 when it does not record them
+func (s *Sha512CryptPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseShaCrypt(s.Name(), "6", sha512.Size, encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"rounds": strconv.Itoa(stored.rounds)}
+}
+
+// AdminStatus reports the rounds and salt length of new SHA-512 crypt hashes
+func (s *Sha512CryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"rounds": s.Rounds, "saltLen": s.SaltLen}}
+}
+
+// Name returns the name of the encoder.
+func (s *Sha512CryptPasswordEncoder) Name() string {
+	return "sha512-crypt"
+}
@@ -0,0 +1,89 @@
+package passforge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSha512CryptPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewSha512CryptPasswordEncoder(WithSha512CryptRounds(1000), WithSha512CryptRand(bytes.NewReader(make([]byte, 16))))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if want := "$6$rounds=1000$................$"; !strings.HasPrefix(encoded, want) || len(encoded) != len(want)+86 {
+		t.Errorf("Encode() = %v, want a %s hash", encoded, want)
+	}
+	if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+
+	encoded, _ = NewSha512CryptPasswordEncoder(WithSha512CryptRounds(5000)).Encode("password123")
+	if strings.Contains(encoded, "rounds=") {
+		t.Errorf("Encode() = %v, want the default rounds to be omitted", encoded)
+	}
+
+	for _, encoder := range []*Sha512CryptPasswordEncoder{
+		NewSha512CryptPasswordEncoder(WithSha512CryptRounds(999)),
+		NewSha512CryptPasswordEncoder(WithSha512CryptSaltLen(17)),
+	} {
+		if _, err := encoder.Encode("password123"); err == nil {
+			t.Errorf("Encode() with %+v expected an error", encoder)
+		}
+	}
+}
+
+func TestSha512CryptPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewSha512CryptPasswordEncoder()
+
+	// The first two hashes are from the SHA-crypt specification, the others were computed with libxcrypt
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"default rounds", "Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", true, false},
+		{"rounds", "Hello world!", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", true, false},
+		{"empty password", "", "$6$rounds=1000$abcdefgh$EXAhR4Ety06RoQxMGyf4SY5662XUrnNYnkMR4YirLV86Bn1i2FXJhH8vYFgzKA/hY4rwctuXaZYa3ju9zuElg/", true, false},
+		{"short salt", "password", "$6$rounds=1000$abc$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg.", true, false},
+		{"long password", strings.Repeat("a", 200), "$6$rounds=1400$anotherlongsalts$dazU2kElEeKV9Aheh3JmfT62f6Xsg3Rv46rdsXuID5hT0jTVZ.5zIKPVsA3bmA58ia1JGOc1fcydXfviJXXFI0", true, false},
+		{"wrong password", "wrong", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", false, false},
+		{"rounds too low", "password", "$6$rounds=999$abc$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg.", false, true},
+		{"leading zero rounds", "password", "$6$rounds=01000$abc$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg.", false, true},
+		{"salt too long", "Hello world!", "$6$rounds=10000$saltstringsaltstring$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", false, true},
+		{"missing salt", "password", "$6$rounds=1000$$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg.", false, true},
+		{"truncated hash", "password", "$6$rounds=1000$abc$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg", false, true},
+		{"sha256-crypt", "password", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZF4j9aP0B", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.want {
+				t.Errorf("Verify() = %v, want %v", match, tt.want)
+			}
+		})
+	}
+}
+
+func TestSha512CryptPasswordEncoder_ValidateEncoded(t *testing.T) {
+	encoder := NewSha512CryptPasswordEncoder()
+	if err := encoder.ValidateEncoded("$6$rounds=1000$abc$vw5PRczzmm7dyJhZWNpaLcy/M.HywGlo.UsELxKYV/ZI4356.iT3zYgbwHVzPSnvkT2lVlMoWMoJdUSLcmUNg."); err == nil {
+		t.Errorf("ValidateEncoded() expected an error for a 3-character salt")
+	}
+}
+
+func TestSha512CryptPasswordEncoder_Name(t *testing.T) {
+	if name := NewSha512CryptPasswordEncoder().Name(); name != "sha512-crypt" {
+		t.Errorf("Name() = %v, want sha512-crypt", name)
+	}
+}
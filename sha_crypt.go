@@ -0,0 +1,153 @@
+package passforge
+
+import (
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SHA-crypt round limits and salt length, as in glibc. Without rounds= the hash uses the default count.
+const (
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+	shaCryptMaxSaltLen    = 16
+)
+
+// shaCryptHash is a parsed SHA-crypt encoded password
+type shaCryptHash struct {
+	rounds int
+	salt   string
+	hash   []byte // Digest in encoding order
+}
+
+// encodeShaCrypt hashes the raw password with a random salt and formats it as $ID$[rounds=N$]SALT$HASH
+func encodeShaCrypt(id string, newHash func() hash.Hash, order []int, rawPassword string, rounds, saltLen int, r io.Reader) (string, error) {
+	if rounds < shaCryptMinRounds || rounds > shaCryptMaxRounds {
+		return "", fmt.Errorf("rounds must be between %d and %d", shaCryptMinRounds, shaCryptMaxRounds)
+	}
+	if saltLen < 1 || saltLen > shaCryptMaxSaltLen {
+		return "", fmt.Errorf("saltLen must be between 1 and %d", shaCryptMaxSaltLen)
+	}
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(randReader(r), salt); err != nil {
+		return "", err
+	}
+	for i, b := range salt {
+		salt[i] = crypt64[b&0x3f]
+	}
+
+	setting := "$" + id + "$"
+	if rounds != shaCryptDefaultRounds {
+		setting += "rounds=" + strconv.Itoa(rounds) + "$"
+	}
+	computedHash := shaCrypt(newHash, []byte(rawPassword), salt, rounds)
+	return setting + string(salt) + "$" + encodeCrypt64(permute(computedHash, order)), nil
+}
+
+// parseShaCrypt parses an encoded password of the form $ID$[rounds=N$]SALT$HASH
+func parseShaCrypt(encoder, id string, size int, encodedPassword string) (*shaCryptHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) < 4 || parts[0] != "" || parts[1] != id {
+		return nil, newFormatError(encoder, "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &shaCryptHash{rounds: shaCryptDefaultRounds}
+	if rounds, found := strings.CutPrefix(parts[2], "rounds="); found {
+		var err error
+		stored.rounds, err = strconv.Atoi(rounds)
+		if err != nil || rounds[0] < '1' || rounds[0] > '9' || stored.rounds < shaCryptMinRounds || stored.rounds > shaCryptMaxRounds {
+			return nil, newFormatError(encoder, "invalid rounds", encodedPassword)
+		}
+		parts = parts[1:]
+	}
+	if len(parts) != 4 {
+		return nil, newFormatError(encoder, "invalid encoded password format", encodedPassword)
+	}
+
+	stored.salt = parts[2]
+	if stored.salt == "" || len(stored.salt) > shaCryptMaxSaltLen || strings.ContainsAny(stored.salt, ":\n") {
+		return nil, newFormatError(encoder, "invalid salt", encodedPassword)
+	}
+	var ok bool
+	if stored.hash, ok = decodeCrypt64(parts[3]); !ok || len(stored.hash) != size {
+		return nil, newFormatError(encoder, "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// shaCrypt computes the SHA-crypt digest of the password as specified by Ulrich Drepper
+func shaCrypt(newHash func() hash.Hash, password, salt []byte, rounds int) []byte {
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	alternate := h.Sum(nil)
+
+	h.Reset()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeatTo(alternate, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(alternate)
+		} else {
+			h.Write(password)
+		}
+	}
+	digest := h.Sum(nil)
+
+	h.Reset()
+	for range password {
+		h.Write(password)
+	}
+	p := repeatTo(h.Sum(nil), len(password))
+
+	h.Reset()
+	for range 16 + int(digest[0]) {
+		h.Write(salt)
+	}
+	s := repeatTo(h.Sum(nil), len(salt))
+
+	for i := range rounds {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(digest)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(digest)
+		} else {
+			h.Write(p)
+		}
+		digest = h.Sum(digest[:0])
+	}
+	return digest
+}
+
+// repeatTo returns n bytes made of src repeated
+func repeatTo(src []byte, n int) []byte {
+	dst := make([]byte, n)
+	for i := 0; i < n; i += len(src) {
+		copy(dst[i:], src)
+	}
+	return dst
+}
+
+// permute returns the bytes of src in the given order
+func permute(src []byte, order []int) []byte {
+	dst := make([]byte, len(order))
+	for i, j := range order {
+		dst[i] = src[j]
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+package shadow
+
+import (
+	"github.com/nduyhai/passforge"
+	"github.com/nduyhai/passforge/internal/unixcrypt"
+)
+
+// verifier verifies the crypt(3) formats of shadow files
+var verifier = unixcrypt.Verifier{
+	Encoder: "shadow",
+	Formats: unixcrypt.Yescrypt | unixcrypt.Sha512Crypt | unixcrypt.Sha256Crypt | unixcrypt.Bcrypt | unixcrypt.MD5Crypt | unixcrypt.DESCrypt,
+}
+
+// Encoder is a password encoder for the password field of shadow entries, dispatching on the crypt(3)
+// prefix: yescrypt ($y$), SHA-512 and SHA-256 crypt ($6$ and $5$), bcrypt ($2b$, $2a$ and $2y$), MD5 crypt
+// ($1$) and traditional DES crypt. Encode uses SHA-512 crypt unless configured with WithCrypt; successful
+// verifications against MD5 and DES crypt hashes are reported to the weak algorithm hook.
+type Encoder struct {
+	Crypt passforge.PasswordEncoder // Encodes new passwords
+}
+
+// Option is a functional option used to configure an Encoder instance.
+type Option func(*Encoder)
+
+// WithCrypt sets the encoder of new passwords, e.g. passforge.NewYescryptPasswordEncoder() to match the
+// default of Debian 11 and Fedora 35 onwards
+// Default: passforge.NewSha512CryptPasswordEncoder()
+func WithCrypt(encoder passforge.PasswordEncoder) Option {
+	return func(e *Encoder) {
+		e.Crypt = encoder
+	}
+}
+
+// NewEncoder creates a new Encoder with default parameters if not specified
+func NewEncoder(opts ...Option) *Encoder {
+	encoder := &Encoder{
+		Crypt: passforge.NewSha512CryptPasswordEncoder(),
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with the configured crypt encoder
+func (e *Encoder) Encode(rawPassword string) (string, error) {
+	return e.Crypt.Encode(rawPassword)
+}
+
+// Verify checks if the raw password matches the crypt(3) hash
+func (e *Encoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return verifier.Verify(rawPassword, encodedPassword)
+}
+
+// Name returns the name of the encoder.
+func (e *Encoder) Name() string {
+	return "shadow"
+}
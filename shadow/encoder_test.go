@@ -0,0 +1,93 @@
+package shadow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nduyhai/passforge"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEncoder_Verify(t *testing.T) {
+	tests := []struct {
+		name, encoded string
+	}{
+		{"yescrypt", "$y$j9T$F5Jx5fExrKuPp53xLKQ..1$tnSYvahCwPBHKZUspmcxMfb0.WiB9W.zEaKlOBL35rC"},
+		{"sha512-crypt", "$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0"},
+		{"sha256-crypt", "$5$saltsaltsaltsalt$WsFBeg1qQ90JL3VkUTuM7xVV/5njhLngIVm6ftSnBR2"},
+		{"bcrypt", "$2b$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm"},
+		{"md5-crypt", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		{"des-crypt", "abJnggxhB/yWI"},
+	}
+	encoder := NewEncoder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if match, err := encoder.Verify("password", tt.encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+			if match, err := encoder.Verify("wrong", tt.encoded); err != nil || match {
+				t.Errorf("Verify() with wrong password = %v, %v, want false", match, err)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "$3$unknown", "$1$saltsalt", "!bJnggxhB/yWI"} {
+		if _, err := encoder.Verify("password", invalid); !errors.Is(err, passforge.ErrInvalidFormat) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidFormat", invalid, err)
+		}
+	}
+}
+
+func TestEncoder_Weak(t *testing.T) {
+	var flagged []passforge.WeakAlgorithmEvent
+	passforge.SetWeakAlgorithmHook(func(event passforge.WeakAlgorithmEvent) {
+		flagged = append(flagged, event)
+	})
+	defer passforge.SetWeakAlgorithmHook(nil)
+
+	encoder := NewEncoder()
+	for _, encoded := range []string{"$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "abJnggxhB/yWI"} {
+		if match, err := encoder.Verify("password", encoded); err != nil || !match {
+			t.Fatalf("Verify(%q) = %v, %v, want true", encoded, match, err)
+		}
+	}
+	if len(flagged) != 2 || flagged[0].Algorithm != "shadow" {
+		t.Errorf("Verify() flagged %v, want two shadow events", flagged)
+	}
+}
+
+func TestEncoder_VerifyLongBcrypt(t *testing.T) {
+	// Other bcrypt implementations truncate passwords to 72 bytes, so their hashes of long passwords verify
+	password := strings.Repeat("p", 100)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(password[:72]), 4)
+	encoded := "$2b$" + string(hashed[4:])
+	if match, err := NewEncoder().Verify(password, encoded); err != nil || !match {
+		t.Errorf("Verify() of a 100-byte password = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder *Encoder
+		prefix  string
+	}{
+		{"default", NewEncoder(), "$6$"},
+		{"yescrypt", NewEncoder(WithCrypt(passforge.NewYescryptPasswordEncoder())), "$y$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encoder.Encode("password")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.prefix) {
+				t.Errorf("Encode() = %q, want prefix %q", encoded, tt.prefix)
+			}
+			if match, err := tt.encoder.Verify("password", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true", match, err)
+			}
+		})
+	}
+}
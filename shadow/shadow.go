@@ -0,0 +1,161 @@
+// Package shadow parses the entries of /etc/shadow, including the password aging fields, and verifies
+// passwords against them with the crypt(3) family encoders of passforge, for system account migrations
+// and PAM replacements.
+//
+// See shadow(5)
+package shadow
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLocked is returned by Entry.Verify for locked accounts, whose password field starts with "!" or is "*"
+var ErrLocked = errors.New("shadow: account locked")
+
+// ErrNoPassword is returned by Entry.Verify for accounts with an empty password field, which PAM only lets
+// in with nullok
+var ErrNoPassword = errors.New("shadow: no password")
+
+// day is the unit of the aging fields
+const day = 24 * time.Hour
+
+// Entry is a line of /etc/shadow. Aging fields are counted in days, from 1970-01-01 for dates, and are -1
+// when empty.
+type Entry struct {
+	Name           string // Login name
+	Password       string // Encrypted password field, as is
+	LastChange     int    // Date of the last password change; 0 makes the user change it at next login
+	MinAge         int    // Days before the password may be changed again
+	MaxAge         int    // Days after which the password must be changed
+	WarnPeriod     int    // Days before the maximum age the user is warned
+	InactivePeriod int    // Days after the maximum age the password is still accepted
+	Expire         int    // Date the account expires
+	Reserved       string // Reserved field
+}
+
+// ParseEntry parses a line of /etc/shadow
+func ParseEntry(line string) (*Entry, error) {
+	fields := strings.Split(line, ":")
+	if len(fields) != 9 || fields[0] == "" {
+		return nil, fmt.Errorf("shadow: expected 9 fields, got %d", len(fields))
+	}
+	entry := &Entry{Name: fields[0], Password: fields[1], Reserved: fields[8]}
+	for i, field := range []*int{&entry.LastChange, &entry.MinAge, &entry.MaxAge, &entry.WarnPeriod,
+		&entry.InactivePeriod, &entry.Expire} {
+		value := fields[i+2]
+		if value == "" {
+			*field = -1
+			continue
+		}
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 || value[0] == '+' {
+			return nil, fmt.Errorf("shadow: invalid aging field %d of %s", i+3, entry.Name)
+		}
+		*field = days
+	}
+	return entry, nil
+}
+
+// Parse reads the entries of a shadow file, skipping blank lines
+func Parse(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		entry, err := ParseEntry(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Load reads the shadow file at path, usually /etc/shadow, readable by root only
+func Load(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Locked reports whether the password is disabled, its field starting with "!" as set by passwd -l and
+// usermod -L, or with "*" as for system accounts
+func (e *Entry) Locked() bool {
+	return e.Password != "" && (e.Password[0] == '!' || e.Password[0] == '*')
+}
+
+// Verify checks the password with the encoder, returning ErrLocked for locked accounts and ErrNoPassword
+// for accounts without a password. Aging isn't checked; see PasswordExpired and AccountExpired.
+func (e *Entry) Verify(encoder *Encoder, password string) (bool, error) {
+	switch {
+	case e.Locked():
+		return false, ErrLocked
+	case e.Password == "":
+		return false, ErrNoPassword
+	}
+	return encoder.Verify(password, e.Password)
+}
+
+// MustChange reports whether the user has to change the password at next login, LastChange being 0
+func (e *Entry) MustChange() bool {
+	return e.LastChange == 0
+}
+
+// PasswordExpired reports whether the password is older than MaxAge at now
+func (e *Entry) PasswordExpired(now time.Time) bool {
+	if e.LastChange < 0 || e.MaxAge < 0 {
+		return false
+	}
+	return !now.Before(date(e.LastChange + e.MaxAge))
+}
+
+// AccountExpired reports whether the account is expired at now
+func (e *Entry) AccountExpired(now time.Time) bool {
+	return e.Expire >= 0 && !now.Before(date(e.Expire))
+}
+
+// SetPassword encodes the password with the encoder and records the change at now
+func (e *Entry) SetPassword(encoder *Encoder, password string, now time.Time) error {
+	encoded, err := encoder.Encode(password)
+	if err != nil {
+		return err
+	}
+	e.Password = encoded
+	e.LastChange = int(now.Unix() / int64(day/time.Second))
+	return nil
+}
+
+// String formats the entry as a line of /etc/shadow
+func (e *Entry) String() string {
+	fields := []string{e.Name, e.Password}
+	for _, value := range []int{e.LastChange, e.MinAge, e.MaxAge, e.WarnPeriod, e.InactivePeriod, e.Expire} {
+		if value < 0 {
+			fields = append(fields, "")
+		} else {
+			fields = append(fields, strconv.Itoa(value))
+		}
+	}
+	return strings.Join(append(fields, e.Reserved), ":")
+}
+
+// date returns the start of the day counted from 1970-01-01 in UTC
+func date(days int) time.Time {
+	return time.Unix(0, 0).UTC().Add(time.Duration(days) * day)
+}
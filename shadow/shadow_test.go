@@ -0,0 +1,159 @@
+package shadow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testShadow = `root:$6$saltsaltsaltsalt$bcXJ8qxwY5sQ4v8MTl.0B1jeZ0z0JlA9jjmbUoCJZ.1wYXiLTU.q2ILyrDJLm890lyfuF7sWAeli0yjOyFPkf0:19000:0:99999:7:::
+daemon:*:19000:0:99999:7:::
+
+alice:$y$j9T$F5Jx5fExrKuPp53xLKQ..1$tnSYvahCwPBHKZUspmcxMfb0.WiB9W.zEaKlOBL35rC:19000:1:90:7:14:19500:
+bob:!$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/:0::::::
+guest::19000:::::20000:
+`
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testShadow))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("Parse() returned %d entries, want 5", len(entries))
+	}
+
+	alice := entries[2]
+	want := Entry{Name: "alice", Password: alice.Password, LastChange: 19000, MinAge: 1, MaxAge: 90,
+		WarnPeriod: 7, InactivePeriod: 14, Expire: 19500}
+	if *alice != want {
+		t.Errorf("Parse() alice = %+v, want %+v", *alice, want)
+	}
+	if bob := entries[3]; bob.MaxAge != -1 || bob.Expire != -1 || !bob.MustChange() {
+		t.Errorf("Parse() bob = %+v, want empty aging fields and a pending change", *bob)
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(testShadow), "\n") {
+		if line == "" {
+			continue
+		}
+		entry, err := ParseEntry(line)
+		if err != nil {
+			t.Fatalf("ParseEntry(%q) error = %v", line, err)
+		}
+		if entry.String() != line {
+			t.Errorf("String() = %q, want %q (line %d)", entry.String(), line, i+1)
+		}
+	}
+
+	for _, invalid := range []string{
+		"alice:x:19000:0:99999:7::",
+		":x:19000:0:99999:7:::",
+		"alice:x:today:0:99999:7:::",
+		"alice:x:-1:0:99999:7:::",
+		"alice:x:+1:0:99999:7:::",
+	} {
+		if _, err := ParseEntry(invalid); err == nil {
+			t.Errorf("ParseEntry(%q) should fail", invalid)
+		}
+	}
+	if _, err := Parse(strings.NewReader("root:x:1\n")); err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Errorf("Parse() error = %v, want line number", err)
+	}
+}
+
+func TestEntry_Verify(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testShadow))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	encoder := NewEncoder()
+
+	tests := []struct {
+		name     string
+		entry    *Entry
+		password string
+		want     bool
+		wantErr  error
+	}{
+		{"sha512-crypt", entries[0], "password", true, nil},
+		{"wrong password", entries[0], "wrong", false, nil},
+		{"yescrypt", entries[2], "password", true, nil},
+		{"system account", entries[1], "password", false, ErrLocked},
+		{"locked", entries[3], "password", false, ErrLocked},
+		{"no password", entries[4], "", false, ErrNoPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := tt.entry.Verify(encoder, tt.password)
+			if !errors.Is(err, tt.wantErr) || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntry_Aging(t *testing.T) {
+	entry := &Entry{Name: "alice", LastChange: 19000, MinAge: -1, MaxAge: 90, WarnPeriod: -1,
+		InactivePeriod: -1, Expire: 19500}
+
+	tests := []struct {
+		name                           string
+		now                            time.Time
+		passwordExpired, accountExpire bool
+	}{
+		{"fresh", date(19000), false, false},
+		{"last day", date(19090).Add(-time.Second), false, false},
+		{"password expired", date(19090), true, false},
+		{"account expired", date(19500), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entry.PasswordExpired(tt.now); got != tt.passwordExpired {
+				t.Errorf("PasswordExpired() = %v, want %v", got, tt.passwordExpired)
+			}
+			if got := entry.AccountExpired(tt.now); got != tt.accountExpire {
+				t.Errorf("AccountExpired() = %v, want %v", got, tt.accountExpire)
+			}
+		})
+	}
+
+	never := &Entry{Name: "bob", LastChange: -1, MaxAge: 90, Expire: -1}
+	if never.PasswordExpired(date(30000)) || never.AccountExpired(date(30000)) {
+		t.Error("entries without aging fields should never expire")
+	}
+}
+
+func TestEntry_SetPassword(t *testing.T) {
+	entry, err := ParseEntry("bob:!:0::::::")
+	if err != nil {
+		t.Fatalf("ParseEntry() error = %v", err)
+	}
+	now := date(20000).Add(12 * time.Hour)
+	if err := entry.SetPassword(NewEncoder(), "secret", now); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if entry.LastChange != 20000 || entry.MustChange() {
+		t.Errorf("SetPassword() LastChange = %d, want 20000", entry.LastChange)
+	}
+	if match, err := entry.Verify(NewEncoder(), "secret"); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true", match, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shadow")
+	if err := os.WriteFile(path, []byte(testShadow), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Load(path)
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("Load() = %d entries, %v, want 5", len(entries), err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want ErrNotExist", err)
+	}
+}
@@ -0,0 +1,264 @@
+package passforge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditFormatter renders an audit event for a SIEM pipeline
+type AuditFormatter interface {
+	Format(event AuditEvent) ([]byte, error)
+}
+
+// auditSeverity rates an event type on the CEF scale from 0 (lowest) to 10 (highest)
+func auditSeverity(eventType AuditEventType) int {
+	switch eventType {
+	case EventHoneywordHit:
+		return 10
+	case EventVerificationFailureSpike, EventMigrationDivergence:
+		return 7
+	case EventForbiddenAlgorithm:
+		return 5
+	}
+	return 3
+}
+
+// CEFFormatter renders events in ArcSight Common Event Format:
+//
+//	CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|rt=... suser=... src=... msg=...
+//
+// The algorithm is sent as cs1, details as cs2 to cs6 in key order.
+type CEFFormatter struct {
+	Vendor  string
+	Product string
+	Version string
+}
+
+// NewCEFFormatter creates a CEFFormatter reporting "passforge|passforge|1.0" as the device
+func NewCEFFormatter() *CEFFormatter {
+	return &CEFFormatter{Vendor: "passforge", Product: "passforge", Version: "1.0"}
+}
+
+// cefHeaderEscaper escapes the CEF header separators
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+
+// cefExtensionEscaper escapes CEF extension values
+var cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+// Format renders the event as a single CEF line
+func (c *CEFFormatter) Format(event AuditEvent) ([]byte, error) {
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscaper.Replace(value))
+		}
+	}
+	add("rt", strconv.FormatInt(event.Time.UnixMilli(), 10))
+	add("suser", event.UserID)
+	add("src", event.IP)
+	add("msg", event.Message)
+	if event.Algorithm != "" {
+		add("cs1Label", "algorithm")
+		add("cs1", event.Algorithm)
+	}
+	n := 2
+	for _, key := range sortedKeys(event.Details) {
+		if n > 6 {
+			break
+		}
+		add("cs"+strconv.Itoa(n)+"Label", key)
+		add("cs"+strconv.Itoa(n), event.Details[key])
+		n++
+	}
+
+	header := []string{
+		"CEF:0",
+		cefHeaderEscaper.Replace(c.Vendor),
+		cefHeaderEscaper.Replace(c.Product),
+		cefHeaderEscaper.Replace(c.Version),
+		cefHeaderEscaper.Replace(string(event.Type)),
+		cefHeaderEscaper.Replace(strings.ReplaceAll(string(event.Type), "_", " ")),
+		strconv.Itoa(auditSeverity(event.Type)),
+	}
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(ext, " ")), nil
+}
+
+// ECSFormatter renders events as Elastic Common Schema JSON documents
+type ECSFormatter struct {
+	ECSVersion string // Reported in ecs.version
+}
+
+// NewECSFormatter creates an ECSFormatter for ECS 8.11
+func NewECSFormatter() *ECSFormatter {
+	return &ECSFormatter{ECSVersion: "8.11.0"}
+}
+
+type ecsDocument struct {
+	Timestamp string       `json:"@timestamp"`
+	Message   string       `json:"message,omitempty"`
+	ECS       ecsVersion   `json:"ecs"`
+	Event     ecsEvent     `json:"event"`
+	User      *ecsUser     `json:"user,omitempty"`
+	Source    *ecsSource   `json:"source,omitempty"`
+	Passforge ecsPassforge `json:"passforge"`
+}
+
+type ecsVersion struct {
+	Version string `json:"version"`
+}
+
+type ecsEvent struct {
+	Kind     string   `json:"kind"`
+	Category []string `json:"category"`
+	Type     []string `json:"type"`
+	Action   string   `json:"action"`
+	Severity int      `json:"severity"`
+}
+
+type ecsUser struct {
+	ID string `json:"id"`
+}
+
+type ecsSource struct {
+	IP string `json:"ip"`
+}
+
+type ecsPassforge struct {
+	Algorithm string            `json:"algorithm,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Format renders the event as an ECS JSON document
+func (e *ECSFormatter) Format(event AuditEvent) ([]byte, error) {
+	doc := ecsDocument{
+		Timestamp: event.Time.UTC().Format(time.RFC3339Nano),
+		Message:   event.Message,
+		ECS:       ecsVersion{Version: e.ECSVersion},
+		Event: ecsEvent{
+			Kind:     "alert",
+			Category: []string{"authentication"},
+			Type:     []string{"info"},
+			Action:   string(event.Type),
+			Severity: auditSeverity(event.Type),
+		},
+		Passforge: ecsPassforge{Algorithm: event.Algorithm, Details: event.Details},
+	}
+	if event.UserID != "" {
+		doc.User = &ecsUser{ID: event.UserID}
+	}
+	if event.IP != "" {
+		doc.Source = &ecsSource{IP: event.IP}
+	}
+	return json.Marshal(doc)
+}
+
+// SyslogAuditSink writes audit events as RFC 5424 syslog messages, one per Write, so it works with
+// UDP connections as well as with newline-delimited TCP streams
+type SyslogAuditSink struct {
+	Writer    io.Writer
+	Formatter AuditFormatter // Renders the message body, CEF by default
+	Facility  int            // Syslog facility, 10 (authpriv) by default
+	Hostname  string
+	AppName   string
+
+	mu sync.Mutex
+}
+
+// SyslogOption is a functional option used to configure a SyslogAuditSink instance.
+type SyslogOption func(*SyslogAuditSink)
+
+// WithSyslogFormatter sets how the message body is rendered
+// Default: NewCEFFormatter()
+func WithSyslogFormatter(formatter AuditFormatter) SyslogOption {
+	return func(s *SyslogAuditSink) {
+		s.Formatter = formatter
+	}
+}
+
+// WithSyslogFacility sets the syslog facility
+// Default: 10 (authpriv)
+func WithSyslogFacility(facility int) SyslogOption {
+	return func(s *SyslogAuditSink) {
+		s.Facility = facility
+	}
+}
+
+// WithSyslogAppName sets the APP-NAME field
+// Default: passforge
+func WithSyslogAppName(appName string) SyslogOption {
+	return func(s *SyslogAuditSink) {
+		s.AppName = appName
+	}
+}
+
+// NewSyslogAuditSink creates a SyslogAuditSink writing to w
+func NewSyslogAuditSink(w io.Writer, opts ...SyslogOption) *SyslogAuditSink {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	sink := &SyslogAuditSink{
+		Writer:    w,
+		Formatter: NewCEFFormatter(),
+		Facility:  10,
+		Hostname:  hostname,
+		AppName:   "passforge",
+	}
+	for _, opt := range opts {
+		opt(sink)
+	}
+	return sink
+}
+
+// DialSyslogAuditSink connects to a syslog collector, e.g. DialSyslogAuditSink("udp", "siem:514")
+func DialSyslogAuditSink(network, address string, opts ...SyslogOption) (*SyslogAuditSink, error) {
+	conn, err := net.DialTimeout(network, address, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyslogAuditSink(conn, opts...), nil
+}
+
+// Emit writes the event as a single syslog message
+func (s *SyslogAuditSink) Emit(_ context.Context, event AuditEvent) error {
+	body, err := s.Formatter.Format(event)
+	if err != nil {
+		return err
+	}
+
+	// Severity: 2 (critical) for the highest rated events down to 5 (notice)
+	severity := 5
+	switch rating := auditSeverity(event.Type); {
+	case rating >= 9:
+		severity = 2
+	case rating >= 7:
+		severity = 4
+	}
+
+	message := fmt.Sprintf("<%d>1 %s %s %s - %s - %s\n",
+		s.Facility*8+severity, event.Time.UTC().Format(time.RFC3339Nano), s.Hostname, s.AppName, event.Type, body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = io.WriteString(s.Writer, message)
+	return err
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
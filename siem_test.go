@@ -0,0 +1,88 @@
+package passforge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAuditEvent = AuditEvent{
+	Type:      EventHoneywordHit,
+	Time:      time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC),
+	Algorithm: "argon2",
+	UserID:    "alice",
+	IP:        "192.0.2.1",
+	Message:   "decoy password used; a=b|c",
+	Details:   map[string]string{"tier": "admin"},
+}
+
+func TestCEFFormatter(t *testing.T) {
+	line, err := NewCEFFormatter().Format(testAuditEvent)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := `CEF:0|passforge|passforge|1.0|honeyword_hit|honeyword hit|10|rt=1735787045000 suser=alice src=192.0.2.1 ` +
+		`msg=decoy password used; a\=b|c cs1Label=algorithm cs1=argon2 cs2Label=tier cs2=admin`
+	if string(line) != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", line, want)
+	}
+
+	// Header separators in device fields are escaped
+	formatter := &CEFFormatter{Vendor: "a|b", Product: `c\d`, Version: "1"}
+	line, _ = formatter.Format(AuditEvent{Type: EventForbiddenAlgorithm})
+	if !strings.HasPrefix(string(line), `CEF:0|a\|b|c\\d|1|forbidden_algorithm|forbidden algorithm|5|`) {
+		t.Errorf("Format() = %s, want escaped header", line)
+	}
+}
+
+func TestECSFormatter(t *testing.T) {
+	data, err := NewECSFormatter().Format(testAuditEvent)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	event := doc["event"].(map[string]interface{})
+	if doc["@timestamp"] != "2025-01-02T03:04:05Z" || event["action"] != "honeyword_hit" || event["severity"] != float64(10) {
+		t.Errorf("Format() = %s", data)
+	}
+	if doc["user"].(map[string]interface{})["id"] != "alice" || doc["source"].(map[string]interface{})["ip"] != "192.0.2.1" {
+		t.Errorf("Format() = %s, want user.id and source.ip", data)
+	}
+
+	// Empty user and IP are omitted rather than sent as empty objects
+	data, _ = NewECSFormatter().Format(AuditEvent{Type: EventForbiddenAlgorithm})
+	if strings.Contains(string(data), `"user"`) || strings.Contains(string(data), `"source"`) {
+		t.Errorf("Format() = %s, want no user or source", data)
+	}
+}
+
+func TestSyslogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSyslogAuditSink(&buf)
+	sink.Hostname = "host"
+
+	if err := sink.Emit(context.Background(), testAuditEvent); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	// authpriv (10) * 8 + critical (2) = 82
+	want := "<82>1 2025-01-02T03:04:05Z host passforge - honeyword_hit - CEF:0|passforge|"
+	if !strings.HasPrefix(buf.String(), want) || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("Emit() wrote %q, want prefix %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	sink = NewSyslogAuditSink(&buf, WithSyslogFormatter(NewECSFormatter()), WithSyslogFacility(4), WithSyslogAppName("auth"))
+	_ = sink.Emit(context.Background(), AuditEvent{Type: EventVerificationFailureSpike})
+	if !strings.HasPrefix(buf.String(), "<36>1 ") || !strings.Contains(buf.String(), ` auth - verification_failure_spike - {"@timestamp"`) {
+		t.Errorf("Emit() wrote %q", buf.String())
+	}
+}
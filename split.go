@@ -0,0 +1,198 @@
+package passforge
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// FieldEncoding is the text encoding of a binary salt or hash column
+type FieldEncoding int
+
+const (
+	// FieldBase64 is standard padded base64, as used by the encoders themselves
+	FieldBase64 FieldEncoding = iota
+	// FieldHex is lowercase hexadecimal
+	FieldHex
+)
+
+// encode renders bytes in the field encoding
+func (e FieldEncoding) encode(data []byte) string {
+	if e == FieldHex {
+		return hex.EncodeToString(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// decode parses a value in the field encoding
+func (e FieldEncoding) decode(value string) ([]byte, error) {
+	if e == FieldHex {
+		return hex.DecodeString(value)
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// SplitRecord is a password stored across separate columns, as found in many legacy schemas
+type SplitRecord struct {
+	Hash   string            // Hash column
+	Salt   string            // Salt column
+	Params map[string]string // Parameter columns, e.g. {"iterations": "10000"}
+}
+
+// splitParamOrder lists the parameters of each self-describing format in the order they are written
+var splitParamOrder = map[string][]string{
+	"argon2": {"time", "memory", "threads", "keyLen"},
+	"scrypt": {"N", "r", "p", "keyLen"},
+	"pbkdf2": {"iterations", "keyLen", "hashFunc"},
+	"bcrypt": {"cost"},
+}
+
+// splitOptionalParams lists parameters written after the required ones, only when present
+var splitOptionalParams = map[string][]string{
+	"argon2": {"v", "variant"},
+}
+
+// SplitAdapter maps split records to and from the self-describing format of one encoder, so schemas
+// with separate salt, hash and parameter columns can use passforge without a migration.
+// Joined values carry no "{id}" prefix; add it when verifying through a DelegatingPasswordEncoder.
+//
+// For bcrypt the salt and hash columns hold the 22 and 31 characters of bcrypt's own base64 alphabet
+// and the field encodings are ignored.
+type SplitAdapter struct {
+	Algorithm    string            // "argon2", "scrypt", "pbkdf2" or "bcrypt"
+	SaltEncoding FieldEncoding     // Encoding of the salt column
+	HashEncoding FieldEncoding     // Encoding of the hash column
+	Defaults     map[string]string // Parameters fixed by the schema instead of stored per row, e.g. {"hashFunc": "sha256"}
+}
+
+// SplitOption is a functional option used to configure a SplitAdapter instance.
+type SplitOption func(*SplitAdapter)
+
+// WithSplitSaltEncoding sets the encoding of the salt column
+// Default: FieldBase64
+func WithSplitSaltEncoding(encoding FieldEncoding) SplitOption {
+	return func(a *SplitAdapter) {
+		a.SaltEncoding = encoding
+	}
+}
+
+// WithSplitHashEncoding sets the encoding of the hash column
+// Default: FieldBase64
+func WithSplitHashEncoding(encoding FieldEncoding) SplitOption {
+	return func(a *SplitAdapter) {
+		a.HashEncoding = encoding
+	}
+}
+
+// WithSplitDefault fixes a parameter the schema doesn't store per row.
+// keyLen never needs a default: it is derived from the hash length.
+func WithSplitDefault(param, value string) SplitOption {
+	return func(a *SplitAdapter) {
+		a.Defaults[param] = value
+	}
+}
+
+// NewSplitAdapter creates a SplitAdapter for the algorithm
+func NewSplitAdapter(algorithm string, opts ...SplitOption) (*SplitAdapter, error) {
+	if _, ok := splitParamOrder[algorithm]; !ok {
+		return nil, fmt.Errorf("split storage not supported for algorithm '%s'", algorithm)
+	}
+	adapter := &SplitAdapter{
+		Algorithm: algorithm,
+		Defaults:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(adapter)
+	}
+	return adapter, nil
+}
+
+// Join builds the encoder's self-describing format from a split record
+func (a *SplitAdapter) Join(record SplitRecord) (string, error) {
+	param := func(name string) string {
+		if value, ok := record.Params[name]; ok {
+			return value
+		}
+		return a.Defaults[name]
+	}
+
+	if a.Algorithm == "bcrypt" {
+		cost, err := strconv.Atoi(param("cost"))
+		if err != nil || len(record.Salt) != 22 || len(record.Hash) != 31 {
+			return "", ErrInvalidFormat
+		}
+		return fmt.Sprintf("$2a$%02d$%s%s", cost, record.Salt, record.Hash), nil
+	}
+
+	salt, err := a.SaltEncoding.decode(record.Salt)
+	if err != nil {
+		return "", ErrInvalidFormat
+	}
+	hash, err := a.HashEncoding.decode(record.Hash)
+	if err != nil {
+		return "", ErrInvalidFormat
+	}
+
+	var params []string
+	for _, name := range splitParamOrder[a.Algorithm] {
+		value := param(name)
+		if name == "keyLen" && value == "" {
+			value = strconv.Itoa(len(hash))
+		}
+		if value == "" {
+			return "", fmt.Errorf("%w: missing parameter %s", ErrInvalidFormat, name)
+		}
+		params = append(params, name+"="+value)
+	}
+	for _, name := range splitOptionalParams[a.Algorithm] {
+		if value := param(name); value != "" {
+			params = append(params, name+"="+value)
+		}
+	}
+	return strings.Join(params, ",") + "$" + base64.StdEncoding.EncodeToString(salt) + "$" + base64.StdEncoding.EncodeToString(hash), nil
+}
+
+// Split breaks an encoded password of the adapter's algorithm into a split record.
+// Parameters fixed by Defaults are omitted from the record.
+func (a *SplitAdapter) Split(encodedPassword string) (SplitRecord, error) {
+	record := SplitRecord{Params: make(map[string]string)}
+
+	if a.Algorithm == "bcrypt" {
+		cost, err := bcrypt.Cost([]byte(encodedPassword))
+		if err != nil || len(encodedPassword) != bcryptHashLen {
+			return SplitRecord{}, newFormatError("bcrypt", "invalid bcrypt hash", encodedPassword)
+		}
+		record.Params["cost"] = strconv.Itoa(cost)
+		record.Salt, record.Hash = encodedPassword[7:29], encodedPassword[29:]
+		return record, nil
+	}
+
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 3 {
+		return SplitRecord{}, newFormatError(a.Algorithm, "invalid encoded password format", encodedPassword)
+	}
+	for _, pair := range strings.Split(parts[0], ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return SplitRecord{}, newFormatError(a.Algorithm, "invalid parameter format", encodedPassword)
+		}
+		if _, fixed := a.Defaults[name]; !fixed {
+			record.Params[name] = value
+		}
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return SplitRecord{}, newFormatError(a.Algorithm, "invalid salt encoding", encodedPassword)
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return SplitRecord{}, newFormatError(a.Algorithm, "invalid hash encoding", encodedPassword)
+	}
+	record.Salt, record.Hash = a.SaltEncoding.encode(salt), a.HashEncoding.encode(hash)
+	return record, nil
+}
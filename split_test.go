@@ -0,0 +1,96 @@
+package passforge
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSplitAdapter_LegacyPBKDF2(t *testing.T) {
+	// A legacy table: hex salt and hash columns, an iterations column and SHA-256 fixed by the application
+	adapter, err := NewSplitAdapter("pbkdf2",
+		WithSplitSaltEncoding(FieldHex),
+		WithSplitHashEncoding(FieldHex),
+		WithSplitDefault("hashFunc", "sha256"))
+	if err != nil {
+		t.Fatalf("NewSplitAdapter() error = %v", err)
+	}
+
+	encoder := NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))
+	encoded, _ := encoder.Encode("password123")
+
+	record, err := adapter.Split(encoded)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if record.Params["iterations"] != "1000" || record.Params["hashFunc"] != "" {
+		t.Errorf("Split() params = %v, want iterations only", record.Params)
+	}
+	if _, err := hex.DecodeString(record.Salt); err != nil {
+		t.Errorf("Split() salt = %v, want hex", record.Salt)
+	}
+
+	// keyLen is derived from the hash when the schema doesn't store it
+	delete(record.Params, "keyLen")
+	joined, err := adapter.Join(record)
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if joined != encoded {
+		t.Errorf("Join() = %v, want %v", joined, encoded)
+	}
+	if match, err := encoder.Verify("password123", joined); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+}
+
+func TestSplitAdapter_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		algorithm string
+		encoder   PasswordEncoder
+	}{
+		{algorithm: "argon2", encoder: NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))},
+		{algorithm: "argon2", encoder: NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1), WithArgon2Variant(Argon2i))},
+		{algorithm: "scrypt", encoder: NewScryptPasswordEncoder(WithScryptN(1024))},
+		{algorithm: "bcrypt", encoder: NewBcryptPasswordEncoder(WithCost(4))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			adapter, _ := NewSplitAdapter(tc.algorithm)
+			encoded, _ := tc.encoder.Encode("password123")
+
+			record, err := adapter.Split(encoded)
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			joined, err := adapter.Join(record)
+			if err != nil || joined != encoded {
+				t.Errorf("Join(Split()) = %v, %v, want %v", joined, err, encoded)
+			}
+		})
+	}
+}
+
+func TestSplitAdapter_Errors(t *testing.T) {
+	if _, err := NewSplitAdapter("md5"); err == nil {
+		t.Errorf("NewSplitAdapter() with unsupported algorithm should return error")
+	}
+
+	adapter, _ := NewSplitAdapter("pbkdf2")
+	_, err := adapter.Join(SplitRecord{Salt: "c2FsdA==", Hash: "aGFzaA==", Params: map[string]string{"iterations": "1000"}})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Join() with missing hashFunc error = %v, want ErrInvalidFormat", err)
+	}
+	if _, err := adapter.Join(SplitRecord{Salt: "!", Hash: "aGFzaA=="}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Join() with invalid salt error = %v, want ErrInvalidFormat", err)
+	}
+	if _, err := adapter.Split("garbage"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Split() error = %v, want ErrInvalidFormat", err)
+	}
+
+	bcryptAdapter, _ := NewSplitAdapter("bcrypt")
+	if _, err := bcryptAdapter.Split("$2a$04$short"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Split() error = %v, want ErrInvalidFormat", err)
+	}
+}
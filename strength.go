@@ -0,0 +1,122 @@
+package passforge
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ErrPasswordTooWeak is returned by StrengthPolicy when a password scores below the required minimum
+var ErrPasswordTooWeak = errors.New("password too weak")
+
+// commonPasswords holds a few of the most frequently leaked password stems
+var commonPasswords = map[string]struct{}{
+	"password": {}, "passw0rd": {}, "123456": {}, "12345678": {}, "123456789": {}, "qwerty": {},
+	"qwertyuiop": {}, "letmein": {}, "welcome": {}, "admin": {}, "iloveyou": {}, "monkey": {},
+	"dragon": {}, "football": {}, "baseball": {}, "abc123": {}, "111111": {}, "sunshine": {},
+	"princess": {}, "master": {}, "login": {}, "starwars": {}, "secret": {}, "changeme": {},
+}
+
+// PasswordStrength is a rough estimate of how hard a password is to guess
+type PasswordStrength struct {
+	Score    int      `json:"score"`   // 0 (very weak) to 4 (very strong)
+	Entropy  float64  `json:"entropy"` // Estimated entropy in bits
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// EstimateStrength estimates the strength of a password from its character classes and length,
+// discounting repeated characters, simple sequences and well-known passwords.
+// It is a cheap heuristic suitable for client-side feedback, not a replacement for breach checks.
+func EstimateStrength(password string) PasswordStrength {
+	var strength PasswordStrength
+	runes := []rune(password)
+	if len(runes) == 0 {
+		strength.Warnings = append(strength.Warnings, "password is empty")
+		return strength
+	}
+
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	perChar := math.Log2(float64(pool))
+
+	// Repeated and sequential characters add almost nothing to the search space
+	predictable := 0
+	entropy := perChar
+	for i := 1; i < len(runes); i++ {
+		if d := runes[i] - runes[i-1]; d >= -1 && d <= 1 {
+			predictable++
+			entropy++
+			continue
+		}
+		entropy += perChar
+	}
+	if predictable*2 >= len(runes) {
+		strength.Warnings = append(strength.Warnings, "avoid repeated characters and sequences")
+	}
+
+	stem := strings.TrimRightFunc(strings.ToLower(password), unicode.IsDigit)
+	if _, ok := commonPasswords[strings.ToLower(password)]; ok || stem != "" && isCommonStem(stem) {
+		strength.Warnings = append(strength.Warnings, "password is commonly used")
+		entropy = math.Min(entropy, 10)
+	}
+	if len(runes) < 8 {
+		strength.Warnings = append(strength.Warnings, "use at least 8 characters")
+	}
+
+	strength.Entropy = math.Round(entropy*10) / 10
+	switch {
+	case entropy < 28:
+		strength.Score = 0
+	case entropy < 36:
+		strength.Score = 1
+	case entropy < 60:
+		strength.Score = 2
+	case entropy < 80:
+		strength.Score = 3
+	default:
+		strength.Score = 4
+	}
+	return strength
+}
+
+// isCommonStem reports whether the stem is a common password
+func isCommonStem(stem string) bool {
+	_, ok := commonPasswords[stem]
+	return ok
+}
+
+// StrengthPolicy is a PasswordPolicy rejecting passwords whose estimated score is below MinScore
+type StrengthPolicy struct {
+	MinScore int // Minimum score from 0 to 4
+}
+
+// Validate checks the estimated strength of the raw password
+func (s StrengthPolicy) Validate(rawPassword string) error {
+	if EstimateStrength(rawPassword).Score < s.MinScore {
+		return ErrPasswordTooWeak
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package passforge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	testCases := []struct {
+		name      string
+		password  string
+		wantScore int
+	}{
+		{
+			name:      "empty password",
+			password:  "",
+			wantScore: 0,
+		},
+		{
+			name:      "common password",
+			password:  "password",
+			wantScore: 0,
+		},
+		{
+			name:      "common password with digits",
+			password:  "Password123",
+			wantScore: 0,
+		},
+		{
+			name:      "repeated characters",
+			password:  "aaaaaaaaaaaa",
+			wantScore: 0,
+		},
+		{
+			name:      "short random",
+			password:  "x7#Kp",
+			wantScore: 1,
+		},
+		{
+			name:      "mixed classes",
+			password:  "Tr0ub4dor&3",
+			wantScore: 3,
+		},
+		{
+			name:      "long passphrase",
+			password:  "correct horse battery staple",
+			wantScore: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			strength := EstimateStrength(tc.password)
+			if strength.Score != tc.wantScore {
+				t.Errorf("EstimateStrength() score = %v (entropy %v), want %v", strength.Score, strength.Entropy, tc.wantScore)
+			}
+		})
+	}
+}
+
+func TestEstimateStrength_Warnings(t *testing.T) {
+	strength := EstimateStrength("letmein")
+	if len(strength.Warnings) != 2 {
+		t.Errorf("EstimateStrength() warnings = %v, want common and length warnings", strength.Warnings)
+	}
+}
+
+func TestStrengthPolicy_Validate(t *testing.T) {
+	policy := StrengthPolicy{MinScore: 3}
+
+	if err := policy.Validate("password123"); !errors.Is(err, ErrPasswordTooWeak) {
+		t.Errorf("Validate() error = %v, want ErrPasswordTooWeak", err)
+	}
+	if err := policy.Validate("correct horse battery staple"); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !js && !wasip1
+
+package passforge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// SystemAuthPasswordEncoder verifies local system accounts by delegating to a helper program,
+// typically a small PAM client such as pamtester or a setuid checker like unix_chkpwd.
+// The "encoded password" is the account name; combined with DelegatingPasswordEncoder,
+// users stored as "{system}alice" are checked against the host while others use local hashes.
+//
+// The helper is run as Command followed by the account name, receives the password on stdin
+// (terminated by a newline) and must exit with status 0 on success and 1 on rejected credentials.
+// Any other outcome is reported as an error.
+type SystemAuthPasswordEncoder struct {
+	Command []string      // Helper program and its leading arguments
+	Timeout time.Duration // Maximum time the helper may run
+}
+
+// SystemAuthOption is a functional option used to configure a SystemAuthPasswordEncoder instance.
+type SystemAuthOption func(*SystemAuthPasswordEncoder)
+
+// WithSystemAuthTimeout sets the maximum time the helper may run
+// Default: 5 seconds
+func WithSystemAuthTimeout(timeout time.Duration) SystemAuthOption {
+	return func(s *SystemAuthPasswordEncoder) {
+		s.Timeout = timeout
+	}
+}
+
+// NewSystemAuthPasswordEncoder creates a new SystemAuthPasswordEncoder running the given helper command,
+// e.g. NewSystemAuthPasswordEncoder([]string{"pamtester", "login"})
+func NewSystemAuthPasswordEncoder(command []string, opts ...SystemAuthOption) *SystemAuthPasswordEncoder {
+	encoder := &SystemAuthPasswordEncoder{
+		Command: command,
+		Timeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode is not supported: system passwords are owned by the host
+func (s *SystemAuthPasswordEncoder) Encode(_ string) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Verify runs the helper for the account stored in encodedPassword
+func (s *SystemAuthPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	if encodedPassword == "" || encodedPassword[0] == '-' {
+		// Reject empty names and names that the helper could parse as flags
+		return false, ErrInvalidFormat
+	}
+	if len(s.Command) == 0 {
+		return false, fmt.Errorf("system auth: no command configured")
+	}
+	if rawPassword == "" {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+
+	args := append(append([]string{}, s.Command[1:]...), encodedPassword)
+	cmd := exec.CommandContext(ctx, s.Command[0], args...) // #nosec G204 -- command is configured by the application
+	cmd.Stdin = bytes.NewBufferString(rawPassword + "\n")
+
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("system auth: helper failed: %w", err)
+}
+
+// Name returns the name of the encoder.
+func (s *SystemAuthPasswordEncoder) Name() string {
+	return "system"
+}
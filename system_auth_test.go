@@ -0,0 +1,96 @@
+//go:build !js && !wasip1
+
+package passforge
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestSystemAuthPasswordEncoder_Verify(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// The helper accepts alice/secret, rejects everything else with status 1
+	helper := []string{"sh", "-c", `read pw; [ "$1" = alice ] && [ "$pw" = secret ] && exit 0; exit 1`, "helper"}
+	encoder := NewSystemAuthPasswordEncoder(helper)
+
+	testCases := []struct {
+		name            string
+		rawPassword     string
+		encodedPassword string
+		wantMatch       bool
+		wantErr         bool
+	}{
+		{
+			name:            "matching password",
+			rawPassword:     "secret",
+			encodedPassword: "alice",
+			wantMatch:       true,
+		},
+		{
+			name:            "wrong password",
+			rawPassword:     "wrong",
+			encodedPassword: "alice",
+			wantMatch:       false,
+		},
+		{
+			name:            "unknown account",
+			rawPassword:     "secret",
+			encodedPassword: "bob",
+			wantMatch:       false,
+		},
+		{
+			name:            "account looking like a flag",
+			rawPassword:     "secret",
+			encodedPassword: "-alice",
+			wantErr:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := encoder.Verify(tc.rawPassword, tc.encodedPassword)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if match != tc.wantMatch {
+				t.Errorf("Verify() got = %v, want %v", match, tc.wantMatch)
+			}
+		})
+	}
+}
+
+func TestSystemAuthPasswordEncoder_HelperFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	encoder := NewSystemAuthPasswordEncoder([]string{"sh", "-c", "exit 3"})
+	if _, err := encoder.Verify("secret", "alice"); err == nil {
+		t.Errorf("Verify() with unexpected exit status should return error")
+	}
+
+	encoder = NewSystemAuthPasswordEncoder([]string{"sh", "-c", "sleep 5"}, WithSystemAuthTimeout(50*time.Millisecond))
+	if _, err := encoder.Verify("secret", "alice"); err == nil {
+		t.Errorf("Verify() with timed out helper should return error")
+	}
+}
+
+func TestSystemAuthPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewSystemAuthPasswordEncoder([]string{"true"})
+
+	if _, err := encoder.Encode("secret"); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+
+	if encoder.Name() != "system" {
+		t.Errorf("Name() = %v, want system", encoder.Name())
+	}
+}
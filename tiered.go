@@ -0,0 +1,130 @@
+package passforge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Common sensitivity tiers
+const (
+	TierInteractive    = "interactive"
+	TierAdmin          = "admin"
+	TierServiceAccount = "service-account"
+)
+
+// ErrUnknownTier is returned when a tier has no encoder configured
+var ErrUnknownTier = errors.New("unknown tier")
+
+// TieredEncoder selects an encoder by sensitivity tier, so accounts with different risk profiles can
+// use different algorithms or parameters through a single component.
+// The tier is recorded in the output as a "{tier}" prefix so NeedsRehash can detect accounts that moved tier.
+type TieredEncoder struct {
+	DefaultTier string                     // Tier used by Encode
+	Tiers       map[string]PasswordEncoder // e.g., "admin" => argon2 encoder with higher memory
+}
+
+// TieredOption is a functional option used to configure a TieredEncoder instance.
+type TieredOption func(*TieredEncoder)
+
+// WithTier maps a tier to the encoder used for it
+func WithTier(tier string, encoder PasswordEncoder) TieredOption {
+	return func(t *TieredEncoder) {
+		t.Tiers[tier] = encoder
+	}
+}
+
+// NewTieredEncoder creates a TieredEncoder. The default tier must be configured with WithTier.
+func NewTieredEncoder(defaultTier string, opts ...TieredOption) (*TieredEncoder, error) {
+	encoder := &TieredEncoder{
+		DefaultTier: defaultTier,
+		Tiers:       make(map[string]PasswordEncoder),
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+
+	if _, exists := encoder.Tiers[defaultTier]; !exists {
+		return nil, fmt.Errorf("default tier '%s' not found in provided tiers", defaultTier)
+	}
+	return encoder, nil
+}
+
+// Encode encodes the raw password using the default tier
+func (t *TieredEncoder) Encode(rawPassword string) (string, error) {
+	return t.EncodeTier(t.DefaultTier, rawPassword)
+}
+
+// EncodeTier encodes the raw password using the encoder of the given tier and prefixes it with the tier
+func (t *TieredEncoder) EncodeTier(tier, rawPassword string) (string, error) {
+	encoder, ok := t.Tiers[tier]
+	if !ok {
+		return "", ErrUnknownTier
+	}
+	encoded, err := encoder.Encode(rawPassword)
+	if err != nil {
+		return "", err
+	}
+	return "{" + tier + "}" + encoded, nil
+}
+
+// Verify checks the raw password using the encoder of the tier recorded in the encoded password
+func (t *TieredEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	return t.VerifyContext(context.Background(), rawPassword, encodedPassword)
+}
+
+// VerifyContext verifies the password with its tier's encoder, passing ctx to it
+func (t *TieredEncoder) VerifyContext(ctx context.Context, rawPassword, encodedPassword string) (bool, error) {
+	tier, realEncoded, err := extractIDAndHash(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	encoder, ok := t.Tiers[tier]
+	if !ok {
+		return false, ErrUnknownTier
+	}
+	return verifyContext(ctx, encoder, rawPassword, realEncoded)
+}
+
+// ValidateEncoded checks that the encoded password has a known tier and is valid for that tier's encoder
+func (t *TieredEncoder) ValidateEncoded(encodedPassword string) error {
+	tier, realEncoded, err := extractIDAndHash(encodedPassword)
+	if err != nil {
+		return err
+	}
+	encoder, ok := t.Tiers[tier]
+	if !ok {
+		return ErrUnknownTier
+	}
+	return ValidateEncoded(encoder, realEncoded)
+}
+
+// AdminStatus reports the default tier and the encoder of every tier
+func (t *TieredEncoder) AdminStatus(describe func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	status := EncoderStatus{Default: t.DefaultTier, Encoders: make(map[string]EncoderStatus, len(t.Tiers))}
+	for tier, inner := range t.Tiers {
+		status.Encoders[tier] = describe(inner)
+	}
+	return status
+}
+
+// Name returns the name of the encoder.
+func (t *TieredEncoder) Name() string {
+	return "tiered"
+}
+
+// TierOf returns the tier recorded in the encoded password
+func (t *TieredEncoder) TierOf(encodedPassword string) (string, error) {
+	tier, _, err := extractIDAndHash(encodedPassword)
+	if err != nil {
+		return "", err
+	}
+	return tier, nil
+}
+
+// NeedsRehash reports whether the encoded password was produced for a different tier than the account's current one,
+// e.g. after a user was promoted to admin. Malformed values also need a rehash.
+func (t *TieredEncoder) NeedsRehash(encodedPassword, tier string) bool {
+	stored, err := t.TierOf(encodedPassword)
+	return err != nil || stored != tier
+}
@@ -0,0 +1,77 @@
+package passforge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestTieredEncoder(t *testing.T) *TieredEncoder {
+	t.Helper()
+	encoder, err := NewTieredEncoder(TierInteractive,
+		WithTier(TierInteractive, NewBcryptPasswordEncoder(WithCost(4))),
+		WithTier(TierAdmin, NewArgon2PasswordEncoder(WithArgon2Memory(1024), WithArgon2Threads(1))),
+		WithTier(TierServiceAccount, NewPBKDF2PasswordEncoder(WithPBKDF2Iterations(1000), WithPBKDF2MinIterations(0))),
+	)
+	if err != nil {
+		t.Fatalf("NewTieredEncoder() error = %v", err)
+	}
+	return encoder
+}
+
+func TestNewTieredEncoder(t *testing.T) {
+	_, err := NewTieredEncoder(TierAdmin, WithTier(TierInteractive, NewNoOpPasswordEncoder()))
+	if err == nil {
+		t.Errorf("NewTieredEncoder() with missing default tier should return error")
+	}
+}
+
+func TestTieredEncoder_EncodeVerify(t *testing.T) {
+	encoder := newTestTieredEncoder(t)
+
+	for _, tier := range []string{TierInteractive, TierAdmin, TierServiceAccount} {
+		t.Run(tier, func(t *testing.T) {
+			encoded, err := encoder.EncodeTier(tier, "password123")
+			if err != nil {
+				t.Fatalf("EncodeTier() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, "{"+tier+"}") {
+				t.Errorf("EncodeTier() = %v, want tier prefix", encoded)
+			}
+
+			if match, err := encoder.Verify("password123", encoded); err != nil || !match {
+				t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+			}
+			if match, _ := encoder.Verify("password124", encoded); match {
+				t.Errorf("Verify() = true for wrong password")
+			}
+		})
+	}
+
+	encoded, _ := encoder.Encode("password123")
+	if tier, _ := encoder.TierOf(encoded); tier != TierInteractive {
+		t.Errorf("Encode() used tier %v, want %v", tier, TierInteractive)
+	}
+
+	if _, err := encoder.EncodeTier("guest", "password123"); !errors.Is(err, ErrUnknownTier) {
+		t.Errorf("EncodeTier() error = %v, want ErrUnknownTier", err)
+	}
+	if _, err := encoder.Verify("password123", "{guest}password123"); !errors.Is(err, ErrUnknownTier) {
+		t.Errorf("Verify() error = %v, want ErrUnknownTier", err)
+	}
+}
+
+func TestTieredEncoder_NeedsRehash(t *testing.T) {
+	encoder := newTestTieredEncoder(t)
+	encoded, _ := encoder.EncodeTier(TierInteractive, "password123")
+
+	if encoder.NeedsRehash(encoded, TierInteractive) {
+		t.Errorf("NeedsRehash() = true for the same tier")
+	}
+	if !encoder.NeedsRehash(encoded, TierAdmin) {
+		t.Errorf("NeedsRehash() = false after promotion to admin")
+	}
+	if !encoder.NeedsRehash("garbage", TierInteractive) {
+		t.Errorf("NeedsRehash() = false for malformed value")
+	}
+}
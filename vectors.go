@@ -0,0 +1,93 @@
+package passforge
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TestVector is a (password, encoded password) pair produced by a registered encoder format with its
+// default parameters. Other implementations can check byte-level compatibility by recomputing Encoded from
+// Password and Salt, or at least by verifying it.
+type TestVector struct {
+	Algorithm     string `json:"algorithm"`
+	Format        string `json:"format"` // Name the format was registered under, see RegisterTestVectors
+	Password      string `json:"password"`
+	Salt          string `json:"salt,omitempty"` // Hex of the random bytes the encoder drew
+	Encoded       string `json:"encoded"`
+	Deterministic bool   `json:"deterministic"` // False when the salt could not be injected (bcrypt)
+}
+
+// testVectorPasswords cover ASCII, empty, non-ASCII and separator characters
+var testVectorPasswords = []string{"password", "", "pässwörd-日本", "p@$$w0rd{}"}
+
+// captureReader records the bytes read from the underlying reader
+type captureReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// Read reads from the underlying reader and records the bytes
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.buf = append(c.buf, p[:n]...)
+	return n, err
+}
+
+// vectorFormat is a format registered with RegisterTestVectors
+type vectorFormat struct {
+	name          string
+	deterministic bool
+	newEncoder    func(rand io.Reader) PasswordEncoder
+}
+
+var (
+	vectorFormatsMu sync.RWMutex
+	vectorFormats   []vectorFormat
+)
+
+// RegisterTestVectors adds a format to GenerateTestVectors under name, e.g. "scrypt-mcf". newEncoder returns
+// an encoder of the format drawing every random byte from rand; deterministic is false when the encoder
+// draws its salt internally, like bcrypt. The built-in encoders register each of their formats.
+func RegisterTestVectors(name string, deterministic bool, newEncoder func(rand io.Reader) PasswordEncoder) {
+	vectorFormatsMu.Lock()
+	defer vectorFormatsMu.Unlock()
+
+	vectorFormats = append(vectorFormats, vectorFormat{name: name, deterministic: deterministic, newEncoder: newEncoder})
+}
+
+// GenerateTestVectors encodes a fixed set of passwords in every registered format, drawing salts
+// from rand. With a deterministic rand (see passforgetesting.NewDeterministicReader) the output is
+// stable across runs and platforms. bcrypt draws its salt internally, so its vectors are only
+// suitable for verification. Formats rejecting the empty password, like bcrypt-pbkdf, have no vector for it.
+func GenerateTestVectors(rand io.Reader) ([]TestVector, error) {
+	vectorFormatsMu.RLock()
+	formats := append([]vectorFormat(nil), vectorFormats...)
+	vectorFormatsMu.RUnlock()
+
+	capture := &captureReader{r: rand}
+	var vectors []TestVector
+	for _, format := range formats {
+		encoder := format.newEncoder(capture)
+		for _, password := range testVectorPasswords {
+			capture.buf = nil
+			encoded, err := encoder.Encode(password)
+			if err != nil && password == "" {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", format.name, err)
+			}
+			vectors = append(vectors, TestVector{
+				Algorithm:     encoder.Name(),
+				Format:        format.name,
+				Password:      password,
+				Salt:          hex.EncodeToString(capture.buf),
+				Encoded:       encoded,
+				Deterministic: format.deterministic,
+			})
+		}
+	}
+	return vectors, nil
+}
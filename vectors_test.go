@@ -0,0 +1,51 @@
+package passforge
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateTestVectors(t *testing.T) {
+	vectors, err := GenerateTestVectors(bytes.NewReader(bytes.Repeat([]byte{42}, 1<<16)))
+	if err != nil {
+		t.Fatalf("GenerateTestVectors() error = %v", err)
+	}
+	if len(vectors) < len(vectorFormats)*(len(testVectorPasswords)-1) {
+		t.Fatalf("GenerateTestVectors() returned %d vectors, want at least %d", len(vectors), len(vectorFormats)*(len(testVectorPasswords)-1))
+	}
+
+	generators := make(map[string]vectorFormat)
+	for _, format := range vectorFormats {
+		generators[format.name] = format
+	}
+	formats := make(map[string]bool)
+	for _, vector := range vectors {
+		formats[vector.Format] = true
+		if vector.Format == "pbkdf2" && vector.Salt != "2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a" {
+			t.Errorf("pbkdf2 vector salt = %v, want the bytes drawn from rand", vector.Salt)
+		}
+		if vector.Password != "password" {
+			continue
+		}
+
+		// Deterministic vectors are reproduced from their salt, and every vector verifies
+		salt, _ := hex.DecodeString(vector.Salt)
+		if vector.Deterministic {
+			encoded, err := generators[vector.Format].newEncoder(bytes.NewReader(salt)).Encode(vector.Password)
+			if err != nil || encoded != vector.Encoded {
+				t.Errorf("%s vector is not reproducible: %v, %v != %v", vector.Format, err, encoded, vector.Encoded)
+			}
+		}
+		if match, err := generators[vector.Format].newEncoder(nil).Verify(vector.Password, vector.Encoded); err != nil || !match {
+			t.Errorf("%s vector does not verify: %v, %v", vector.Format, match, err)
+		}
+	}
+
+	for _, format := range []string{"argon2-phc", "argon2-spring", "scrypt-phc", "scrypt-mcf", "scrypt-spring", "pbkdf2-spring",
+		"pbkdf2-passlib", "sha512-crypt", "yescrypt", "django", "scram-sha-256", "bcrypt"} {
+		if !formats[format] {
+			t.Errorf("GenerateTestVectors() has no %s vectors", format)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package passforge
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/nduyhai/passforge/internal/yescrypt"
+)
+
+// ErrYescryptMemoryLimit is returned when a yescrypt hash would need more memory than MaxMem allows
+var ErrYescryptMemoryLimit = errors.New("yescrypt: parameters exceed memory limit")
+
+// yescryptRWFlavor is the encoded flavor of the default RW flavor, the "j" of $y$j9T$
+const yescryptRWFlavor = 2 + yescrypt.RW>>2
+
+// YescryptPasswordEncoder is a password encoder producing and verifying the $y$ yescrypt hashes of
+// libxcrypt, the default of /etc/shadow on current Linux distributions. Verify accepts the classic,
+// WORM and RW flavors with any N, r, p and t; hashes using a ROM or hash upgrades are rejected.
+type YescryptPasswordEncoder struct {
+	N       uint64    // Block count, a power of two
+	R       uint32    // Block size in 128-byte units
+	SaltLen int       // Length of the salt
+	Rand    io.Reader // Source of salts, crypto/rand.Reader when nil
+	MaxMem  int64     // Upper bound for the memory used by a single hash in bytes, 0 means unbounded
+}
+
+// YescryptOption is a functional option used to configure a YescryptPasswordEncoder instance.
+type YescryptOption func(*YescryptPasswordEncoder)
+
+// WithYescryptN sets the block count, a power of two greater than 1
+// Default: 4096, as chosen by libxcrypt's default cost 5
+func WithYescryptN(n uint64) YescryptOption {
+	return func(y *YescryptPasswordEncoder) {
+		y.N = n
+	}
+}
+
+// WithYescryptR sets the block size in 128-byte units
+// Default: 32
+// Memory use is 128*N*r bytes, 16 MiB with the defaults.
+func WithYescryptR(r uint32) YescryptOption {
+	return func(y *YescryptPasswordEncoder) {
+		y.R = r
+	}
+}
+
+// WithYescryptSaltLen sets the length of the salt
+// Default: 16
+func WithYescryptSaltLen(saltLen int) YescryptOption {
+	return func(y *YescryptPasswordEncoder) {
+		y.SaltLen = saltLen
+	}
+}
+
+// WithYescryptRand sets the source of random salts
+// Default: crypto/rand.Reader
+// Only replace it to produce deterministic output in tests.
+func WithYescryptRand(r io.Reader) YescryptOption {
+	return func(y *YescryptPasswordEncoder) {
+		y.Rand = r
+	}
+}
+
+// WithYescryptMaxMem bounds the memory a single Encode or Verify may use, in bytes
+// Default: 0 (unbounded)
+// Hashes from other systems may carry any parameters; the bound keeps them from exhausting memory.
+func WithYescryptMaxMem(maxMem int64) YescryptOption {
+	return func(y *YescryptPasswordEncoder) {
+		y.MaxMem = maxMem
+	}
+}
+
+// NewYescryptPasswordEncoder creates a new YescryptPasswordEncoder with default parameters if not specified
+func NewYescryptPasswordEncoder(opts ...YescryptOption) *YescryptPasswordEncoder {
+	encoder := &YescryptPasswordEncoder{
+		N:       4096,
+		R:       32,
+		SaltLen: 16,
+	}
+	for _, opt := range opts {
+		opt(encoder)
+	}
+	return encoder
+}
+
+// Encode hashes the raw password with the RW flavor, e.g. $y$j9T$SALT$HASH
+func (y *YescryptPasswordEncoder) Encode(rawPassword string) (string, error) {
+	params := yescrypt.Params{Flavor: yescrypt.RW, N: y.N, R: y.R, P: 1}
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	if err := y.checkMemory(params); err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, y.SaltLen)
+	if _, err := io.ReadFull(randReader(y.Rand), salt); err != nil {
+		return "", err
+	}
+	hash, err := yescrypt.Key([]byte(rawPassword), salt, params, 32)
+	if err != nil {
+		return "", err
+	}
+
+	setting := "$y$" + encodeYescryptUint32(yescryptRWFlavor, 0) +
+		encodeYescryptUint32(uint32(bits.TrailingZeros64(y.N)), 1) + encodeYescryptUint32(y.R, 1)
+	return setting + "$" + encodeCrypt64(salt) + "$" + encodeCrypt64(hash), nil
+}
+
+// Verify checks if the raw password matches the encoded password
+func (y *YescryptPasswordEncoder) Verify(rawPassword, encodedPassword string) (bool, error) {
+	stored, err := parseYescrypt(encodedPassword)
+	if err != nil {
+		return false, err
+	}
+	if err := y.checkMemory(stored.params); err != nil {
+		return false, err
+	}
+
+	computedHash, err := yescrypt.Key([]byte(rawPassword), stored.salt, stored.params, len(stored.hash))
+	if err != nil {
+		return false, newFormatError("yescrypt", "invalid parameters", encodedPassword)
+	}
+	return subtle.ConstantTimeCompare(stored.hash, computedHash) == 1, nil
+}
+
+// ValidateEncoded checks the encoded password against the yescrypt parameter constraints and MaxMem without verifying it
+func (y *YescryptPasswordEncoder) ValidateEncoded(encodedPassword string) error {
+	stored, err := parseYescrypt(encodedPassword)
+	if err != nil {
+		return err
+	}
+	if err := y.checkMemory(stored.params); err != nil {
+		return err
+	}
+	if len(stored.salt) < minSaltLen {
+		return newFormatError("yescrypt", "salt too short", encodedPassword)
+	}
+	return nil
+}
+
+// checkMemory reports whether the parameters fit within MaxMem
+func (y *YescryptPasswordEncoder) checkMemory(params yescrypt.Params) error {
+	if needed := params.Memory(); y.MaxMem > 0 && needed > y.MaxMem {
+		return fmt.Errorf("%w: %d bytes needed, limit is %d", ErrYescryptMemoryLimit, needed, y.MaxMem)
+	}
+	return nil
+}
+
+func init() {
+	RegisterHashFormat(&YescryptPasswordEncoder{})
+	RegisterTestVectors("yescrypt", true, func(rand io.Reader) PasswordEncoder {
+		return NewYescryptPasswordEncoder(WithYescryptRand(rand))
+	})
+}
+
+// Identify reports whether the encoded password is a yescrypt hash
+func (y *YescryptPasswordEncoder) Identify(encodedPassword string) bool {
+	return strings.HasPrefix(encodedPassword, "$y$")
+}
+
+// EncodedParams returns the decoded N, r, p and t of the hash
+func (y *YescryptPasswordEncoder) EncodedParams(encodedPassword string) map[string]string {
+	stored, err := parseYescrypt(encodedPassword)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{
+		"N": strconv.FormatUint(stored.params.N, 10),
+		"r": strconv.FormatUint(uint64(stored.params.R), 10),
+		"p": strconv.FormatUint(uint64(stored.params.P), 10),
+		"t": strconv.FormatUint(uint64(stored.params.T), 10),
+	}
+}
+
+// AdminStatus reports the yescrypt cost parameters
+func (y *YescryptPasswordEncoder) AdminStatus(_ func(PasswordEncoder) EncoderStatus) EncoderStatus {
+	return EncoderStatus{Params: map[string]interface{}{"N": y.N, "r": y.R, "saltLen": y.SaltLen, "maxMem": y.MaxMem}}
+}
+
+// Name returns the name of the encoder.
+func (y *YescryptPasswordEncoder) Name() string {
+	return "yescrypt"
+}
+
+// yescryptHash is a parsed yescrypt encoded password
+type yescryptHash struct {
+	params     yescrypt.Params
+	salt, hash []byte
+}
+
+// parseYescrypt parses an encoded password of the form $y$PARAMS$SALT$HASH. PARAMS holds the flavor,
+// log2(N) and r, optionally followed by a bit set announcing p, t, g and the ROM size.
+func parseYescrypt(encodedPassword string) (*yescryptHash, error) {
+	parts := strings.Split(encodedPassword, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != "y" {
+		return nil, newFormatError("yescrypt", "invalid encoded password format", encodedPassword)
+	}
+
+	stored := &yescryptHash{params: yescrypt.Params{P: 1}}
+	src := parts[2]
+	var flavor, logN, have uint32
+	ok := decodeYescryptUint32(&src, &flavor, 0) && decodeYescryptUint32(&src, &logN, 1) &&
+		decodeYescryptUint32(&src, &stored.params.R, 1)
+	if ok && src != "" {
+		ok = decodeYescryptUint32(&src, &have, 1)
+		if ok && have&1 != 0 {
+			ok = decodeYescryptUint32(&src, &stored.params.P, 2)
+		}
+		if ok && have&2 != 0 {
+			ok = decodeYescryptUint32(&src, &stored.params.T, 1)
+		}
+		if have&^3 != 0 {
+			// Hash upgrades (g) and ROMs are not supported
+			return nil, newFormatError("yescrypt", "unsupported parameters", encodedPassword)
+		}
+	}
+	if !ok || src != "" || logN > 63 {
+		return nil, newFormatError("yescrypt", "invalid parameter format", encodedPassword)
+	}
+	stored.params.N = 1 << logN
+
+	switch {
+	case flavor < 2:
+		stored.params.Flavor = flavor
+	case flavor == yescryptRWFlavor:
+		stored.params.Flavor = yescrypt.RW
+	default:
+		return nil, newFormatError("yescrypt", "unsupported flavor", encodedPassword)
+	}
+	if err := stored.params.Validate(); err != nil {
+		return nil, newFormatError("yescrypt", "invalid parameters", encodedPassword)
+	}
+
+	stored.salt, ok = decodeCrypt64(parts[3])
+	if !ok {
+		return nil, newFormatError("yescrypt", "invalid salt encoding", encodedPassword)
+	}
+	stored.hash, ok = decodeCrypt64(parts[4])
+	if !ok || len(stored.hash) != 32 {
+		return nil, newFormatError("yescrypt", "invalid hash encoding", encodedPassword)
+	}
+	return stored, nil
+}
+
+// encodeYescryptUint32 encodes value-offset in yescrypt's variable-length encoding: values below 48
+// take one character, larger ones a prefix character followed by 6 bits per further character
+func encodeYescryptUint32(value, offset uint32) string {
+	value -= offset
+	start, end, chars, width := uint32(0), uint32(47), 1, uint32(0)
+	for {
+		count := (end + 1 - start) << width
+		if value < count {
+			break
+		}
+		start = end + 1
+		end = start + (62-end)/2
+		value -= count
+		chars++
+		width += 6
+	}
+
+	dst := []byte{crypt64[start+value>>width]}
+	for ; chars > 1; chars-- {
+		width -= 6
+		dst = append(dst, crypt64[value>>width&0x3f])
+	}
+	return string(dst)
+}
+
+// decodeYescryptUint32 decodes a value written by encodeYescryptUint32 from the start of src and advances src
+func decodeYescryptUint32(src *string, dst *uint32, offset uint32) bool {
+	if *src == "" {
+		return false
+	}
+	c := strings.IndexByte(crypt64, (*src)[0])
+	if c < 0 {
+		return false
+	}
+	*src = (*src)[1:]
+
+	value := uint64(offset)
+	start, end, chars, width := uint32(0), uint32(47), 1, uint32(0)
+	for uint32(c) > end {
+		value += uint64(end+1-start) << width
+		start = end + 1
+		end = start + (62-end)/2
+		chars++
+		width += 6
+	}
+	value += uint64(uint32(c)-start) << width
+
+	for ; chars > 1; chars-- {
+		if *src == "" {
+			return false
+		}
+		c := strings.IndexByte(crypt64, (*src)[0])
+		if c < 0 {
+			return false
+		}
+		*src = (*src)[1:]
+		width -= 6
+		value += uint64(c) << width
+	}
+	if value > 1<<32-1 {
+		return false
+	}
+	*dst = uint32(value)
+	return true
+}
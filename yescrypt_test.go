@@ -0,0 +1,95 @@
+package passforge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestYescryptPasswordEncoder_Encode(t *testing.T) {
+	encoder := NewYescryptPasswordEncoder(WithYescryptN(1024), WithYescryptR(8), WithYescryptRand(bytes.NewReader([]byte("0123456789abcdef"))))
+
+	encoded, err := encoder.Encode("password123")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$y$j75$") || len(encoded) != len("$y$j75$")+22+1+43 {
+		t.Errorf("Encode() = %v, want a $y$j75$ hash with a 22-character salt", encoded)
+	}
+	if match, err := NewYescryptPasswordEncoder().Verify("password123", encoded); err != nil || !match {
+		t.Errorf("Verify() = %v, %v, want true, nil", match, err)
+	}
+	if err := encoder.ValidateEncoded(encoded); err != nil {
+		t.Errorf("ValidateEncoded() error = %v", err)
+	}
+}
+
+func TestYescryptPasswordEncoder_Verify(t *testing.T) {
+	encoder := NewYescryptPasswordEncoder()
+
+	// Hashes produced by libxcrypt's crypt(3)
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  bool
+	}{
+		{"default cost with pre-hashing", "password", "$y$j9T$saltsaltsaltsalt$Uxvkjnhdr/2B6SINV1mXACdXVbd5kc899ms5aqhxMQD", true, false},
+		{"rw", "password", "$y$j75$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", true, false},
+		{"rw p=2", "password", "$y$j75..$saltsaltsaltsalt$m89vc6HyWxsfkkH188K53LeyR7/KCYBs.jfnq610747", true, false},
+		{"rw t=1", "password", "$y$j75/.$saltsaltsaltsalt$sFYLocQqUtAxuWjBNStLIcLA4LRy9tBVhDBqtZfP/a5", true, false},
+		{"rw p=2 t=2", "password", "$y$j750./$saltsaltsaltsalt$cpY77WS/XTQdMxGXy7Z4efKAdAIfMr8gWVXXk7md5lA", true, false},
+		{"classic scrypt", "password", "$y$.75$saltsaltsaltsalt$htRE.RgnnyJvLmIBls.xMqAJWqQLqZrpM8.Zksar5H.", true, false},
+		{"worm", "password", "$y$/75$saltsaltsaltsalt$ajDS8nt1YQa5qYzZoBxnzBW5fej7mTblixS1htlz7L4", true, false},
+		{"worm t=1", "password", "$y$/75/.$saltsaltsaltsalt$h9QvX3ns4dTP8yBm7bnIiPTcIy/i6gypr9lg70tU5b1", true, false},
+		{"empty salt", "password", "$y$jA5$$VaIuGczafzbzEpwNSxcR/f2zlPymFSiLtwkI5oHTQFC", true, false},
+		{"empty password", "", "$y$j75$saltsaltsaltsalt$be0TEvbyQ3RpHnd6nIEtraxfwH8NQ7boFpMFINFVi83", true, false},
+		{"unicode password", "pässwörd", "$y$j75$saltsaltsaltsalt$sUvjWaMaA0INyO4LaQ02oFHiO9pSSapsACS2qRRWY24", true, false},
+		{"wrong password", "wrong", "$y$j75$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", false, false},
+		{"unsupported flavor", "password", "$y$k75$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", false, true},
+		{"truncated hash", "password", "$y$j75$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J", false, true},
+		{"invalid salt", "password", "$y$j75$/$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", false, true},
+		{"missing r", "password", "$y$j7$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", false, true},
+		{"rom", "password", "$y$j756.$saltsaltsaltsalt$hI02SdBpr3mSssvBRd05Dwe0nTFc/hsy01KTxh646J.", false, true},
+		{"not yescrypt", "password", "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := encoder.Verify(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr || match != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", match, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestYescryptPasswordEncoder_MaxMem(t *testing.T) {
+	encoder := NewYescryptPasswordEncoder(WithYescryptMaxMem(8 << 20))
+
+	_, err := encoder.Verify("password", "$y$j9T$saltsaltsaltsalt$Uxvkjnhdr/2B6SINV1mXACdXVbd5kc899ms5aqhxMQD")
+	if !errors.Is(err, ErrYescryptMemoryLimit) {
+		t.Errorf("Verify() error = %v, want ErrYescryptMemoryLimit", err)
+	}
+	if _, err := encoder.Encode("password"); !errors.Is(err, ErrYescryptMemoryLimit) {
+		t.Errorf("Encode() error = %v, want ErrYescryptMemoryLimit", err)
+	}
+}
+
+func TestYescryptParamsEncoding(t *testing.T) {
+	for _, value := range []uint32{0, 1, 47, 48, 100, 1 << 10, 1 << 20, 1<<30 - 1} {
+		encoded := encodeYescryptUint32(value, 0)
+		src := encoded
+		var decoded uint32
+		if !decodeYescryptUint32(&src, &decoded, 0) || decoded != value || src != "" {
+			t.Errorf("round trip of %d via %q = %d, remaining %q", value, encoded, decoded, src)
+		}
+	}
+}
+
+func TestYescryptPasswordEncoder_Name(t *testing.T) {
+	if got := NewYescryptPasswordEncoder().Name(); got != "yescrypt" {
+		t.Errorf("Name() = %v, want yescrypt", got)
+	}
+}